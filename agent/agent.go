@@ -36,14 +36,15 @@ type Agent struct {
 	registry registry.Registry
 	um       unit.UnitManager
 	uGen     *unit.UnitStateGenerator
+	health   *HealthMonitor
 	Machine  machine.Machine
 	ttl      time.Duration
 
 	cache *agentCache
 }
 
-func New(mgr unit.UnitManager, uGen *unit.UnitStateGenerator, reg registry.Registry, mach machine.Machine, ttl time.Duration) *Agent {
-	return &Agent{reg, mgr, uGen, mach, ttl, &agentCache{}}
+func New(mgr unit.UnitManager, uGen *unit.UnitStateGenerator, health *HealthMonitor, reg registry.Registry, mach machine.Machine, ttl time.Duration) *Agent {
+	return &Agent{reg, mgr, uGen, health, mach, ttl, &agentCache{}}
 }
 
 func (a *Agent) MarshalJSON() ([]byte, error) {
@@ -97,7 +98,8 @@ func (a *Agent) reloadUnitFiles() error {
 func (a *Agent) loadUnit(u *job.Unit) error {
 	a.cache.setTargetState(u.Name, job.JobStateLoaded)
 	a.uGen.Subscribe(u.Name)
-	return a.um.Load(u.Name, u.Unit)
+	a.health.Watch(u.Name, u.HealthChecks())
+	return a.um.Load(u.Name, u.Unit, u.DropIns)
 }
 
 func (a *Agent) unloadUnit(unitName string) error {
@@ -112,6 +114,7 @@ func (a *Agent) unloadUnit(unitName string) error {
 	}
 
 	a.uGen.Unsubscribe(unitName)
+	a.health.Unwatch(unitName)
 
 	// unit should be unloaded and unit file should be removed, only if the unit
 	// could be successfully stopped. Otherwise the unit could get into a state
@@ -142,8 +145,9 @@ func (a *Agent) stopUnit(unitName string) error {
 }
 
 type unitState struct {
-	state job.JobState
-	hash  string
+	state       job.JobState
+	hash        string
+	dropInsHash string
 }
 type unitStates map[string]unitState
 
@@ -183,8 +187,9 @@ func (a *Agent) units() (unitStates, error) {
 			js = job.JobStateLaunched
 		}
 		us := unitState{
-			state: js,
-			hash:  uState.UnitHash,
+			state:       js,
+			hash:        uState.UnitHash,
+			dropInsHash: uState.DropInsHash,
 		}
 		states[uName] = us
 	}
@@ -60,10 +60,10 @@ func newTestJobWithXFleetValues(t *testing.T, metadata string) *job.Job {
 
 func TestAgentLoadUnloadUnit(t *testing.T) {
 	uManager := unit.NewFakeUnitManager()
-	usGenerator := unit.NewUnitStateGenerator(uManager)
+	usGenerator := unit.NewUnitStateGenerator(uManager, nil)
 	fReg := registry.NewFakeRegistry()
 	mach := &machine.FakeMachine{MachineState: machine.MachineState{ID: "XXX"}}
-	a := New(uManager, usGenerator, fReg, mach, time.Second)
+	a := New(uManager, usGenerator, NewHealthMonitor(), fReg, mach, time.Second)
 
 	u := newTestUnitFromUnitContents(t, "foo.service", "")
 	err := a.loadUnit(u)
@@ -105,10 +105,10 @@ func TestAgentLoadUnloadUnit(t *testing.T) {
 
 func TestAgentLoadStartStopUnit(t *testing.T) {
 	uManager := unit.NewFakeUnitManager()
-	usGenerator := unit.NewUnitStateGenerator(uManager)
+	usGenerator := unit.NewUnitStateGenerator(uManager, nil)
 	fReg := registry.NewFakeRegistry()
 	mach := &machine.FakeMachine{MachineState: machine.MachineState{ID: "XXX"}}
-	a := New(uManager, usGenerator, fReg, mach, time.Second)
+	a := New(uManager, usGenerator, NewHealthMonitor(), fReg, mach, time.Second)
 
 	u := newTestUnitFromUnitContents(t, "foo.service", "")
 
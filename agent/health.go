@@ -0,0 +1,315 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/unit"
+)
+
+var (
+	// files user and group names of health check commands are resolved
+	// from
+	passwdFile = "/etc/passwd"
+	groupFile  = "/etc/group"
+
+	errHealthCheckRedirect = errors.New("redirect")
+)
+
+const (
+	// how often the health checks of each unit are run
+	healthCheckInterval = 10 * time.Second
+	// how long a single health check may take before it fails
+	healthCheckTimeout = 5 * time.Second
+)
+
+func NewHealthMonitor() *HealthMonitor {
+	return &HealthMonitor{
+		checks: make(map[string][]job.HealthCheck),
+		health: make(map[string]string),
+		probe:  probeHealthCheck,
+	}
+}
+
+// HealthMonitor periodically runs the health checks declared by the units
+// loaded by the local agent. It reports their outcome to the
+// UnitStateGenerator, which publishes it along with the state of each unit.
+type HealthMonitor struct {
+	mutex  sync.RWMutex
+	checks map[string][]job.HealthCheck
+	health map[string]string
+
+	probe func(job.HealthCheck) error
+}
+
+func (m *HealthMonitor) MarshalJSON() ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	data := struct {
+		Health map[string]string
+	}{
+		Health: m.health,
+	}
+	return json.Marshal(data)
+}
+
+// Watch starts monitoring the named unit with the given health checks,
+// replacing any health checks it was monitored with before. A unit without
+// health checks is not monitored.
+func (m *HealthMonitor) Watch(name string, checks []job.HealthCheck) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(checks) == 0 {
+		delete(m.checks, name)
+		delete(m.health, name)
+		return
+	}
+
+	if !reflect.DeepEqual(m.checks[name], checks) {
+		m.checks[name] = checks
+		delete(m.health, name)
+	}
+}
+
+// Unwatch stops monitoring the named unit.
+func (m *HealthMonitor) Unwatch(name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.checks, name)
+	delete(m.health, name)
+}
+
+// Health returns unit.UnitHealthy or unit.UnitUnhealthy according to the
+// last run of the health checks of the named unit, or an empty string if the
+// unit is not monitored or its health checks have not run yet.
+func (m *HealthMonitor) Health(name string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.health[name]
+}
+
+// Run runs the health checks of all monitored units every
+// healthCheckInterval until the stop channel is closed.
+func (m *HealthMonitor) Run(stop <-chan struct{}) {
+	tick := time.Tick(healthCheckInterval)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick:
+			m.check()
+		}
+	}
+}
+
+// check runs the health checks of all monitored units concurrently and
+// records their outcome.
+func (m *HealthMonitor) check() {
+	m.mutex.RLock()
+	checks := make(map[string][]job.HealthCheck, len(m.checks))
+	for name, c := range m.checks {
+		checks[name] = c
+	}
+	m.mutex.RUnlock()
+
+	var wg sync.WaitGroup
+	var resultsMutex sync.Mutex
+	results := make(map[string]string, len(checks))
+	for name, c := range checks {
+		wg.Add(1)
+		go func(name string, c []job.HealthCheck) {
+			defer wg.Done()
+
+			health := unit.UnitHealthy
+			for _, hc := range c {
+				if err := m.probe(hc); err != nil {
+					log.Debugf("Health check %s %q of unit(%s) failed: %v", hc.Type, hc.Target, name, err)
+					health = unit.UnitUnhealthy
+					break
+				}
+			}
+
+			resultsMutex.Lock()
+			results[name] = health
+			resultsMutex.Unlock()
+		}(name, c)
+	}
+	wg.Wait()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for name, health := range results {
+		// The unit may have been unwatched or changed its health checks
+		// while they were running
+		if reflect.DeepEqual(m.checks[name], checks[name]) {
+			m.health[name] = health
+		}
+	}
+}
+
+// probeHealthCheck runs the given health check, returning an error if it
+// fails.
+func probeHealthCheck(hc job.HealthCheck) error {
+	switch hc.Type {
+	case job.HealthCheckHTTP:
+		client := http.Client{
+			Timeout: healthCheckTimeout,
+			// a redirect passes the check rather than being followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return errHealthCheckRedirect
+			},
+		}
+		resp, err := client.Get(hc.Target)
+		if uerr, ok := err.(*url.Error); ok && uerr.Err == errHealthCheckRedirect {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	case job.HealthCheckTCP:
+		conn, err := net.DialTimeout("tcp", hc.Target, healthCheckTimeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case job.HealthCheckExec:
+		return runHealthCheckCommand(hc)
+	}
+	return fmt.Errorf("unknown health check type %q", hc.Type)
+}
+
+// runHealthCheckCommand runs the command of an exec health check with
+// /bin/sh as the user and group of its unit. The command runs in a process
+// group of its own, which is killed as a whole if the command does not exit
+// within healthCheckTimeout.
+func runHealthCheckCommand(hc job.HealthCheck) error {
+	cred, err := healthCheckCredential(hc.User, hc.Group)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", hc.Target)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Credential: cred}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(healthCheckTimeout):
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return fmt.Errorf("timed out after %v", healthCheckTimeout)
+	}
+}
+
+// healthCheckCredential returns the credential to run a health check command
+// with for the given User= and Group= of a unit, or nil if both are empty
+// and the command runs as fleetd itself. Names are resolved from passwdFile
+// and groupFile, as systemd would resolve them for a system unit.
+func healthCheckCredential(user, group string) (*syscall.Credential, error) {
+	if user == "" && group == "" {
+		return nil, nil
+	}
+
+	cred := &syscall.Credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid())}
+	if user != "" {
+		entry, err := lookupEntry(passwdFile, user)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve user %q: %v", user, err)
+		}
+		if cred.Uid, err = parseID(entry[2]); err != nil {
+			return nil, fmt.Errorf("invalid entry of user %q: %v", user, err)
+		}
+		if cred.Gid, err = parseID(entry[3]); err != nil {
+			return nil, fmt.Errorf("invalid entry of user %q: %v", user, err)
+		}
+	}
+	if group != "" {
+		entry, err := lookupEntry(groupFile, group)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve group %q: %v", group, err)
+		}
+		if cred.Gid, err = parseID(entry[2]); err != nil {
+			return nil, fmt.Errorf("invalid entry of group %q: %v", group, err)
+		}
+	}
+	return cred, nil
+}
+
+// lookupEntry returns the fields of the entry of the given name or numeric
+// ID in a file in the format of /etc/passwd or /etc/group. As os/user cannot
+// be used without cgo, the file is parsed directly.
+func lookupEntry(file, name string) ([]string, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var byID []string
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 4 {
+			continue
+		}
+		if fields[0] == name {
+			return fields, nil
+		}
+		if fields[2] == name && byID == nil {
+			byID = fields
+		}
+	}
+	if byID != nil {
+		return byID, nil
+	}
+	return nil, fmt.Errorf("no entry in %s", file)
+}
+
+func parseID(s string) (uint32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	return uint32(id), err
+}
@@ -0,0 +1,186 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	m := NewHealthMonitor()
+	m.probe = func(hc job.HealthCheck) error {
+		if hc.Target == "bad" {
+			return errors.New("unhealthy")
+		}
+		return nil
+	}
+
+	good := job.HealthCheck{Type: job.HealthCheckExec, Target: "good"}
+	bad := job.HealthCheck{Type: job.HealthCheckExec, Target: "bad"}
+
+	m.Watch("foo.service", []job.HealthCheck{good})
+	m.Watch("bar.service", []job.HealthCheck{good, bad})
+	m.Watch("baz.service", nil)
+
+	for name, want := range map[string]string{"foo.service": "", "bar.service": "", "baz.service": ""} {
+		if got := m.Health(name); got != want {
+			t.Errorf("before running health checks: expected health %q for %s, got %q", want, name, got)
+		}
+	}
+
+	m.check()
+	for name, want := range map[string]string{"foo.service": unit.UnitHealthy, "bar.service": unit.UnitUnhealthy, "baz.service": ""} {
+		if got := m.Health(name); got != want {
+			t.Errorf("after running health checks: expected health %q for %s, got %q", want, name, got)
+		}
+	}
+
+	// Changing the health checks of a unit forgets its health, while
+	// watching it with the same health checks does not
+	m.Watch("foo.service", []job.HealthCheck{bad})
+	m.Watch("bar.service", []job.HealthCheck{good, bad})
+	m.Unwatch("baz.service")
+	for name, want := range map[string]string{"foo.service": "", "bar.service": unit.UnitUnhealthy} {
+		if got := m.Health(name); got != want {
+			t.Errorf("after changing health checks: expected health %q for %s, got %q", want, name, got)
+		}
+	}
+
+	m.Unwatch("bar.service")
+	m.check()
+	for name, want := range map[string]string{"foo.service": unit.UnitUnhealthy, "bar.service": ""} {
+		if got := m.Health(name); got != want {
+			t.Errorf("after unwatching: expected health %q for %s, got %q", want, name, got)
+		}
+	}
+}
+
+func TestProbeHealthCheck(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer ok.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+	// redirects are not followed, the unreachable target would fail
+	redirect := httptest.NewServer(http.RedirectHandler("http://127.0.0.1:0/", http.StatusFound))
+	defer redirect.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed listening: %v", err)
+	}
+	defer l.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed listening: %v", err)
+	}
+	closed.Close()
+
+	testCases := []struct {
+		hc      job.HealthCheck
+		healthy bool
+	}{
+		{job.HealthCheck{Type: job.HealthCheckHTTP, Target: ok.URL}, true},
+		{job.HealthCheck{Type: job.HealthCheckHTTP, Target: broken.URL}, false},
+		{job.HealthCheck{Type: job.HealthCheckHTTP, Target: redirect.URL}, true},
+		{job.HealthCheck{Type: job.HealthCheckTCP, Target: l.Addr().String()}, true},
+		{job.HealthCheck{Type: job.HealthCheckTCP, Target: closed.Addr().String()}, false},
+		{job.HealthCheck{Type: job.HealthCheckExec, Target: "exit 0"}, true},
+		{job.HealthCheck{Type: job.HealthCheckExec, Target: "exit 1"}, false},
+		{job.HealthCheck{Type: job.HealthCheckExec, Target: "exit 0", User: "no-such-user"}, false},
+	}
+	for i, tt := range testCases {
+		err := probeHealthCheck(tt.hc)
+		if (err == nil) != tt.healthy {
+			t.Errorf("case %d: bad error value (got err=%v, want healthy=%t)", i, err, tt.healthy)
+		}
+	}
+}
+
+func TestHealthCheckCredential(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet-health-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldPasswd, oldGroup := passwdFile, groupFile
+	defer func() {
+		passwdFile, groupFile = oldPasswd, oldGroup
+	}()
+	passwdFile, groupFile = path.Join(dir, "passwd"), path.Join(dir, "group")
+	ioutil.WriteFile(passwdFile, []byte("root:x:0:0:root:/root:/bin/sh\nweb:x:1000:1001:web:/home/web:/bin/sh\n"), 0644)
+	ioutil.WriteFile(groupFile, []byte("root:x:0:\nweb:x:1001:\nadm:x:4:web\n"), 0644)
+
+	testCases := []struct {
+		user  string
+		group string
+		cred  *syscall.Credential
+		ok    bool
+	}{
+		{"", "", nil, true},
+		{"web", "", &syscall.Credential{Uid: 1000, Gid: 1001}, true},
+		{"web", "adm", &syscall.Credential{Uid: 1000, Gid: 4}, true},
+		{"1000", "", &syscall.Credential{Uid: 1000, Gid: 1001}, true},
+		{"root", "4", &syscall.Credential{Uid: 0, Gid: 4}, true},
+		{"", "adm", &syscall.Credential{Uid: uint32(os.Getuid()), Gid: 4}, true},
+		{"nobody", "", nil, false},
+		{"web", "nogroup", nil, false},
+	}
+	for i, tt := range testCases {
+		cred, err := healthCheckCredential(tt.user, tt.group)
+		if (err == nil) != tt.ok {
+			t.Errorf("case %d: bad error value (got err=%v, want ok=%t)", i, err, tt.ok)
+			continue
+		}
+		if !reflect.DeepEqual(cred, tt.cred) {
+			t.Errorf("case %d: got credential %#v, want %#v", i, cred, tt.cred)
+		}
+	}
+}
+
+func TestHealthCheckCommandUser(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("running commands as another user requires root")
+	}
+
+	cred, err := healthCheckCredential("nobody", "")
+	if err != nil {
+		t.Skipf("no nobody user: %v", err)
+	}
+
+	hc := job.HealthCheck{Type: job.HealthCheckExec, Target: fmt.Sprintf(`test "$(id -u)" = %d`, cred.Uid), User: "nobody"}
+	if err := probeHealthCheck(hc); err != nil {
+		t.Errorf("health check did not run as nobody: %v", err)
+	}
+	hc.User = ""
+	if err := probeHealthCheck(hc); err == nil {
+		t.Errorf("health check without user did not run as root")
+	}
+}
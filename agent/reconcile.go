@@ -21,9 +21,9 @@ import (
 
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/log"
-	"github.com/coreos/fleet/machine"
 	"github.com/coreos/fleet/pkg"
 	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/unit"
 )
 
 const (
@@ -80,10 +80,49 @@ func (ar *AgentReconciler) Reconcile(a *Agent) {
 		return
 	}
 
+	completeBatchUnits(a, ar.reg, dAgentState, cAgentState)
+
 	tasks := ar.calculateTasksForUnits(dAgentState, cAgentState)
 	ar.launchTasks(tasks, a)
 }
 
+// completeBatchUnits records in the Registry which of the batch units
+// launched by the Agent ran to completion. The desired state of such units
+// is lowered to loaded so that they are not started again.
+func completeBatchUnits(a *Agent, reg registry.Registry, dState *AgentState, cState unitStates) {
+	for name, u := range dState.Units {
+		if !u.IsBatch() || u.TargetState != job.JobStateLaunched {
+			continue
+		}
+		if us, ok := cState[name]; !ok || us.state != job.JobStateLaunched {
+			continue
+		}
+
+		done, err := a.um.UnitCompleted(name)
+		if err != nil {
+			log.Errorf("Unable to determine whether Unit(%s) completed: %v", name, err)
+			continue
+		}
+		if !done {
+			continue
+		}
+
+		c := job.Completion{
+			MachineID: a.Machine.State().ID,
+			Time:      time.Now(),
+		}
+		if err := reg.SetUnitCompleted(name, c); err != nil {
+			log.Errorf("Failed recording completion of Unit(%s): %v", name, err)
+			continue
+		}
+		log.Infof("Unit(%s) completed", name)
+
+		completed := *u
+		completed.TargetState = job.JobStateLoaded
+		dState.Units[name] = &completed
+	}
+}
+
 // Purge attempts to unload all Units that have been loaded locally
 func (ar *AgentReconciler) Purge(a *Agent) {
 	for {
@@ -127,6 +166,18 @@ func desiredAgentState(a *Agent, reg registry.Registry) (*AgentState, error) {
 		return nil, err
 	}
 
+	completions, err := reg.CompletedUnits()
+	if err != nil {
+		log.Errorf("Failed fetching completed Units from Registry: %v", err)
+		return nil, err
+	}
+
+	dropIns, err := reg.DropIns()
+	if err != nil {
+		log.Errorf("Failed fetching drop-ins from Registry: %v", err)
+		return nil, err
+	}
+
 	// fetch full machine state from registry instead of
 	// using the local version to allow for dynamic metadata
 	ms, err := reg.MachineState(a.Machine.State().ID)
@@ -145,31 +196,51 @@ func desiredAgentState(a *Agent, reg registry.Registry) (*AgentState, error) {
 		sUnitMap[sUnit.Name] = &sUnit
 	}
 
+	// Units scheduled here by the engine take precedence over global
+	// units, so they are considered first when resolving conflicts.
+	var gUnits []job.Unit
 	for _, u := range units {
 		u := u
-		md := u.RequiredTargetMetadata()
-
 		if u.IsGlobal() {
-			if !machine.HasMetadata(&ms, md) {
-				log.Debugf("Agent unable to run global unit %s: missing required metadata", u.Name)
-				continue
-			}
+			gUnits = append(gUnits, u)
+			continue
 		}
 
-		if !u.IsGlobal() {
-			sUnit, ok := sUnitMap[u.Name]
-			if !ok || sUnit.TargetMachineID == "" || sUnit.TargetMachineID != ms.ID {
-				continue
-			}
+		sUnit, ok := sUnitMap[u.Name]
+		if !ok || sUnit.TargetMachineID == "" || sUnit.TargetMachineID != ms.ID {
+			continue
 		}
 
 		if cExists, _ := as.HasConflict(u.Name, u.Conflicts()); cExists {
 			continue
 		}
 
+		// completed batch units stay loaded but are not started again
+		if _, ok := completions[u.Name]; ok && u.IsBatch() && u.TargetState == job.JobStateLaunched {
+			u.TargetState = job.JobStateLoaded
+		}
+
+		as.Units[u.Name] = &u
+	}
+
+	for _, u := range gUnits {
+		u := u
+		if ok, reason := as.AbleToRunGlobal(&u); !ok {
+			log.Debugf("Agent unable to run global unit %s: %s", u.Name, reason)
+			continue
+		}
+
 		as.Units[u.Name] = &u
 	}
 
+	for _, u := range as.Units {
+		for _, d := range dropIns {
+			if d.AppliesTo(u.Name, ms.Metadata) {
+				u.DropIns = append(u.DropIns, d)
+			}
+		}
+	}
+
 	return &as, nil
 }
 
@@ -213,18 +284,20 @@ func (ar *AgentReconciler) calculateTasksForUnits(dState *AgentState, cState uni
 
 func (ar *AgentReconciler) calculateTasksForUnit(dState *AgentState, cState unitStates, jName string) (tasks []task) {
 	var dJob *job.Unit
-	var dJHash string
+	var dJHash, dDropInsHash string
 	if dState != nil {
 		dJob = dState.Units[jName]
 		if dJob != nil {
 			dJHash = dJob.Unit.Hash().String()
+			dDropInsHash = unit.HashDropIns(dJob.DropIns)
 		}
 	}
 	var cJState *job.JobState
-	var cJHash string
+	var cJHash, cDropInsHash string
 	if us, ok := cState[jName]; ok {
 		cJState = &us.state
 		cJHash = us.hash
+		cDropInsHash = us.dropInsHash
 	}
 	if dJob == nil && cJState == nil {
 		log.Errorf("Desired state and current state of Job(%s) nil, not sure what to do", jName)
@@ -248,6 +321,7 @@ func (ar *AgentReconciler) calculateTasksForUnit(dState *AgentState, cState unit
 	}
 
 	u.Unit = dJob.Unit
+	u.DropIns = dJob.DropIns
 
 	if cJState == nil {
 		tasks = append(tasks, task{
@@ -268,8 +342,8 @@ func (ar *AgentReconciler) calculateTasksForUnit(dState *AgentState, cState unit
 		return
 	}
 
-	if cJHash != dJHash {
-		log.Debugf("Desired hash %q differs to current hash %s of Job(%s) - unloading", dJHash, cJHash, jName)
+	if cJHash != dJHash || cDropInsHash != dDropInsHash {
+		log.Debugf("Desired hash %q differs to current hash %s of Job(%s), or drop-ins changed - unloading", dJHash, cJHash, jName)
 		// queue the correct unit for loading immediately after unloading the old one
 		tasks = append(tasks,
 			task{
@@ -16,6 +16,7 @@ package agent
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/coreos/fleet/job"
@@ -272,6 +273,191 @@ MachineMetadata=dog=woof`),
 	}
 }
 
+func TestDesiredAgentStateDropIns(t *testing.T) {
+	env := unit.DropIn{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"}
+	west := unit.DropIn{Unit: "foo.service", Name: "20-west.conf", Metadata: map[string]string{"region": "us-west"}}
+	east := unit.DropIn{Unit: "foo.service", Name: "20-east.conf", Metadata: map[string]string{"region": "us-east"}}
+	web := unit.DropIn{Unit: "web@.service", Name: "10-limits.conf"}
+	other := unit.DropIn{Unit: "bar.service", Name: "10-env.conf"}
+
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs([]job.Job{
+		{Name: "foo.service", Unit: newUF(t, "blah"), TargetMachineID: "this_machine"},
+		{Name: "web@1.service", Unit: newUF(t, "blah"), TargetMachineID: "this_machine"},
+		{Name: "baz.service", Unit: newUF(t, "blah"), TargetMachineID: "this_machine"},
+	})
+	for _, d := range []unit.DropIn{env, west, east, web, other} {
+		reg.SetDropIn(d)
+	}
+	a := makeAgentWithMetadata(map[string]string{"region": "us-west"})
+	reg.SetMachines([]machine.MachineState{a.Machine.State()})
+
+	as, err := desiredAgentState(a, reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string][]unit.DropIn{
+		"foo.service":   {env, west},
+		"web@1.service": {web},
+		"baz.service":   nil,
+	}
+	for name, dropIns := range want {
+		u, ok := as.Units[name]
+		if !ok {
+			t.Errorf("unit %s missing from desired state", name)
+			continue
+		}
+		if !reflect.DeepEqual(dropIns, u.DropIns) {
+			t.Errorf("unexpected drop-ins of %s\nwant %#v\ngot  %#v", name, dropIns, u.DropIns)
+		}
+	}
+}
+
+func TestDesiredAgentStateGlobalRestrictions(t *testing.T) {
+	testCases := []struct {
+		paused  []string
+		regJobs []job.Job
+		want    []string
+	}{
+		// excluded by ID
+		{
+			nil,
+			[]job.Job{
+				{Name: "global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true\nExcludeMachineID=other_machine this_machine")},
+			},
+			[]string{},
+		},
+		// excluded machines are elsewhere
+		{
+			nil,
+			[]job.Job{
+				{Name: "global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true\nExcludeMachineID=other_machine")},
+			},
+			[]string{"global.service"},
+		},
+		// paused on this machine
+		{
+			[]string{"global.service"},
+			[]job.Job{
+				{Name: "global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true")},
+				{Name: "other.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true")},
+			},
+			[]string{"other.service"},
+		},
+		// a scheduled Unit wins over a global Unit conflicting with it,
+		// whatever their names
+		{
+			nil,
+			[]job.Job{
+				{Name: "a-global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true\nConflicts=foo.service")},
+				{Name: "foo.service", Unit: newUF(t, "blah"), TargetMachineID: "this_machine"},
+			},
+			[]string{"foo.service"},
+		},
+		{
+			nil,
+			[]job.Job{
+				{Name: "foo.service", Unit: newUF(t, "[X-Fleet]\nConflicts=z-global.service"), TargetMachineID: "this_machine"},
+				{Name: "z-global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true")},
+			},
+			[]string{"foo.service"},
+		},
+		// conflicting global Units are resolved by name
+		{
+			nil,
+			[]job.Job{
+				{Name: "a-global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true")},
+				{Name: "b-global.service", Unit: newUF(t, "[X-Fleet]\nGlobal=true\nConflicts=a-global.service")},
+			},
+			[]string{"a-global.service"},
+		},
+	}
+
+	for i, tt := range testCases {
+		reg := registry.NewFakeRegistry()
+		reg.SetJobs(tt.regJobs)
+		a := makeAgentWithMetadata(nil)
+		ms := a.Machine.State()
+		ms.PausedUnits = tt.paused
+		reg.SetMachines([]machine.MachineState{ms})
+		as, err := desiredAgentState(a, reg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		got := make([]string, 0)
+		for name := range as.Units {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: expected Units %v, got %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestCompleteBatchUnits(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs([]job.Job{
+		{Name: "done.service", Unit: newUF(t, "[X-Fleet]\nType=batch"), TargetState: job.JobStateLaunched, TargetMachineID: "this_machine"},
+		{Name: "running.service", Unit: newUF(t, "[X-Fleet]\nType=batch"), TargetState: job.JobStateLaunched, TargetMachineID: "this_machine"},
+		{Name: "service.service", Unit: newUF(t, "blah"), TargetState: job.JobStateLaunched, TargetMachineID: "this_machine"},
+	})
+	a := makeAgentWithMetadata(nil)
+	reg.SetMachines([]machine.MachineState{a.Machine.State()})
+
+	um := unit.NewFakeUnitManager()
+	for _, name := range []string{"done.service", "running.service", "service.service"} {
+		um.Load(name, unit.UnitFile{}, nil)
+	}
+	um.Complete("done.service")
+	um.Complete("service.service")
+	a.um = um
+
+	dState, err := desiredAgentState(a, reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cState := unitStates{
+		"done.service":    unitState{state: job.JobStateLaunched},
+		"running.service": unitState{state: job.JobStateLaunched},
+		"service.service": unitState{state: job.JobStateLaunched},
+	}
+	completeBatchUnits(a, reg, dState, cState)
+
+	completions, err := reg.CompletedUnits()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(completions) != 1 || completions["done.service"].MachineID != "this_machine" {
+		t.Fatalf("expected only done.service to complete on this_machine, got %v", completions)
+	}
+
+	want := map[string]job.JobState{
+		"done.service":    job.JobStateLoaded,
+		"running.service": job.JobStateLaunched,
+		"service.service": job.JobStateLaunched,
+	}
+	for name, ts := range want {
+		if got := dState.Units[name].TargetState; got != ts {
+			t.Errorf("unexpected desired state of %s: got %s, want %s", name, got, ts)
+		}
+	}
+
+	// the completion is remembered by later reconciliations
+	dState, err = desiredAgentState(a, reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, ts := range want {
+		if got := dState.Units[name].TargetState; got != ts {
+			t.Errorf("unexpected desired state of %s: got %s, want %s", name, got, ts)
+		}
+	}
+}
+
 func TestAbleToRun(t *testing.T) {
 	tests := []struct {
 		dState *AgentState
@@ -299,6 +485,20 @@ func TestAbleToRun(t *testing.T) {
 			want:   job.JobActionUnschedule,
 		},
 
+		// excluded MachineID
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123"}),
+			job:    newTestJobWithXFleetValues(t, "ExcludeMachineID=XYZ 123"),
+			want:   job.JobActionUnschedule,
+		},
+
+		// other MachineID excluded
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123"}),
+			job:    newTestJobWithXFleetValues(t, "ExcludeMachineID=XYZ"),
+			want:   job.JobActionSchedule,
+		},
+
 		// match MachineMetadata
 		{
 			dState: NewAgentState(&machine.MachineState{ID: "123", Metadata: map[string]string{"region": "us-west"}}),
@@ -435,6 +635,20 @@ func TestAbleToRun(t *testing.T) {
 			job:  newTestJobWithXFleetValues(t, "Replaces=ping.service"),
 			want: job.JobActionReschedule,
 		},
+
+		// cordoned agent accepts no new units
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Cordoned: true}),
+			job:    &job.Job{Name: "easy-street.service", Unit: unit.UnitFile{}},
+			want:   job.JobActionUnschedule,
+		},
+
+		// cordoned agent keeps the units already scheduled to it
+		{
+			dState: NewAgentState(&machine.MachineState{ID: "123", Cordoned: true}),
+			job:    &job.Job{Name: "easy-street.service", Unit: unit.UnitFile{}, TargetMachineID: "123"},
+			want:   job.JobActionSchedule,
+		},
 	}
 
 	for i, tt := range tests {
@@ -549,6 +763,46 @@ func TestCalculateTasksForJob(t *testing.T) {
 			},
 		},
 
+		// when the drop-ins differ, unit should be unloaded and then reloaded with them
+		{
+			dState: &AgentState{
+				MState: &machine.MachineState{ID: "XXX"},
+				Units: map[string]*job.Unit{
+					"foo.service": &job.Unit{
+						TargetState: jsLoaded,
+						DropIns:     []unit.DropIn{{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"}},
+					},
+				},
+			},
+			cState: unitStates{
+				"foo.service": unitState{
+					state: jsLoaded,
+					hash:  emptyStringHash,
+				},
+			},
+			uName: "foo.service",
+			want: []task{
+				task{
+					typ:    taskTypeUnloadUnit,
+					reason: taskReasonLoadedButHashDiffers,
+					unit: &job.Unit{
+						Name:    "foo.service",
+						Unit:    unit.UnitFile{},
+						DropIns: []unit.DropIn{{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"}},
+					},
+				},
+				task{
+					typ:    taskTypeLoadUnit,
+					reason: taskReasonScheduledButUnloaded,
+					unit: &job.Unit{
+						Name:    "foo.service",
+						Unit:    unit.UnitFile{},
+						DropIns: []unit.DropIn{{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"}},
+					},
+				},
+			},
+		},
+
 		// when current state != desired state and hash differs, unit should be unloaded and then reloaded
 		{
 			dState: &AgentState{
@@ -17,15 +17,21 @@ package agent
 import (
 	"fmt"
 	"path"
+	"sort"
 
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/log"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/resource"
+	"github.com/coreos/fleet/unit"
 )
 
 type AgentState struct {
 	MState *machine.MachineState
 	Units  map[string]*job.Unit
+	// Blacklisted holds the names of the Units barred from the Agent's
+	// machine after failing on it repeatedly
+	Blacklisted map[string]bool
 }
 
 func NewAgentState(ms *machine.MachineState) *AgentState {
@@ -39,6 +45,52 @@ func (as *AgentState) unitScheduled(name string) bool {
 	return as.Units[name] != nil
 }
 
+// AvailableResources returns the free resources of the Agent's machine that
+// are not reserved for any locally-scheduled Unit.
+func (as *AgentState) AvailableResources() resource.ResourceTuple {
+	reserved := make([]resource.ResourceTuple, 0, len(as.Units))
+	for _, u := range as.Units {
+		reserved = append(reserved, u.Resources())
+	}
+	return resource.Sub(as.MState.FreeResources, resource.Sum(reserved...))
+}
+
+// HasResources determines whether the Agent has enough available resources
+// to additionally run the given Job. Jobs that do not declare any resource
+// requirements always fit.
+func (as *AgentState) HasResources(j *job.Job) bool {
+	req := j.Resources()
+	if req.Empty() {
+		return true
+	}
+
+	avail := as.AvailableResources()
+	return avail.Cores >= req.Cores && avail.Memory >= req.Memory && avail.Disk >= req.Disk
+}
+
+// InstancesOf returns the number of locally-scheduled Units, other than the
+// given Job itself, that are instances of the same template as the Job. Zero
+// is returned for Jobs that are not template instances.
+func (as *AgentState) InstancesOf(j *job.Job) int {
+	uni := unit.NewUnitNameInfo(j.Name)
+	if uni == nil || !uni.IsInstance() {
+		return 0
+	}
+
+	count := 0
+	for _, u := range as.Units {
+		if u.Name == j.Name {
+			continue
+		}
+
+		eUni := unit.NewUnitNameInfo(u.Name)
+		if eUni != nil && eUni.IsInstance() && eUni.Template == uni.Template {
+			count++
+		}
+	}
+	return count
+}
+
 func hasStringInSlice(inSlice []string, unitName string) bool {
 	for _, elem := range inSlice {
 		if globMatches(elem, unitName) {
@@ -77,6 +129,25 @@ func (as *AgentState) HasConflict(pUnitName string, pConflicts []string) (bool,
 	return true, conflicts
 }
 
+// ConflictingUnits returns the names of all locally-scheduled Units that
+// conflict with the given Unit, whichever side declares the conflict. The
+// names are returned in lexicographical order.
+func (as *AgentState) ConflictingUnits(pUnitName string, pConflicts []string) []string {
+	var conflicts []string
+	for _, eUnit := range as.Units {
+		if pUnitName == eUnit.Name {
+			continue
+		}
+
+		if hasStringInSlice(eUnit.Conflicts(), pUnitName) || hasStringInSlice(pConflicts, eUnit.Name) {
+			conflicts = append(conflicts, eUnit.Name)
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts
+}
+
 // hasReplace determines whether there are any known replaces with the given Unit
 func (as *AgentState) hasReplace(pUnitName string, pReplaces []string) (found bool, replace string) {
 	for _, eUnit := range as.Units {
@@ -130,16 +201,29 @@ func globMatches(pattern, target string) bool {
 // AbleToRun determines if an Agent can run the provided Job based on
 // the Agent's current state. A boolean indicating whether this is the
 // case or not is returned. The following criteria is used:
+//   - Agent must not be cordoned, unless the Job is already scheduled to it
 //   - Agent must meet the Job's machine target requirement (if any)
 //   - Agent must have all of the Job's required metadata (if any)
 //   - Agent must have all required Peers of the Job scheduled locally (if any)
 //   - Job must not conflict with any other Units scheduled to the agent
 //   - Job must specially handle replaced units to be rescheduled
 func (as *AgentState) AbleToRun(j *job.Job) (jobAction job.JobAction, errstr string) {
+	if as.MState.Cordoned && j.TargetMachineID != as.MState.ID {
+		return job.JobActionUnschedule, "agent is cordoned"
+	}
+
+	if as.Blacklisted[j.Name] {
+		return job.JobActionUnschedule, "unit is blacklisted on agent after repeated failures"
+	}
+
 	if tgt, ok := j.RequiredTarget(); ok && !as.MState.MatchID(tgt) {
 		return job.JobActionUnschedule, fmt.Sprintf("agent ID %q does not match required %q", as.MState.ID, tgt)
 	}
 
+	if excluded, ok := as.excludedBy(j.ExcludedMachineIDs()); ok {
+		return job.JobActionUnschedule, fmt.Sprintf("agent ID %q is excluded by %q", as.MState.ID, excluded)
+	}
+
 	metadata := j.RequiredTargetMetadata()
 	if len(metadata) != 0 {
 		if !machine.HasMetadata(as.MState, metadata) {
@@ -168,6 +252,79 @@ func (as *AgentState) AbleToRun(j *job.Job) (jobAction job.JobAction, errstr str
 	return job.JobActionSchedule, ""
 }
 
+// AbleToRunGlobal determines whether the Agent should run the given global
+// Unit. Global units are not scheduled, so only the Unit's metadata
+// requirements and excluded machines, the Units paused on the Agent and
+// conflicts with the Units already in the AgentState are considered. If the
+// Agent should not run the Unit, the reason is returned.
+func (as *AgentState) AbleToRunGlobal(u *job.Unit) (bool, string) {
+	if !machine.HasMetadata(as.MState, u.RequiredTargetMetadata()) {
+		return false, "missing required metadata"
+	}
+
+	if excluded, ok := as.excludedBy(u.ExcludedMachineIDs()); ok {
+		return false, fmt.Sprintf("agent ID %q is excluded by %q", as.MState.ID, excluded)
+	}
+
+	if as.MState.HasPausedUnit(u.Name) {
+		return false, "unit is paused on this agent"
+	}
+
+	if cExists, cUnits := as.HasConflict(u.Name, u.Conflicts()); cExists {
+		return false, fmt.Sprintf("found conflict with locally-scheduled Unit(%s)", cUnits[0])
+	}
+
+	return true, ""
+}
+
+// excludedBy returns the first of the given machine IDs which matches the
+// Agent, if any.
+func (as *AgentState) excludedBy(machIDs []string) (string, bool) {
+	for _, id := range machIDs {
+		if as.MState.MatchID(id) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// PreferenceScore ranks how well the Agent suits the soft requirements of the
+// provided Job. A higher score means a better match. The score is computed
+// as follows:
+//   - one point if the Agent has all of the Job's preferred metadata (if any)
+//   - one point for each of the Job's preferred peers scheduled locally
+//   - minus one point for each locally-scheduled Unit the Job avoids, or
+//     that avoids the Job
+//
+// Agents the Job has no preferences about all score zero.
+func (as *AgentState) PreferenceScore(j *job.Job) int {
+	score := 0
+
+	metadata := j.PreferredTargetMetadata()
+	if len(metadata) != 0 && machine.HasMetadata(as.MState, metadata) {
+		score++
+	}
+
+	for _, peer := range j.PreferredPeers() {
+		if as.unitScheduled(peer) {
+			score++
+		}
+	}
+
+	avoids := j.AvoidConflicts()
+	for _, eUnit := range as.Units {
+		if eUnit.Name == j.Name {
+			continue
+		}
+
+		if hasStringInSlice(avoids, eUnit.Name) || hasStringInSlice(eUnit.AvoidConflicts(), j.Name) {
+			score--
+		}
+	}
+
+	return score
+}
+
 func (as *AgentState) GetReplacedUnit(j *job.Job) (string, error) {
 	cExists, replaced := as.hasReplace(j.Name, j.Replaces())
 	if !cExists {
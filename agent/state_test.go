@@ -16,10 +16,12 @@ package agent
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/resource"
 	"github.com/coreos/fleet/unit"
 )
 
@@ -255,6 +257,196 @@ func TestHasReplaces(t *testing.T) {
 	}
 }
 
+func TestHasResources(t *testing.T) {
+	free := resource.ResourceTuple{Cores: 300, Memory: 1792, Disk: 10240}
+
+	tests := []struct {
+		cState *AgentState
+		job    *job.Job
+		want   bool
+	}{
+		// Job without requirements fits anywhere
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX"}),
+			job:    &job.Job{Name: "foo.service", Unit: unit.UnitFile{}},
+			want:   true,
+		},
+
+		// machine without reported resources cannot take a Job with requirements
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX"}),
+			job:    &job.Job{Name: "foo.service", Unit: fleetUnit(t, "Resources=memory=1")},
+			want:   false,
+		},
+
+		// Job fits within the free resources of the machine
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX", FreeResources: free}),
+			job:    &job.Job{Name: "foo.service", Unit: fleetUnit(t, "Resources=cores=300 memory=1792")},
+			want:   true,
+		},
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX", FreeResources: free}),
+			job:    &job.Job{Name: "foo.service", Unit: fleetUnit(t, "Resources=cores=301")},
+			want:   false,
+		},
+
+		// locally-scheduled Units consume resources
+		{
+			cState: &AgentState{
+				MState: &machine.MachineState{ID: "XXX", FreeResources: free},
+				Units: map[string]*job.Unit{
+					"bar.service": &job.Unit{
+						Name: "bar.service",
+						Unit: fleetUnit(t, "Resources=memory=1024"),
+					},
+				},
+			},
+			job:  &job.Job{Name: "foo.service", Unit: fleetUnit(t, "Resources=memory=1024")},
+			want: false,
+		},
+	}
+
+	for i, tt := range tests {
+		got := tt.cState.HasResources(tt.job)
+		if got != tt.want {
+			t.Errorf("case %d: HasResources returned %t, want %t", i, got, tt.want)
+		}
+	}
+}
+
+func TestPreferenceScore(t *testing.T) {
+	tests := []struct {
+		cState *AgentState
+		job    *job.Job
+		want   int
+	}{
+		// no preferences
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX"}),
+			job:    &job.Job{Name: "foo.service", Unit: unit.UnitFile{}},
+			want:   0,
+		},
+
+		// preferred metadata
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX", Metadata: map[string]string{"disk": "ssd"}}),
+			job:    &job.Job{Name: "foo.service", Unit: fleetUnit(t, "PreferMachineMetadata=disk=ssd")},
+			want:   1,
+		},
+		{
+			cState: NewAgentState(&machine.MachineState{ID: "XXX", Metadata: map[string]string{"disk": "hdd"}}),
+			job:    &job.Job{Name: "foo.service", Unit: fleetUnit(t, "PreferMachineMetadata=disk=ssd")},
+			want:   0,
+		},
+
+		// preferred peer and avoided unit, in both directions
+		{
+			cState: &AgentState{
+				MState: &machine.MachineState{ID: "XXX"},
+				Units: map[string]*job.Unit{
+					"cache.service": &job.Unit{
+						Name: "cache.service",
+						Unit: unit.UnitFile{},
+					},
+					"db@1.service": &job.Unit{
+						Name: "db@1.service",
+						Unit: unit.UnitFile{},
+					},
+					"batch.service": &job.Unit{
+						Name: "batch.service",
+						Unit: fleetUnit(t, "AvoidConflicts=foo.*"),
+					},
+				},
+			},
+			job:  &job.Job{Name: "foo.service", Unit: fleetUnit(t, "PreferMachineOf=cache.service", "AvoidConflicts=db@*")},
+			want: -1,
+		},
+	}
+
+	for i, tt := range tests {
+		got := tt.cState.PreferenceScore(tt.job)
+		if got != tt.want {
+			t.Errorf("case %d: PreferenceScore returned %d, want %d", i, got, tt.want)
+		}
+	}
+}
+
+func TestConflictingUnits(t *testing.T) {
+	cState := &AgentState{
+		MState: &machine.MachineState{ID: "XXX"},
+		Units: map[string]*job.Unit{
+			"foo.service": &job.Unit{
+				Name: "foo.service",
+				Unit: fleetUnit(t, "Conflicts=bar.service"),
+			},
+			"ping.service": &job.Unit{
+				Name: "ping.service",
+				Unit: unit.UnitFile{},
+			},
+			"pong.service": &job.Unit{
+				Name: "pong.service",
+				Unit: unit.UnitFile{},
+			},
+			"bar.service": &job.Unit{
+				Name: "bar.service",
+				Unit: fleetUnit(t, "Conflicts=bar.service"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		conflicts []string
+		want      []string
+	}{
+		{"baz.service", nil, nil},
+		{"bar.service", nil, []string{"foo.service"}},
+		{"bar.service", []string{"p*.service"}, []string{"foo.service", "ping.service", "pong.service"}},
+	}
+
+	for i, tt := range tests {
+		got := cState.ConflictingUnits(tt.name, tt.conflicts)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: ConflictingUnits returned %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestInstancesOf(t *testing.T) {
+	as := &AgentState{
+		MState: &machine.MachineState{ID: "XXX"},
+		Units: map[string]*job.Unit{
+			"foo@1.service": &job.Unit{Name: "foo@1.service"},
+			"foo@2.service": &job.Unit{Name: "foo@2.service"},
+			"foo@.service":  &job.Unit{Name: "foo@.service"},
+			"foo.service":   &job.Unit{Name: "foo.service"},
+			"bar@1.service": &job.Unit{Name: "bar@1.service"},
+			"foo@1.socket":  &job.Unit{Name: "foo@1.socket"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"foo@3.service", 2},
+		// the Job itself does not count
+		{"foo@1.service", 1},
+		{"bar@2.service", 1},
+		{"baz@1.service", 0},
+		// not a template instance
+		{"foo.service", 0},
+	}
+
+	for i, tt := range tests {
+		got := as.InstancesOf(&job.Job{Name: tt.name})
+		if got != tt.want {
+			t.Errorf("case %d: InstancesOf(%s) returned %d, want %d", i, tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestGlobMatches(t *testing.T) {
 	tests := []struct {
 		pattern  string
@@ -24,6 +24,7 @@ import (
 
 	"github.com/coreos/fleet/log"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/metrics"
 	"github.com/coreos/fleet/registry"
 	"github.com/coreos/fleet/unit"
 )
@@ -135,6 +136,7 @@ func (p *UnitStatePublisher) Run(beatchan <-chan *unit.UnitStateHeartbeat, stop
 			if p.updateCache(bt) {
 				go p.queueForPublish(bt.Name, bt.State)
 			}
+			reportUsage(bt)
 		}
 	}
 }
@@ -186,12 +188,34 @@ func (p *UnitStatePublisher) updateCache(update *unit.UnitStateHeartbeat) (chang
 	last, ok := p.cache[update.Name]
 	p.cache[update.Name] = update.State
 
-	if !ok || !reflect.DeepEqual(last, update.State) {
+	// Resource usage changes all the time, so it is only published
+	// periodically or along with other changes
+	if !ok || !reflect.DeepEqual(withoutUsage(last), withoutUsage(update.State)) {
 		changed = true
 	}
 	return
 }
 
+func withoutUsage(us *unit.UnitState) *unit.UnitState {
+	if us == nil || us.Usage == nil {
+		return us
+	}
+	stripped := *us
+	stripped.Usage = nil
+	return &stripped
+}
+
+// reportUsage exposes the resource usage reported by the given heartbeat as
+// metrics, or stops exposing it once the unit has none.
+func reportUsage(bt *unit.UnitStateHeartbeat) {
+	if bt.State == nil || bt.State.Usage == nil {
+		metrics.ForgetUnitUsage(bt.Name)
+		return
+	}
+	u := bt.State.Usage
+	metrics.ReportUnitUsage(bt.Name, u.MemoryCurrent, u.MemoryPeak, u.CPUUsageNSec, u.Tasks)
+}
+
 // Purge ensures that the UnitStates for all Units known in the
 // UnitStatePublisher's cache are removed from the registry.
 func (p *UnitStatePublisher) Purge() {
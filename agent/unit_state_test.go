@@ -59,6 +59,16 @@ func TestUpdateCache(t *testing.T) {
 		Name:  name,
 		State: nil,
 	}
+	us1Usage := &unit.UnitState{
+		ActiveState: "active",
+		UnitName:    name,
+		MachineID:   mID,
+		Usage:       &unit.UnitUsage{MemoryCurrent: 1024, Tasks: 1},
+	}
+	ush1Usage := &unit.UnitStateHeartbeat{
+		Name:  name,
+		State: us1Usage,
+	}
 
 	tests := []struct {
 		ush         *unit.UnitStateHeartbeat
@@ -108,6 +118,14 @@ func TestUpdateCache(t *testing.T) {
 			map[string]*unit.UnitState{ush1.Name: nil},
 			false,
 		},
+		{
+			// heartbeat differing only in usage should be saved
+			// without counting as a change
+			ush1Usage,
+			map[string]*unit.UnitState{ush1.Name: us1},
+			map[string]*unit.UnitState{ush1.Name: us1Usage},
+			false,
+		},
 	}
 
 	for i, tt := range tests {
@@ -819,7 +837,7 @@ func TestMarshalJSON(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error marshalling: %v", err)
 	}
-	want = `{"Cache":{"bar.service":{"LoadState":"","ActiveState":"inactive","SubState":"","MachineID":"asdf","UnitHash":"","UnitName":"bar.service"},"foo.service":{"LoadState":"","ActiveState":"active","SubState":"","MachineID":"asdf","UnitHash":"","UnitName":"foo.service"}},"ToPublish":{"woof.service":{"LoadState":"","ActiveState":"active","SubState":"","MachineID":"asdf","UnitHash":"","UnitName":"woof.service"}}}`
+	want = `{"Cache":{"bar.service":{"LoadState":"","ActiveState":"inactive","SubState":"","MachineID":"asdf","UnitHash":"","UnitName":"bar.service","Health":"","DropInsHash":"","MainPID":0,"ExecMainStatus":0,"NRestarts":0,"ActiveEnterTimestamp":0,"Result":"","Usage":null},"foo.service":{"LoadState":"","ActiveState":"active","SubState":"","MachineID":"asdf","UnitHash":"","UnitName":"foo.service","Health":"","DropInsHash":"","MainPID":0,"ExecMainStatus":0,"NRestarts":0,"ActiveEnterTimestamp":0,"Result":"","Usage":null}},"ToPublish":{"woof.service":{"LoadState":"","ActiveState":"active","SubState":"","MachineID":"asdf","UnitHash":"","UnitName":"woof.service","Health":"","DropInsHash":"","MainPID":0,"ExecMainStatus":0,"NRestarts":0,"ActiveEnterTimestamp":0,"Result":"","Usage":null}}}`
 	if string(got) != want {
 		t.Fatalf("Bad JSON representation: got\n%s\n\nwant\n%s", string(got), want)
 	}
@@ -0,0 +1,171 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/schema"
+)
+
+func wireUpDeploymentsResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API) {
+	base := path.Join(prefix, "deployments")
+	dr := deploymentsResource{cAPI, base, uint16(tokenLimit)}
+	mux.Handle(base, &dr)
+	mux.Handle(base+"/", &dr)
+}
+
+type deploymentsResource struct {
+	cAPI       client.API
+	basePath   string
+	tokenLimit uint16
+}
+
+func (dr *deploymentsResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if isCollectionPath(dr.basePath, req.URL.Path) {
+		switch req.Method {
+		case "GET":
+			dr.list(rw, req)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		}
+	} else if item, ok := isItemPath(dr.basePath, req.URL.Path); ok {
+		switch req.Method {
+		case "PUT":
+			dr.set(rw, req, item)
+		case "DELETE":
+			dr.destroy(rw, req, item)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only PUT and DELETE supported against this resource"))
+		}
+	} else {
+		sendError(rw, http.StatusNotFound, nil)
+	}
+}
+
+func (dr *deploymentsResource) list(rw http.ResponseWriter, req *http.Request) {
+	token, err := findNextPageToken(req.URL, dr.tokenLimit)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if token == nil {
+		def := DefaultPageToken(dr.tokenLimit)
+		token = &def
+	}
+
+	all, err := dr.cAPI.Deployments()
+	if err != nil {
+		log.Errorf("Failed fetching Deployments: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, extractDeploymentPage(all, *token))
+}
+
+func extractDeploymentPage(all []*schema.Deployment, tok PageToken) *schema.DeploymentPage {
+	total := len(all)
+
+	startIndex := int((tok.Page - 1) * tok.Limit)
+	stopIndex := int(tok.Page * tok.Limit)
+
+	page := schema.DeploymentPage{
+		Deployments: make([]*schema.Deployment, 0),
+	}
+
+	if startIndex < total {
+		if stopIndex > total {
+			stopIndex = total
+		} else {
+			n := tok.Next()
+			page.NextPageToken = n.Encode()
+		}
+
+		page.Deployments = all[startIndex:stopIndex]
+	}
+
+	return &page
+}
+
+func (dr *deploymentsResource) set(rw http.ResponseWriter, req *http.Request, item string) {
+	if err := validateContentType(req); err != nil {
+		sendError(rw, http.StatusUnsupportedMediaType, err)
+		return
+	}
+
+	var sd schema.Deployment
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&sd); err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("unable to decode body: %v", err))
+		return
+	}
+	if sd.Name == "" {
+		sd.Name = item
+	}
+	if item != sd.Name {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("name in URL %q differs from deployment name in request body %q", item, sd.Name))
+		return
+	}
+
+	if err := schema.MapSchemaToDeployment(&sd).Validate(); err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := dr.cAPI.SetDeployment(&sd); err != nil {
+		log.Errorf("Failed storing Deployment(%s): %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (dr *deploymentsResource) destroy(rw http.ResponseWriter, req *http.Request, item string) {
+	all, err := dr.cAPI.Deployments()
+	if err != nil {
+		log.Errorf("Failed fetching Deployments: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	found := false
+	for _, sd := range all {
+		if sd.Name == item {
+			found = true
+			break
+		}
+	}
+	if !found {
+		sendError(rw, http.StatusNotFound, errors.New("deployment does not exist"))
+		return
+	}
+
+	if err := dr.cAPI.DestroyDeployment(item); err != nil {
+		log.Errorf("Failed destroying Deployment(%s): %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/registry"
+)
+
+func TestDeploymentsList(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fr.SetDeployment(job.Deployment{Template: "web@.service", Replicas: 3})
+	fr.SetDeployment(job.Deployment{Template: "api@.service", Replicas: 0})
+
+	fAPI := &client.RegistryClient{Registry: fr}
+	resource := &deploymentsResource{fAPI, "/deployments", testTokenLimit}
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com/deployments", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+
+	expected := `{"deployments":[{"name":"api@.service","replicas":0},{"name":"web@.service","replicas":3}]}`
+	if body := rw.Body.String(); body != expected {
+		t.Errorf("Expected body:\n%s\n\nReceived body:\n%s\n", expected, body)
+	}
+}
+
+func TestDeploymentsSet(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		// expected deployments in the registry after the request
+		deployments []job.Deployment
+	}{
+		{
+			name:        "web@.service",
+			body:        `{"replicas":3}`,
+			code:        http.StatusNoContent,
+			deployments: []job.Deployment{{Template: "web@.service", Replicas: 3}},
+		},
+		{
+			name:        "web@.service",
+			body:        `{"name":"web@.service","replicas":0}`,
+			code:        http.StatusNoContent,
+			deployments: []job.Deployment{{Template: "web@.service", Replicas: 0}},
+		},
+		// name in body must match URL
+		{
+			name: "web@.service",
+			body: `{"name":"api@.service","replicas":3}`,
+			code: http.StatusBadRequest,
+		},
+		// only templates can be deployed
+		{
+			name: "web.service",
+			body: `{"replicas":3}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "web@1.service",
+			body: `{"replicas":3}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "web@.service",
+			body: `{"replicas":-1}`,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range tests {
+		fr := registry.NewFakeRegistry()
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &deploymentsResource{fAPI, "/deployments", testTokenLimit}
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("PUT", "http://example.com/deployments/"+tt.name, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resource.ServeHTTP(rw, req)
+		if tt.code/100 == 2 {
+			if rw.Code != tt.code {
+				t.Errorf("case %d: expected %d, got %d: %s", i, tt.code, rw.Code, rw.Body.String())
+			}
+		} else if err := assertErrorResponse(rw, tt.code); err != nil {
+			t.Errorf("case %d: %v", i, err)
+		}
+
+		deployments, err := fr.Deployments()
+		if err != nil {
+			t.Fatalf("case %d: failed fetching Deployments: %v", i, err)
+		}
+		if !reflect.DeepEqual(tt.deployments, deployments) {
+			t.Errorf("case %d: expected Deployments %v, got %v", i, tt.deployments, deployments)
+		}
+	}
+}
+
+func TestDeploymentsDestroy(t *testing.T) {
+	for i, tt := range []struct {
+		arg  string
+		code int
+		left int
+	}{
+		{"web@.service", http.StatusNoContent, 0},
+		{"api@.service", http.StatusNotFound, 1},
+	} {
+		fr := registry.NewFakeRegistry()
+		fr.SetDeployment(job.Deployment{Template: "web@.service", Replicas: 3})
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &deploymentsResource{fAPI, "/deployments", testTokenLimit}
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("DELETE", "http://example.com/deployments/"+tt.arg, nil)
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if tt.code/100 == 2 {
+			if rw.Code != tt.code {
+				t.Errorf("case %d: expected %d, got %d", i, tt.code, rw.Code)
+			}
+		} else if err := assertErrorResponse(rw, tt.code); err != nil {
+			t.Errorf("case %d: %v", i, err)
+		}
+
+		deployments, _ := fr.Deployments()
+		if len(deployments) != tt.left {
+			t.Errorf("case %d: expected %d Deployments left, got %d", i, tt.left, len(deployments))
+		}
+	}
+}
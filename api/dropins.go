@@ -0,0 +1,174 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/schema"
+)
+
+func wireUpDropInsResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API) {
+	base := path.Join(prefix, "dropins")
+	dr := dropInsResource{cAPI, base, uint16(tokenLimit)}
+	mux.Handle(base, &dr)
+	mux.Handle(base+"/", &dr)
+}
+
+type dropInsResource struct {
+	cAPI       client.API
+	basePath   string
+	tokenLimit uint16
+}
+
+func (dr *dropInsResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if isCollectionPath(dr.basePath, req.URL.Path) {
+		switch req.Method {
+		case "GET":
+			dr.list(rw, req)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		}
+	} else if unitName, name, ok := isNestedItemPath(dr.basePath, req.URL.Path); ok {
+		switch req.Method {
+		case "PUT":
+			dr.set(rw, req, unitName, name)
+		case "DELETE":
+			dr.destroy(rw, req, unitName, name)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only PUT and DELETE supported against this resource"))
+		}
+	} else {
+		sendError(rw, http.StatusNotFound, nil)
+	}
+}
+
+func (dr *dropInsResource) list(rw http.ResponseWriter, req *http.Request) {
+	token, err := findNextPageToken(req.URL, dr.tokenLimit)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if token == nil {
+		def := DefaultPageToken(dr.tokenLimit)
+		token = &def
+	}
+
+	all, err := dr.cAPI.DropIns()
+	if err != nil {
+		log.Errorf("Failed fetching drop-ins: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, extractDropInPage(all, *token))
+}
+
+func extractDropInPage(all []*schema.DropIn, tok PageToken) *schema.DropInPage {
+	total := len(all)
+
+	startIndex := int((tok.Page - 1) * tok.Limit)
+	stopIndex := int(tok.Page * tok.Limit)
+
+	page := schema.DropInPage{
+		DropIns: make([]*schema.DropIn, 0),
+	}
+
+	if startIndex < total {
+		if stopIndex > total {
+			stopIndex = total
+		} else {
+			n := tok.Next()
+			page.NextPageToken = n.Encode()
+		}
+
+		page.DropIns = all[startIndex:stopIndex]
+	}
+
+	return &page
+}
+
+func (dr *dropInsResource) set(rw http.ResponseWriter, req *http.Request, unitName, name string) {
+	if err := validateContentType(req); err != nil {
+		sendError(rw, http.StatusUnsupportedMediaType, err)
+		return
+	}
+
+	var sd schema.DropIn
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&sd); err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("unable to decode body: %v", err))
+		return
+	}
+	if sd.Unit == "" {
+		sd.Unit = unitName
+	}
+	if sd.Name == "" {
+		sd.Name = name
+	}
+	if unitName != sd.Unit || name != sd.Name {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("drop-in %s/%s in URL differs from drop-in %s/%s in request body", unitName, name, sd.Unit, sd.Name))
+		return
+	}
+
+	if err := schema.MapSchemaToDropIn(&sd).Validate(); err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := dr.cAPI.SetDropIn(&sd); err != nil {
+		log.Errorf("Failed storing drop-in %s of Unit(%s): %v", name, unitName, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (dr *dropInsResource) destroy(rw http.ResponseWriter, req *http.Request, unitName, name string) {
+	all, err := dr.cAPI.DropIns()
+	if err != nil {
+		log.Errorf("Failed fetching drop-ins: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	found := false
+	for _, sd := range all {
+		if sd.Unit == unitName && sd.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		sendError(rw, http.StatusNotFound, errors.New("drop-in does not exist"))
+		return
+	}
+
+	if err := dr.cAPI.DestroyDropIn(unitName, name); err != nil {
+		log.Errorf("Failed removing drop-in %s of Unit(%s): %v", name, unitName, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
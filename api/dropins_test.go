@@ -0,0 +1,172 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestDropInsList(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fr.SetDropIn(unit.DropIn{Unit: "web@.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"})
+	fr.SetDropIn(unit.DropIn{Unit: "api.service", Name: "10-env.conf", Metadata: map[string]string{"region": "us-west"}, Contents: "[Service]\nEnvironment=FOO=baz\n"})
+
+	fAPI := &client.RegistryClient{Registry: fr}
+	resource := &dropInsResource{fAPI, "/dropins", testTokenLimit}
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com/dropins", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+
+	expected := `{"dropIns":[{"contents":"[Service]\nEnvironment=FOO=baz\n","metadata":{"region":"us-west"},"name":"10-env.conf","unit":"api.service"},{"contents":"[Service]\nEnvironment=FOO=bar\n","name":"10-env.conf","unit":"web@.service"}]}`
+	if body := rw.Body.String(); body != expected {
+		t.Errorf("Expected body:\n%s\n\nReceived body:\n%s\n", expected, body)
+	}
+}
+
+func TestDropInsSet(t *testing.T) {
+	tests := []struct {
+		path string
+		body string
+		code int
+		// expected drop-ins in the registry after the request
+		dropIns []unit.DropIn
+	}{
+		{
+			path:    "web@.service/10-env.conf",
+			body:    `{"contents":"[Service]\nEnvironment=FOO=bar\n"}`,
+			code:    http.StatusNoContent,
+			dropIns: []unit.DropIn{{Unit: "web@.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"}},
+		},
+		{
+			path:    "web.service/10-env.conf",
+			body:    `{"unit":"web.service","name":"10-env.conf","metadata":{"region":"us-west"},"contents":"[Service]\nEnvironment=FOO=bar\n"}`,
+			code:    http.StatusNoContent,
+			dropIns: []unit.DropIn{{Unit: "web.service", Name: "10-env.conf", Metadata: map[string]string{"region": "us-west"}, Contents: "[Service]\nEnvironment=FOO=bar\n"}},
+		},
+		// unit and name in body must match URL
+		{
+			path: "web.service/10-env.conf",
+			body: `{"unit":"api.service","contents":"[Service]\n"}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			path: "web.service/10-env.conf",
+			body: `{"name":"20-env.conf","contents":"[Service]\n"}`,
+			code: http.StatusBadRequest,
+		},
+		// drop-in names must end in .conf
+		{
+			path: "web.service/10-env",
+			body: `{"contents":"[Service]\n"}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			path: "web/10-env.conf",
+			body: `{"contents":"[Service]\n"}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			path: "web.service/10-env.conf",
+			body: `{"contents":"[Service\n"}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			path: "web.service/10-env.conf",
+			body: `garbage`,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range tests {
+		fr := registry.NewFakeRegistry()
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &dropInsResource{fAPI, "/dropins", testTokenLimit}
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("PUT", "http://example.com/dropins/"+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resource.ServeHTTP(rw, req)
+		if tt.code/100 == 2 {
+			if rw.Code != tt.code {
+				t.Errorf("case %d: expected %d, got %d: %s", i, tt.code, rw.Code, rw.Body.String())
+			}
+		} else if err := assertErrorResponse(rw, tt.code); err != nil {
+			t.Errorf("case %d: %v", i, err)
+		}
+
+		dropIns, err := fr.DropIns()
+		if err != nil {
+			t.Fatalf("case %d: failed fetching DropIns: %v", i, err)
+		}
+		if !reflect.DeepEqual(tt.dropIns, dropIns) {
+			t.Errorf("case %d: expected DropIns %v, got %v", i, tt.dropIns, dropIns)
+		}
+	}
+}
+
+func TestDropInsDestroy(t *testing.T) {
+	for i, tt := range []struct {
+		path string
+		code int
+		left int
+	}{
+		{"web@.service/10-env.conf", http.StatusNoContent, 0},
+		{"web@.service/20-env.conf", http.StatusNotFound, 1},
+		{"api.service/10-env.conf", http.StatusNotFound, 1},
+		{"web@.service", http.StatusNotFound, 1},
+	} {
+		fr := registry.NewFakeRegistry()
+		fr.SetDropIn(unit.DropIn{Unit: "web@.service", Name: "10-env.conf", Contents: "[Service]\n"})
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &dropInsResource{fAPI, "/dropins", testTokenLimit}
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("DELETE", "http://example.com/dropins/"+tt.path, nil)
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if tt.code/100 == 2 {
+			if rw.Code != tt.code {
+				t.Errorf("case %d: expected %d, got %d", i, tt.code, rw.Code)
+			}
+		} else if err := assertErrorResponse(rw, tt.code); err != nil {
+			t.Errorf("case %d: %v", i, err)
+		}
+
+		dropIns, _ := fr.DropIns()
+		if len(dropIns) != tt.left {
+			t.Errorf("case %d: expected %d DropIns left, got %d", i, tt.left, len(dropIns))
+		}
+	}
+}
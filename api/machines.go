@@ -29,6 +29,8 @@ import (
 
 var (
 	metadataPathRegex = regexp.MustCompile("^/([^/]+)/metadata/([A-Za-z0-9_.-]+$)")
+	cordonedPathRegex = regexp.MustCompile("^/([^/]+)/cordoned$")
+	pausedPathRegex   = regexp.MustCompile("^/([^/]+)/paused/([^/]+)$")
 )
 
 func wireUpMachinesResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API) {
@@ -97,6 +99,22 @@ func (mr *machinesResource) patch(rw http.ResponseWriter, req *http.Request) {
 			return
 		}
 
+		if cordonedPathRegex.MatchString(op.Path) {
+			if op.Operation != "remove" && op.Value.Value != "true" && op.Value.Value != "false" {
+				sendError(rw, http.StatusBadRequest, errors.New("invalid value: cordoned must be true or false"))
+				return
+			}
+			continue
+		}
+
+		if pausedPathRegex.MatchString(op.Path) {
+			if op.Operation != "remove" && op.Value.Value != "true" && op.Value.Value != "false" {
+				sendError(rw, http.StatusBadRequest, errors.New("invalid value: paused must be true or false"))
+				return
+			}
+			continue
+		}
+
 		if metadataPathRegex.FindStringSubmatch(op.Path) == nil {
 			sendError(rw, http.StatusBadRequest, errors.New("machine metadata path invalid"))
 			return
@@ -109,6 +127,24 @@ func (mr *machinesResource) patch(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	for _, op := range ops {
+		if s := cordonedPathRegex.FindStringSubmatch(op.Path); s != nil {
+			cordoned := op.Operation != "remove" && op.Value.Value == "true"
+			if err := mr.cAPI.SetMachineCordoned(s[1], cordoned); err != nil {
+				sendError(rw, http.StatusInternalServerError, err)
+				return
+			}
+			continue
+		}
+
+		if s := pausedPathRegex.FindStringSubmatch(op.Path); s != nil {
+			paused := op.Operation != "remove" && op.Value.Value == "true"
+			if err := mr.cAPI.SetGlobalUnitPaused(s[1], s[2], paused); err != nil {
+				sendError(rw, http.StatusInternalServerError, err)
+				return
+			}
+			continue
+		}
+
 		// regex already validated above
 		s := metadataPathRegex.FindStringSubmatch(op.Path)
 		machID := s[1]
@@ -266,3 +266,108 @@ func TestMachinesPatchBadValue(t *testing.T) {
 		t.Errorf("Expected 400, got %d", rw.Code)
 	}
 }
+
+func TestMachinesPatchCordon(t *testing.T) {
+	tests := []struct {
+		reqBody  string
+		expected string
+	}{
+		{
+			reqBody:  `[{"op": "replace", "path": "/XXX/cordoned", "value": { "value": "true" }}]`,
+			expected: `{"machines":[{"cordoned":true,"id":"XXX"},{"id":"YYY","metadata":{"ping":"pong"},"primaryIP":"1.2.3.4"}]}`,
+		},
+		{
+			reqBody: `[{"op": "add", "path": "/XXX/cordoned", "value": { "value": "true" }},
+			{"op": "replace", "path": "/XXX/cordoned", "value": { "value": "false" }}]`,
+			expected: `{"machines":[{"id":"XXX"},{"id":"YYY","metadata":{"ping":"pong"},"primaryIP":"1.2.3.4"}]}`,
+		},
+		{
+			reqBody: `[{"op": "add", "path": "/YYY/cordoned", "value": { "value": "true" }},
+			{"op": "remove", "path": "/YYY/cordoned"}]`,
+			expected: `{"machines":[{"id":"XXX"},{"id":"YYY","metadata":{"ping":"pong"},"primaryIP":"1.2.3.4"}]}`,
+		},
+	}
+
+	for i, tt := range tests {
+		resource, rw := fakeMachinesSetup()
+		req, err := http.NewRequest("PATCH", "http://example.com/machines", strings.NewReader(tt.reqBody))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if rw.Code != http.StatusNoContent {
+			t.Errorf("case %d: expected 204, got %d", i, rw.Code)
+		}
+
+		req, err = http.NewRequest("GET", "http://example.com/machines", nil)
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		rw.Body.Reset()
+		resource.ServeHTTP(rw, req)
+
+		if body := rw.Body.String(); body != tt.expected {
+			t.Errorf("case %d: expected body:\n%s\n\nReceived body:\n%s\n", i, tt.expected, body)
+		}
+	}
+}
+
+func TestMachinesPatchPaused(t *testing.T) {
+	tests := []struct {
+		reqBody  string
+		expected string
+	}{
+		{
+			reqBody: `[{"op": "add", "path": "/XXX/paused/b.service", "value": { "value": "true" }},
+			{"op": "add", "path": "/XXX/paused/a.service", "value": { "value": "true" }}]`,
+			expected: `{"machines":[{"id":"XXX","pausedUnits":["a.service","b.service"]},{"id":"YYY","metadata":{"ping":"pong"},"primaryIP":"1.2.3.4"}]}`,
+		},
+		{
+			reqBody: `[{"op": "add", "path": "/YYY/paused/a.service", "value": { "value": "true" }},
+			{"op": "remove", "path": "/YYY/paused/a.service"}]`,
+			expected: `{"machines":[{"id":"XXX"},{"id":"YYY","metadata":{"ping":"pong"},"primaryIP":"1.2.3.4"}]}`,
+		},
+	}
+
+	for i, tt := range tests {
+		resource, rw := fakeMachinesSetup()
+		req, err := http.NewRequest("PATCH", "http://example.com/machines", strings.NewReader(tt.reqBody))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if rw.Code != http.StatusNoContent {
+			t.Errorf("case %d: expected 204, got %d", i, rw.Code)
+		}
+
+		req, err = http.NewRequest("GET", "http://example.com/machines", nil)
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		rw.Body.Reset()
+		resource.ServeHTTP(rw, req)
+
+		if body := rw.Body.String(); body != tt.expected {
+			t.Errorf("case %d: expected body:\n%s\n\nReceived body:\n%s\n", i, tt.expected, body)
+		}
+	}
+}
+
+func TestMachinesPatchBadCordonValue(t *testing.T) {
+	reqBody := `
+	[{"op": "add", "path": "/XXX/cordoned", "value": { "value": "yes" }}]
+	`
+
+	resource, rw := fakeMachinesSetup()
+	req, err := http.NewRequest("PATCH", "http://example.com/machines", strings.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rw.Code)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/schema"
+)
+
+func wireUpMaintenanceResource(mux *http.ServeMux, prefix string, tokenLimit int, cAPI client.API) {
+	base := path.Join(prefix, "maintenance")
+	mr := maintenanceResource{cAPI, base, uint16(tokenLimit)}
+	mux.Handle(base, &mr)
+	mux.Handle(base+"/", &mr)
+}
+
+type maintenanceResource struct {
+	cAPI       client.API
+	basePath   string
+	tokenLimit uint16
+}
+
+func (mr *maintenanceResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if isCollectionPath(mr.basePath, req.URL.Path) {
+		switch req.Method {
+		case "GET":
+			mr.list(rw, req)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		}
+	} else if item, ok := isItemPath(mr.basePath, req.URL.Path); ok {
+		switch req.Method {
+		case "PUT":
+			mr.set(rw, req, item)
+		case "DELETE":
+			mr.destroy(rw, req, item)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only PUT and DELETE supported against this resource"))
+		}
+	} else {
+		sendError(rw, http.StatusNotFound, nil)
+	}
+}
+
+func (mr *maintenanceResource) list(rw http.ResponseWriter, req *http.Request) {
+	token, err := findNextPageToken(req.URL, mr.tokenLimit)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if token == nil {
+		def := DefaultPageToken(mr.tokenLimit)
+		token = &def
+	}
+
+	all, err := mr.cAPI.MaintenanceWindows()
+	if err != nil {
+		log.Errorf("Failed fetching maintenance windows: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, extractMaintenanceWindowPage(all, *token))
+}
+
+func extractMaintenanceWindowPage(all []*schema.MaintenanceWindow, tok PageToken) *schema.MaintenanceWindowPage {
+	total := len(all)
+
+	startIndex := int((tok.Page - 1) * tok.Limit)
+	stopIndex := int(tok.Page * tok.Limit)
+
+	page := schema.MaintenanceWindowPage{
+		MaintenanceWindows: make([]*schema.MaintenanceWindow, 0),
+	}
+
+	if startIndex < total {
+		if stopIndex > total {
+			stopIndex = total
+		} else {
+			n := tok.Next()
+			page.NextPageToken = n.Encode()
+		}
+
+		page.MaintenanceWindows = all[startIndex:stopIndex]
+	}
+
+	return &page
+}
+
+func (mr *maintenanceResource) set(rw http.ResponseWriter, req *http.Request, item string) {
+	if err := validateContentType(req); err != nil {
+		sendError(rw, http.StatusUnsupportedMediaType, err)
+		return
+	}
+
+	var smw schema.MaintenanceWindow
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&smw); err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("unable to decode body: %v", err))
+		return
+	}
+	if smw.Name == "" {
+		smw.Name = item
+	}
+	if item != smw.Name {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("name in URL %q differs from maintenance window name in request body %q", item, smw.Name))
+		return
+	}
+
+	mw, err := schema.MapSchemaToMaintenanceWindow(&smw)
+	if err == nil {
+		err = mw.Validate()
+	}
+	if err == nil && !mw.End.After(time.Now()) {
+		err = errors.New("maintenance window has already ended")
+	}
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := mr.cAPI.SetMaintenanceWindow(&smw); err != nil {
+		log.Errorf("Failed storing maintenance window %s: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (mr *maintenanceResource) destroy(rw http.ResponseWriter, req *http.Request, item string) {
+	all, err := mr.cAPI.MaintenanceWindows()
+	if err != nil {
+		log.Errorf("Failed fetching maintenance windows: %v", err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	found := false
+	for _, smw := range all {
+		if smw.Name == item {
+			found = true
+			break
+		}
+	}
+	if !found {
+		sendError(rw, http.StatusNotFound, errors.New("maintenance window does not exist"))
+		return
+	}
+
+	if err := mr.cAPI.DestroyMaintenanceWindow(item); err != nil {
+		log.Errorf("Failed removing maintenance window %s: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
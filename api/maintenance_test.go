@@ -0,0 +1,186 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/registry"
+)
+
+func TestMaintenanceList(t *testing.T) {
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	fr := registry.NewFakeRegistry()
+	fr.SetMaintenanceWindow(machine.MaintenanceWindow{
+		Name:      "kernel",
+		MachineID: "XXX",
+		Start:     start,
+		End:       start.Add(time.Hour),
+	})
+
+	fAPI := &client.RegistryClient{Registry: fr}
+	resource := &maintenanceResource{fAPI, "/maintenance", testTokenLimit}
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com/maintenance", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+
+	resource.ServeHTTP(rw, req)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+
+	expected := fmt.Sprintf(`{"maintenanceWindows":[{"end":"%s","machineID":"XXX","name":"kernel","phase":"pending","start":"%s"}]}`,
+		start.Add(time.Hour).Format(time.RFC3339), start.Format(time.RFC3339))
+	if body := rw.Body.String(); body != expected {
+		t.Errorf("Expected body:\n%s\n\nReceived body:\n%s\n", expected, body)
+	}
+}
+
+func TestMaintenanceSet(t *testing.T) {
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	end := start.Add(time.Hour)
+	past := time.Now().Add(-time.Hour).UTC()
+
+	tests := []struct {
+		name string
+		body string
+		code int
+		// expected windows in the registry after the request
+		windows []string
+	}{
+		{
+			name:    "kernel",
+			body:    fmt.Sprintf(`{"machineID":"XXX","lead":"10m","start":"%s","end":"%s"}`, start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			code:    http.StatusNoContent,
+			windows: []string{"kernel"},
+		},
+		{
+			name:    "rack",
+			body:    fmt.Sprintf(`{"name":"rack","metadata":{"rack":"a"},"start":"%s","end":"%s"}`, start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			code:    http.StatusNoContent,
+			windows: []string{"rack"},
+		},
+		// name in body must match URL
+		{
+			name: "kernel",
+			body: fmt.Sprintf(`{"name":"other","machineID":"XXX","start":"%s","end":"%s"}`, start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			code: http.StatusBadRequest,
+		},
+		// no machines selected
+		{
+			name: "kernel",
+			body: fmt.Sprintf(`{"start":"%s","end":"%s"}`, start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			code: http.StatusBadRequest,
+		},
+		// window ends before it starts
+		{
+			name: "kernel",
+			body: fmt.Sprintf(`{"machineID":"XXX","start":"%s","end":"%s"}`, end.Format(time.RFC3339), start.Format(time.RFC3339)),
+			code: http.StatusBadRequest,
+		},
+		// window already over
+		{
+			name: "kernel",
+			body: fmt.Sprintf(`{"machineID":"XXX","start":"%s","end":"%s"}`, past.Add(-time.Hour).Format(time.RFC3339), past.Format(time.RFC3339)),
+			code: http.StatusBadRequest,
+		},
+		// malformed lead time
+		{
+			name: "kernel",
+			body: fmt.Sprintf(`{"machineID":"XXX","lead":"soon","start":"%s","end":"%s"}`, start.Format(time.RFC3339), end.Format(time.RFC3339)),
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i, tt := range tests {
+		fr := registry.NewFakeRegistry()
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &maintenanceResource{fAPI, "/maintenance", testTokenLimit}
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("PUT", "http://example.com/maintenance/"+tt.name, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resource.ServeHTTP(rw, req)
+		if tt.code/100 == 2 {
+			if rw.Code != tt.code {
+				t.Errorf("case %d: expected %d, got %d: %s", i, tt.code, rw.Code, rw.Body.String())
+			}
+		} else if err := assertErrorResponse(rw, tt.code); err != nil {
+			t.Errorf("case %d: %v", i, err)
+		}
+
+		windows, err := fr.MaintenanceWindows()
+		if err != nil {
+			t.Fatalf("case %d: failed fetching maintenance windows: %v", i, err)
+		}
+		var names []string
+		for _, mw := range windows {
+			names = append(names, mw.Name)
+		}
+		if !reflect.DeepEqual(tt.windows, names) {
+			t.Errorf("case %d: expected windows %v, got %v", i, tt.windows, names)
+		}
+	}
+}
+
+func TestMaintenanceDestroy(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+
+	for i, tt := range []struct {
+		arg  string
+		code int
+		left int
+	}{
+		{"kernel", http.StatusNoContent, 0},
+		{"rack", http.StatusNotFound, 1},
+	} {
+		fr := registry.NewFakeRegistry()
+		fr.SetMaintenanceWindow(machine.MaintenanceWindow{Name: "kernel", MachineID: "XXX", Start: start, End: start.Add(time.Hour)})
+		fAPI := &client.RegistryClient{Registry: fr}
+		resource := &maintenanceResource{fAPI, "/maintenance", testTokenLimit}
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("DELETE", "http://example.com/maintenance/"+tt.arg, nil)
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+
+		resource.ServeHTTP(rw, req)
+		if tt.code/100 == 2 {
+			if rw.Code != tt.code {
+				t.Errorf("case %d: expected %d, got %d", i, tt.code, rw.Code)
+			}
+		} else if err := assertErrorResponse(rw, tt.code); err != nil {
+			t.Errorf("case %d: %v", i, err)
+		}
+
+		windows, _ := fr.MaintenanceWindows()
+		if len(windows) != tt.left {
+			t.Errorf("case %d: expected %d windows left, got %d", i, tt.left, len(windows))
+		}
+	}
+}
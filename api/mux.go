@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/engine"
 	"github.com/coreos/fleet/log"
 	"github.com/coreos/fleet/registry"
 	"github.com/coreos/fleet/version"
@@ -25,7 +26,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-func NewServeMux(reg registry.Registry, tokenLimit int) http.Handler {
+func NewServeMux(reg registry.Registry, sched engine.Scheduler, tokenLimit int) http.Handler {
 	sm := http.NewServeMux()
 	cAPI := &client.RegistryClient{Registry: reg}
 
@@ -35,6 +36,10 @@ func NewServeMux(reg registry.Registry, tokenLimit int) http.Handler {
 		wireUpMachinesResource(sm, prefix, tokenLimit, cAPI)
 		wireUpStateResource(sm, prefix, tokenLimit, cAPI)
 		wireUpUnitsResource(sm, prefix, tokenLimit, cAPI)
+		wireUpScheduleResource(sm, prefix, cAPI, reg, sched)
+		wireUpMaintenanceResource(sm, prefix, tokenLimit, cAPI)
+		wireUpDeploymentsResource(sm, prefix, tokenLimit, cAPI)
+		wireUpDropInsResource(sm, prefix, tokenLimit, cAPI)
 		sm.HandleFunc(prefix, methodNotAllowedHandler)
 	}
 
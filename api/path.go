@@ -43,3 +43,27 @@ func isItemPath(base, p string) (item string, matched bool) {
 
 	return
 }
+
+// isSubItemPath determines whether the given path refers to the named
+// sub-resource of an item in the collection at base, e.g. /units/foo/scheduling.
+func isSubItemPath(base, p, sub string) (item string, matched bool) {
+	if strings.HasSuffix(p, "/") || path.Base(p) != sub {
+		return
+	}
+
+	return isItemPath(base, path.Dir(p))
+}
+
+// isNestedItemPath determines whether the given path refers to an item
+// nested in an item of the collection at base, e.g.
+// /dropins/foo.service/10-env.conf.
+func isNestedItemPath(base, p string) (item, nested string, matched bool) {
+	if strings.HasSuffix(p, "/") {
+		return
+	}
+
+	if item, matched = isItemPath(base, path.Dir(p)); matched {
+		nested = path.Base(p)
+	}
+	return
+}
@@ -74,6 +74,30 @@ func TestIsItemPathPass(t *testing.T) {
 	}
 }
 
+func TestIsSubItemPath(t *testing.T) {
+	tests := []struct {
+		base  string
+		arg   string
+		item  string
+		match bool
+	}{
+		{"/v1/units", "/v1/units/foo.service/scheduling", "foo.service", true},
+		{"/v1/units", "/v1/units/foo.service/scheduling/", "", false},
+		{"/v1/units", "/v1/units/foo.service/bar", "", false},
+		{"/v1/units", "/v1/units/scheduling", "", false},
+		{"/v1/units", "/v1/units/foo/bar/scheduling", "", false},
+	}
+
+	for i, tt := range tests {
+		item, ok := isSubItemPath(tt.base, tt.arg, "scheduling")
+		if ok != tt.match {
+			t.Errorf("case %d: expected match=%t with base=%s arg=%s", i, tt.match, tt.base, tt.arg)
+		} else if item != tt.item {
+			t.Errorf("case %d: expected item=%s, got %s", i, tt.item, item)
+		}
+	}
+}
+
 func TestIsItemPathFail(t *testing.T) {
 	tests := []struct {
 		base string
@@ -93,3 +117,27 @@ func TestIsItemPathFail(t *testing.T) {
 		}
 	}
 }
+
+func TestIsNestedItemPath(t *testing.T) {
+	tests := []struct {
+		base   string
+		arg    string
+		item   string
+		nested string
+		ok     bool
+	}{
+		{"/dropins", "/dropins/foo.service/10-env.conf", "foo.service", "10-env.conf", true},
+		{"/v1/dropins", "/v1/dropins/foo@.service/bar.conf", "foo@.service", "bar.conf", true},
+		{"/dropins", "/dropins/foo.service", "", "", false},
+		{"/dropins", "/dropins/foo.service/", "", "", false},
+		{"/dropins", "/dropins/foo.service/bar/baz.conf", "", "", false},
+		{"/dropins", "/units/foo.service/bar.conf", "", "", false},
+	}
+
+	for i, tt := range tests {
+		item, nested, ok := isNestedItemPath(tt.base, tt.arg)
+		if ok != tt.ok || item != tt.item || nested != tt.nested {
+			t.Errorf("case %d: expected (%q, %q, %t), got (%q, %q, %t)", i, tt.item, tt.nested, tt.ok, item, nested, ok)
+		}
+	}
+}
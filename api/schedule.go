@@ -0,0 +1,133 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/engine"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/schema"
+)
+
+func wireUpScheduleResource(mux *http.ServeMux, prefix string, cAPI client.API, reg registry.Registry, sched engine.Scheduler) {
+	res := path.Join(prefix, "schedule:simulate")
+	sr := scheduleResource{cAPI, reg, sched}
+	mux.Handle(res, &sr)
+}
+
+// scheduleResource simulates scheduling decisions with the Scheduler the
+// engine of this fleetd uses.
+type scheduleResource struct {
+	cAPI  client.API
+	reg   registry.Registry
+	sched engine.Scheduler
+}
+
+func (sr *scheduleResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case "POST":
+		sr.simulate(rw, req)
+	default:
+		sendError(rw, http.StatusMethodNotAllowed, errors.New("only POST supported against this resource"))
+	}
+}
+
+// simulate responds with the tasks the engine would perform if the Unit in
+// the request body were submitted. If the body carries no options, the
+// Unit already stored in the Registry under the same name is simulated.
+func (sr *scheduleResource) simulate(rw http.ResponseWriter, req *http.Request) {
+	if err := validateContentType(req); err != nil {
+		sendError(rw, http.StatusUnsupportedMediaType, err)
+		return
+	}
+
+	var su schema.Unit
+	dec := json.NewDecoder(req.Body)
+	if err := dec.Decode(&su); err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("unable to decode body: %v", err))
+		return
+	}
+	if err := ValidateName(su.Name); err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+	ju := job.Unit{
+		Name:        su.Name,
+		TargetState: job.JobStateLaunched,
+	}
+	if len(su.DesiredState) > 0 {
+		ts, err := job.ParseJobState(su.DesiredState)
+		if err != nil {
+			sendError(rw, http.StatusBadRequest, err)
+			return
+		}
+		ju.TargetState = ts
+	}
+
+	if len(su.Options) == 0 {
+		eu, err := sr.cAPI.Unit(su.Name)
+		if err != nil {
+			log.Errorf("Failed fetching Unit(%s) from Registry: %v", su.Name, err)
+			sendError(rw, http.StatusInternalServerError, nil)
+			return
+		}
+		if eu == nil {
+			sendError(rw, http.StatusConflict, errors.New("unit does not exist and options field empty"))
+			return
+		}
+		su.Options = eu.Options
+	} else if err := ValidateOptions(su.Options); err != nil {
+		sendError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	ju.Unit = *schema.MapSchemaUnitOptionsToUnitFile(su.Options)
+
+	tasks, err := engine.Simulate(sr.reg, sr.sched, &ju)
+	if err == engine.ErrUnpredictableScheduler {
+		sendError(rw, http.StatusNotImplemented, err)
+		return
+	} else if err != nil {
+		log.Errorf("Failed simulating scheduling of Unit(%s): %v", su.Name, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, mapSimulatedTasksToSchema(tasks))
+}
+
+func mapSimulatedTasksToSchema(tasks []engine.SimulatedTask) schema.ScheduleSimulation {
+	sim := schema.ScheduleSimulation{
+		Tasks: make([]*schema.SimulatedTask, len(tasks)),
+	}
+	for i, t := range tasks {
+		sim.Tasks[i] = &schema.SimulatedTask{
+			Type:      t.Type,
+			Reason:    t.Reason,
+			UnitName:  t.JobName,
+			MachineID: t.MachineID,
+		}
+	}
+
+	return sim
+}
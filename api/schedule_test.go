@@ -0,0 +1,160 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/engine"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/schema"
+)
+
+func TestScheduleSimulate(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fr.SetMachines([]machine.MachineState{
+		machine.MachineState{ID: "XXX", Metadata: map[string]string{"region": "us-east"}},
+	})
+	fr.SetJobs([]job.Job{
+		{Name: "existing.service", TargetState: job.JobStateInactive},
+	})
+	fAPI := &client.RegistryClient{Registry: fr}
+	sched, err := engine.NewScheduler(engine.DefaultScheduler, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sr := &scheduleResource{fAPI, fr, sched}
+
+	scheduled := &schema.ScheduleSimulation{
+		Tasks: []*schema.SimulatedTask{
+			&schema.SimulatedTask{
+				Type:      "AttemptScheduleUnit",
+				Reason:    "target state launched and unit not scheduled",
+				UnitName:  "foo.service",
+				MachineID: "XXX",
+			},
+		},
+	}
+
+	tests := []struct {
+		method string
+		unit   schema.Unit
+		code   int
+		sim    *schema.ScheduleSimulation
+	}{
+		{
+			method: "POST",
+			unit: schema.Unit{
+				Name:    "foo.service",
+				Options: []*schema.UnitOption{{Section: "Service", Name: "ExecStart", Value: "/usr/bin/true"}},
+			},
+			code: http.StatusOK,
+			sim:  scheduled,
+		},
+		{
+			method: "POST",
+			unit: schema.Unit{
+				Name:    "foo.service",
+				Options: []*schema.UnitOption{{Section: "X-Fleet", Name: "MachineMetadata", Value: "region=eu-west"}},
+			},
+			code: http.StatusOK,
+			sim:  &schema.ScheduleSimulation{Tasks: []*schema.SimulatedTask{}},
+		},
+		{
+			method: "POST",
+			unit:   schema.Unit{Name: "existing.service"},
+			code:   http.StatusOK,
+			sim: &schema.ScheduleSimulation{
+				Tasks: []*schema.SimulatedTask{
+					&schema.SimulatedTask{
+						Type:      "AttemptScheduleUnit",
+						Reason:    "target state launched and unit not scheduled",
+						UnitName:  "existing.service",
+						MachineID: "XXX",
+					},
+				},
+			},
+		},
+		{
+			method: "POST",
+			unit:   schema.Unit{Name: "missing.service"},
+			code:   http.StatusConflict,
+		},
+		{
+			method: "POST",
+			unit:   schema.Unit{Name: "foo.service", DesiredState: "sleeping"},
+			code:   http.StatusBadRequest,
+		},
+		{
+			method: "POST",
+			unit:   schema.Unit{Name: "foo"},
+			code:   http.StatusBadRequest,
+		},
+		{
+			method: "GET",
+			unit:   schema.Unit{Name: "foo.service"},
+			code:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for i, tt := range tests {
+		body, err := json.Marshal(tt.unit)
+		if err != nil {
+			t.Fatalf("case %d: unable to encode request body: %v", i, err)
+		}
+
+		req, err := http.NewRequest(tt.method, "http://example.com/fleet/v1/schedule:simulate", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("case %d: failed creating http.Request: %v", i, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		rw := httptest.NewRecorder()
+		sr.ServeHTTP(rw, req)
+
+		if tt.code/100 != 2 {
+			if err := assertErrorResponse(rw, tt.code); err != nil {
+				t.Errorf("case %d: %v", i, err)
+			}
+			continue
+		}
+
+		if tt.code != rw.Code {
+			t.Errorf("case %d: expected %d, got %d", i, tt.code, rw.Code)
+			continue
+		}
+
+		var sim schema.ScheduleSimulation
+		if err := json.Unmarshal(rw.Body.Bytes(), &sim); err != nil {
+			t.Errorf("case %d: unable to decode response: %v", i, err)
+			continue
+		}
+		if sim.Tasks == nil {
+			sim.Tasks = []*schema.SimulatedTask{}
+		}
+
+		if !reflect.DeepEqual(tt.sim, &sim) {
+			t.Errorf("case %d: expected %#v, got %#v", i, tt.sim, sim)
+		}
+	}
+}
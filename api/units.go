@@ -18,9 +18,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/coreos/fleet/client"
 	"github.com/coreos/fleet/job"
@@ -64,6 +66,20 @@ func (ur *unitsResource) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		default:
 			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET, PUT and DELETE supported against this resource"))
 		}
+	} else if item, ok := isSubItemPath(ur.basePath, req.URL.Path, "scheduling"); ok {
+		switch req.Method {
+		case "GET":
+			ur.scheduling(rw, req, item)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		}
+	} else if item, ok := isSubItemPath(ur.basePath, req.URL.Path, "revisions"); ok {
+		switch req.Method {
+		case "GET":
+			ur.revisions(rw, req, item)
+		default:
+			sendError(rw, http.StatusMethodNotAllowed, errors.New("only GET supported against this resource"))
+		}
 	} else {
 		sendError(rw, http.StatusNotFound, nil)
 	}
@@ -129,6 +145,13 @@ func (ur *unitsResource) set(rw http.ResponseWriter, req *http.Request, item str
 	}
 
 	if newUnit {
+		// Clients which do not identify themselves are recorded in the
+		// revision history of the unit by address
+		if su.Client == "" {
+			if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+				su.Client = host
+			}
+		}
 		ur.create(rw, su.Name, &su)
 		return
 	}
@@ -255,6 +278,7 @@ func ValidateOptions(opts []*schema.UnitOption) error {
 	hasConflicts := conflicts.Length() != 0
 	hasReplaces := replaces.Length() != 0
 	_, hasReqTarget := j.RequiredTarget()
+	_, hasSpreadBy := j.SpreadBy()
 	u := &job.Unit{
 		Unit: *uf,
 	}
@@ -273,10 +297,72 @@ func ValidateOptions(opts []*schema.UnitOption) error {
 		return errors.New("Global cannot be used with Peers")
 	case isGlobal && hasReplaces:
 		return errors.New("Global cannot be used with Replaces")
+	case isGlobal && hasSpreadBy:
+		return errors.New("Global cannot be used with SpreadBy")
 	case hasConflicts && hasReplaces:
 		return errors.New("Conflicts cannot be used with Replaces")
 	}
 
+	for _, hc := range j.HealthChecks() {
+		if err := hc.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, opt := range opts {
+		if opt.Section != "X-Fleet" {
+			continue
+		}
+		switch opt.Name {
+		case "RescheduleOnFailure":
+			if isGlobal {
+				return errors.New("Global cannot be used with RescheduleOnFailure")
+			}
+			if _, err := job.ParseFailurePolicy(opt.Value); err != nil {
+				return err
+			}
+		case "Type":
+			if err := job.ValidateType(opt.Value); err != nil {
+				return err
+			}
+		case "Priority":
+			if err := job.ValidatePriority(opt.Value); err != nil {
+				return err
+			}
+		case "MaxUnavailable":
+			if err := job.ValidateMaxUnavailable(opt.Value); err != nil {
+				return err
+			}
+		case "Resources":
+			if err := job.ValidateResources(opt.Value); err != nil {
+				return err
+			}
+		case "CompletedTTL":
+			if ttl, err := time.ParseDuration(opt.Value); err != nil || ttl <= 0 {
+				return fmt.Errorf("CompletedTTL requires a positive duration, got %q", opt.Value)
+			}
+			if !u.IsBatch() {
+				return errors.New("CompletedTTL can only be used with Type=batch")
+			}
+		case "Schedule":
+			if _, err := job.ParseCronSchedule(opt.Value); err != nil {
+				return err
+			}
+		case "ScheduleHistory":
+			if _, err := job.ParseScheduleHistory(opt.Value); err != nil {
+				return err
+			}
+		case "ConcurrencyPolicy":
+			if _, err := job.ParseConcurrencyPolicy(opt.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if isGlobal && u.IsBatch() {
+		return errors.New("Global cannot be used with Type=batch")
+	}
+
 	return nil
 }
 
@@ -339,6 +425,64 @@ func (ur *unitsResource) get(rw http.ResponseWriter, req *http.Request, item str
 	sendResponse(rw, http.StatusOK, *u)
 }
 
+// scheduling describes why the referenced Unit could not be scheduled. Only
+// units the engine is still trying to schedule carry an explanation.
+func (ur *unitsResource) scheduling(rw http.ResponseWriter, req *http.Request, item string) {
+	u, err := ur.cAPI.Unit(item)
+	if err != nil {
+		log.Errorf("Failed fetching Unit(%s) from Registry: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if u == nil {
+		sendError(rw, http.StatusNotFound, errors.New("unit does not exist"))
+		return
+	}
+
+	if u.MachineID != "" || job.JobState(u.DesiredState) == job.JobStateInactive {
+		sendResponse(rw, http.StatusOK, *schema.MapSchedulingExplanationToSchema(item, nil))
+		return
+	}
+
+	us, err := ur.cAPI.UnitScheduling(item)
+	if err != nil {
+		log.Errorf("Failed fetching scheduling explanation of Unit(%s) from Registry: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if us == nil {
+		us = schema.MapSchedulingExplanationToSchema(item, nil)
+	}
+
+	sendResponse(rw, http.StatusOK, *us)
+}
+
+// revisions lists the revisions kept for the referenced Unit, oldest first.
+func (ur *unitsResource) revisions(rw http.ResponseWriter, req *http.Request, item string) {
+	u, err := ur.cAPI.Unit(item)
+	if err != nil {
+		log.Errorf("Failed fetching Unit(%s) from Registry: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	if u == nil {
+		sendError(rw, http.StatusNotFound, errors.New("unit does not exist"))
+		return
+	}
+
+	revisions, err := ur.cAPI.UnitRevisions(item)
+	if err != nil {
+		log.Errorf("Failed fetching revisions of Unit(%s) from Registry: %v", item, err)
+		sendError(rw, http.StatusInternalServerError, nil)
+		return
+	}
+
+	sendResponse(rw, http.StatusOK, schema.UnitRevisionPage{Revisions: revisions})
+}
+
 func (ur *unitsResource) list(rw http.ResponseWriter, req *http.Request) {
 	token, err := findNextPageToken(req.URL, ur.tokenLimit)
 	if err != nil {
@@ -17,6 +17,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -209,6 +210,194 @@ func TestUnitGet(t *testing.T) {
 	}
 }
 
+func TestUnitScheduling(t *testing.T) {
+	ex := &job.SchedulingExplanation{
+		Reason: "no agents able to run job",
+		Rejections: map[string]string{
+			"YYY": "local Machine metadata insufficient",
+			"XXX": "found conflict with locally-scheduled Unit(bar.service)",
+		},
+	}
+
+	tests := []struct {
+		item string
+		code int
+		us   *schema.UnitScheduling
+	}{
+		{
+			item: "pending.service",
+			code: http.StatusOK,
+			us: &schema.UnitScheduling{
+				Name:   "pending.service",
+				Reason: "no agents able to run job",
+				Rejections: []*schema.SchedulingRejection{
+					&schema.SchedulingRejection{MachineID: "XXX", Reason: "found conflict with locally-scheduled Unit(bar.service)"},
+					&schema.SchedulingRejection{MachineID: "YYY", Reason: "local Machine metadata insufficient"},
+				},
+			},
+		},
+		{
+			item: "unexplained.service",
+			code: http.StatusOK,
+			us:   &schema.UnitScheduling{Name: "unexplained.service"},
+		},
+		{
+			item: "scheduled.service",
+			code: http.StatusOK,
+			us:   &schema.UnitScheduling{Name: "scheduled.service"},
+		},
+		{
+			item: "inactive.service",
+			code: http.StatusOK,
+			us:   &schema.UnitScheduling{Name: "inactive.service"},
+		},
+		{
+			item: "missing.service",
+			code: http.StatusNotFound,
+		},
+	}
+
+	fr := registry.NewFakeRegistry()
+	fr.SetJobs([]job.Job{
+		{Name: "pending.service", TargetState: job.JobStateLaunched},
+		{Name: "unexplained.service", TargetState: job.JobStateLaunched},
+		{Name: "scheduled.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+		{Name: "inactive.service", TargetState: job.JobStateInactive},
+	})
+	for _, name := range []string{"pending.service", "scheduled.service", "inactive.service"} {
+		fr.SetUnitSchedulingExplanation(name, ex, 0)
+	}
+	fAPI := &client.RegistryClient{Registry: fr}
+	resource := &unitsResource{fAPI, "/units", testTokenLimit}
+
+	for i, tt := range tests {
+		rw := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", fmt.Sprintf("http://example.com/units/%s/scheduling", tt.item), nil)
+		if err != nil {
+			t.Errorf("case %d: failed creating http.Request: %v", i, err)
+			continue
+		}
+
+		resource.ServeHTTP(rw, req)
+
+		if tt.code/100 != 2 {
+			err = assertErrorResponse(rw, tt.code)
+			if err != nil {
+				t.Errorf("case %d: %v", i, err)
+			}
+			continue
+		}
+
+		if tt.code != rw.Code {
+			t.Errorf("case %d: expected %d, got %d", i, tt.code, rw.Code)
+			continue
+		}
+
+		var us schema.UnitScheduling
+		if err := json.Unmarshal(rw.Body.Bytes(), &us); err != nil {
+			t.Errorf("case %d: unable to decode response: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.us, &us) {
+			t.Errorf("case %d: expected %#v, got %#v", i, tt.us, us)
+		}
+	}
+}
+
+func TestUnitRevisions(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fAPI := &client.RegistryClient{Registry: fr}
+	for i, contents := range []string{"[Service]\nFoo=Bar", "[Service]\nFoo=Bar", "[Service]\nFoo=Baz"} {
+		uf := newUnit(t, contents)
+		su := schema.Unit{
+			Name:    "XXX.service",
+			Options: schema.MapUnitFileToSchemaUnitOptions(&uf),
+			Client:  fmt.Sprintf("core@host%d", i+1),
+		}
+		if err := fAPI.CreateUnit(&su); err != nil {
+			t.Fatalf("Failed creating Unit: %v", err)
+		}
+	}
+	resource := &unitsResource{fAPI, "/units", testTokenLimit}
+
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://example.com/units/XXX.service/revisions", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+	resource.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rw.Code)
+	}
+
+	var page schema.UnitRevisionPage
+	if err := json.Unmarshal(rw.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Unable to decode response: %v", err)
+	}
+	if len(page.Revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(page.Revisions))
+	}
+	for i, expected := range []struct {
+		number   int64
+		client   string
+		contents string
+	}{
+		{1, "core@host1", "[Service]\nFoo=Bar"},
+		{2, "core@host3", "[Service]\nFoo=Baz"},
+	} {
+		rev := page.Revisions[i]
+		uf := newUnit(t, expected.contents)
+		if rev.Number != expected.number || rev.Client != expected.client || rev.Hash != uf.Hash().String() {
+			t.Errorf("revision %d: expected number %d, client %s and hash %s, got %d, %s and %s",
+				i, expected.number, expected.client, uf.Hash(), rev.Number, rev.Client, rev.Hash)
+		}
+	}
+
+	rw = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://example.com/units/YYY.service/revisions", nil)
+	if err != nil {
+		t.Fatalf("Failed creating http.Request: %v", err)
+	}
+	resource.ServeHTTP(rw, req)
+
+	if err := assertErrorResponse(rw, http.StatusNotFound); err != nil {
+		t.Error(err)
+	}
+}
+
+// revisionFailingRegistry is a Registry failing to record revisions
+type revisionFailingRegistry struct {
+	registry.Registry
+}
+
+func (revisionFailingRegistry) AddUnitRevision(string, unit.UnitFile, string) error {
+	return errors.New("revision failed")
+}
+
+func TestUnitRevisionFailure(t *testing.T) {
+	fr := registry.NewFakeRegistry()
+	fAPI := &client.RegistryClient{Registry: revisionFailingRegistry{fr}}
+	uf := newUnit(t, "[Service]\nFoo=Bar")
+	su := schema.Unit{
+		Name:         "XXX.service",
+		DesiredState: "loaded",
+		Options:      schema.MapUnitFileToSchemaUnitOptions(&uf),
+	}
+
+	// the unit is created even though its revision is not recorded
+	if err := fAPI.CreateUnit(&su); err != nil {
+		t.Fatalf("Failed creating Unit: %v", err)
+	}
+	if u, err := fr.Unit("XXX.service"); err != nil || u == nil {
+		t.Fatalf("Expected Unit to be created, got %v: %v", u, err)
+	}
+	if revisions, _ := fr.UnitRevisions("XXX.service"); len(revisions) != 0 {
+		t.Errorf("Expected no revisions, got %v", revisions)
+	}
+}
+
 func TestUnitsDestroy(t *testing.T) {
 	tests := []struct {
 		// initial state of registry
@@ -721,6 +910,344 @@ func TestValidateOptions(t *testing.T) {
 			},
 			false,
 		},
+		// Global with SpreadBy no good
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Global",
+					Value:   "true",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "SpreadBy",
+					Value:   "az",
+				},
+			},
+			false,
+		},
+		// Well-formed health checks are fine
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheckHTTP",
+					Value:   "http://localhost:8080/health",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheckTCP",
+					Value:   "localhost:80%i",
+				},
+			},
+			true,
+		},
+		// Health checks must have a URL or an address
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheckHTTP",
+					Value:   "localhost:8080",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "HealthCheckTCP",
+					Value:   "localhost",
+				},
+			},
+			false,
+		},
+		// Well-formed failure policies are fine
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "RescheduleOnFailure",
+					Value:   "3/10m",
+				},
+			},
+			true,
+		},
+		// Failure policies need a number of failures and a window
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "RescheduleOnFailure",
+					Value:   "3",
+				},
+			},
+			false,
+		},
+		// Batch units may be garbage-collected
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Type",
+					Value:   "batch",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "CompletedTTL",
+					Value:   "24h",
+				},
+			},
+			true,
+		},
+		// Unknown types no good
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Type",
+					Value:   "cron",
+				},
+			},
+			false,
+		},
+		// CompletedTTL only for batch units
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "CompletedTTL",
+					Value:   "24h",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Type",
+					Value:   "batch",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "CompletedTTL",
+					Value:   "some",
+				},
+			},
+			false,
+		},
+		// Scheduled batch units are fine
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Type",
+					Value:   "batch",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Schedule",
+					Value:   "*/15 * * * *",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ScheduleHistory",
+					Value:   "5",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ConcurrencyPolicy",
+					Value:   "forbid",
+				},
+			},
+			true,
+		},
+		// Long-running units may be scheduled too
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Schedule",
+					Value:   "*/15 * * * *",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ConcurrencyPolicy",
+					Value:   "replace",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Type",
+					Value:   "batch",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Schedule",
+					Value:   "every 15 minutes",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ConcurrencyPolicy",
+					Value:   "queue",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "ScheduleHistory",
+					Value:   "0",
+				},
+			},
+			false,
+		},
+		// Priorities, budgets and resources must be well-formed
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Priority",
+					Value:   "-10",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Priority",
+					Value:   "high",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MaxUnavailable",
+					Value:   "0",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MaxUnavailable",
+					Value:   "one",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "MaxUnavailable",
+					Value:   "-1",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Resources",
+					Value:   "cores=100 memory=512",
+				},
+			},
+			true,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Resources",
+					Value:   "cores=lots",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Resources",
+					Value:   "memory=-1",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Resources",
+					Value:   "gpus=1",
+				},
+			},
+			false,
+		},
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Resources",
+					Value:   "disk",
+				},
+			},
+			false,
+		},
+		// Global with Type=batch no good
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Global",
+					Value:   "true",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Type",
+					Value:   "batch",
+				},
+			},
+			false,
+		},
+		// Global with RescheduleOnFailure no good
+		{
+			[]*schema.UnitOption{
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "Global",
+					Value:   "true",
+				},
+				&schema.UnitOption{
+					Section: "X-Fleet",
+					Name:    "RescheduleOnFailure",
+					Value:   "3/10m",
+				},
+			},
+			false,
+		},
 	}
 	for i, tt := range testCases {
 		err := ValidateOptions(tt.opts)
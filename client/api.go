@@ -23,13 +23,30 @@ type API interface {
 	Machines() ([]machine.MachineState, error)
 	SetMachineMetadata(machID, key, value string) error
 	DeleteMachineMetadata(machID, key string) error
+	SetMachineCordoned(machID string, cordoned bool) error
+	SetGlobalUnitPaused(machID, unitName string, paused bool) error
 
 	Unit(string) (*schema.Unit, error)
 	Units() ([]*schema.Unit, error)
 	UnitState(string) (*schema.UnitState, error)
 	UnitStates() ([]*schema.UnitState, error)
+	UnitScheduling(string) (*schema.UnitScheduling, error)
+	UnitRevisions(string) ([]*schema.UnitRevision, error)
 
 	SetUnitTargetState(name, target string) error
 	CreateUnit(*schema.Unit) error
+	SimulateUnit(*schema.Unit) (*schema.ScheduleSimulation, error)
 	DestroyUnit(string) error
+
+	MaintenanceWindows() ([]*schema.MaintenanceWindow, error)
+	SetMaintenanceWindow(*schema.MaintenanceWindow) error
+	DestroyMaintenanceWindow(string) error
+
+	Deployments() ([]*schema.Deployment, error)
+	SetDeployment(*schema.Deployment) error
+	DestroyDeployment(string) error
+
+	DropIns() ([]*schema.DropIn, error)
+	SetDropIn(*schema.DropIn) error
+	DestroyDropIn(unitName, name string) error
 }
@@ -15,9 +15,12 @@
 package client
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 
 	"google.golang.org/api/googleapi"
 
@@ -35,11 +38,12 @@ func NewHTTPClient(c *http.Client, ep url.URL) (API, error) {
 	ep.Path = path.Join(ep.Path, "fleet", "v1") + "/"
 	svc.BasePath = ep.String()
 
-	return &HTTPClient{svc: svc}, nil
+	return &HTTPClient{svc: svc, client: c}, nil
 }
 
 type HTTPClient struct {
-	svc *schema.Service
+	svc    *schema.Service
+	client *http.Client
 
 	//NOTE(bcwaldon): This is only necessary until the API interface
 	// is fully implemented by HTTPClient
@@ -67,6 +71,53 @@ func (c *HTTPClient) Machines() ([]machine.MachineState, error) {
 	return machines, nil
 }
 
+func (c *HTTPClient) SetMachineCordoned(machID string, cordoned bool) error {
+	return c.patchMachine("replace", path.Join("/", machID, "cordoned"), strconv.FormatBool(cordoned))
+}
+
+func (c *HTTPClient) SetGlobalUnitPaused(machID, unitName string, paused bool) error {
+	op := "remove"
+	if paused {
+		op = "add"
+	}
+	return c.patchMachine(op, path.Join("/", machID, "paused", unitName), "true")
+}
+
+// patchMachine sends a JSON Patch document holding a single operation to
+// the machines collection. Such a request cannot be described by the
+// discovery document, so it is built here rather than by the generated
+// client.
+func (c *HTTPClient) patchMachine(op, opPath, val string) error {
+	type value struct {
+		Value string `json:"value"`
+	}
+	ops := []struct {
+		Operation string `json:"op"`
+		Path      string `json:"path"`
+		Value     value  `json:"value"`
+	}{
+		{op, opPath, value{val}},
+	}
+
+	body, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PATCH", googleapi.ResolveRelative(c.svc.BasePath, "machines"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	return googleapi.CheckResponse(res)
+}
+
 func (c *HTTPClient) Units() ([]*schema.Unit, error) {
 	var units []*schema.Unit
 	call := c.svc.Units.List()
@@ -125,6 +176,25 @@ func (c *HTTPClient) UnitState(name string) (*schema.UnitState, error) {
 	return u, nil
 }
 
+func (c *HTTPClient) UnitRevisions(name string) ([]*schema.UnitRevision, error) {
+	page, err := c.svc.UnitRevisions.List(name).Do()
+	if err != nil {
+		if is404(err) {
+			err = nil
+		}
+		return nil, err
+	}
+	return page.Revisions, nil
+}
+
+func (c *HTTPClient) UnitScheduling(name string) (*schema.UnitScheduling, error) {
+	us, err := c.svc.UnitScheduling.Get(name).Do()
+	if err != nil && !is404(err) {
+		return nil, err
+	}
+	return us, nil
+}
+
 func (c *HTTPClient) DestroyUnit(name string) error {
 	return c.svc.Units.Delete(name).Do()
 }
@@ -133,6 +203,10 @@ func (c *HTTPClient) CreateUnit(u *schema.Unit) error {
 	return c.svc.Units.Set(u.Name, u).Do()
 }
 
+func (c *HTTPClient) SimulateUnit(u *schema.Unit) (*schema.ScheduleSimulation, error) {
+	return c.svc.Schedule.Simulate(u).Do()
+}
+
 func (c *HTTPClient) SetUnitTargetState(name, target string) error {
 	u := schema.Unit{
 		Name:         name,
@@ -141,6 +215,95 @@ func (c *HTTPClient) SetUnitTargetState(name, target string) error {
 	return c.svc.Units.Set(name, &u).Do()
 }
 
+func (c *HTTPClient) MaintenanceWindows() ([]*schema.MaintenanceWindow, error) {
+	var windows []*schema.MaintenanceWindow
+	call := c.svc.Maintenance.List()
+	for call != nil {
+		page, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, page.MaintenanceWindows...)
+
+		if len(page.NextPageToken) > 0 {
+			call = c.svc.Maintenance.List()
+			call.NextPageToken(page.NextPageToken)
+		} else {
+			call = nil
+		}
+	}
+	return windows, nil
+}
+
+func (c *HTTPClient) SetMaintenanceWindow(mw *schema.MaintenanceWindow) error {
+	return c.svc.Maintenance.Set(mw.Name, mw).Do()
+}
+
+func (c *HTTPClient) DestroyMaintenanceWindow(name string) error {
+	return c.svc.Maintenance.Delete(name).Do()
+}
+
+func (c *HTTPClient) Deployments() ([]*schema.Deployment, error) {
+	var deployments []*schema.Deployment
+	call := c.svc.Deployments.List()
+	for call != nil {
+		page, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+
+		deployments = append(deployments, page.Deployments...)
+
+		if len(page.NextPageToken) > 0 {
+			call = c.svc.Deployments.List()
+			call.NextPageToken(page.NextPageToken)
+		} else {
+			call = nil
+		}
+	}
+	return deployments, nil
+}
+
+func (c *HTTPClient) SetDeployment(d *schema.Deployment) error {
+	// zero replicas is meaningful
+	d.ForceSendFields = append(d.ForceSendFields, "Replicas")
+	return c.svc.Deployments.Set(d.Name, d).Do()
+}
+
+func (c *HTTPClient) DestroyDeployment(name string) error {
+	return c.svc.Deployments.Delete(name).Do()
+}
+
+func (c *HTTPClient) DropIns() ([]*schema.DropIn, error) {
+	var dropIns []*schema.DropIn
+	call := c.svc.DropIns.List()
+	for call != nil {
+		page, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+
+		dropIns = append(dropIns, page.DropIns...)
+
+		if len(page.NextPageToken) > 0 {
+			call = c.svc.DropIns.List()
+			call.NextPageToken(page.NextPageToken)
+		} else {
+			call = nil
+		}
+	}
+	return dropIns, nil
+}
+
+func (c *HTTPClient) SetDropIn(d *schema.DropIn) error {
+	return c.svc.DropIns.Set(d.Unit, d.Name, d).Do()
+}
+
+func (c *HTTPClient) DestroyDropIn(unitName, name string) error {
+	return c.svc.DropIns.Delete(unitName, name).Do()
+}
+
 func is404(err error) bool {
 	googerr, ok := err.(*googleapi.Error)
 	return ok && googerr.Code == http.StatusNotFound
@@ -15,7 +15,11 @@
 package client
 
 import (
+	"errors"
+	"time"
+
 	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/log"
 	"github.com/coreos/fleet/registry"
 	"github.com/coreos/fleet/schema"
 )
@@ -41,9 +45,17 @@ func (rc *RegistryClient) Units() ([]*schema.Unit, error) {
 		sUnitMap[sUnit.Name] = &sUnit
 	}
 
+	completions, err := rc.Registry.CompletedUnits()
+	if err != nil {
+		return nil, err
+	}
+
 	units := make([]*schema.Unit, len(rUnits))
 	for i, ru := range rUnits {
 		units[i] = schema.MapUnitToSchemaUnit(&ru, sUnitMap[ru.Name])
+		if _, ok := completions[ru.Name]; ok && ru.IsBatch() {
+			units[i].CurrentState = string(job.JobStateCompleted)
+		}
 	}
 
 	return units, nil
@@ -64,7 +76,18 @@ func (rc *RegistryClient) Unit(name string) (*schema.Unit, error) {
 		}
 	}
 
-	return schema.MapUnitToSchemaUnit(rUnit, sUnit), nil
+	u := schema.MapUnitToSchemaUnit(rUnit, sUnit)
+	if rUnit.IsBatch() {
+		completions, err := rc.Registry.CompletedUnits()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := completions[name]; ok {
+			u.CurrentState = string(job.JobStateCompleted)
+		}
+	}
+
+	return u, nil
 }
 
 func (rc *RegistryClient) CreateUnit(u *schema.Unit) error {
@@ -83,7 +106,23 @@ func (rc *RegistryClient) CreateUnit(u *schema.Unit) error {
 		rUnit.TargetState = ts
 	}
 
-	return rc.Registry.CreateUnit(&rUnit)
+	if err := rc.Registry.CreateUnit(&rUnit); err != nil {
+		return err
+	}
+
+	// The unit is created at this point, so failing would only make a
+	// retry fail as the unit already exists. Its history merely lacks
+	// this revision.
+	if err := rc.Registry.AddUnitRevision(rUnit.Name, rUnit.Unit, u.Client); err != nil {
+		log.Errorf("Failed recording revision of Unit(%s): %v", rUnit.Name, err)
+	}
+	return nil
+}
+
+// SimulateUnit is not supported, as only the fleet API knows the scheduler
+// the engine of the cluster uses.
+func (rc *RegistryClient) SimulateUnit(u *schema.Unit) (*schema.ScheduleSimulation, error) {
+	return nil, errors.New("scheduling can only be simulated through the fleet API")
 }
 
 func (rc *RegistryClient) UnitState(name string) (*schema.UnitState, error) {
@@ -109,6 +148,108 @@ func (rc *RegistryClient) UnitStates() ([]*schema.UnitState, error) {
 	return states, nil
 }
 
+func (rc *RegistryClient) UnitRevisions(name string) ([]*schema.UnitRevision, error) {
+	rRevisions, err := rc.Registry.UnitRevisions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*schema.UnitRevision, len(rRevisions))
+	for i := range rRevisions {
+		revisions[i] = schema.MapUnitRevisionToSchema(&rRevisions[i])
+	}
+
+	return revisions, nil
+}
+
+func (rc *RegistryClient) UnitScheduling(name string) (*schema.UnitScheduling, error) {
+	ex, err := rc.Registry.UnitSchedulingExplanation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.MapSchedulingExplanationToSchema(name, ex), nil
+}
+
 func (rc *RegistryClient) SetUnitTargetState(name, target string) error {
 	return rc.Registry.SetUnitTargetState(name, job.JobState(target))
 }
+
+func (rc *RegistryClient) MaintenanceWindows() ([]*schema.MaintenanceWindow, error) {
+	windows, err := rc.Registry.MaintenanceWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	smws := make([]*schema.MaintenanceWindow, len(windows))
+	for i := range windows {
+		smws[i] = schema.MapMaintenanceWindowToSchema(&windows[i], now)
+	}
+	return smws, nil
+}
+
+func (rc *RegistryClient) SetMaintenanceWindow(smw *schema.MaintenanceWindow) error {
+	mw, err := schema.MapSchemaToMaintenanceWindow(smw)
+	if err != nil {
+		return err
+	}
+	if err := mw.Validate(); err != nil {
+		return err
+	}
+	return rc.Registry.SetMaintenanceWindow(*mw)
+}
+
+func (rc *RegistryClient) DestroyMaintenanceWindow(name string) error {
+	return rc.Registry.RemoveMaintenanceWindow(name)
+}
+
+func (rc *RegistryClient) Deployments() ([]*schema.Deployment, error) {
+	deployments, err := rc.Registry.Deployments()
+	if err != nil {
+		return nil, err
+	}
+
+	sds := make([]*schema.Deployment, len(deployments))
+	for i := range deployments {
+		sds[i] = schema.MapDeploymentToSchema(&deployments[i])
+	}
+	return sds, nil
+}
+
+func (rc *RegistryClient) SetDeployment(sd *schema.Deployment) error {
+	d := schema.MapSchemaToDeployment(sd)
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	return rc.Registry.SetDeployment(*d)
+}
+
+func (rc *RegistryClient) DestroyDeployment(name string) error {
+	return rc.Registry.DestroyDeployment(name)
+}
+
+func (rc *RegistryClient) DropIns() ([]*schema.DropIn, error) {
+	dropIns, err := rc.Registry.DropIns()
+	if err != nil {
+		return nil, err
+	}
+
+	sds := make([]*schema.DropIn, len(dropIns))
+	for i := range dropIns {
+		sds[i] = schema.MapDropInToSchema(&dropIns[i])
+	}
+	return sds, nil
+}
+
+func (rc *RegistryClient) SetDropIn(sd *schema.DropIn) error {
+	d := schema.MapSchemaToDropIn(sd)
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	return rc.Registry.SetDropIn(*d)
+}
+
+func (rc *RegistryClient) DestroyDropIn(unitName, name string) error {
+	return rc.Registry.RemoveDropIn(unitName, name)
+}
@@ -30,6 +30,10 @@ type Config struct {
 	EtcdCAFile              string
 	EtcdRequestTimeout      float64
 	EngineReconcileInterval float64
+	Scheduler               string
+	SchedulerMetadataKey    string
+	RebalanceMaxMoves       int
+	RebalanceMetric         string
 	PublicIP                string
 	Verbosity               int
 	RawMetadata             string
@@ -41,6 +45,7 @@ type Config struct {
 	VerifyUnits             bool
 	UnitsDirectory          string
 	SystemdUser             bool
+	UnitManager             string
 	AuthorizedKeysFile      string
 }
 
@@ -0,0 +1,122 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/unit"
+)
+
+// disruptionBudget tracks how many instances of each template unit are
+// unavailable during a reconciliation, and whether the engine may
+// voluntarily make another one unavailable, i.e. by unscheduling it to
+// honor Replaces, to preempt it or to rebalance it. Instances unscheduled
+// because their machine went away or can no longer run them are not
+// subject to the budget, but they do count as unavailable.
+type disruptionBudget struct {
+	// limits maps a template name to the maximum number of its instances
+	// allowed to be unavailable
+	limits map[string]int
+	// unavailable maps a template name to the number of its instances
+	// which are currently unavailable
+	unavailable map[string]int
+	// down holds the names of all unavailable instances
+	down map[string]bool
+}
+
+// newDisruptionBudget builds the disruptionBudget of the given cluster
+// state. If instances of a template disagree on MaxUnavailable, the
+// strictest value applies. An instance is available if it is scheduled and
+// its current state matches its target state; instances with target state
+// inactive and completed batch instances are ignored.
+func newDisruptionBudget(clust *clusterState) *disruptionBudget {
+	b := &disruptionBudget{
+		limits:      make(map[string]int),
+		unavailable: make(map[string]int),
+		down:        make(map[string]bool),
+	}
+
+	for _, j := range clust.jobs {
+		tmpl := templateOf(j.Name)
+		if tmpl == "" {
+			continue
+		}
+
+		if max, ok := j.MaxUnavailable(); ok {
+			if cur, ok := b.limits[tmpl]; !ok || max < cur {
+				b.limits[tmpl] = max
+			}
+		}
+
+		if j.TargetState == job.JobStateInactive {
+			continue
+		}
+		if j.State != nil && *j.State == job.JobStateCompleted {
+			continue
+		}
+		if !j.Scheduled() || j.State == nil || *j.State != j.TargetState {
+			b.unavailable[tmpl]++
+			b.down[j.Name] = true
+		}
+	}
+
+	return b
+}
+
+// allows returns whether the named units may all be made unavailable
+// without exceeding the budget of their templates. Units which are already
+// unavailable do not count against the budget.
+func (b *disruptionBudget) allows(names ...string) bool {
+	disrupted := make(map[string]int)
+	for _, name := range names {
+		if b.down[name] {
+			continue
+		}
+
+		tmpl := templateOf(name)
+		max, ok := b.limits[tmpl]
+		if !ok {
+			continue
+		}
+
+		disrupted[tmpl]++
+		if b.unavailable[tmpl]+disrupted[tmpl] > max {
+			return false
+		}
+	}
+	return true
+}
+
+// disrupt records that the named unit was made unavailable.
+func (b *disruptionBudget) disrupt(name string) {
+	if b.down[name] {
+		return
+	}
+
+	if tmpl := templateOf(name); tmpl != "" {
+		b.unavailable[tmpl]++
+		b.down[name] = true
+	}
+}
+
+// templateOf returns the name of the template the named unit is an instance
+// of, or an empty string if it is not an instance.
+func templateOf(name string) string {
+	uni := unit.NewUnitNameInfo(name)
+	if uni == nil || !uni.IsInstance() {
+		return ""
+	}
+	return uni.Template
+}
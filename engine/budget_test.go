@@ -0,0 +1,193 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+)
+
+func TestDisruptionBudget(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	jsLoaded := job.JobStateLoaded
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{Name: "web@1.service", Unit: newFleetUnit(t, "MaxUnavailable=2"), TargetState: jsLaunched},
+			job.Unit{Name: "web@2.service", Unit: newFleetUnit(t, "MaxUnavailable=2"), TargetState: jsLaunched},
+			job.Unit{Name: "web@3.service", Unit: newFleetUnit(t, "MaxUnavailable=2"), TargetState: jsLaunched},
+			job.Unit{Name: "web@4.service", Unit: newFleetUnit(t, "MaxUnavailable=2"), TargetState: jsLaunched},
+			job.Unit{Name: "db@1.service", Unit: newFleetUnit(t), TargetState: jsLaunched},
+			job.Unit{Name: "db@2.service", Unit: newFleetUnit(t), TargetState: jsLaunched},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{Name: "web@1.service", State: &jsLaunched, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "web@2.service", State: &jsLaunched, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "web@3.service", State: &jsLoaded, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "db@1.service", State: &jsLaunched, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "db@2.service", State: &jsLaunched, TargetMachineID: "XXX"},
+		},
+		[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+	)
+
+	// web@3 is not running yet and web@4 is not scheduled, which
+	// exhausts the budget of web@.service
+	b := newDisruptionBudget(clust)
+	if b.allows("web@1.service") {
+		t.Errorf("expected budget of web@.service to be exhausted")
+	}
+	if !b.allows("web@3.service", "web@4.service") {
+		t.Errorf("expected unavailable units not to count against the budget")
+	}
+	if !b.allows("db@1.service", "db@2.service", "foo.service") {
+		t.Errorf("expected units without budget to be allowed")
+	}
+
+	clust.jobs["web@4.service"].TargetState = job.JobStateInactive
+	b = newDisruptionBudget(clust)
+	if !b.allows("web@1.service") {
+		t.Errorf("expected one more instance of web@.service to be allowed")
+	}
+	if b.allows("web@1.service", "web@2.service") {
+		t.Errorf("expected two more instances of web@.service to exceed the budget")
+	}
+
+	b.disrupt("web@1.service")
+	b.disrupt("web@1.service")
+	if b.allows("web@2.service") {
+		t.Errorf("expected budget of web@.service to be exhausted")
+	}
+	if !b.allows("web@1.service") {
+		t.Errorf("expected disrupted unit not to count against the budget twice")
+	}
+}
+
+func TestCalculateClusterTasksDisruptionBudget(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	twoMachines := []machine.MachineState{
+		machine.MachineState{ID: "XXX"},
+		machine.MachineState{ID: "YYY"},
+	}
+
+	tests := []struct {
+		clust *clusterState
+		tasks []*task
+	}{
+		// only one instance is moved despite the imbalance
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "web@1.service", Unit: newFleetUnit(t, "Rebalanceable=true", "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "web@2.service", Unit: newFleetUnit(t, "Rebalanceable=true", "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "web@3.service", Unit: newFleetUnit(t, "Rebalanceable=true", "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "web@4.service", Unit: newFleetUnit(t, "Rebalanceable=true", "MaxUnavailable=1"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "web@1.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "web@2.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "web@3.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "web@4.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				twoMachines,
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "rebalancing away from Machine(XXX)",
+					JobName:   "web@1.service",
+					MachineID: "XXX",
+				},
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "rebalancing away from Machine(XXX)",
+					JobName:   "web@1.service",
+					MachineID: "YYY",
+				},
+			},
+		},
+
+		// instances are not preempted while another one is unavailable
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "web@1.service", Unit: newFleetUnit(t, "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "web@2.service", Unit: newFleetUnit(t, "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "high.service", Unit: newFleetUnit(t, "Priority=10", "Conflicts=web@*"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "web@1.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "web@2.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+
+		// instances are preempted within the budget
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "web@1.service", Unit: newFleetUnit(t, "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "web@2.service", Unit: newFleetUnit(t, "MaxUnavailable=1"), TargetState: jsLaunched},
+					job.Unit{Name: "high.service", Unit: newFleetUnit(t, "Priority=10", "Conflicts=web@*"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "web@1.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "web@2.service", State: &jsLaunched, TargetMachineID: "YYY"},
+				},
+				twoMachines,
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "preempted by higher-priority Unit(high.service)",
+					JobName:   "web@1.service",
+					MachineID: "XXX",
+				},
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "high.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		rb, err := NewRebalancer(5, RebalanceByUnits)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		r := NewReconciler(&leastLoadedScheduler{}, rb)
+		tasks := make([]*task, 0)
+		for tsk := range r.calculateClusterTasks(tt.clust, make(chan struct{})) {
+			tasks = append(tasks, tsk)
+		}
+
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: task mismatch\nexpected %v\n got %v", i, tt.tasks, tasks)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// collectCompleted returns the tasks destroying the completed batch units
+// in the given cluster state whose CompletedTTL expired at the given time.
+// The units are removed from the cluster state.
+func collectCompleted(clust *clusterState, now time.Time) []*task {
+	var names []string
+	for name := range clust.completions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tasks []*task
+	for _, name := range names {
+		j, ok := clust.jobs[name]
+		if !ok {
+			continue
+		}
+		ttl, ok := j.CompletedTTL()
+		if !ok {
+			continue
+		}
+
+		c := clust.completions[name]
+		if now.Sub(c.Time) < ttl {
+			continue
+		}
+
+		reason := fmt.Sprintf("completed on Machine(%s) more than %s ago", c.MachineID, ttl)
+		tasks = append(tasks, &task{Type: taskTypeDestroyUnit, Reason: reason, JobName: name})
+		clust.destroy(name)
+		delete(clust.completions, name)
+	}
+	return tasks
+}
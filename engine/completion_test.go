@@ -0,0 +1,83 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+)
+
+func TestCollectCompleted(t *testing.T) {
+	jsLoaded := job.JobStateLoaded
+	now := time.Now()
+
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{Name: "expired.service", Unit: newFleetUnit(t, "Type=batch", "CompletedTTL=1h"), TargetState: job.JobStateLaunched},
+			job.Unit{Name: "recent.service", Unit: newFleetUnit(t, "Type=batch", "CompletedTTL=1h"), TargetState: job.JobStateLaunched},
+			job.Unit{Name: "kept.service", Unit: newFleetUnit(t, "Type=batch"), TargetState: job.JobStateLaunched},
+			job.Unit{Name: "running.service", Unit: newFleetUnit(t, "Type=batch", "CompletedTTL=1h"), TargetState: job.JobStateLaunched},
+			// only batch units complete
+			job.Unit{Name: "service.service", Unit: newFleetUnit(t, "CompletedTTL=1h"), TargetState: job.JobStateLaunched},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{Name: "expired.service", State: &jsLoaded, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "recent.service", State: &jsLoaded, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "kept.service", State: &jsLoaded, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "service.service", State: &jsLoaded, TargetMachineID: "XXX"},
+		},
+		[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+	)
+	clust.applyCompletions(map[string]job.Completion{
+		"expired.service": job.Completion{MachineID: "XXX", Time: now.Add(-2 * time.Hour)},
+		"recent.service":  job.Completion{MachineID: "XXX", Time: now.Add(-time.Minute)},
+		"kept.service":    job.Completion{MachineID: "XXX", Time: now.Add(-48 * time.Hour)},
+		"service.service": job.Completion{MachineID: "XXX", Time: now.Add(-2 * time.Hour)},
+	})
+
+	for name, completed := range map[string]bool{
+		"expired.service": true,
+		"recent.service":  true,
+		"kept.service":    true,
+		"running.service": false,
+		"service.service": false,
+	} {
+		j := clust.jobs[name]
+		if got := j.State != nil && *j.State == job.JobStateCompleted; got != completed {
+			t.Errorf("unexpected state of %s: got completed=%t, want %t", name, got, completed)
+		}
+	}
+
+	want := []*task{
+		&task{
+			Type:    taskTypeDestroyUnit,
+			Reason:  "completed on Machine(XXX) more than 1h0m0s ago",
+			JobName: "expired.service",
+		},
+	}
+	if got := collectCompleted(clust, now); !reflect.DeepEqual(want, got) {
+		t.Errorf("task mismatch\nexpected %v\n got %v", want, got)
+	}
+	if _, ok := clust.jobs["expired.service"]; ok {
+		t.Errorf("expected expired.service to be removed from the cluster state")
+	}
+	if got := collectCompleted(clust, now); len(got) != 0 {
+		t.Errorf("expected no more tasks, got %v", got)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/unit"
+)
+
+// cronStartingDeadline is how late the engine may launch the instance of a
+// tick of a schedule. Ticks missed for longer, e.g. while no engine was
+// leading the cluster, are skipped.
+const cronStartingDeadline = time.Minute
+
+// scheduledInstance is an instance of a scheduled template unit.
+type scheduledInstance struct {
+	name string
+	tick time.Time
+}
+
+type scheduledInstancesByTick []scheduledInstance
+
+func (si scheduledInstancesByTick) Len() int           { return len(si) }
+func (si scheduledInstancesByTick) Swap(i, j int)      { si[i], si[j] = si[j], si[i] }
+func (si scheduledInstancesByTick) Less(i, j int) bool { return si[i].tick.Before(si[j].tick) }
+
+// runSchedules launches a new instance of each scheduled template unit of
+// the given cluster state whose schedule fired recently, following the
+// ConcurrencyPolicy of the template, and destroys the finished instances
+// beyond its ScheduleHistory. It returns the tasks doing so; the cluster
+// state is updated accordingly. Templates which are also the target of a
+// Deployment are skipped.
+func runSchedules(clust *clusterState, now time.Time) []*task {
+	deployed := make(map[string]bool)
+	for _, d := range clust.deployments {
+		deployed[d.Template] = true
+	}
+
+	var templates []string
+	for name, j := range clust.jobs {
+		uni := unit.NewUnitNameInfo(name)
+		if uni == nil || !uni.IsTemplate() {
+			continue
+		}
+		if _, ok := j.CronSchedule(); ok {
+			templates = append(templates, name)
+		}
+	}
+	sort.Strings(templates)
+
+	var tasks []*task
+	for _, name := range templates {
+		if deployed[name] {
+			log.Infof("Not running schedule of %s: template unit is deployed", name)
+			continue
+		}
+		tasks = append(tasks, runSchedule(clust, clust.jobs[name], now)...)
+	}
+	return tasks
+}
+
+// runSchedule runs the schedule of the given template unit.
+func runSchedule(clust *clusterState, tmpl *job.Job, now time.Time) []*task {
+	cs, _ := tmpl.CronSchedule()
+
+	var running, finished []scheduledInstance
+	for name := range clust.jobs {
+		tick, ok := job.ScheduledInstanceTick(tmpl.Name, name)
+		if !ok {
+			continue
+		}
+		si := scheduledInstance{name: name, tick: tick}
+		if clust.finished(name) {
+			finished = append(finished, si)
+		} else {
+			running = append(running, si)
+		}
+	}
+	sort.Sort(scheduledInstancesByTick(running))
+	sort.Sort(scheduledInstancesByTick(finished))
+
+	var tasks []*task
+	destroy := func(si scheduledInstance, reason string) {
+		tasks = append(tasks, &task{Type: taskTypeDestroyUnit, Reason: reason, JobName: si.name})
+		clust.destroy(si.name)
+	}
+
+	if tick, ok := cs.LastTick(now, cronStartingDeadline); ok {
+		name := job.ScheduledInstanceName(tmpl.Name, tick)
+		if _, exists := clust.jobs[name]; !exists {
+			launch := true
+			switch policy := tmpl.ConcurrencyPolicy(); {
+			case len(running) == 0:
+			case policy == job.ConcurrencyForbid:
+				log.Infof("Not launching %s: previous instances of %s are still running", name, tmpl.Name)
+				launch = false
+			case policy == job.ConcurrencyReplace:
+				for _, si := range running {
+					destroy(si, fmt.Sprintf("replaced by %s", name))
+				}
+			}
+
+			if launch {
+				u := &job.Unit{
+					Name:        name,
+					Unit:        tmpl.Unit,
+					TargetState: job.JobStateLaunched,
+				}
+				reason := fmt.Sprintf("schedule of %s fired at %s", tmpl.Name, tick.UTC().Format(time.RFC3339))
+				tasks = append(tasks, &task{Type: taskTypeCreateUnit, Reason: reason, JobName: name, Unit: u})
+				clust.submit(u)
+			}
+		}
+	}
+
+	history := tmpl.ScheduleHistory()
+	for i := 0; i < len(finished)-history; i++ {
+		destroy(finished[i], fmt.Sprintf("beyond the %d finished instances of %s kept", history, tmpl.Name))
+	}
+
+	return tasks
+}
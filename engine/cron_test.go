@@ -0,0 +1,171 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestRunSchedules(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	tick := time.Date(2016, time.October, 17, 12, 15, 0, 0, time.UTC)
+	now := tick.Add(30 * time.Second)
+	name := func(tick time.Time) string {
+		return job.ScheduledInstanceName("backup@.service", tick)
+	}
+	older, old := tick.Add(-30*time.Minute), tick.Add(-15*time.Minute)
+	launch := &task{
+		Type:    taskTypeCreateUnit,
+		Reason:  "schedule of backup@.service fired at 2016-10-17T12:15:00Z",
+		JobName: name(tick),
+	}
+
+	tests := []struct {
+		policy string
+		// running is whether the most recent previous instance is still
+		// running
+		running bool
+		now     time.Time
+		tasks   []*task
+	}{
+		// the finished instances beyond the history are destroyed
+		{
+			policy: "allow",
+			now:    now,
+			tasks: []*task{
+				launch,
+				&task{
+					Type:    taskTypeDestroyUnit,
+					Reason:  "beyond the 1 finished instances of backup@.service kept",
+					JobName: name(older),
+				},
+			},
+		},
+		// no tick within the deadline
+		{
+			policy: "allow",
+			now:    tick.Add(2 * time.Minute),
+			tasks: []*task{
+				&task{
+					Type:    taskTypeDestroyUnit,
+					Reason:  "beyond the 1 finished instances of backup@.service kept",
+					JobName: name(older),
+				},
+			},
+		},
+		{
+			policy:  "allow",
+			running: true,
+			now:     now,
+			tasks:   []*task{launch},
+		},
+		{
+			policy:  "forbid",
+			running: true,
+			now:     now,
+			tasks:   nil,
+		},
+		{
+			policy:  "replace",
+			running: true,
+			now:     now,
+			tasks: []*task{
+				&task{
+					Type:    taskTypeDestroyUnit,
+					Reason:  fmt.Sprintf("replaced by %s", name(tick)),
+					JobName: name(old),
+				},
+				launch,
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		uf := newFleetUnit(t, "Type=batch", "Schedule=*/15 * * * *", "ScheduleHistory=1", "ConcurrencyPolicy="+tt.policy)
+		clust := newClusterState(
+			[]job.Unit{
+				job.Unit{Name: "backup@.service", Unit: uf, TargetState: job.JobStateInactive},
+				job.Unit{Name: name(older), Unit: uf, TargetState: jsLaunched},
+				job.Unit{Name: name(old), Unit: uf, TargetState: jsLaunched},
+			},
+			[]job.ScheduledUnit{
+				job.ScheduledUnit{Name: name(older), State: &jsLaunched, TargetMachineID: "XXX"},
+				job.ScheduledUnit{Name: name(old), State: &jsLaunched, TargetMachineID: "XXX"},
+			},
+			[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+		)
+		completions := map[string]job.Completion{
+			name(older): job.Completion{MachineID: "XXX", Time: older.Add(time.Minute)},
+		}
+		if !tt.running {
+			completions[name(old)] = job.Completion{MachineID: "XXX", Time: old.Add(time.Minute)}
+		}
+		clust.applyCompletions(completions)
+
+		tasks := runSchedules(clust, tt.now)
+		for _, tsk := range tasks {
+			if tsk.Type != taskTypeCreateUnit {
+				continue
+			}
+			if tsk.Unit == nil || tsk.Unit.Name != tsk.JobName || tsk.Unit.TargetState != job.JobStateLaunched {
+				t.Errorf("case %d: unexpected unit created: %#v", i, tsk.Unit)
+			}
+			if _, ok := clust.jobs[tsk.JobName]; !ok {
+				t.Errorf("case %d: expected %s to be added to the cluster state", i, tsk.JobName)
+			}
+			// the unit is compared separately
+			tsk.Unit = nil
+		}
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: task mismatch\nexpected %v\n got %v", i, tt.tasks, tasks)
+		}
+	}
+}
+
+func TestRunSchedulesFailedInstance(t *testing.T) {
+	// failed instances are finished, so they do not block the schedule
+	jsLaunched := job.JobStateLaunched
+	tick := time.Date(2016, time.October, 17, 12, 15, 0, 0, time.UTC)
+	old := job.ScheduledInstanceName("backup@.service", tick.Add(-15*time.Minute))
+	uf := newFleetUnit(t, "Type=batch", "Schedule=*/15 * * * *", "ConcurrencyPolicy=forbid")
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{Name: "backup@.service", Unit: uf, TargetState: job.JobStateInactive},
+			job.Unit{Name: old, Unit: uf, TargetState: jsLaunched},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{Name: old, State: &jsLaunched, TargetMachineID: "XXX"},
+		},
+		[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+	)
+	if !clust.needsUnitStates() {
+		t.Fatalf("expected scheduled templates to need unit states")
+	}
+	clust.applyUnitStates([]*unit.UnitState{
+		&unit.UnitState{UnitName: old, MachineID: "XXX", ActiveState: "failed"},
+	})
+
+	tasks := runSchedules(clust, tick)
+	if len(tasks) != 1 || tasks[0].Type != taskTypeCreateUnit {
+		t.Errorf("expected a new instance to be launched, got %v", tasks)
+	}
+}
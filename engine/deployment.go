@@ -0,0 +1,76 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/log"
+)
+
+// scaleDeployments creates the missing instances of each Deployment of the
+// given cluster state and destroys those numbered beyond its number of
+// replicas, returning the tasks doing so. The cluster state is updated
+// accordingly. Instances are created from the template unit stored in the
+// Registry and launched; a Deployment whose template does not exist is
+// skipped. Instances of the template not named after a number are ignored.
+func scaleDeployments(clust *clusterState) []*task {
+	var tasks []*task
+	for _, d := range clust.deployments {
+		d := d
+		tmpl, ok := clust.jobs[d.Template]
+		if !ok {
+			if _, ok := clust.gUnits[d.Template]; ok {
+				log.Infof("Not scaling Deployment(%s): global template units cannot be deployed", d.Template)
+			} else {
+				log.Infof("Not scaling Deployment(%s): template unit does not exist", d.Template)
+			}
+			continue
+		}
+
+		var names []string
+		for name := range clust.jobs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if n, ok := d.InstanceNumber(name); ok && n > d.Replicas {
+				reason := fmt.Sprintf("Deployment(%s) scaled to %d replicas", d.Template, d.Replicas)
+				tasks = append(tasks, &task{Type: taskTypeDestroyUnit, Reason: reason, JobName: name})
+				clust.destroy(name)
+			}
+		}
+
+		for n := 1; n <= d.Replicas; n++ {
+			name := d.InstanceName(n)
+			if _, ok := clust.jobs[name]; ok {
+				continue
+			}
+
+			u := &job.Unit{
+				Name:        name,
+				Unit:        tmpl.Unit,
+				TargetState: job.JobStateLaunched,
+			}
+			reason := fmt.Sprintf("Deployment(%s) scaled to %d replicas", d.Template, d.Replicas)
+			tasks = append(tasks, &task{Type: taskTypeCreateUnit, Reason: reason, JobName: name, Unit: u})
+			clust.submit(u)
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,132 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+)
+
+func TestScaleDeployments(t *testing.T) {
+	jsInactive := job.JobStateInactive
+	jsLaunched := job.JobStateLaunched
+	tmpl := job.Unit{Name: "web@.service", TargetState: jsInactive}
+
+	tests := []struct {
+		units       []job.Unit
+		deployments []job.Deployment
+		// expected tasks, as "Type JobName"
+		tasks []string
+		// expected units in the cluster state afterwards
+		remaining []string
+	}{
+		// missing instances are created
+		{
+			units: []job.Unit{
+				tmpl,
+				job.Unit{Name: "web@2.service", TargetState: jsLaunched},
+			},
+			deployments: []job.Deployment{{Template: "web@.service", Replicas: 3}},
+			tasks:       []string{"CreateUnit web@1.service", "CreateUnit web@3.service"},
+			remaining:   []string{"web@.service", "web@1.service", "web@2.service", "web@3.service"},
+		},
+		// extra instances are destroyed, others are left alone
+		{
+			units: []job.Unit{
+				tmpl,
+				job.Unit{Name: "web@1.service", TargetState: jsLaunched},
+				job.Unit{Name: "web@2.service", TargetState: jsLaunched},
+				job.Unit{Name: "web@3.service", TargetState: jsLaunched},
+				job.Unit{Name: "web@canary.service", TargetState: jsLaunched},
+				job.Unit{Name: "db@4.service", TargetState: jsLaunched},
+			},
+			deployments: []job.Deployment{{Template: "web@.service", Replicas: 1}},
+			tasks:       []string{"DestroyUnit web@2.service", "DestroyUnit web@3.service"},
+			remaining:   []string{"db@4.service", "web@.service", "web@1.service", "web@canary.service"},
+		},
+		// nothing to do without the template
+		{
+			units: []job.Unit{
+				job.Unit{Name: "web@1.service", TargetState: jsLaunched},
+			},
+			deployments: []job.Deployment{{Template: "web@.service", Replicas: 0}},
+			tasks:       []string{},
+			remaining:   []string{"web@1.service"},
+		},
+	}
+
+	for i, tt := range tests {
+		clust := newClusterState(tt.units, nil, nil)
+		clust.deployments = tt.deployments
+
+		tasks := make([]string, 0)
+		for _, tsk := range scaleDeployments(clust) {
+			tasks = append(tasks, tsk.Type+" "+tsk.JobName)
+			if tsk.Type == taskTypeCreateUnit && (tsk.Unit == nil || tsk.Unit.Name != tsk.JobName || tsk.Unit.TargetState != jsLaunched) {
+				t.Errorf("case %d: bad Unit in task %v", i, tsk)
+			}
+		}
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: expected tasks %v, got %v", i, tt.tasks, tasks)
+		}
+
+		remaining := make([]string, 0)
+		for name := range clust.jobs {
+			remaining = append(remaining, name)
+		}
+		sort.Strings(remaining)
+		if !reflect.DeepEqual(tt.remaining, remaining) {
+			t.Errorf("case %d: expected units %v, got %v", i, tt.remaining, remaining)
+		}
+	}
+}
+
+func TestCalculateClusterTasksDeployment(t *testing.T) {
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{Name: "web@.service", TargetState: job.JobStateInactive},
+		},
+		nil,
+		[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+	)
+	clust.deployments = []job.Deployment{{Template: "web@.service", Replicas: 1}}
+
+	r := NewReconciler(&leastLoadedScheduler{}, nil)
+	var tasks []*task
+	for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+		tasks = append(tasks, tsk)
+	}
+
+	// the new instance is scheduled right away
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %v", tasks)
+	}
+	if tasks[0].Type != taskTypeCreateUnit || tasks[0].JobName != "web@1.service" {
+		t.Errorf("unexpected first task %v", tasks[0])
+	}
+	expect := &task{
+		Type:      taskTypeAttemptScheduleUnit,
+		Reason:    "target state launched and unit not scheduled",
+		JobName:   "web@1.service",
+		MachineID: "XXX",
+	}
+	if !reflect.DeepEqual(expect, tasks[1]) {
+		t.Errorf("expected second task %v, got %v", expect, tasks[1])
+	}
+}
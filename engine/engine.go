@@ -16,8 +16,10 @@ package engine
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
+	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/log"
 	"github.com/coreos/fleet/machine"
 	"github.com/coreos/fleet/metrics"
@@ -32,6 +34,11 @@ const (
 
 	// version at which the current engine code operates
 	engineVersion = 1
+
+	// explanationTTL determines how long the reasons a unit could not be
+	// scheduled remain in the Registry without being refreshed. Unchanged
+	// explanations are refreshed once half of it has passed.
+	explanationTTL = 10 * time.Minute
 )
 
 type Engine struct {
@@ -44,6 +51,10 @@ type Engine struct {
 
 	lease lease.Lease
 
+	// explained maps the name of each unit whose scheduling explanation
+	// was last recorded by this engine to that explanation
+	explained map[string]recordedExplanation
+
 	updateEngineState func(newEngine machine.MachineState)
 }
 
@@ -52,8 +63,8 @@ type CompleteRegistry interface {
 	registry.ClusterRegistry
 }
 
-func New(reg CompleteRegistry, lManager lease.Manager, rStream pkg.EventStream, mach machine.Machine, updateEngineState func(newEngine machine.MachineState)) *Engine {
-	rec := NewReconciler()
+func New(reg CompleteRegistry, lManager lease.Manager, rStream pkg.EventStream, mach machine.Machine, sched Scheduler, rebalancer *Rebalancer, updateEngineState func(newEngine machine.MachineState)) *Engine {
+	rec := NewReconciler(sched, rebalancer)
 	return &Engine{
 		rec:               rec,
 		registry:          reg,
@@ -62,6 +73,7 @@ func New(reg CompleteRegistry, lManager lease.Manager, rStream pkg.EventStream,
 		rStream:           rStream,
 		machine:           mach,
 		updateEngineState: updateEngineState,
+		explained:         make(map[string]recordedExplanation),
 	}
 }
 
@@ -103,6 +115,8 @@ func (e *Engine) Run(ival time.Duration, stop <-chan struct{}) {
 		}
 
 		if !isLeader(e.lease, machID) {
+			// the leader records explanations meanwhile
+			e.explained = make(map[string]recordedExplanation)
 			return
 		}
 
@@ -245,25 +259,72 @@ func renewLeadership(l lease.Lease, ttl time.Duration) lease.Lease {
 }
 
 func (e *Engine) clusterState() (*clusterState, error) {
-	units, err := e.registry.Units()
+	return clusterStateFromRegistry(e.registry)
+}
+
+func clusterStateFromRegistry(reg registry.Registry) (*clusterState, error) {
+	units, err := reg.Units()
 	if err != nil {
 		log.Errorf("Failed fetching Units from Registry: %v", err)
 		return nil, err
 	}
 
-	sUnits, err := e.registry.Schedule()
+	sUnits, err := reg.Schedule()
 	if err != nil {
 		log.Errorf("Failed fetching schedule from Registry: %v", err)
 		return nil, err
 	}
 
-	machines, err := e.registry.Machines()
+	machines, err := reg.Machines()
 	if err != nil {
 		log.Errorf("Failed fetching Machines from Registry: %v", err)
 		return nil, err
 	}
 
-	return newClusterState(units, sUnits, machines), nil
+	windows, err := reg.MaintenanceWindows()
+	if err != nil {
+		log.Errorf("Failed fetching maintenance windows from Registry: %v", err)
+		return nil, err
+	}
+
+	deployments, err := reg.Deployments()
+	if err != nil {
+		log.Errorf("Failed fetching Deployments from Registry: %v", err)
+		return nil, err
+	}
+
+	blacklist, err := reg.UnitBlacklist()
+	if err != nil {
+		log.Errorf("Failed fetching unit blacklist from Registry: %v", err)
+		return nil, err
+	}
+
+	completions, err := reg.CompletedUnits()
+	if err != nil {
+		log.Errorf("Failed fetching completed Units from Registry: %v", err)
+		return nil, err
+	}
+
+	clust := newClusterState(units, sUnits, machines)
+	clust.applyMaintenance(windows, time.Now())
+	clust.applyCompletions(completions)
+	clust.deployments = deployments
+	for name, machIDs := range blacklist {
+		for _, machID := range machIDs {
+			clust.blacklistUnit(name, machID)
+		}
+	}
+
+	if clust.needsUnitStates() {
+		states, err := reg.UnitStates()
+		if err != nil {
+			log.Errorf("Failed fetching UnitStates from Registry: %v", err)
+			return nil, err
+		}
+		clust.applyUnitStates(states)
+	}
+
+	return clust, nil
 }
 
 func (e *Engine) unscheduleUnit(name, machID string) (err error) {
@@ -276,6 +337,70 @@ func (e *Engine) unscheduleUnit(name, machID string) (err error) {
 	return
 }
 
+func (e *Engine) blacklistUnit(name, machID string, ttl time.Duration) (err error) {
+	err = e.registry.BlacklistUnit(name, machID, ttl)
+	if err != nil {
+		log.Errorf("Failed blacklisting Unit(%s) on Machine(%s): %v", name, machID, err)
+	} else {
+		log.Infof("Blacklisted Unit(%s) on Machine(%s) for %s", name, machID, ttl)
+	}
+	return
+}
+
+func (e *Engine) createUnit(u *job.Unit) (err error) {
+	err = e.registry.CreateUnit(u)
+	if err != nil {
+		log.Errorf("Failed creating Unit(%s): %v", u.Name, err)
+	} else {
+		log.Infof("Created Unit(%s)", u.Name)
+	}
+	return
+}
+
+func (e *Engine) destroyUnit(name string) (err error) {
+	err = e.registry.DestroyUnit(name)
+	if err != nil {
+		log.Errorf("Failed destroying Unit(%s): %v", name, err)
+	} else {
+		log.Infof("Destroyed Unit(%s)", name)
+	}
+	return
+}
+
+// recordedExplanation is a scheduling explanation recorded in the Registry
+type recordedExplanation struct {
+	ex       *job.SchedulingExplanation
+	recorded time.Time
+}
+
+// explainUnits records in the Registry why each of the given units could not
+// be scheduled.
+// To spare the Registry, an explanation is only written if it changed since
+// it was last recorded, or is about to expire.
+func (e *Engine) explainUnits(explanations map[string]*job.SchedulingExplanation) {
+	now := time.Now()
+	for name := range e.explained {
+		// the unit was scheduled meanwhile, its explanation is
+		// written anew should it become unschedulable again
+		if _, ok := explanations[name]; !ok {
+			delete(e.explained, name)
+		}
+	}
+
+	for name, ex := range explanations {
+		if prev, ok := e.explained[name]; ok && reflect.DeepEqual(prev.ex, ex) && now.Sub(prev.recorded) < explanationTTL/2 {
+			continue
+		}
+
+		if err := e.registry.SetUnitSchedulingExplanation(name, ex, explanationTTL); err != nil {
+			log.Errorf("Failed recording scheduling explanation for Unit(%s): %v", name, err)
+			delete(e.explained, name)
+			continue
+		}
+		e.explained[name] = recordedExplanation{ex: ex, recorded: now}
+	}
+}
+
 // attemptScheduleUnit tries to persist a scheduling decision in the
 // Registry, returning true on success. If any communication with the
 // Registry fails, false is returned.
@@ -18,6 +18,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/registry"
 )
 
@@ -162,3 +163,60 @@ func TestIsLeader(t *testing.T) {
 		}
 	}
 }
+
+// explanationCountingRegistry counts the scheduling explanations written
+type explanationCountingRegistry struct {
+	registry.Registry
+	writes int
+}
+
+func (r *explanationCountingRegistry) SetUnitSchedulingExplanation(name string, ex *job.SchedulingExplanation, ttl time.Duration) error {
+	r.writes++
+	return r.Registry.SetUnitSchedulingExplanation(name, ex, ttl)
+}
+
+func TestExplainUnits(t *testing.T) {
+	reg := &explanationCountingRegistry{Registry: registry.NewFakeRegistry()}
+	e := &Engine{registry: reg, explained: make(map[string]recordedExplanation)}
+
+	noAgents := map[string]*job.SchedulingExplanation{
+		"foo.service": &job.SchedulingExplanation{Reason: "no agents available to run job"},
+	}
+	e.explainUnits(noAgents)
+	if reg.writes != 1 {
+		t.Fatalf("expected 1 write, got %d", reg.writes)
+	}
+
+	// unchanged explanations are not written again
+	e.explainUnits(map[string]*job.SchedulingExplanation{
+		"foo.service": &job.SchedulingExplanation{Reason: "no agents available to run job"},
+	})
+	if reg.writes != 1 {
+		t.Errorf("expected unchanged explanation not to be written, got %d writes", reg.writes)
+	}
+
+	// unless they are about to expire
+	e.explained["foo.service"] = recordedExplanation{ex: noAgents["foo.service"], recorded: time.Now().Add(-explanationTTL)}
+	e.explainUnits(noAgents)
+	if reg.writes != 2 {
+		t.Errorf("expected expiring explanation to be refreshed, got %d writes", reg.writes)
+	}
+
+	// changed explanations are written
+	e.explainUnits(map[string]*job.SchedulingExplanation{
+		"foo.service": &job.SchedulingExplanation{Reason: "no agents able to run job"},
+	})
+	if reg.writes != 3 {
+		t.Errorf("expected changed explanation to be written, got %d writes", reg.writes)
+	}
+	if ex, _ := reg.UnitSchedulingExplanation("foo.service"); ex == nil || ex.Reason != "no agents able to run job" {
+		t.Errorf("unexpected explanation in registry: %#v", ex)
+	}
+
+	// a unit scheduled meanwhile is explained anew
+	e.explainUnits(nil)
+	e.explainUnits(noAgents)
+	if reg.writes != 4 {
+		t.Errorf("expected explanation of unit pending again to be written, got %d writes", reg.writes)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/coreos/fleet/job"
+)
+
+// unitObservation is the state of a unit as last seen by the engine.
+type unitObservation struct {
+	machineID   string
+	activeState string
+	// nRestarts is how often systemd restarted the unit automatically
+	nRestarts uint32
+	// activeEnter is when the unit last entered the active state
+	activeEnter uint64
+}
+
+// failuresSince returns how often the unit failed between the given previous
+// observation on the same machine and this one. Failures the unit recovered
+// from are counted from the increase of its restart count, which systemd
+// resets when the unit is started anew. Entering the failed state counts as
+// one more failure, as does failing again after having been active in the
+// meantime.
+func (obs unitObservation) failuresSince(prev unitObservation) int {
+	failures := int(obs.nRestarts)
+	if obs.nRestarts >= prev.nRestarts {
+		failures = int(obs.nRestarts - prev.nRestarts)
+	}
+
+	if obs.activeState == "failed" && (prev.activeState != "failed" || obs.activeEnter != prev.activeEnter) {
+		failures++
+	}
+	return failures
+}
+
+// failedJob is a Job that entered the failed state on its machine more
+// often than its FailurePolicy allows.
+type failedJob struct {
+	name      string
+	machineID string
+	policy    *job.FailurePolicy
+}
+
+// failureTracker remembers, across reconciliations, when units with a
+// FailurePolicy were seen failing on their machine. Failures are detected by
+// comparing the state of a unit with its state in the previous
+// reconciliation, so a unit staying failed counts as a single failure, while
+// a unit restarted by systemd counts each of its restarts.
+type failureTracker struct {
+	last     map[string]unitObservation
+	failures map[string][]time.Time
+}
+
+func newFailureTracker() *failureTracker {
+	return &failureTracker{
+		last:     make(map[string]unitObservation),
+		failures: make(map[string][]time.Time),
+	}
+}
+
+// failedJobs records the state of the scheduled units with a FailurePolicy
+// in the given cluster state, and returns those which failed on their
+// machine at least as many times as their policy allows within its window,
+// sorted by name. The failure history of the returned units is reset.
+func (ft *failureTracker) failedJobs(clust *clusterState, now time.Time) []failedJob {
+	seen := make(map[string]bool)
+	var failed []failedJob
+	for _, j := range clust.jobs {
+		policy, ok := j.RescheduleOnFailure()
+		if !ok || !j.Scheduled() || j.TargetState == job.JobStateInactive {
+			continue
+		}
+		seen[j.Name] = true
+
+		obs := unitObservation{machineID: j.TargetMachineID}
+		if us, ok := clust.states[j.Name]; ok {
+			obs.activeState = us.ActiveState
+			obs.nRestarts = us.NRestarts
+			obs.activeEnter = us.ActiveEnterTimestamp
+		}
+
+		prev, ok := ft.last[j.Name]
+		ft.last[j.Name] = obs
+		if ok && prev.machineID != obs.machineID {
+			// failures on another machine do not count
+			delete(ft.failures, j.Name)
+			ok = false
+		}
+
+		var recent []time.Time
+		for _, t := range ft.failures[j.Name] {
+			if now.Sub(t) < policy.Window {
+				recent = append(recent, t)
+			}
+		}
+
+		// restarts before the unit was first seen on its machine are
+		// unaccounted for, but being failed already is a failure
+		failures := 0
+		if ok {
+			failures = obs.failuresSince(prev)
+		} else if obs.activeState == "failed" {
+			failures = 1
+		}
+		for i := 0; i < failures && len(recent) < policy.Failures; i++ {
+			recent = append(recent, now)
+		}
+
+		if len(recent) < policy.Failures {
+			ft.failures[j.Name] = recent
+			continue
+		}
+
+		failed = append(failed, failedJob{name: j.Name, machineID: j.TargetMachineID, policy: policy})
+		delete(ft.failures, j.Name)
+	}
+
+	// forget units that are gone or no longer subject to a policy
+	for name := range ft.last {
+		if !seen[name] {
+			delete(ft.last, name)
+			delete(ft.failures, name)
+		}
+	}
+
+	sort.Sort(failedJobsByName(failed))
+	return failed
+}
+
+type failedJobsByName []failedJob
+
+func (fj failedJobsByName) Len() int           { return len(fj) }
+func (fj failedJobsByName) Swap(i, j int)      { fj[i], fj[j] = fj[j], fj[i] }
+func (fj failedJobsByName) Less(i, j int) bool { return fj[i].name < fj[j].name }
@@ -0,0 +1,235 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/unit"
+)
+
+// observeFailures returns the names of the failed jobs after observing
+// foo.service, subject to the given RescheduleOnFailure policy, in the given
+// state
+func observeFailures(t *testing.T, ft *failureTracker, policy string, us unit.UnitState, now time.Time) []string {
+	jsLaunched := job.JobStateLaunched
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{
+				Name:        "foo.service",
+				Unit:        newFleetUnit(t, "RescheduleOnFailure="+policy),
+				TargetState: job.JobStateLaunched,
+			},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{
+				Name:            "foo.service",
+				State:           &jsLaunched,
+				TargetMachineID: us.MachineID,
+			},
+		},
+		[]machine.MachineState{
+			machine.MachineState{ID: us.MachineID},
+		},
+	)
+	us.UnitName = "foo.service"
+	clust.applyUnitStates([]*unit.UnitState{&us})
+
+	var names []string
+	for _, fj := range ft.failedJobs(clust, now) {
+		names = append(names, fj.name)
+	}
+	return names
+}
+
+func TestFailureTracker(t *testing.T) {
+	start := time.Now()
+	ft := newFailureTracker()
+
+	steps := []struct {
+		machID      string
+		activeState string
+		after       time.Duration
+		failed      []string
+	}{
+		{"XXX", "failed", 0, nil},
+		// staying failed counts as a single failure
+		{"XXX", "failed", time.Minute, nil},
+		{"XXX", "active", 2 * time.Minute, nil},
+		{"XXX", "failed", 3 * time.Minute, []string{"foo.service"}},
+		// history is reset once the policy triggered
+		{"XXX", "active", 4 * time.Minute, nil},
+		{"XXX", "failed", 5 * time.Minute, nil},
+		{"XXX", "active", 6 * time.Minute, nil},
+		// the previous failure fell out of the window
+		{"XXX", "failed", 16 * time.Minute, nil},
+		// failures on another machine do not count
+		{"YYY", "active", 17 * time.Minute, nil},
+		{"YYY", "failed", 18 * time.Minute, nil},
+		{"YYY", "active", 19 * time.Minute, nil},
+		{"YYY", "failed", 20 * time.Minute, []string{"foo.service"}},
+	}
+	for i, st := range steps {
+		us := unit.UnitState{MachineID: st.machID, ActiveState: st.activeState}
+		failed := observeFailures(t, ft, "2/10m", us, start.Add(st.after))
+		if !reflect.DeepEqual(st.failed, failed) {
+			t.Errorf("step %d: unexpected failed jobs: got %v, want %v", i, failed, st.failed)
+		}
+	}
+}
+
+func TestFailureTrackerRestarts(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		states []unit.UnitState
+		failed []string
+	}{
+		// a unit cycling through failed and active counts each failure,
+		// whether or not the engine sees it in between
+		{
+			states: []unit.UnitState{
+				{MachineID: "XXX", ActiveState: "failed"},
+				{MachineID: "XXX", ActiveState: "activating", NRestarts: 1},
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 1, ActiveEnterTimestamp: 100},
+				{MachineID: "XXX", ActiveState: "failed", NRestarts: 1, ActiveEnterTimestamp: 100},
+			},
+			failed: []string{"foo.service"},
+		},
+		{
+			states: []unit.UnitState{
+				{MachineID: "XXX", ActiveState: "failed", ActiveEnterTimestamp: 100},
+				{MachineID: "XXX", ActiveState: "failed", ActiveEnterTimestamp: 200},
+				{MachineID: "XXX", ActiveState: "failed", ActiveEnterTimestamp: 300},
+			},
+			failed: []string{"foo.service"},
+		},
+		// a unit restarted by systemd never reports failed, its
+		// restarts count instead
+		{
+			states: []unit.UnitState{
+				{MachineID: "XXX", ActiveState: "active"},
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 2},
+				{MachineID: "XXX", ActiveState: "activating", NRestarts: 3},
+			},
+			failed: []string{"foo.service"},
+		},
+		// restarts from before the unit was first seen do not count
+		{
+			states: []unit.UnitState{
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 5},
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 6},
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 7},
+			},
+			failed: nil,
+		},
+		// the restart count is reset when the unit is started anew
+		{
+			states: []unit.UnitState{
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 5},
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 1},
+				{MachineID: "XXX", ActiveState: "active", NRestarts: 3},
+			},
+			failed: []string{"foo.service"},
+		},
+		// staying failed counts once
+		{
+			states: []unit.UnitState{
+				{MachineID: "XXX", ActiveState: "failed", NRestarts: 1},
+				{MachineID: "XXX", ActiveState: "failed", NRestarts: 1},
+				{MachineID: "XXX", ActiveState: "failed", NRestarts: 1},
+			},
+			failed: nil,
+		},
+	}
+
+	for i, tt := range tests {
+		ft := newFailureTracker()
+		var failed []string
+		for j, us := range tt.states {
+			failed = observeFailures(t, ft, "3/10m", us, start.Add(time.Duration(j)*time.Minute))
+			if failed != nil && j != len(tt.states)-1 {
+				t.Errorf("case %d: policy triggered early at state %d", i, j)
+			}
+		}
+		if !reflect.DeepEqual(tt.failed, failed) {
+			t.Errorf("case %d: unexpected failed jobs: got %v, want %v", i, failed, tt.failed)
+		}
+	}
+}
+
+func TestCalculateClusterTasksRescheduleOnFailure(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{
+				Name:        "foo.service",
+				Unit:        newFleetUnit(t, "RescheduleOnFailure=1/10m/2h"),
+				TargetState: job.JobStateLaunched,
+			},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{
+				Name:            "foo.service",
+				State:           &jsLaunched,
+				TargetMachineID: "XXX",
+			},
+		},
+		[]machine.MachineState{
+			machine.MachineState{ID: "XXX"},
+			machine.MachineState{ID: "YYY"},
+		},
+	)
+	clust.applyUnitStates([]*unit.UnitState{
+		// a stale state published by another machine is ignored
+		&unit.UnitState{UnitName: "foo.service", MachineID: "YYY", ActiveState: "active"},
+		&unit.UnitState{UnitName: "foo.service", MachineID: "XXX", ActiveState: "failed"},
+	})
+
+	want := []*task{
+		&task{
+			Type:      taskTypeBlacklistUnit,
+			Reason:    "failed 1 times within 10m0s on Machine(XXX)",
+			JobName:   "foo.service",
+			MachineID: "XXX",
+			TTL:       2 * time.Hour,
+		},
+		&task{
+			Type:      taskTypeUnscheduleUnit,
+			Reason:    "target Machine(XXX) unable to run unit: unit is blacklisted on agent after repeated failures",
+			JobName:   "foo.service",
+			MachineID: "XXX",
+		},
+		&task{
+			Type:      taskTypeAttemptScheduleUnit,
+			Reason:    "target state launched and unit not scheduled",
+			JobName:   "foo.service",
+			MachineID: "YYY",
+		},
+	}
+
+	r := NewReconciler(&leastLoadedScheduler{}, nil)
+	var tasks []*task
+	for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+		tasks = append(tasks, tsk)
+	}
+	if !reflect.DeepEqual(want, tasks) {
+		t.Errorf("task mismatch\nexpected %v\n got %v", want, tasks)
+	}
+}
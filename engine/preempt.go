@@ -0,0 +1,113 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+
+	"github.com/coreos/fleet/agent"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/pkg"
+)
+
+// preemptFor finds an agent able to run the given job once some units of
+// lower priority scheduled to it are unscheduled. The agent requiring the
+// fewest units to be preempted is chosen, ties being broken in favor of the
+// least-loaded agent. Units are only preempted within the disruption budget
+// of their template. The ID of the agent is returned along with the names of
+// the units to preempt, or false if no agent could run the job.
+func preemptFor(clust *clusterState, j *job.Job, budget *disruptionBudget) (machID string, victims []string, ok bool) {
+	sas := make(sortableAgentStates, 0)
+	for _, as := range clust.agents() {
+		sas = append(sas, as)
+	}
+	sort.Sort(sas)
+
+	for _, as := range sas {
+		v, found := preemptionVictims(clust, as, j, budget)
+		if !found {
+			continue
+		}
+
+		if !ok || len(v) < len(victims) {
+			machID, victims, ok = as.MState.ID, v, true
+		}
+	}
+
+	return
+}
+
+// preemptionVictims determines which units of lower priority than the job
+// must be removed from the agent for it to run the job. Units conflicting
+// with the job are removed first, then units are removed in ascending order
+// of priority until enough resources are available. Global units and the
+// job's peers are never removed, nor are units beyond the disruption budget
+// of their template. The agent is modified in the process.
+func preemptionVictims(clust *clusterState, as *agent.AgentState, j *job.Job, budget *disruptionBudget) ([]string, bool) {
+	priority := j.Priority()
+	peers := pkg.NewUnsafeSet(j.Peers()...)
+
+	var candidates prioritySortableJobs
+	for name := range as.Units {
+		cj, ok := clust.jobs[name]
+		if !ok || peers.Contains(name) || cj.Priority() >= priority || !budget.allows(name) {
+			continue
+		}
+		candidates = append(candidates, cj)
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	// lowest priority first
+	sort.Sort(sort.Reverse(candidates))
+
+	preemptible := pkg.NewUnsafeSet()
+	for _, cj := range candidates {
+		preemptible.Add(cj.Name)
+	}
+
+	var victims []string
+	preempt := func(name string) {
+		delete(as.Units, name)
+		victims = append(victims, name)
+	}
+
+	for _, name := range as.ConflictingUnits(j.Name, j.Conflicts()) {
+		if !preemptible.Contains(name) {
+			return nil, false
+		}
+		preempt(name)
+	}
+
+	for _, cj := range candidates {
+		if as.HasResources(j) {
+			break
+		}
+		if _, ok := as.Units[cj.Name]; ok {
+			preempt(cj.Name)
+		}
+	}
+
+	if act, _ := as.AbleToRun(j); act == job.JobActionUnschedule || !as.HasResources(j) {
+		return nil, false
+	}
+
+	if len(victims) == 0 || !budget.allows(victims...) {
+		return nil, false
+	}
+
+	return victims, true
+}
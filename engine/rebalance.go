@@ -0,0 +1,217 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coreos/fleet/agent"
+	"github.com/coreos/fleet/job"
+)
+
+const (
+	// RebalanceByUnits balances the number of units scheduled to each agent
+	RebalanceByUnits = "units"
+	// RebalanceByResources balances the share of each agent's memory
+	// reserved by the units scheduled to it
+	RebalanceByResources = "resources"
+)
+
+// Rebalancer moves units declaring Rebalanceable=true from the most loaded
+// agents to the least loaded ones. A unit is only moved if its new agent
+// ends up less loaded than its old agent was, so repeated rebalancing
+// settles instead of shuffling units back and forth.
+type Rebalancer struct {
+	// maxMoves is the disruption budget, i.e. the maximum number of
+	// moves in flight at any time
+	maxMoves int
+	load     func(as *agent.AgentState) (float64, bool)
+	cost     func(as *agent.AgentState, j *job.Job) float64
+
+	// moving maps the name of each unit moved across reconciliations to
+	// the ID of its new agent, until it reaches its target state there
+	moving map[string]string
+}
+
+// NewRebalancer returns a Rebalancer keeping at most maxMoves moves in
+// flight, comparing agents by the given metric. A nil Rebalancer is
+// returned if maxMoves is not positive, which disables rebalancing.
+func NewRebalancer(maxMoves int, metric string) (*Rebalancer, error) {
+	if maxMoves <= 0 {
+		return nil, nil
+	}
+
+	rb := &Rebalancer{maxMoves: maxMoves, moving: make(map[string]string)}
+	switch metric {
+	case "", RebalanceByUnits:
+		rb.load = unitsLoad
+		rb.cost = unitsCost
+	case RebalanceByResources:
+		rb.load = memoryLoad
+		rb.cost = memoryCost
+	default:
+		return nil, fmt.Errorf("unknown rebalance metric %q, valid values are %q and %q", metric, RebalanceByUnits, RebalanceByResources)
+	}
+
+	return rb, nil
+}
+
+func unitsLoad(as *agent.AgentState) (float64, bool) {
+	return float64(len(as.Units)), true
+}
+
+func unitsCost(as *agent.AgentState, j *job.Job) float64 {
+	return 1
+}
+
+// memoryLoad is the share of the agent's free memory reserved by its units.
+// Agents that do not report their resources are not considered.
+func memoryLoad(as *agent.AgentState) (float64, bool) {
+	free := as.MState.FreeResources.Memory
+	if free <= 0 {
+		return 0, false
+	}
+	return float64(free-as.AvailableResources().Memory) / float64(free), true
+}
+
+func memoryCost(as *agent.AgentState, j *job.Job) float64 {
+	return float64(j.Resources().Memory) / float64(as.MState.FreeResources.Memory)
+}
+
+// move describes a unit being moved from one agent to another
+type move struct {
+	jobName string
+	from    string
+	to      string
+}
+
+// settle forgets the moves of units that reached their target state on their
+// new agent in the given cluster state, or were since unscheduled, moved
+// elsewhere or destroyed, so that they no longer count against maxMoves.
+func (rb *Rebalancer) settle(clust *clusterState) {
+	for name, to := range rb.moving {
+		j, ok := clust.jobs[name]
+		switch {
+		case !ok, j.TargetMachineID != to, j.TargetState == job.JobStateInactive:
+		case j.State != nil && (*j.State == j.TargetState || *j.State == job.JobStateCompleted):
+		default:
+			continue
+		}
+		delete(rb.moving, name)
+	}
+}
+
+// inFlight returns the number of moves that have not settled yet.
+func (rb *Rebalancer) inFlight() int {
+	return len(rb.moving)
+}
+
+// moved records that the named unit is being moved to the given agent.
+func (rb *Rebalancer) moved(name, to string) {
+	rb.moving[name] = to
+}
+
+// nextMove finds a unit to move in the given cluster state, starting from
+// the most loaded agent. Units are only moved within the disruption budget
+// of their template. It returns false if the cluster is balanced.
+func (rb *Rebalancer) nextMove(clust *clusterState, budget *disruptionBudget) (*move, bool) {
+	var agents []*agent.AgentState
+	loads := make(map[string]float64)
+	for _, as := range clust.agents() {
+		load, ok := rb.load(as)
+		if !ok {
+			continue
+		}
+		loads[as.MState.ID] = load
+		agents = append(agents, as)
+	}
+
+	// least loaded first, ties broken by ID
+	sort.Sort(loadSortableAgentStates{agents: agents, loads: loads})
+
+	followed := followedUnits(clust)
+	for i := len(agents) - 1; i > 0; i-- {
+		src := agents[i]
+
+		var candidates []string
+		for name := range src.Units {
+			j, ok := clust.jobs[name]
+			if !ok || !j.Rebalanceable() || followed[name] || !budget.allows(name) {
+				continue
+			}
+			// a unit still settling after a move stays put
+			if _, moving := rb.moving[name]; moving {
+				continue
+			}
+			candidates = append(candidates, name)
+		}
+		sort.Strings(candidates)
+
+		for _, name := range candidates {
+			j := clust.jobs[name]
+			if rb.cost(src, j) <= 0 {
+				continue
+			}
+
+			for _, dst := range agents[:i] {
+				if loads[dst.MState.ID]+rb.cost(dst, j) >= loads[src.MState.ID] {
+					break
+				}
+
+				if act, _ := dst.AbleToRun(j); act != job.JobActionSchedule {
+					continue
+				}
+				if !dst.HasResources(j) {
+					continue
+				}
+
+				return &move{jobName: name, from: src.MState.ID, to: dst.MState.ID}, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// followedUnits returns the names of all units that other scheduled units
+// require as peers, as moving them would force their followers to move too.
+func followedUnits(clust *clusterState) map[string]bool {
+	followed := make(map[string]bool)
+	for _, j := range clust.jobs {
+		if !j.Scheduled() {
+			continue
+		}
+		for _, peer := range j.Peers() {
+			followed[peer] = true
+		}
+	}
+	return followed
+}
+
+type loadSortableAgentStates struct {
+	agents []*agent.AgentState
+	loads  map[string]float64
+}
+
+func (lsas loadSortableAgentStates) Len() int { return len(lsas.agents) }
+func (lsas loadSortableAgentStates) Swap(i, j int) {
+	lsas.agents[i], lsas.agents[j] = lsas.agents[j], lsas.agents[i]
+}
+
+func (lsas loadSortableAgentStates) Less(i, j int) bool {
+	li, lj := lsas.loads[lsas.agents[i].MState.ID], lsas.loads[lsas.agents[j].MState.ID]
+	return li < lj || (li == lj && lsas.agents[i].MState.ID < lsas.agents[j].MState.ID)
+}
@@ -0,0 +1,243 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/resource"
+)
+
+func TestNewRebalancer(t *testing.T) {
+	rb, err := NewRebalancer(0, RebalanceByUnits)
+	if rb != nil || err != nil {
+		t.Errorf("expected disabled rebalancer, got %v, %v", rb, err)
+	}
+
+	rb, err = NewRebalancer(1, "")
+	if rb == nil || err != nil {
+		t.Errorf("expected rebalancer for default metric, got %v, %v", rb, err)
+	}
+
+	rb, err = NewRebalancer(1, RebalanceByResources)
+	if rb == nil || err != nil {
+		t.Errorf("expected rebalancer for resources metric, got %v, %v", rb, err)
+	}
+
+	if _, err = NewRebalancer(1, "cpu"); err == nil {
+		t.Errorf("expected error for unknown metric")
+	}
+}
+
+func TestCalculateClusterTasksRebalance(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	twoMachines := []machine.MachineState{
+		machine.MachineState{ID: "XXX"},
+		machine.MachineState{ID: "YYY"},
+	}
+	twoMachines1G := []machine.MachineState{
+		machine.MachineState{ID: "XXX", FreeResources: resource.ResourceTuple{Memory: 1024}},
+		machine.MachineState{ID: "YYY", FreeResources: resource.ResourceTuple{Memory: 1024}},
+	}
+	moved := func(name, from, to string) []*task {
+		reason := "rebalancing away from Machine(" + from + ")"
+		return []*task{
+			&task{Type: taskTypeUnscheduleUnit, Reason: reason, JobName: name, MachineID: from},
+			&task{Type: taskTypeAttemptScheduleUnit, Reason: reason, JobName: name, MachineID: to},
+		}
+	}
+	fourUnitsOnXXX := func(opts ...string) *clusterState {
+		var units []job.Unit
+		var sUnits []job.ScheduledUnit
+		for _, name := range []string{"a.service", "b.service", "c.service", "d.service"} {
+			units = append(units, job.Unit{Name: name, Unit: newFleetUnit(t, opts...), TargetState: jsLaunched})
+			sUnits = append(sUnits, job.ScheduledUnit{Name: name, State: &jsLaunched, TargetMachineID: "XXX"})
+		}
+		return newClusterState(units, sUnits, twoMachines)
+	}
+
+	tests := []struct {
+		maxMoves int
+		metric   string
+		clust    *clusterState
+		tasks    []*task
+	}{
+		// disruption budget limits the number of moves per reconciliation
+		{
+			maxMoves: 1,
+			clust:    fourUnitsOnXXX("Rebalanceable=true"),
+			tasks:    moved("a.service", "XXX", "YYY"),
+		},
+
+		// units stop moving once the agents are balanced
+		{
+			maxMoves: 5,
+			clust:    fourUnitsOnXXX("Rebalanceable=true"),
+			tasks:    append(moved("a.service", "XXX", "YYY"), moved("b.service", "XXX", "YYY")...),
+		},
+
+		// units not opting in are never moved
+		{
+			maxMoves: 5,
+			clust:    fourUnitsOnXXX(),
+			tasks:    []*task{},
+		},
+
+		// a move must not make the destination more loaded than the source
+		{
+			maxMoves: 5,
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "a.service", Unit: newFleetUnit(t, "Rebalanceable=true"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "a.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				twoMachines,
+			),
+			tasks: []*task{},
+		},
+
+		// units are only moved to agents able to run them
+		{
+			maxMoves: 5,
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "a.service", Unit: newFleetUnit(t, "Rebalanceable=true", "MachineID=XXX"), TargetState: jsLaunched},
+					job.Unit{Name: "b.service", Unit: newFleetUnit(t, "Rebalanceable=true"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "a.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "b.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				twoMachines,
+			),
+			tasks: moved("b.service", "XXX", "YYY"),
+		},
+
+		// units followed by other units are never moved
+		{
+			maxMoves: 5,
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "a.service", Unit: newFleetUnit(t, "Rebalanceable=true"), TargetState: jsLaunched},
+					job.Unit{Name: "b.service", Unit: newFleetUnit(t, "MachineOf=a.service"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "a.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "b.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				twoMachines,
+			),
+			tasks: []*task{},
+		},
+
+		// reserved resources are balanced with the resources metric
+		{
+			maxMoves: 5,
+			metric:   RebalanceByResources,
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "a.service", Unit: newFleetUnit(t, "Rebalanceable=true", "Resources=memory=256"), TargetState: jsLaunched},
+					job.Unit{Name: "b.service", Unit: newFleetUnit(t, "Rebalanceable=true", "Resources=memory=256"), TargetState: jsLaunched},
+					job.Unit{Name: "c.service", Unit: newFleetUnit(t, "Rebalanceable=true"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "a.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "b.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "c.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				twoMachines1G,
+			),
+			tasks: moved("a.service", "XXX", "YYY"),
+		},
+	}
+
+	for i, tt := range tests {
+		rb, err := NewRebalancer(tt.maxMoves, tt.metric)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		r := NewReconciler(&leastLoadedScheduler{}, rb)
+		tasks := make([]*task, 0)
+		for tsk := range r.calculateClusterTasks(tt.clust, make(chan struct{})) {
+			tasks = append(tasks, tsk)
+		}
+
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: task mismatch\nexpected %v\n got %v", i, tt.tasks, tasks)
+		}
+	}
+}
+
+func TestRebalanceMovesInFlight(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	jsLoaded := job.JobStateLoaded
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "XXX"},
+		machine.MachineState{ID: "YYY"},
+	}
+	// clusterWith returns the cluster state with a.service in the given
+	// state on the given machine, and three other units launched on XXX
+	clusterWith := func(aMachine string, aState *job.JobState) *clusterState {
+		var units []job.Unit
+		for _, name := range []string{"a.service", "b.service", "c.service", "d.service"} {
+			units = append(units, job.Unit{Name: name, Unit: newFleetUnit(t, "Rebalanceable=true"), TargetState: jsLaunched})
+		}
+		sUnits := []job.ScheduledUnit{
+			job.ScheduledUnit{Name: "a.service", State: aState, TargetMachineID: aMachine},
+			job.ScheduledUnit{Name: "b.service", State: &jsLaunched, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "c.service", State: &jsLaunched, TargetMachineID: "XXX"},
+			job.ScheduledUnit{Name: "d.service", State: &jsLaunched, TargetMachineID: "XXX"},
+		}
+		return newClusterState(units, sUnits, machines)
+	}
+	moves := func(r *Reconciler, clust *clusterState) []string {
+		var names []string
+		for tsk := range r.calculateClusterTasks(clust, make(chan struct{})) {
+			if tsk.Type == taskTypeAttemptScheduleUnit {
+				names = append(names, tsk.JobName)
+			}
+		}
+		return names
+	}
+
+	rb, err := NewRebalancer(1, RebalanceByUnits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := NewReconciler(&leastLoadedScheduler{}, rb)
+
+	if got := moves(r, clusterWith("XXX", &jsLaunched)); !reflect.DeepEqual(got, []string{"a.service"}) {
+		t.Fatalf("expected a.service to be moved, got %v", got)
+	}
+
+	// the move is in flight until a.service runs on YYY
+	if got := moves(r, clusterWith("YYY", nil)); len(got) != 0 {
+		t.Errorf("expected no move while a.service is unscheduled on YYY, got %v", got)
+	}
+	if got := moves(r, clusterWith("YYY", &jsLoaded)); len(got) != 0 {
+		t.Errorf("expected no move while a.service is loaded on YYY, got %v", got)
+	}
+
+	// once it settled, the next unit may move
+	if got := moves(r, clusterWith("YYY", &jsLaunched)); !reflect.DeepEqual(got, []string{"b.service"}) {
+		t.Errorf("expected b.service to be moved after a.service settled, got %v", got)
+	}
+}
@@ -16,6 +16,7 @@ package engine
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/coreos/fleet/job"
@@ -26,6 +27,9 @@ import (
 const (
 	taskTypeUnscheduleUnit      = "UnscheduleUnit"
 	taskTypeAttemptScheduleUnit = "AttemptScheduleUnit"
+	taskTypeCreateUnit          = "CreateUnit"
+	taskTypeDestroyUnit         = "DestroyUnit"
+	taskTypeBlacklistUnit       = "BlacklistUnit"
 )
 
 type task struct {
@@ -33,20 +37,36 @@ type task struct {
 	Reason    string
 	JobName   string
 	MachineID string
+	// Unit is the Unit to create, only set for CreateUnit tasks
+	Unit *job.Unit
+	// TTL is how long the unit is barred from the machine, only set for
+	// BlacklistUnit tasks
+	TTL time.Duration
 }
 
 func (t *task) String() string {
 	return fmt.Sprintf("{Type: %s, JobName: %s, MachineID: %s, Reason: %q}", t.Type, t.JobName, t.MachineID, t.Reason)
 }
 
-func NewReconciler() *Reconciler {
+// NewReconciler returns a Reconciler placing units with the given
+// Scheduler. A nil Rebalancer disables rebalancing.
+func NewReconciler(sched Scheduler, rebalancer *Rebalancer) *Reconciler {
 	return &Reconciler{
-		sched: &leastLoadedScheduler{},
+		sched:      sched,
+		rebalancer: rebalancer,
+		failures:   newFailureTracker(),
 	}
 }
 
 type Reconciler struct {
-	sched Scheduler
+	sched      Scheduler
+	rebalancer *Rebalancer
+	// failures tracks units failing on their machine across
+	// reconciliations
+	failures *failureTracker
+	// simulated reconcilers report no metrics, as they do not act on
+	// the cluster
+	simulated bool
 }
 
 func (r *Reconciler) Reconcile(e *Engine, stop chan struct{}) {
@@ -67,23 +87,48 @@ func (r *Reconciler) Reconcile(e *Engine, stop chan struct{}) {
 		}
 	}
 
+	// explanations are only complete if reconciliation was not stopped
+	select {
+	case <-stop:
+	default:
+		e.explainUnits(clust.explanations)
+	}
+
 	metrics.ReportEngineReconcileSuccess(start)
 }
 
 func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan struct{}) (taskchan chan *task) {
 	taskchan = make(chan *task)
 
-	send := func(typ, reason, jName, machID string) bool {
+	sendTask := func(t *task) bool {
 		select {
 		case <-stopchan:
 			return false
 		default:
 		}
 
-		taskchan <- &task{Type: typ, Reason: reason, JobName: jName, MachineID: machID}
+		taskchan <- t
 		return true
 	}
 
+	send := func(typ, reason, jName, machID string) bool {
+		return sendTask(&task{Type: typ, Reason: reason, JobName: jName, MachineID: machID})
+	}
+
+	// instances created to satisfy deployments are scheduled during the
+	// same reconciliation
+	scaling := scaleDeployments(clust)
+
+	// completed batch units are destroyed once they expired, so they are
+	// not considered further
+	collected := collectCompleted(clust, time.Now())
+
+	// instances launched by schedules are scheduled during the same
+	// reconciliation as well
+	cron := runSchedules(clust, time.Now())
+
+	budget := newDisruptionBudget(clust)
+
 	decide := func(j *job.Job) (jobAction job.JobAction, reason string) {
 		if j.TargetState == job.JobStateInactive {
 			return job.JobActionUnschedule, "target state inactive"
@@ -93,12 +138,12 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 
 		as, ok := agents[j.TargetMachineID]
 		if !ok {
-			metrics.ReportEngineReconcileFailure(metrics.MachineAway)
+			r.reportFailure(metrics.MachineAway)
 			return job.JobActionUnschedule, fmt.Sprintf("target Machine(%s) went away", j.TargetMachineID)
 		}
 
 		if act, ableReason := as.AbleToRun(j); act != job.JobActionSchedule {
-			metrics.ReportEngineReconcileFailure(metrics.RunFailure)
+			r.reportFailure(metrics.RunFailure)
 			return act, fmt.Sprintf("target Machine(%s) unable to run unit: %v",
 				j.TargetMachineID, ableReason)
 		}
@@ -113,7 +158,7 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 
 		as, ok := agents[j.TargetMachineID]
 		if !ok {
-			metrics.ReportEngineReconcileFailure(metrics.MachineAway)
+			r.reportFailure(metrics.MachineAway)
 			return false
 		}
 
@@ -128,26 +173,42 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 			replacedUnit, err := as.GetReplacedUnit(j)
 			if err != nil {
 				log.Debugf("No unit to reschedule: %v", err)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				r.reportFailure(metrics.ScheduleFailure)
+				continue
+			}
+
+			if !budget.allows(replacedUnit) {
+				log.Infof("Not rescheduling Job(%s): disruption budget exhausted", replacedUnit)
+				r.reportFailure(metrics.ScheduleFailure)
 				continue
 			}
 
 			if !send(taskTypeUnscheduleUnit, reason, replacedUnit, j.TargetMachineID) {
 				log.Infof("Job(%s) unschedule send failed", replacedUnit)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				r.reportFailure(metrics.ScheduleFailure)
+				continue
+			}
+			budget.disrupt(replacedUnit)
+
+			// the replaced unit is the one moving, so the new
+			// machine has to be able to run it
+			rj, ok := clust.jobs[replacedUnit]
+			if !ok {
+				log.Debugf("Unable to reschedule unknown Job(%s)", replacedUnit)
+				r.reportFailure(metrics.ScheduleFailure)
 				continue
 			}
 
-			dec, err := r.sched.DecideReschedule(clust, j)
+			dec, err := r.sched.DecideReschedule(clust, rj)
 			if err != nil {
-				log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				log.Debugf("Unable to schedule Job(%s): %v", replacedUnit, err)
+				r.reportFailure(metrics.ScheduleFailure)
 				continue
 			}
 
 			if !send(taskTypeAttemptScheduleUnit, reason, replacedUnit, dec.machineID) {
 				log.Infof("Job(%s) attemptschedule send failed", replacedUnit)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				r.reportFailure(metrics.ScheduleFailure)
 				continue
 			}
 			clust.schedule(replacedUnit, dec.machineID)
@@ -165,11 +226,56 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 	go func() {
 		defer close(taskchan)
 
+		for _, t := range scaling {
+			if !sendTask(t) {
+				return
+			}
+		}
+
+		for _, t := range collected {
+			if !sendTask(t) {
+				return
+			}
+		}
+
+		for _, t := range cron {
+			if !sendTask(t) {
+				return
+			}
+		}
+
+		// units which keep failing are blacklisted on their machine, so
+		// they are unscheduled below and placed elsewhere
+		for _, fj := range r.failures.failedJobs(clust, time.Now()) {
+			reason := fmt.Sprintf("failed %d times within %s on Machine(%s)", fj.policy.Failures, fj.policy.Window, fj.machineID)
+			if !sendTask(&task{Type: taskTypeBlacklistUnit, Reason: reason, JobName: fj.name, MachineID: fj.machineID, TTL: fj.policy.Blacklist}) {
+				r.reportFailure(metrics.ScheduleFailure)
+				return
+			}
+			clust.blacklistUnit(fj.name, fj.machineID)
+		}
+
 		for _, j := range clust.jobs {
 			if !j.Scheduled() {
 				continue
 			}
 
+			if name, ok := clust.draining[j.TargetMachineID]; ok && j.TargetState != job.JobStateInactive {
+				if budget.allows(j.Name) {
+					reason := fmt.Sprintf("target Machine(%s) in maintenance window %s", j.TargetMachineID, name)
+					if !send(taskTypeUnscheduleUnit, reason, j.Name, j.TargetMachineID) {
+						r.reportFailure(metrics.ScheduleFailure)
+						return
+					}
+
+					log.Debugf("Job(%s) unscheduling for maintenance.", j.Name)
+					clust.unschedule(j.Name)
+					budget.disrupt(j.Name)
+					continue
+				}
+				log.Infof("Not draining Job(%s): disruption budget exhausted", j.Name)
+			}
+
 			act, reason := decide(j)
 			if act == job.JobActionReschedule && handle_reschedule(j, reason) {
 				log.Debugf("Job(%s) is rescheduled: %v", j.Name, reason)
@@ -178,45 +284,119 @@ func (r *Reconciler) calculateClusterTasks(clust *clusterState, stopchan chan st
 
 			if act != job.JobActionUnschedule {
 				log.Debugf("Job(%s) is not to be unscheduled, reason: %v", j.Name, reason)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				r.reportFailure(metrics.ScheduleFailure)
 				continue
 			}
 
 			if !send(taskTypeUnscheduleUnit, reason, j.Name, j.TargetMachineID) {
 				log.Infof("Job(%s) send failed.", j.Name)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				r.reportFailure(metrics.ScheduleFailure)
 				return
 			}
 
 			log.Debugf("Job(%s) unscheduling.", j.Name)
 			clust.unschedule(j.Name)
+			budget.disrupt(j.Name)
 		}
 
+		var pending prioritySortableJobs
 		for _, j := range clust.jobs {
 			if j.Scheduled() || j.TargetState == job.JobStateInactive {
 				continue
 			}
+			pending = append(pending, j)
+		}
+		sort.Sort(pending)
 
+		for _, j := range pending {
 			dec, err := r.sched.Decide(clust, j)
 			if err != nil {
-				log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
-				continue
+				machID, victims, ok := preemptFor(clust, j, budget)
+				if !ok {
+					log.Debugf("Unable to schedule Job(%s): %v", j.Name, err)
+					r.reportFailure(metrics.ScheduleFailure)
+					clust.explanations[j.Name] = explainJob(clust, j, err)
+					continue
+				}
+
+				reason := fmt.Sprintf("preempted by higher-priority Unit(%s)", j.Name)
+				for _, victim := range victims {
+					if !send(taskTypeUnscheduleUnit, reason, victim, machID) {
+						r.reportFailure(metrics.ScheduleFailure)
+						return
+					}
+					clust.unschedule(victim)
+					budget.disrupt(victim)
+				}
+
+				dec = &decision{machineID: machID}
 			}
 
 			reason := fmt.Sprintf("target state %s and unit not scheduled", j.TargetState)
 			if !send(taskTypeAttemptScheduleUnit, reason, j.Name, dec.machineID) {
-				metrics.ReportEngineReconcileFailure(metrics.ScheduleFailure)
+				r.reportFailure(metrics.ScheduleFailure)
 				return
 			}
 
 			clust.schedule(j.Name, dec.machineID)
 		}
+
+		if r.rebalancer == nil {
+			return
+		}
+
+		// moves from earlier reconciliations count against the
+		// budget until the units run on their new agents
+		r.rebalancer.settle(clust)
+		for r.rebalancer.inFlight() < r.rebalancer.maxMoves {
+			mv, ok := r.rebalancer.nextMove(clust, budget)
+			if !ok {
+				break
+			}
+
+			reason := fmt.Sprintf("rebalancing away from Machine(%s)", mv.from)
+			if !send(taskTypeUnscheduleUnit, reason, mv.jobName, mv.from) {
+				r.reportFailure(metrics.ScheduleFailure)
+				return
+			}
+			clust.unschedule(mv.jobName)
+			budget.disrupt(mv.jobName)
+
+			if !send(taskTypeAttemptScheduleUnit, reason, mv.jobName, mv.to) {
+				r.reportFailure(metrics.ScheduleFailure)
+				return
+			}
+			clust.schedule(mv.jobName, mv.to)
+			r.rebalancer.moved(mv.jobName, mv.to)
+			log.Debugf("Job(%s) rebalanced from Machine(%s) to Machine(%s)", mv.jobName, mv.from, mv.to)
+		}
 	}()
 
 	return
 }
 
+// prioritySortableJobs orders jobs by descending priority, then by name
+type prioritySortableJobs []*job.Job
+
+func (psj prioritySortableJobs) Len() int      { return len(psj) }
+func (psj prioritySortableJobs) Swap(i, j int) { psj[i], psj[j] = psj[j], psj[i] }
+
+func (psj prioritySortableJobs) Less(i, j int) bool {
+	pi, pj := psj[i].Priority(), psj[j].Priority()
+	if pi != pj {
+		return pi > pj
+	}
+	return psj[i].Name < psj[j].Name
+}
+
+// reportFailure reports a reconciliation failure of the given kind, unless
+// the Reconciler is simulated.
+func (r *Reconciler) reportFailure(reason metrics.EngineFailure) {
+	if !r.simulated {
+		metrics.ReportEngineReconcileFailure(reason)
+	}
+}
+
 func doTask(t *task, e *Engine) (err error) {
 	switch t.Type {
 	case taskTypeUnscheduleUnit:
@@ -225,6 +405,15 @@ func doTask(t *task, e *Engine) (err error) {
 	case taskTypeAttemptScheduleUnit:
 		e.attemptScheduleUnit(t.JobName, t.MachineID)
 		metrics.ReportEngineTask(t.Type)
+	case taskTypeCreateUnit:
+		err = e.createUnit(t.Unit)
+		metrics.ReportEngineTask(t.Type)
+	case taskTypeDestroyUnit:
+		err = e.destroyUnit(t.JobName)
+		metrics.ReportEngineTask(t.Type)
+	case taskTypeBlacklistUnit:
+		err = e.blacklistUnit(t.JobName, t.MachineID, t.TTL)
+		metrics.ReportEngineTask(t.Type)
 	default:
 		err = fmt.Errorf("unrecognized task type %q", t.Type)
 	}
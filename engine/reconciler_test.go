@@ -17,9 +17,11 @@ package engine
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/resource"
 )
 
 func TestCalculateClusterTasks(t *testing.T) {
@@ -157,7 +159,286 @@ func TestCalculateClusterTasks(t *testing.T) {
 	}
 
 	for i, tt := range tests {
-		r := NewReconciler()
+		r := NewReconciler(&leastLoadedScheduler{}, nil)
+		tasks := make([]*task, 0)
+		for tsk := range r.calculateClusterTasks(tt.clust, make(chan struct{})) {
+			tasks = append(tasks, tsk)
+		}
+
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: task mismatch\nexpected %v\n got %v", i, tt.tasks, tasks)
+		}
+	}
+}
+
+func TestExplainUnscheduled(t *testing.T) {
+	jsInactive := job.JobStateInactive
+	jsLaunched := job.JobStateLaunched
+
+	clust := newClusterState(
+		[]job.Unit{
+			job.Unit{
+				Name:        "bar.service",
+				TargetState: job.JobStateLaunched,
+			},
+			job.Unit{
+				Name:        "foo.service",
+				Unit:        newFleetUnit(t, "MachineMetadata=region=us-east", "Conflicts=bar.service"),
+				TargetState: job.JobStateLaunched,
+			},
+			job.Unit{
+				Name:        "baz.service",
+				Unit:        newFleetUnit(t, "MachineMetadata=region=eu-west"),
+				TargetState: job.JobStateInactive,
+			},
+		},
+		[]job.ScheduledUnit{
+			job.ScheduledUnit{
+				Name:            "bar.service",
+				State:           &jsLaunched,
+				TargetMachineID: "XXX",
+			},
+			job.ScheduledUnit{
+				Name:  "baz.service",
+				State: &jsInactive,
+			},
+		},
+		[]machine.MachineState{
+			machine.MachineState{ID: "XXX", Metadata: map[string]string{"region": "us-east"}},
+			machine.MachineState{ID: "YYY", Metadata: map[string]string{"region": "us-west"}},
+		},
+	)
+
+	want := map[string]*job.SchedulingExplanation{
+		"foo.service": &job.SchedulingExplanation{
+			Reason: "no agents able to run job",
+			Rejections: map[string]string{
+				"XXX": "found conflict with locally-scheduled Unit([bar.service])",
+				"YYY": "local Machine metadata insufficient",
+			},
+		},
+	}
+
+	r := NewReconciler(&leastLoadedScheduler{}, nil)
+	for range r.calculateClusterTasks(clust, make(chan struct{})) {
+	}
+	got := clust.explanations
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("explanation mismatch\nexpected %#v\n got %#v", want["foo.service"], got["foo.service"])
+	}
+}
+
+func TestCalculateClusterTasksPriority(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	mem1G := []machine.MachineState{
+		machine.MachineState{ID: "XXX", FreeResources: resource.ResourceTuple{Memory: 1024}},
+	}
+
+	tests := []struct {
+		clust *clusterState
+		tasks []*task
+	}{
+		// higher-priority unit preempts a conflicting unit
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "low.service", TargetState: jsLaunched},
+					job.Unit{Name: "high.service", Unit: newFleetUnit(t, "Priority=10", "Conflicts=low.service"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "low.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "preempted by higher-priority Unit(high.service)",
+					JobName:   "low.service",
+					MachineID: "XXX",
+				},
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "high.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+
+		// units of equal priority are never preempted
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "low.service", TargetState: jsLaunched},
+					job.Unit{Name: "high.service", Unit: newFleetUnit(t, "Conflicts=low.service"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "low.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+			),
+			tasks: []*task{},
+		},
+
+		// lowest-priority units are preempted first to free resources
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "a.service", Unit: newFleetUnit(t, "Priority=1", "Resources=memory=512"), TargetState: jsLaunched},
+					job.Unit{Name: "b.service", Unit: newFleetUnit(t, "Priority=-1", "Resources=memory=512"), TargetState: jsLaunched},
+					job.Unit{Name: "high.service", Unit: newFleetUnit(t, "Priority=5", "Resources=memory=512"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "a.service", State: &jsLaunched, TargetMachineID: "XXX"},
+					job.ScheduledUnit{Name: "b.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				mem1G,
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "preempted by higher-priority Unit(high.service)",
+					JobName:   "b.service",
+					MachineID: "XXX",
+				},
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "high.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+
+		// pending units are placed in priority order
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "a.service", Unit: newFleetUnit(t, "Resources=memory=1024"), TargetState: jsLaunched},
+					job.Unit{Name: "b.service", Unit: newFleetUnit(t, "Priority=5", "Resources=memory=1024"), TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{},
+				mem1G,
+			),
+			tasks: []*task{
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "b.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		r := NewReconciler(&leastLoadedScheduler{}, nil)
+		tasks := make([]*task, 0)
+		for tsk := range r.calculateClusterTasks(tt.clust, make(chan struct{})) {
+			tasks = append(tasks, tsk)
+		}
+
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: task mismatch\nexpected %v\n got %v", i, tt.tasks, tasks)
+		}
+	}
+}
+
+func TestCalculateClusterTasksMaintenance(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	now := time.Now()
+	started := []machine.MaintenanceWindow{
+		{Name: "kernel", MachineID: "XXX", Start: now.Add(-time.Minute), End: now.Add(time.Hour)},
+	}
+	upcoming := []machine.MaintenanceWindow{
+		{Name: "kernel", MachineID: "XXX", Lead: time.Hour, Start: now.Add(time.Minute), End: now.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		clust   *clusterState
+		windows []machine.MaintenanceWindow
+		tasks   []*task
+	}{
+		// units are moved off a machine in an active window
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "foo.service", TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "foo.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				[]machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY"}},
+			),
+			windows: started,
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "target Machine(XXX) in maintenance window kernel",
+					JobName:   "foo.service",
+					MachineID: "XXX",
+				},
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "foo.service",
+					MachineID: "YYY",
+				},
+			},
+		},
+
+		// units stay on a machine whose window has not started, but
+		// new units go elsewhere
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "foo.service", TargetState: jsLaunched},
+					job.Unit{Name: "bar.service", TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "foo.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				[]machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY"}},
+			),
+			windows: upcoming,
+			tasks: []*task{
+				&task{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "bar.service",
+					MachineID: "YYY",
+				},
+			},
+		},
+
+		// units are unscheduled even if no other machine can run them
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "foo.service", TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "foo.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				[]machine.MachineState{machine.MachineState{ID: "XXX"}},
+			),
+			windows: started,
+			tasks: []*task{
+				&task{
+					Type:      taskTypeUnscheduleUnit,
+					Reason:    "target Machine(XXX) in maintenance window kernel",
+					JobName:   "foo.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		tt.clust.applyMaintenance(tt.windows, now)
+
+		r := NewReconciler(&leastLoadedScheduler{}, nil)
 		tasks := make([]*task, 0)
 		for tsk := range r.calculateClusterTasks(tt.clust, make(chan struct{})) {
 			tasks = append(tasks, tsk)
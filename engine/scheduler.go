@@ -16,12 +16,21 @@ package engine
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/coreos/fleet/agent"
 	"github.com/coreos/fleet/job"
 )
 
+const (
+	// DefaultScheduler is the name of the scheduling strategy used by the
+	// engine unless configured otherwise.
+	DefaultScheduler = "least-loaded"
+)
+
 type decision struct {
 	machineID string
 }
@@ -31,63 +40,120 @@ type Scheduler interface {
 	DecideReschedule(*clusterState, *job.Job) (*decision, error)
 }
 
-type leastLoadedScheduler struct{}
+// schedulers maps the names of the available scheduling strategies to their
+// constructors. The metadata key is only used by strategies that take machine
+// metadata into account.
+var schedulers = map[string]func(metadataKey string) Scheduler{
+	"least-loaded": func(string) Scheduler {
+		return &leastLoadedScheduler{}
+	},
+	"spread-by-metadata": func(key string) Scheduler {
+		return &spreadByMetadataScheduler{key: key}
+	},
+	"pack": func(string) Scheduler {
+		return &binPackScheduler{}
+	},
+	"random": func(string) Scheduler {
+		return newRandomScheduler(time.Now().UnixNano())
+	},
+}
 
-func (lls *leastLoadedScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
-	agents := lls.sortedAgents(clust)
+// NewScheduler returns the scheduling strategy registered under the given
+// name. An empty name selects DefaultScheduler.
+func NewScheduler(name, metadataKey string) (Scheduler, error) {
+	if name == "" {
+		name = DefaultScheduler
+	}
+
+	newSched, ok := schedulers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheduler %q, must be one of %s", name, strings.Join(SchedulerNames(), ", "))
+	}
 
+	return newSched(metadataKey), nil
+}
+
+// SchedulerNames returns the sorted names of all available scheduling
+// strategies.
+func SchedulerNames() []string {
+	names := make([]string, 0, len(schedulers))
+	for name := range schedulers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decideAmong picks the first of the given agents that is able to run the
+// job and has enough resources available for it. If the job is to be spread
+// across machine metadata values, only the agents in the least-populated
+// eligible domain are considered. Agents matching more of the job's
+// preferences are picked before others, regardless of their order.
+func decideAmong(agents []*agent.AgentState, j *job.Job) (*decision, error) {
 	if len(agents) == 0 {
 		return nil, fmt.Errorf("zero agents available")
 	}
 
-	var target *agent.AgentState
+	var candidates []*agent.AgentState
 	for _, as := range agents {
 		if act, _ := as.AbleToRun(j); act == job.JobActionUnschedule {
 			continue
 		}
 
-		as := as
-		target = as
-		break
+		if !as.HasResources(j) {
+			continue
+		}
+
+		candidates = append(candidates, as)
 	}
 
-	if target == nil {
+	candidates = spreadAgents(agents, candidates, j)
+	if len(candidates) == 0 {
 		return nil, fmt.Errorf("no agents able to run job")
 	}
 
+	sort.Stable(preferenceSortableAgentStates{
+		agents: candidates,
+		scores: preferenceScores(candidates, j),
+	})
+
 	dec := decision{
-		machineID: target.MState.ID,
+		machineID: candidates[0].MState.ID,
 	}
 
 	return &dec, nil
 }
 
-// DecideReschedule() decides scheduling in a much simpler way than
-// Decide(). It just tries to find out another free machine to be scheduled,
-// except for the current target machine. It does not have to run
-// as.AbleToRun(), because its job action must have been already decided
-// before getting into the function.
-func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
-	agents := lls.sortedAgents(clust)
-
+// decideRescheduleAmong picks the first of the given agents, other than the
+// current target machine of the job, that is able to run the job and has
+// enough resources available.
+func decideRescheduleAmong(agents []*agent.AgentState, j *job.Job) (*decision, error) {
 	if len(agents) == 0 {
 		return nil, fmt.Errorf("zero agents available")
 	}
 
-	found := false
 	var target *agent.AgentState
 	for _, as := range agents {
 		if as.MState.ID == j.TargetMachineID {
 			continue
 		}
 
+		// an agent which would replace the job as well is no
+		// better than the current one
+		if act, _ := as.AbleToRun(j); act != job.JobActionSchedule {
+			continue
+		}
+
+		if !as.HasResources(j) {
+			continue
+		}
+
 		as := as
 		target = as
-		found = true
 		break
 	}
 
-	if !found {
+	if target == nil {
 		return nil, fmt.Errorf("no agents able to run job")
 	}
 
@@ -98,6 +164,45 @@ func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Jo
 	return &dec, nil
 }
 
+// preferenceScores returns the preference score of each of the given agents
+// for the job, keyed by machine ID.
+func preferenceScores(agents []*agent.AgentState, j *job.Job) map[string]int {
+	scores := make(map[string]int, len(agents))
+	for _, as := range agents {
+		scores[as.MState.ID] = as.PreferenceScore(j)
+	}
+	return scores
+}
+
+// preferenceSortableAgentStates orders agents descending by their
+// preference score.
+type preferenceSortableAgentStates struct {
+	agents []*agent.AgentState
+	scores map[string]int
+}
+
+func (pas preferenceSortableAgentStates) Len() int { return len(pas.agents) }
+func (pas preferenceSortableAgentStates) Swap(i, j int) {
+	pas.agents[i], pas.agents[j] = pas.agents[j], pas.agents[i]
+}
+
+func (pas preferenceSortableAgentStates) Less(i, j int) bool {
+	return pas.scores[pas.agents[i].MState.ID] > pas.scores[pas.agents[j].MState.ID]
+}
+
+type leastLoadedScheduler struct{}
+
+func (lls *leastLoadedScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideAmong(lls.sortedAgents(clust), j)
+}
+
+// DecideReschedule() decides scheduling in a much simpler way than
+// Decide(). It just tries to find out another free machine able to run the
+// job, except for the current target machine.
+func (lls *leastLoadedScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideRescheduleAmong(lls.sortedAgents(clust), j)
+}
+
 // sortedAgents returns a list of AgentState objects sorted ascending
 // by the number of scheduled units
 func (lls *leastLoadedScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
@@ -122,3 +227,183 @@ func (sas sortableAgentStates) Less(i, j int) bool {
 	njUnits := len(sas[j].Units)
 	return niUnits < njUnits || (niUnits == njUnits && sas[i].MState.ID < sas[j].MState.ID)
 }
+
+// binPackScheduler places units on the agent with the least available
+// resources that is still able to fit them, keeping the remaining capacity
+// of the cluster concentrated on as few machines as possible. Units that
+// declare no resource requirements are placed like leastLoadedScheduler does.
+type binPackScheduler struct{}
+
+func (bps *binPackScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	if j.Resources().Empty() {
+		return (&leastLoadedScheduler{}).Decide(clust, j)
+	}
+
+	return decideAmong(bps.sortedAgents(clust), j)
+}
+
+// DecideReschedule finds the tightest-fitting agent other than the current
+// target machine of the job.
+func (bps *binPackScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	if j.Resources().Empty() {
+		return (&leastLoadedScheduler{}).DecideReschedule(clust, j)
+	}
+
+	return decideRescheduleAmong(bps.sortedAgents(clust), j)
+}
+
+// sortedAgents returns a list of AgentState objects sorted ascending
+// by their available resources
+func (bps *binPackScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
+	agents := clust.agents()
+
+	sas := make(resourceSortableAgentStates, 0)
+	for _, as := range agents {
+		sas = append(sas, as)
+	}
+	sort.Sort(sas)
+
+	return []*agent.AgentState(sas)
+}
+
+// resourceSortableAgentStates orders agents by available memory, then cores,
+// then disk. Ties are broken the same way as sortableAgentStates.
+type resourceSortableAgentStates []*agent.AgentState
+
+func (sas resourceSortableAgentStates) Len() int      { return len(sas) }
+func (sas resourceSortableAgentStates) Swap(i, j int) { sas[i], sas[j] = sas[j], sas[i] }
+
+func (sas resourceSortableAgentStates) Less(i, j int) bool {
+	ri := sas[i].AvailableResources()
+	rj := sas[j].AvailableResources()
+	if ri.Memory != rj.Memory {
+		return ri.Memory < rj.Memory
+	}
+	if ri.Cores != rj.Cores {
+		return ri.Cores < rj.Cores
+	}
+	if ri.Disk != rj.Disk {
+		return ri.Disk < rj.Disk
+	}
+	return sortableAgentStates(sas).Less(i, j)
+}
+
+// spreadByMetadataScheduler places units on the least-loaded agent among
+// those whose value for a given metadata key is shared by the fewest
+// scheduled units. Agents lacking the key are considered to share the empty
+// value. With no key configured, it behaves like leastLoadedScheduler.
+type spreadByMetadataScheduler struct {
+	key string
+}
+
+func (sms *spreadByMetadataScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideAmong(sms.sortedAgents(clust), j)
+}
+
+func (sms *spreadByMetadataScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideRescheduleAmong(sms.sortedAgents(clust), j)
+}
+
+// sortedAgents returns a list of AgentState objects sorted ascending by the
+// number of units scheduled to agents sharing their metadata value, then as
+// sortableAgentStates would.
+func (sms *spreadByMetadataScheduler) sortedAgents(clust *clusterState) []*agent.AgentState {
+	agents := clust.agents()
+
+	counts := make(map[string]int)
+	for _, as := range agents {
+		counts[as.MState.Metadata[sms.key]] += len(as.Units)
+	}
+
+	sas := make(sortableAgentStates, 0)
+	for _, as := range agents {
+		sas = append(sas, as)
+	}
+	sort.Sort(sas)
+
+	sort.Stable(domainSortableAgentStates{
+		agents: []*agent.AgentState(sas),
+		key:    sms.key,
+		counts: counts,
+	})
+
+	return []*agent.AgentState(sas)
+}
+
+// domainSortableAgentStates orders agents by the number of units scheduled
+// to agents sharing their value for a metadata key.
+type domainSortableAgentStates struct {
+	agents []*agent.AgentState
+	key    string
+	counts map[string]int
+}
+
+func (das domainSortableAgentStates) Len() int { return len(das.agents) }
+func (das domainSortableAgentStates) Swap(i, j int) {
+	das.agents[i], das.agents[j] = das.agents[j], das.agents[i]
+}
+
+func (das domainSortableAgentStates) Less(i, j int) bool {
+	return das.counts[das.agents[i].MState.Metadata[das.key]] < das.counts[das.agents[j].MState.Metadata[das.key]]
+}
+
+// randomScheduler places units on a randomly chosen agent able to run them.
+type randomScheduler struct {
+	rand *rand.Rand
+}
+
+func newRandomScheduler(seed int64) *randomScheduler {
+	return &randomScheduler{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (rs *randomScheduler) Decide(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideAmong(rs.shuffledAgents(clust), j)
+}
+
+func (rs *randomScheduler) DecideReschedule(clust *clusterState, j *job.Job) (*decision, error) {
+	return decideRescheduleAmong(rs.shuffledAgents(clust), j)
+}
+
+// shuffledAgents returns a list of AgentState objects in random order
+func (rs *randomScheduler) shuffledAgents(clust *clusterState) []*agent.AgentState {
+	agents := clust.agents()
+
+	// sort first so that the outcome only depends on the random source
+	sas := make(sortableAgentStates, 0)
+	for _, as := range agents {
+		sas = append(sas, as)
+	}
+	sort.Sort(sas)
+
+	perm := rs.rand.Perm(len(sas))
+
+	shuffled := make([]*agent.AgentState, len(sas))
+	for i, p := range perm {
+		shuffled[i] = sas[p]
+	}
+
+	return shuffled
+}
+
+// explainJob describes why the job could not be scheduled in the given
+// cluster state, given the error returned by the Scheduler. Each agent that
+// is unable to run the job is listed along with the reason AbleToRun gave,
+// or the lack of available resources.
+func explainJob(clust *clusterState, j *job.Job, err error) *job.SchedulingExplanation {
+	ex := &job.SchedulingExplanation{
+		Reason:     err.Error(),
+		Rejections: make(map[string]string),
+	}
+
+	for id, as := range clust.agents() {
+		if act, reason := as.AbleToRun(j); act == job.JobActionUnschedule {
+			ex.Rejections[id] = reason
+		} else if !as.HasResources(j) {
+			ex.Rejections[id] = "insufficient resources available"
+		}
+	}
+
+	return ex
+}
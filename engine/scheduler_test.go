@@ -15,6 +15,7 @@
 package engine
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 	"testing"
@@ -22,8 +23,23 @@ import (
 	"github.com/coreos/fleet/agent"
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/resource"
+	"github.com/coreos/fleet/unit"
 )
 
+func newFleetUnit(t *testing.T, opts ...string) unit.UnitFile {
+	contents := "[X-Fleet]"
+	for _, v := range opts {
+		contents = fmt.Sprintf("%s\n%s", contents, v)
+	}
+
+	u, err := unit.NewUnitFile(contents)
+	if err != nil {
+		t.Fatalf("error creating unit from %q: %v", contents, err)
+	}
+	return *u
+}
+
 func TestSchedulerDecisions(t *testing.T) {
 	tests := []struct {
 		clust *clusterState
@@ -65,6 +81,177 @@ func TestSchedulerDecisions(t *testing.T) {
 	}
 }
 
+func TestSchedulerRescheduleDecisions(t *testing.T) {
+	units := []job.Unit{job.Unit{Name: "foo.service"}}
+	sUnits := []job.ScheduledUnit{job.ScheduledUnit{Name: "foo.service", TargetMachineID: "XXX"}}
+
+	tests := []struct {
+		machines  []machine.MachineState
+		blacklist string
+		job       *job.Job
+		dec       *decision
+	}{
+		// the current machine is not picked again
+		{
+			machines: []machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY"}},
+			job:      &job.Job{Name: "foo.service", TargetMachineID: "XXX"},
+			dec: &decision{
+				machineID: "YYY",
+			},
+		},
+
+		// cordoned machines are skipped
+		{
+			machines: []machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY", Cordoned: true}, machine.MachineState{ID: "ZZZ"}},
+			job:      &job.Job{Name: "foo.service", TargetMachineID: "XXX"},
+			dec: &decision{
+				machineID: "ZZZ",
+			},
+		},
+
+		// the only other machine is cordoned
+		{
+			machines: []machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY", Cordoned: true}},
+			job:      &job.Job{Name: "foo.service", TargetMachineID: "XXX"},
+			dec:      nil,
+		},
+
+		// machines the unit is blacklisted on are skipped
+		{
+			machines:  []machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY"}, machine.MachineState{ID: "ZZZ"}},
+			blacklist: "YYY",
+			job:       &job.Job{Name: "foo.service", TargetMachineID: "XXX"},
+			dec: &decision{
+				machineID: "ZZZ",
+			},
+		},
+
+		// excluded machines are skipped
+		{
+			machines: []machine.MachineState{machine.MachineState{ID: "XXX"}, machine.MachineState{ID: "YYY"}, machine.MachineState{ID: "ZZZ"}},
+			job:      &job.Job{Name: "foo.service", TargetMachineID: "XXX", Unit: newFleetUnit(t, "ExcludeMachineID=YYY")},
+			dec: &decision{
+				machineID: "ZZZ",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		clust := newClusterState(units, sUnits, tt.machines)
+		if tt.blacklist != "" {
+			clust.blacklistUnit("foo.service", tt.blacklist)
+		}
+		sched := &leastLoadedScheduler{}
+		dec, err := sched.DecideReschedule(clust, tt.job)
+
+		if err != nil && tt.dec != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		} else if err == nil && tt.dec == nil {
+			t.Errorf("case %d: expected error", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
+func TestBinPackSchedulerDecisions(t *testing.T) {
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "XXX", FreeResources: resource.ResourceTuple{Cores: 400, Memory: 4096}},
+		machine.MachineState{ID: "YYY", FreeResources: resource.ResourceTuple{Cores: 400, Memory: 2048}},
+		machine.MachineState{ID: "ZZZ"},
+	}
+
+	tests := []struct {
+		clust *clusterState
+		job   *job.Job
+		dec   *decision
+	}{
+		// no machines to receive job
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{}),
+			job:   &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "Resources=memory=1024")},
+			dec:   nil,
+		},
+
+		// pick the machine with the least resources left that fits
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, machines),
+			job:   &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "Resources=memory=1024")},
+			dec: &decision{
+				machineID: "YYY",
+			},
+		},
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, machines),
+			job:   &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "Resources=memory=3072")},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+
+		// units scheduled to a machine reduce its available resources
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{
+						Name:        "bar.service",
+						Unit:        newFleetUnit(t, "Resources=memory=1536"),
+						TargetState: job.JobStateLaunched,
+					},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{
+						Name:            "bar.service",
+						TargetMachineID: "YYY",
+					},
+				},
+				machines,
+			),
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "Resources=memory=1024")},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+
+		// no machine has enough resources
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, machines),
+			job:   &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "Resources=memory=8192")},
+			dec:   nil,
+		},
+
+		// units without requirements are placed on the least-loaded machine
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, machines),
+			job:   &job.Job{Name: "foo.service"},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		sched := &binPackScheduler{}
+		dec, err := sched.Decide(tt.clust, tt.job)
+
+		if err != nil && tt.dec != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		} else if err == nil && tt.dec == nil {
+			t.Errorf("case %d: expected error", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
 func TestAgentStateSorting(t *testing.T) {
 	tests := []struct {
 		in  []*agent.AgentState
@@ -175,3 +362,325 @@ func TestAgentStateSorting(t *testing.T) {
 		}
 	}
 }
+
+func TestNewScheduler(t *testing.T) {
+	tests := []struct {
+		name string
+		want Scheduler
+	}{
+		{"", &leastLoadedScheduler{}},
+		{"least-loaded", &leastLoadedScheduler{}},
+		{"spread-by-metadata", &spreadByMetadataScheduler{key: "az"}},
+		{"pack", &binPackScheduler{}},
+	}
+
+	for i, tt := range tests {
+		sched, err := NewScheduler(tt.name, "az")
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, sched) {
+			t.Errorf("case %d: expected scheduler %#v, got %#v", i, tt.want, sched)
+		}
+	}
+
+	if _, ok := mustNewScheduler(t, "random").(*randomScheduler); !ok {
+		t.Errorf("expected random scheduler")
+	}
+
+	if _, err := NewScheduler("bogus", ""); err == nil {
+		t.Errorf("expected error for unknown scheduler")
+	}
+}
+
+func mustNewScheduler(t *testing.T, name string) Scheduler {
+	sched, err := NewScheduler(name, "")
+	if err != nil {
+		t.Fatalf("unexpected error creating scheduler %q: %v", name, err)
+	}
+	return sched
+}
+
+func TestSpreadByMetadataSchedulerDecisions(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "XXX", Metadata: map[string]string{"az": "a"}},
+		machine.MachineState{ID: "YYY", Metadata: map[string]string{"az": "a"}},
+		machine.MachineState{ID: "ZZZ", Metadata: map[string]string{"az": "b"}},
+	}
+
+	tests := []struct {
+		clust *clusterState
+		job   *job.Job
+		dec   *decision
+	}{
+		// no machines to receive job
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{}),
+			job:   &job.Job{Name: "foo.service"},
+			dec:   nil,
+		},
+
+		// empty cluster, pick the first machine
+		{
+			clust: newClusterState([]job.Unit{}, []job.ScheduledUnit{}, machines),
+			job:   &job.Job{Name: "foo.service"},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+
+		// a unit in zone a makes zone b preferable
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "bar.service", TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "bar.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				machines,
+			),
+			job: &job.Job{Name: "foo.service"},
+			dec: &decision{
+				machineID: "ZZZ",
+			},
+		},
+
+		// constraints still apply
+		{
+			clust: newClusterState(
+				[]job.Unit{
+					job.Unit{Name: "bar.service", TargetState: jsLaunched},
+				},
+				[]job.ScheduledUnit{
+					job.ScheduledUnit{Name: "bar.service", State: &jsLaunched, TargetMachineID: "XXX"},
+				},
+				machines,
+			),
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "MachineMetadata=az=a")},
+			dec: &decision{
+				machineID: "YYY",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		sched := &spreadByMetadataScheduler{key: "az"}
+		dec, err := sched.Decide(tt.clust, tt.job)
+
+		if err != nil && tt.dec != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		} else if err == nil && tt.dec == nil {
+			t.Errorf("case %d: expected error", i)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
+func TestRandomSchedulerDecisions(t *testing.T) {
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "XXX", Metadata: map[string]string{"az": "a"}},
+		machine.MachineState{ID: "YYY", Metadata: map[string]string{"az": "b"}},
+		machine.MachineState{ID: "ZZZ", Metadata: map[string]string{"az": "b"}},
+	}
+	clust := newClusterState([]job.Unit{}, []job.ScheduledUnit{}, machines)
+	sched := newRandomScheduler(1)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		dec, err := sched.Decide(clust, &job.Job{Name: "foo.service"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[dec.machineID] = true
+	}
+	if len(seen) != len(machines) {
+		t.Errorf("expected all machines to be picked eventually, got %v", seen)
+	}
+
+	for i := 0; i < 50; i++ {
+		dec, err := sched.Decide(clust, &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "MachineMetadata=az=a")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dec.machineID != "XXX" {
+			t.Fatalf("expected decision for XXX, got %s", dec.machineID)
+		}
+	}
+
+	if _, err := sched.Decide(newClusterState([]job.Unit{}, []job.ScheduledUnit{}, []machine.MachineState{}), &job.Job{Name: "foo.service"}); err == nil {
+		t.Errorf("expected error with zero agents")
+	}
+}
+
+func TestSpreadByDecisions(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "AAA", Metadata: map[string]string{"az": "a"}},
+		machine.MachineState{ID: "BBB", Metadata: map[string]string{"az": "a"}},
+		machine.MachineState{ID: "CCC", Metadata: map[string]string{"az": "b"}},
+		machine.MachineState{ID: "DDD", Metadata: map[string]string{"az": "c", "disk": "hdd"}},
+	}
+
+	instance := func(name, machID string) (job.Unit, job.ScheduledUnit) {
+		return job.Unit{Name: name, Unit: newFleetUnit(t, "SpreadBy=az"), TargetState: jsLaunched},
+			job.ScheduledUnit{Name: name, State: &jsLaunched, TargetMachineID: machID}
+	}
+	u1, su1 := instance("foo@1.service", "CCC")
+	u2, su2 := instance("foo@2.service", "DDD")
+	u3, su3 := instance("foo@3.service", "AAA")
+
+	tests := []struct {
+		clust *clusterState
+		job   *job.Job
+		dec   *decision
+	}{
+		// without SpreadBy, least-loaded placement wins
+		{
+			clust: newClusterState([]job.Unit{u1, u2}, []job.ScheduledUnit{su1, su2}, machines),
+			job:   &job.Job{Name: "foo@3.service"},
+			dec: &decision{
+				machineID: "AAA",
+			},
+		},
+
+		// zones b and c already hold an instance, so zone a is chosen
+		{
+			clust: newClusterState([]job.Unit{u1, u2}, []job.ScheduledUnit{su1, su2}, machines),
+			job:   &job.Job{Name: "foo@3.service", Unit: newFleetUnit(t, "SpreadBy=az")},
+			dec: &decision{
+				machineID: "AAA",
+			},
+		},
+
+		// all zones hold an instance, pick the least-loaded machine
+		{
+			clust: newClusterState([]job.Unit{u1, u2, u3}, []job.ScheduledUnit{su1, su2, su3}, machines),
+			job:   &job.Job{Name: "foo@4.service", Unit: newFleetUnit(t, "SpreadBy=az")},
+			dec: &decision{
+				machineID: "BBB",
+			},
+		},
+
+		// instances of other templates do not count
+		{
+			clust: newClusterState([]job.Unit{u1, u2}, []job.ScheduledUnit{su1, su2}, machines),
+			job:   &job.Job{Name: "bar@1.service", Unit: newFleetUnit(t, "SpreadBy=az")},
+			dec: &decision{
+				machineID: "AAA",
+			},
+		},
+
+		// fall back to the next least-populated zone when constraints rule out the best one
+		{
+			clust: newClusterState([]job.Unit{u1, u2}, []job.ScheduledUnit{su1, su2}, machines),
+			job:   &job.Job{Name: "foo@3.service", Unit: newFleetUnit(t, "SpreadBy=az", "MachineMetadata=disk=hdd")},
+			dec: &decision{
+				machineID: "DDD",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		sched := &leastLoadedScheduler{}
+		dec, err := sched.Decide(tt.clust, tt.job)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
+
+func TestPreferenceDecisions(t *testing.T) {
+	jsLaunched := job.JobStateLaunched
+	machines := []machine.MachineState{
+		machine.MachineState{ID: "XXX", Metadata: map[string]string{"disk": "hdd"}},
+		machine.MachineState{ID: "YYY", Metadata: map[string]string{"disk": "ssd"}},
+		machine.MachineState{ID: "ZZZ", Metadata: map[string]string{"disk": "ssd"}},
+	}
+	units := []job.Unit{
+		job.Unit{Name: "cache.service", TargetState: jsLaunched},
+		job.Unit{Name: "db@1.service", TargetState: jsLaunched},
+	}
+	sUnits := []job.ScheduledUnit{
+		job.ScheduledUnit{Name: "cache.service", State: &jsLaunched, TargetMachineID: "ZZZ"},
+		job.ScheduledUnit{Name: "db@1.service", State: &jsLaunched, TargetMachineID: "YYY"},
+	}
+
+	tests := []struct {
+		job *job.Job
+		dec *decision
+	}{
+		// least-loaded without preferences
+		{
+			job: &job.Job{Name: "foo.service"},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+
+		// preferred metadata outranks load
+		{
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "PreferMachineMetadata=disk=ssd")},
+			dec: &decision{
+				machineID: "YYY",
+			},
+		},
+
+		// preferred peer
+		{
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "PreferMachineOf=cache.service")},
+			dec: &decision{
+				machineID: "ZZZ",
+			},
+		},
+
+		// avoided unit
+		{
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "PreferMachineMetadata=disk=ssd", "AvoidConflicts=db@*")},
+			dec: &decision{
+				machineID: "ZZZ",
+			},
+		},
+
+		// still scheduled when no machine matches the preferences
+		{
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "PreferMachineMetadata=disk=nvme", "PreferMachineOf=nope.service")},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+
+		// hard requirements still apply
+		{
+			job: &job.Job{Name: "foo.service", Unit: newFleetUnit(t, "PreferMachineOf=cache.service", "MachineMetadata=disk=hdd")},
+			dec: &decision{
+				machineID: "XXX",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		sched := &leastLoadedScheduler{}
+		dec, err := sched.Decide(newClusterState(units, sUnits, machines), tt.job)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.dec, dec) {
+			t.Errorf("case %d: expected decision %#v, got %#v", i, tt.dec, dec)
+		}
+	}
+}
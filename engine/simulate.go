@@ -0,0 +1,110 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/registry"
+)
+
+// SimulatedTask describes a task the engine would perform during
+// reconciliation.
+type SimulatedTask struct {
+	Type      string
+	Reason    string
+	JobName   string
+	MachineID string
+}
+
+// ErrUnpredictableScheduler is returned when simulating scheduling with a
+// Scheduler whose decisions cannot be predicted.
+var ErrUnpredictableScheduler = errors.New("decisions of the random scheduler cannot be simulated")
+
+// Simulate returns the tasks the engine would perform if the given Unit were
+// submitted to the cluster described by the Registry, using the provided
+// Scheduler. Tasks caused by the Unit over successive reconciliations, like
+// units it displaces, are included, while tasks the engine would perform
+// regardless of the Unit are left out. None of the tasks are performed and
+// the Registry is left untouched. The decisions of the random scheduler
+// differ from one run to the next, so they cannot be told apart from the
+// effects of the Unit and ErrUnpredictableScheduler is returned instead.
+func Simulate(reg registry.Registry, sched Scheduler, u *job.Unit) ([]SimulatedTask, error) {
+	if _, ok := sched.(*randomScheduler); ok {
+		return nil, ErrUnpredictableScheduler
+	}
+
+	baseline, err := clusterStateFromRegistry(reg)
+	if err != nil {
+		return nil, err
+	}
+	clust, err := clusterStateFromRegistry(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	unrelated := make(map[SimulatedTask]bool)
+	for _, t := range simulate(baseline, sched) {
+		unrelated[t] = true
+	}
+
+	clust.submit(u)
+	tasks := make([]SimulatedTask, 0)
+	for _, t := range simulate(clust, sched) {
+		if !unrelated[t] {
+			tasks = append(tasks, t)
+		}
+	}
+
+	return tasks, nil
+}
+
+// maxSimulatedReconciliations bounds the number of reconciliations
+// simulated before the cluster is expected to settle
+const maxSimulatedReconciliations = 8
+
+// simulate returns the distinct tasks of successive reconciliations of the
+// given cluster, until it settles.
+func simulate(clust *clusterState, sched Scheduler) []SimulatedTask {
+	r := NewReconciler(sched, nil)
+	r.simulated = true
+	tasks := make([]SimulatedTask, 0)
+	seen := make(map[SimulatedTask]bool)
+	for i := 0; i < maxSimulatedReconciliations; i++ {
+		settled := true
+		for t := range r.calculateClusterTasks(clust, make(chan struct{})) {
+			st := SimulatedTask{
+				Type:      t.Type,
+				Reason:    t.Reason,
+				JobName:   t.JobName,
+				MachineID: t.MachineID,
+			}
+			if seen[st] {
+				continue
+			}
+
+			seen[st] = true
+			tasks = append(tasks, st)
+			settled = false
+		}
+
+		if settled {
+			break
+		}
+	}
+
+	return tasks
+}
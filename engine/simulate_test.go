@@ -0,0 +1,170 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/registry"
+)
+
+func TestSimulate(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{
+		machine.MachineState{ID: "XXX", Metadata: map[string]string{"region": "us-east"}},
+		machine.MachineState{ID: "YYY", Metadata: map[string]string{"region": "us-west"}},
+	})
+	reg.SetJobs([]job.Job{
+		job.Job{
+			Name:            "bar.service",
+			TargetState:     job.JobStateLaunched,
+			TargetMachineID: "YYY",
+		},
+	})
+
+	tests := []struct {
+		unit  *job.Unit
+		tasks []SimulatedTask
+	}{
+		// new unit lands on the least-loaded eligible machine
+		{
+			unit: &job.Unit{
+				Name:        "foo.service",
+				TargetState: job.JobStateLaunched,
+			},
+			tasks: []SimulatedTask{
+				SimulatedTask{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "foo.service",
+					MachineID: "XXX",
+				},
+			},
+		},
+
+		// unit that cannot be placed causes no tasks
+		{
+			unit: &job.Unit{
+				Name:        "foo.service",
+				Unit:        newFleetUnit(t, "MachineMetadata=region=eu-west"),
+				TargetState: job.JobStateLaunched,
+			},
+			tasks: []SimulatedTask{},
+		},
+
+		// resubmitting a scheduled unit keeps it in place
+		{
+			unit: &job.Unit{
+				Name:        "bar.service",
+				TargetState: job.JobStateLaunched,
+			},
+			tasks: []SimulatedTask{},
+		},
+
+		// unit scheduled only once its machine is known
+		{
+			unit: &job.Unit{
+				Name:        "foo.service",
+				Unit:        newFleetUnit(t, "MachineOf=bar.service"),
+				TargetState: job.JobStateLaunched,
+			},
+			tasks: []SimulatedTask{
+				SimulatedTask{
+					Type:      taskTypeAttemptScheduleUnit,
+					Reason:    "target state launched and unit not scheduled",
+					JobName:   "foo.service",
+					MachineID: "YYY",
+				},
+			},
+		},
+
+		// inactive units are not scheduled
+		{
+			unit: &job.Unit{
+				Name:        "foo.service",
+				TargetState: job.JobStateInactive,
+			},
+			tasks: []SimulatedTask{},
+		},
+	}
+
+	for i, tt := range tests {
+		tasks, err := Simulate(reg, &leastLoadedScheduler{}, tt.unit)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.tasks, tasks) {
+			t.Errorf("case %d: task mismatch\nexpected %v\n got %v", i, tt.tasks, tasks)
+		}
+	}
+
+	units, err := reg.Units()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(units) != 1 {
+		t.Errorf("simulation modified the registry: %v", units)
+	}
+}
+
+func TestSimulateOmitsUnrelatedTasks(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{
+		machine.MachineState{ID: "XXX"},
+	})
+	reg.SetJobs([]job.Job{
+		job.Job{
+			Name:        "baz.service",
+			TargetState: job.JobStateLaunched,
+		},
+	})
+
+	u := &job.Unit{
+		Name:        "foo.service",
+		TargetState: job.JobStateLaunched,
+	}
+	tasks, err := Simulate(reg, &leastLoadedScheduler{}, u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expect := []SimulatedTask{
+		SimulatedTask{
+			Type:      taskTypeAttemptScheduleUnit,
+			Reason:    "target state launched and unit not scheduled",
+			JobName:   "foo.service",
+			MachineID: "XXX",
+		},
+	}
+	if !reflect.DeepEqual(expect, tasks) {
+		t.Errorf("task mismatch\nexpected %v\n got %v", expect, tasks)
+	}
+}
+
+func TestSimulateRandomScheduler(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	u := &job.Unit{
+		Name:        "foo.service",
+		TargetState: job.JobStateLaunched,
+	}
+	if _, err := Simulate(reg, newRandomScheduler(1), u); err != ErrUnpredictableScheduler {
+		t.Errorf("expected ErrUnpredictableScheduler, got %v", err)
+	}
+}
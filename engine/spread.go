@@ -0,0 +1,53 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/coreos/fleet/agent"
+	"github.com/coreos/fleet/job"
+)
+
+// spreadAgents narrows down the candidate agents for the given Job to those
+// whose value for the Job's SpreadBy metadata key is shared by the fewest
+// instances of the Job's template across all agents. Agents lacking the key
+// are considered to share the empty value. Candidates keep their order, and
+// are returned unchanged if the Job declares no SpreadBy key.
+func spreadAgents(all, candidates []*agent.AgentState, j *job.Job) []*agent.AgentState {
+	key, ok := j.SpreadBy()
+	if !ok || len(candidates) == 0 {
+		return candidates
+	}
+
+	counts := make(map[string]int)
+	for _, as := range all {
+		counts[as.MState.Metadata[key]] += as.InstancesOf(j)
+	}
+
+	min := -1
+	for _, as := range candidates {
+		if c := counts[as.MState.Metadata[key]]; min == -1 || c < min {
+			min = c
+		}
+	}
+
+	spread := make([]*agent.AgentState, 0, len(candidates))
+	for _, as := range candidates {
+		if counts[as.MState.Metadata[key]] == min {
+			spread = append(spread, as)
+		}
+	}
+
+	return spread
+}
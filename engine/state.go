@@ -15,15 +15,36 @@
 package engine
 
 import (
+	"sort"
+	"time"
+
 	"github.com/coreos/fleet/agent"
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/unit"
 )
 
 type clusterState struct {
 	jobs     map[string]*job.Job
 	gUnits   map[string]*job.Unit
 	machines map[string]*machine.MachineState
+	// draining maps the ID of each machine in an active maintenance
+	// window to the name of that window
+	draining map[string]string
+	// deployments holds the Deployments the engine converges on
+	deployments []job.Deployment
+	// blacklist maps the name of each unit to the IDs of the machines it
+	// is temporarily barred from
+	blacklist map[string]map[string]bool
+	// states maps the name of each scheduled unit to the state published
+	// for it by its target machine; it is only populated if needed
+	states map[string]*unit.UnitState
+	// completions maps the name of each batch unit that ran to completion
+	// to its Completion
+	completions map[string]job.Completion
+	// explanations maps the name of each unit the reconciler was unable
+	// to schedule to the reasons why
+	explanations map[string]*job.SchedulingExplanation
 }
 
 func newClusterState(units []job.Unit, sUnits []job.ScheduledUnit, machines []machine.MachineState) *clusterState {
@@ -62,10 +83,101 @@ func newClusterState(units []job.Unit, sUnits []job.ScheduledUnit, machines []ma
 	}
 
 	return &clusterState{
-		jobs:     jMap,
-		gUnits:   guMap,
-		machines: mMap,
+		jobs:         jMap,
+		gUnits:       guMap,
+		machines:     mMap,
+		draining:     make(map[string]string),
+		blacklist:    make(map[string]map[string]bool),
+		states:       make(map[string]*unit.UnitState),
+		completions:  make(map[string]job.Completion),
+		explanations: make(map[string]*job.SchedulingExplanation),
+	}
+}
+
+// applyMaintenance cordons the machines selected by any of the given
+// MaintenanceWindows that is about to start or has started at the given
+// time, and marks those in a started window as draining.
+func (cs *clusterState) applyMaintenance(windows []machine.MaintenanceWindow, now time.Time) {
+	for _, mw := range windows {
+		mw := mw
+		phase := mw.Phase(now)
+		if phase != machine.MaintenanceCordoned && phase != machine.MaintenanceActive {
+			continue
+		}
+
+		for _, ms := range cs.machines {
+			if !mw.Selects(ms) {
+				continue
+			}
+
+			ms.Cordoned = true
+			if _, ok := cs.draining[ms.ID]; !ok && phase == machine.MaintenanceActive {
+				cs.draining[ms.ID] = mw.Name
+			}
+		}
+	}
+}
+
+// applyCompletions marks the batch units that ran to completion according
+// to the given Completions as completed.
+func (cs *clusterState) applyCompletions(completions map[string]job.Completion) {
+	for name, c := range completions {
+		j, ok := cs.jobs[name]
+		if !ok || !j.IsBatch() {
+			continue
+		}
+		completed := job.JobStateCompleted
+		j.State = &completed
+		cs.completions[name] = c
+	}
+}
+
+// blacklistUnit bars the named unit from the given machine.
+func (cs *clusterState) blacklistUnit(name, machID string) {
+	if _, ok := cs.blacklist[name]; !ok {
+		cs.blacklist[name] = make(map[string]bool)
+	}
+	cs.blacklist[name][machID] = true
+}
+
+// needsUnitStates determines whether the engine needs to know the state of
+// the units, i.e. whether any unit has a FailurePolicy or a schedule.
+func (cs *clusterState) needsUnitStates() bool {
+	for _, j := range cs.jobs {
+		if _, ok := j.RescheduleOnFailure(); ok {
+			return true
+		}
+		if _, ok := j.CronSchedule(); ok {
+			return true
+		}
 	}
+	return false
+}
+
+// applyUnitStates records the given UnitStates of the scheduled units.
+// States published by machines other than the target machine of their unit
+// are ignored.
+func (cs *clusterState) applyUnitStates(states []*unit.UnitState) {
+	for _, us := range states {
+		j, ok := cs.jobs[us.UnitName]
+		if !ok || !j.Scheduled() || us.MachineID != j.TargetMachineID {
+			continue
+		}
+		cs.states[us.UnitName] = us
+	}
+}
+
+// finished determines whether the named unit is done running, i.e. it
+// completed, failed or is not meant to run.
+func (cs *clusterState) finished(name string) bool {
+	if _, ok := cs.completions[name]; ok {
+		return true
+	}
+	if us, ok := cs.states[name]; ok && us.ActiveState == "failed" {
+		return true
+	}
+	j, ok := cs.jobs[name]
+	return ok && j.TargetState == job.JobStateInactive
 }
 
 func (cs *clusterState) agents() map[string]*agent.AgentState {
@@ -75,6 +187,19 @@ func (cs *clusterState) agents() map[string]*agent.AgentState {
 		agents[ms.ID] = agent.NewAgentState(ms)
 	}
 
+	for name, machIDs := range cs.blacklist {
+		for machID := range machIDs {
+			as, ok := agents[machID]
+			if !ok {
+				continue
+			}
+			if as.Blacklisted == nil {
+				as.Blacklisted = make(map[string]bool)
+			}
+			as.Blacklisted[name] = true
+		}
+	}
+
 	for _, j := range cs.jobs {
 		j := j
 		if !j.Scheduled() || j.TargetState == job.JobStateInactive {
@@ -90,14 +215,17 @@ func (cs *clusterState) agents() map[string]*agent.AgentState {
 		}
 	}
 
-	for _, gu := range cs.gUnits {
-		gu := gu
-		for _, a := range agents {
-			if !machine.HasMetadata(a.MState, gu.RequiredTargetMetadata()) {
-				continue
-			}
+	// consider global units in the same order as the agents do
+	gNames := make([]string, 0, len(cs.gUnits))
+	for name := range cs.gUnits {
+		gNames = append(gNames, name)
+	}
+	sort.Strings(gNames)
 
-			if cExists, _ := a.HasConflict(gu.Name, gu.Conflicts()); cExists {
+	for _, name := range gNames {
+		gu := cs.gUnits[name]
+		for _, a := range agents {
+			if ok, _ := a.AbleToRunGlobal(gu); !ok {
 				continue
 			}
 			a.Units[gu.Name] = gu
@@ -107,6 +235,28 @@ func (cs *clusterState) agents() map[string]*agent.AgentState {
 	return agents
 }
 
+// submit adds the given Unit to the cluster state, replacing any Unit of the
+// same name. A replaced non-global Unit keeps its current schedule.
+func (cs *clusterState) submit(u *job.Unit) {
+	delete(cs.gUnits, u.Name)
+	if u.IsGlobal() {
+		delete(cs.jobs, u.Name)
+		cs.gUnits[u.Name] = u
+		return
+	}
+
+	j := &job.Job{
+		Name:        u.Name,
+		Unit:        u.Unit,
+		TargetState: u.TargetState,
+	}
+	if existing, ok := cs.jobs[u.Name]; ok {
+		j.TargetMachineID = existing.TargetMachineID
+		j.State = existing.State
+	}
+	cs.jobs[u.Name] = j
+}
+
 func (cs *clusterState) schedule(jobName, targetMachineID string) {
 	j := cs.jobs[jobName]
 	if j == nil {
@@ -122,3 +272,9 @@ func (cs *clusterState) unschedule(jobName string) {
 	}
 	j.TargetMachineID = ""
 }
+
+// destroy removes the named Unit from the cluster state.
+func (cs *clusterState) destroy(name string) {
+	delete(cs.jobs, name)
+	delete(cs.gUnits, name)
+}
@@ -17,7 +17,9 @@ package engine
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/coreos/fleet/agent"
 	"github.com/coreos/fleet/job"
@@ -345,3 +347,81 @@ func TestClusterStateAgents(t *testing.T) {
 		}
 	}
 }
+
+func TestClusterStateAgentsGlobalRestrictions(t *testing.T) {
+	excluded := &job.Unit{Name: "excluded.service", Unit: newFleetUnit(t, "Global=true", "ExcludeMachineID=YYY")}
+	paused := &job.Unit{Name: "paused.service", Unit: newFleetUnit(t, "Global=true")}
+	conflicting := &job.Unit{Name: "conflicting.service", Unit: newFleetUnit(t, "Global=true", "Conflicts=foo.service")}
+
+	clust := &clusterState{
+		jobs: map[string]*job.Job{
+			"foo.service": &job.Job{
+				Name:            "foo.service",
+				TargetState:     job.JobStateLaunched,
+				TargetMachineID: "XXX",
+			},
+		},
+		gUnits: map[string]*job.Unit{
+			excluded.Name:    excluded,
+			paused.Name:      paused,
+			conflicting.Name: conflicting,
+		},
+		machines: map[string]*machine.MachineState{
+			"XXX": &machine.MachineState{ID: "XXX", PausedUnits: []string{"paused.service"}},
+			"YYY": &machine.MachineState{ID: "YYY"},
+		},
+	}
+
+	want := map[string][]string{
+		"XXX": []string{"excluded.service", "foo.service"},
+		"YYY": []string{"conflicting.service", "paused.service"},
+	}
+
+	for id, a := range clust.agents() {
+		var got []string
+		for name := range a.Units {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(want[id], got) {
+			t.Errorf("Machine(%s): expected Units %v, got %v", id, want[id], got)
+		}
+	}
+}
+
+func TestClusterStateApplyMaintenance(t *testing.T) {
+	now := time.Date(2016, time.May, 1, 2, 0, 0, 0, time.UTC)
+	windows := []machine.MaintenanceWindow{
+		// started, selecting by metadata
+		{Name: "rack", Metadata: map[string]string{"rack": "r12"}, Start: now.Add(-time.Minute), End: now.Add(time.Hour)},
+		// about to start
+		{Name: "soon", MachineID: "ZZZ", Lead: time.Hour, Start: now.Add(time.Minute), End: now.Add(time.Hour)},
+		// not yet within its lead time
+		{Name: "later", MachineID: "AAA", Lead: time.Minute, Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+		// ended
+		{Name: "past", MachineID: "BBB", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+	}
+
+	clust := newClusterState(nil, nil, []machine.MachineState{
+		machine.MachineState{ID: "XXX", Metadata: map[string]string{"rack": "r12"}},
+		machine.MachineState{ID: "YYY", Metadata: map[string]string{"rack": "r13"}},
+		machine.MachineState{ID: "ZZZ"},
+		machine.MachineState{ID: "AAA"},
+		machine.MachineState{ID: "BBB"},
+	})
+	clust.applyMaintenance(windows, now)
+
+	cordoned := make(map[string]bool)
+	for id, ms := range clust.machines {
+		if ms.Cordoned {
+			cordoned[id] = true
+		}
+	}
+	if want := map[string]bool{"XXX": true, "ZZZ": true}; !reflect.DeepEqual(want, cordoned) {
+		t.Errorf("unexpected cordoned machines: want %v, got %v", want, cordoned)
+	}
+
+	if want := map[string]string{"XXX": "rack"}; !reflect.DeepEqual(want, clust.draining) {
+		t.Errorf("unexpected draining machines: want %v, got %v", want, clust.draining)
+	}
+}
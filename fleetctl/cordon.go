@@ -0,0 +1,114 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/machine"
+)
+
+var (
+	cmdCordon = &cobra.Command{
+		Use:   "cordon MACHINE",
+		Short: "Prevent new units from being scheduled to a machine",
+		Long: `Marks a machine as cordoned. The engine schedules no new units to a cordoned
+machine, while units already scheduled to it keep running. Global units are
+not affected.
+
+The machine may be given as a full machine ID or any unique prefix of it.
+A machine stays cordoned across leaving and rejoining the cluster until it is
+uncordoned.`,
+		Run: runWrapper(runCordonMachine),
+	}
+
+	cmdUncordon = &cobra.Command{
+		Use:   "uncordon MACHINE",
+		Short: "Allow new units to be scheduled to a cordoned machine",
+		Long: `Clears the cordoned mark of a machine, making it eligible for new units again.
+Units moved off the machine while it was cordoned are not moved back.`,
+		Run: runWrapper(runUncordonMachine),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdCordon)
+	cmdFleet.AddCommand(cmdUncordon)
+}
+
+func runCordonMachine(cCmd *cobra.Command, args []string) (exit int) {
+	return setMachineCordoned(args, true)
+}
+
+func runUncordonMachine(cCmd *cobra.Command, args []string) (exit int) {
+	return setMachineCordoned(args, false)
+}
+
+func setMachineCordoned(args []string, cordoned bool) (exit int) {
+	if len(args) != 1 {
+		stderr("One machine must be provided")
+		return 1
+	}
+
+	ms, err := findMachine(args[0])
+	if err != nil {
+		stderr("Error finding machine %s: %v", args[0], err)
+		return 1
+	}
+
+	if err := cAPI.SetMachineCordoned(ms.ID, cordoned); err != nil {
+		stderr("Error updating machine %s: %v", ms.ID, err)
+		return 1
+	}
+
+	if cordoned {
+		stdout("Machine %s cordoned", ms.ID)
+	} else {
+		stdout("Machine %s uncordoned", ms.ID)
+	}
+	return 0
+}
+
+// findMachine returns the state of the single machine whose ID starts with
+// the given lookup string.
+func findMachine(lookup string) (*machine.MachineState, error) {
+	states, err := cAPI.Machines()
+	if err != nil {
+		return nil, err
+	}
+
+	var match *machine.MachineState
+	for i := range states {
+		machState := states[i]
+		if !strings.HasPrefix(machState.ID, lookup) {
+			continue
+		}
+
+		if match != nil {
+			return nil, fmt.Errorf("found more than one machine")
+		}
+
+		match = &machState
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("machine does not exist")
+	}
+
+	return match, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/registry"
+)
+
+func newFakeRegistryForCordon() *registry.FakeRegistry {
+	reg := registry.NewFakeRegistry()
+	reg.SetMachines([]machine.MachineState{
+		machine.MachineState{ID: "c31e44e1-f858-436e-933e-59c642517860"},
+		machine.MachineState{ID: "c3a9a0f4-0b2a-4a9e-8b1a-3c1c8f0f4a52"},
+		machine.MachineState{ID: "595989bb-cbb7-49ce-8726-722d6e157b4e"},
+	})
+	reg.SetJobs([]job.Job{
+		{Name: "hello.service", TargetState: job.JobStateLaunched, TargetMachineID: "c31e44e1-f858-436e-933e-59c642517860"},
+	})
+	return reg
+}
+
+func TestRunCordonMachine(t *testing.T) {
+	reg := newFakeRegistryForCordon()
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	if exit := runCordonMachine(cmdCordon, []string{"5959"}); exit != 0 {
+		t.Fatalf("cordon: expected exit code 0 but received %d", exit)
+	}
+	if ms, _ := findMachine("5959"); !ms.Cordoned {
+		t.Errorf("cordon: expected machine to be cordoned")
+	}
+
+	if exit := runUncordonMachine(cmdUncordon, []string{"5959"}); exit != 0 {
+		t.Fatalf("uncordon: expected exit code 0 but received %d", exit)
+	}
+	if ms, _ := findMachine("5959"); ms.Cordoned {
+		t.Errorf("uncordon: expected machine not to be cordoned")
+	}
+
+	results := []commandTestResults{
+		{
+			"cordon ambiguous machine",
+			[]string{"c3"},
+			1,
+		},
+		{
+			"cordon non-existent machine",
+			[]string{"ffff"},
+			1,
+		},
+		{
+			"cordon several machines",
+			[]string{"5959", "c31e"},
+			1,
+		},
+	}
+
+	for _, r := range results {
+		exit := runCordonMachine(cmdCordon, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+	}
+}
+
+func TestRunDrainMachine(t *testing.T) {
+	reg := newFakeRegistryForCordon()
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	// no units are scheduled to the machine, so nothing needs to move
+	if exit := runDrainMachine(cmdDrain, []string{"5959"}); exit != 0 {
+		t.Fatalf("drain: expected exit code 0 but received %d", exit)
+	}
+	if ms, _ := findMachine("5959"); !ms.Cordoned {
+		t.Errorf("drain: expected machine to be cordoned")
+	}
+
+	if exit := runDrainMachine(cmdDrain, []string{"ffff"}); exit != 1 {
+		t.Errorf("drain non-existent machine: expected exit code 1 but received %d", exit)
+	}
+}
+
+func TestRunDrainMachineTimeout(t *testing.T) {
+	reg := newFakeRegistryForCordon()
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	oldAttempts := sharedFlags.BlockAttempts
+	sharedFlags.BlockAttempts = 1
+	defer func() { sharedFlags.BlockAttempts = oldAttempts }()
+
+	// without an engine the unit is never unscheduled, so the drain
+	// gives up on it
+	if exit := runDrainMachine(cmdDrain, []string{"c31e"}); exit != 1 {
+		t.Fatalf("drain: expected exit code 1 but received %d", exit)
+	}
+
+	u, err := cAPI.Unit("hello.service")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.DesiredState != string(job.JobStateLaunched) {
+		t.Errorf("drain: expected target state of unit to be restored to %s, got %s", job.JobStateLaunched, u.DesiredState)
+	}
+}
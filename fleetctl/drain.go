@@ -0,0 +1,149 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/schema"
+)
+
+var cmdDrain = &cobra.Command{
+	Use:   "drain [--block-attempts=N] MACHINE",
+	Short: "Cordon a machine and move its units to other machines",
+	Long: `Cordons a machine, then moves each non-global unit scheduled to it to another
+machine, one unit at a time. A unit is moved by unscheduling it and letting the
+engine schedule it again, and fleetctl waits for each unit to reach its
+previous state on its new machine before moving the next one. If a unit cannot
+be moved, its previous state is restored and the drain stops.
+
+Global units keep running on the drained machine. Once maintenance is over,
+use uncordon to make the machine eligible for new units again.
+
+Drain a machine given a prefix of its ID:
+fleetctl drain 2444264c`,
+	Run: runWrapper(runDrainMachine),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdDrain)
+
+	cmdDrain.Flags().IntVar(&sharedFlags.BlockAttempts, "block-attempts", 0, "Wait until each unit has moved, performing up to N attempts before giving up. A value of 0 indicates no limit.")
+}
+
+func runDrainMachine(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One machine must be provided")
+		return 1
+	}
+
+	ms, err := findMachine(args[0])
+	if err != nil {
+		stderr("Error finding machine %s: %v", args[0], err)
+		return 1
+	}
+
+	if !ms.Cordoned {
+		if err := cAPI.SetMachineCordoned(ms.ID, true); err != nil {
+			stderr("Error cordoning machine %s: %v", ms.ID, err)
+			return 1
+		}
+		stdout("Machine %s cordoned", ms.ID)
+	}
+
+	units, err := cAPI.Units()
+	if err != nil {
+		stderr("Error retrieving list of units from repository: %v", err)
+		return 1
+	}
+
+	attempts := getBlockAttempts(cCmd)
+	for _, u := range units {
+		if u.MachineID != ms.ID || suToGlobal(*u) || job.JobState(u.DesiredState) == job.JobStateInactive {
+			continue
+		}
+
+		if err := moveUnit(u, ms.ID, attempts); err != nil {
+			stderr("Error moving unit %s off machine %s: %v", u.Name, ms.ID, err)
+			return 1
+		}
+	}
+
+	stdout("Machine %s drained", ms.ID)
+	return 0
+}
+
+// moveUnit moves the given unit away from the given machine by unscheduling
+// it, then restoring its target state once it is no longer scheduled to the
+// machine. It waits for the unit to reach that state again, unless
+// maxAttempts is negative. If the unit cannot be moved, its target state is
+// restored all the same, so a failed drain does not leave it stopped.
+func moveUnit(u *schema.Unit, machID string, maxAttempts int) error {
+	state := job.JobState(u.DesiredState)
+	if err := cAPI.SetUnitTargetState(u.Name, string(job.JobStateInactive)); err != nil {
+		return err
+	}
+
+	restored := false
+	defer func() {
+		if restored {
+			return
+		}
+		if err := cAPI.SetUnitTargetState(u.Name, string(state)); err != nil {
+			stderr("Error restoring target state of unit %s: %v", u.Name, err)
+		}
+	}()
+
+	// a unit destroyed meanwhile has nothing left to move or restore
+	destroyed := false
+	unscheduled := func() error {
+		cur, err := cAPI.Unit(u.Name)
+		if err != nil {
+			return fmt.Errorf("Error retrieving Unit(%s) from Registry: %v", u.Name, err)
+		}
+		if cur == nil {
+			destroyed = true
+			return nil
+		}
+		if cur.MachineID == machID {
+			return fmt.Errorf("Waiting for Unit(%s) to be unscheduled from Machine(%s)", u.Name, machID)
+		}
+		return nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		if maxAttempts > 0 && attempt == maxAttempts {
+			return fmt.Errorf("timed out waiting for unit %s to be unscheduled", u.Name)
+		}
+		if _, err := waitForState(unscheduled); err == nil {
+			break
+		}
+	}
+
+	if destroyed {
+		restored = true
+		return nil
+	}
+
+	if err := cAPI.SetUnitTargetState(u.Name, string(state)); err != nil {
+		return err
+	}
+	restored = true
+
+	return tryWaitForUnitStates([]string{u.Name}, "move", state, maxAttempts, out)
+}
@@ -0,0 +1,146 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/schema"
+)
+
+var (
+	dropInFlags = struct {
+		Metadata string
+	}{}
+
+	cmdSetDropIn = &cobra.Command{
+		Use:   "set-drop-in [--metadata=KEY=VALUE,...] UNIT FILE",
+		Short: "Add or replace a drop-in of a unit",
+		Long: `Stores the given file as a drop-in of a unit, named after the base name of the
+file, which must end in ".conf". The agents apply the drop-in to the unit on
+every machine running it, reloading and restarting the unit if its drop-ins
+change. Drop-ins of a template unit apply to all of its instances.
+
+A drop-in may be restricted to machines having each of the given metadata
+pairs, e.g. to set a different environment per region. Setting a drop-in
+with the name of an existing drop-in of the unit replaces it.
+
+Set the environment of all instances of web@.service in us-west:
+	fleetctl set-drop-in --metadata=region=us-west web@.service 10-env.conf`,
+		Run: runWrapper(runSetDropIn),
+	}
+
+	cmdListDropIns = &cobra.Command{
+		Use:   "list-drop-ins [--no-legend]",
+		Short: "Enumerate the drop-ins of all units",
+		Long:  `Lists all drop-ins stored in the cluster, along with the machines they apply to.`,
+		Run:   runWrapper(runListDropIns),
+	}
+
+	cmdRemoveDropIn = &cobra.Command{
+		Use:   "remove-drop-in UNIT NAME",
+		Short: "Remove a drop-in of a unit",
+		Long: `Removes a drop-in of a unit. The agents reload and restart the units it applied
+to without it.`,
+		Run: runWrapper(runRemoveDropIn),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdSetDropIn)
+	cmdFleet.AddCommand(cmdListDropIns)
+	cmdFleet.AddCommand(cmdRemoveDropIn)
+
+	cmdSetDropIn.Flags().StringVar(&dropInFlags.Metadata, "metadata", "", "Apply the drop-in only on machines having each of these comma-separated KEY=VALUE metadata pairs.")
+
+	cmdListDropIns.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+}
+
+func runSetDropIn(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 2 {
+		stderr("One unit name and one drop-in file must be provided")
+		return 1
+	}
+
+	contents, err := ioutil.ReadFile(args[1])
+	if err != nil {
+		stderr("Error reading drop-in file %s: %v", args[1], err)
+		return 1
+	}
+
+	d := schema.DropIn{
+		Unit:     args[0],
+		Name:     filepath.Base(args[1]),
+		Contents: string(contents),
+	}
+	if dropInFlags.Metadata != "" {
+		md, err := parseMetadataSelector(dropInFlags.Metadata)
+		if err != nil {
+			stderr("Invalid metadata selector: %v", err)
+			return 1
+		}
+		d.Metadata = md
+	}
+
+	if err := cAPI.SetDropIn(&d); err != nil {
+		stderr("Error setting drop-in %s of %s: %v", d.Name, d.Unit, err)
+		return 1
+	}
+
+	stdout("Set drop-in %s of %s", d.Name, d.Unit)
+	return 0
+}
+
+func runListDropIns(cCmd *cobra.Command, args []string) (exit int) {
+	dropIns, err := cAPI.DropIns()
+	if err != nil {
+		stderr("Error retrieving list of drop-ins from fleet API: %v", err)
+		return 1
+	}
+
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "UNIT\tNAME\tMACHINES")
+	}
+
+	for _, d := range dropIns {
+		machines := "-"
+		if len(d.Metadata) > 0 {
+			machines = formatMetadata(d.Metadata)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\n", d.Unit, d.Name, machines)
+	}
+
+	out.Flush()
+	return 0
+}
+
+func runRemoveDropIn(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 2 {
+		stderr("One unit name and one drop-in name must be provided")
+		return 1
+	}
+
+	if err := cAPI.DestroyDropIn(args[0], args[1]); err != nil {
+		stderr("Error removing drop-in %s of %s: %v", args[1], args[0], err)
+		return 1
+	}
+
+	stdout("Removed drop-in %s of %s", args[1], args[0])
+	return 0
+}
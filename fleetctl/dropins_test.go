@@ -0,0 +1,93 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestRunSetDropIn(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	dir, err := ioutil.TempDir("", "fleetctl-dropins")
+	if err != nil {
+		t.Fatalf("Failed creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	contents := "[Service]\nEnvironment=REGION=us-west\n"
+	file := filepath.Join(dir, "10-env.conf")
+	if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed writing drop-in file: %v", err)
+	}
+
+	dropInFlags.Metadata = "region=us-west"
+	defer func() {
+		dropInFlags.Metadata = ""
+	}()
+
+	if exit := runSetDropIn(cmdSetDropIn, []string{"web@.service", file}); exit != 0 {
+		t.Fatalf("set-drop-in: expected exit code 0 but received %d", exit)
+	}
+
+	dropIns, err := reg.DropIns()
+	if err != nil {
+		t.Fatalf("Failed fetching drop-ins: %v", err)
+	}
+	want := []unit.DropIn{{Unit: "web@.service", Name: "10-env.conf", Metadata: map[string]string{"region": "us-west"}, Contents: contents}}
+	if !reflect.DeepEqual(want, dropIns) {
+		t.Errorf("Expected drop-ins %v, got %v", want, dropIns)
+	}
+
+	if exit := runListDropIns(cmdListDropIns, nil); exit != 0 {
+		t.Errorf("list-drop-ins: expected exit code 0 but received %d", exit)
+	}
+
+	if exit := runRemoveDropIn(cmdRemoveDropIn, []string{"web@.service", "10-env.conf"}); exit != 0 {
+		t.Errorf("remove-drop-in: expected exit code 0 but received %d", exit)
+	}
+	if exit := runRemoveDropIn(cmdRemoveDropIn, []string{"web@.service", "10-env.conf"}); exit != 1 {
+		t.Errorf("remove-drop-in twice: expected exit code 1 but received %d", exit)
+	}
+
+	// drop-in names must end in .conf
+	invalid := filepath.Join(dir, "env")
+	if err := ioutil.WriteFile(invalid, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed writing drop-in file: %v", err)
+	}
+	if exit := runSetDropIn(cmdSetDropIn, []string{"web@.service", invalid}); exit != 1 {
+		t.Errorf("set-drop-in with invalid name: expected exit code 1 but received %d", exit)
+	}
+
+	// missing file
+	if exit := runSetDropIn(cmdSetDropIn, []string{"web@.service", filepath.Join(dir, "missing.conf")}); exit != 1 {
+		t.Errorf("set-drop-in with missing file: expected exit code 1 but received %d", exit)
+	}
+
+	// invalid metadata selector
+	dropInFlags.Metadata = "region"
+	if exit := runSetDropIn(cmdSetDropIn, []string{"web@.service", file}); exit != 1 {
+		t.Errorf("set-drop-in with invalid metadata: expected exit code 1 but received %d", exit)
+	}
+}
@@ -658,6 +658,7 @@ func createUnit(name string, uf *unit.UnitFile) (*schema.Unit, error) {
 	u := schema.Unit{
 		Name:    name,
 		Options: schema.MapUnitFileToSchemaUnitOptions(uf),
+		Client:  clientName(),
 	}
 	// TODO(jonboulle): this dependency on the API package is awkward, and
 	// redundant with the check in api.unitsResource.set, but it is a
@@ -682,6 +683,19 @@ func createUnit(name string, uf *unit.UnitFile) (*schema.Unit, error) {
 	return &u, nil
 }
 
+// clientName identifies the user of fleetctl in the revision history of the
+// units it submits, e.g. core@host1.
+func clientName() string {
+	name := os.Getenv("USER")
+	if name == "" {
+		name = "fleetctl"
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
 // checkReplaceUnitState checks if the unit should be replaced.
 // It takes a Unit object as a parameter.
 // It returns 0 on success and if the unit should be replaced, 1 if the
@@ -1043,7 +1057,9 @@ func assertUnitState(name string, js job.JobState, out io.Writer) bool {
 			state = u.CurrentState
 		}
 
-		if job.JobState(state) != js {
+		// a batch unit may complete before it is seen launched
+		launched := js == job.JobStateLaunched && job.JobState(state) == job.JobStateCompleted
+		if job.JobState(state) != js && !launched {
 			return fmt.Errorf("Waiting for Unit(%s) state(%s) to be %s", name, job.JobState(state), js)
 		}
 
@@ -0,0 +1,184 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/schema"
+	"github.com/coreos/fleet/unit"
+)
+
+var (
+	rollbackFlags = struct {
+		To int
+	}{}
+
+	cmdHistory = &cobra.Command{
+		Use:   "history [--no-legend] [--full] UNIT",
+		Short: "List the revisions of a unit file",
+		Long: `Lists the unit files submitted under the name of a unit, oldest first, along
+with the time they were submitted and the client which submitted them. Only
+the most recent revisions are kept.`,
+		Run: runWrapper(runHistory),
+	}
+
+	cmdRollback = &cobra.Command{
+		Use:   "rollback [--to=REVISION] UNIT",
+		Short: "Replace a unit file with one of its previous revisions",
+		Long: `Rollback replaces the unit file of a unit with a revision listed by
+fleetctl history, keeping the desired state of the unit. By default, the
+revision preceding the current unit file is used. The rollback itself is
+recorded as a new revision.
+
+Instances of a template unit are not affected by a rollback of the template.
+
+Revert the last change to foo.service:
+	fleetctl rollback foo.service
+
+Revert foo.service to its third revision:
+	fleetctl rollback --to=3 foo.service`,
+		Run: runWrapper(runRollback),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdHistory)
+	cmdFleet.AddCommand(cmdRollback)
+
+	cmdHistory.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+	cmdHistory.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdRollback.Flags().IntVar(&rollbackFlags.To, "to", 0, "Number of the revision to roll back to. Defaults to the revision preceding the current unit file.")
+}
+
+func runHistory(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One unit must be provided")
+		return 1
+	}
+	name := unitNameMangle(args[0])
+
+	u, revisions, err := unitAndRevisions(name)
+	if err != nil {
+		stderr("%v", err)
+		return 1
+	}
+
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "REVISION\tCREATED\tCLIENT\tHASH\tCURRENT")
+	}
+
+	current := currentRevision(u, revisions)
+	for i, rev := range revisions {
+		hash := rev.Hash
+		if !sharedFlags.Full {
+			hash = fmt.Sprintf("%.7s", hash)
+		}
+		mark := "-"
+		if i == current {
+			mark = "*"
+		}
+		fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\n", rev.Number, rev.Created, rev.Client, hash, mark)
+	}
+
+	out.Flush()
+	return 0
+}
+
+func runRollback(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One unit must be provided")
+		return 1
+	}
+	name := unitNameMangle(args[0])
+
+	u, revisions, err := unitAndRevisions(name)
+	if err != nil {
+		stderr("%v", err)
+		return 1
+	}
+
+	var target *schema.UnitRevision
+	if rollbackFlags.To != 0 {
+		for _, rev := range revisions {
+			if rev.Number == int64(rollbackFlags.To) {
+				target = rev
+			}
+		}
+		if target == nil {
+			stderr("Revision %d of %s does not exist", rollbackFlags.To, name)
+			return 1
+		}
+	} else {
+		current := currentRevision(u, revisions)
+		if current < 1 {
+			stderr("No revision of %s precedes its current unit file", name)
+			return 1
+		}
+		target = revisions[current-1]
+	}
+
+	if unit.MatchUnitFiles(schema.MapSchemaUnitOptionsToUnitFile(u.Options), schema.MapSchemaUnitOptionsToUnitFile(target.Options)) {
+		stdout("Unit %s already runs revision %d", name, target.Number)
+		return 0
+	}
+
+	err = cAPI.CreateUnit(&schema.Unit{
+		Name:         name,
+		Options:      target.Options,
+		DesiredState: u.DesiredState,
+		Client:       clientName(),
+	})
+	if err != nil {
+		stderr("Error rolling back %s: %v", name, err)
+		return 1
+	}
+
+	stdout("Rolled back %s to revision %d", name, target.Number)
+	return 0
+}
+
+// unitAndRevisions retrieves the named unit and its revisions. An error is
+// returned if the unit does not exist.
+func unitAndRevisions(name string) (*schema.Unit, []*schema.UnitRevision, error) {
+	u, err := cAPI.Unit(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error retrieving unit %s: %v", name, err)
+	}
+	if u == nil {
+		return nil, nil, fmt.Errorf("Unit %s does not exist", name)
+	}
+
+	revisions, err := cAPI.UnitRevisions(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error retrieving revisions of %s: %v", name, err)
+	}
+	return u, revisions, nil
+}
+
+// currentRevision returns the index of the latest revision matching the
+// unit file of the given unit, or -1 if there is none, e.g. because the unit
+// was submitted before revisions were recorded.
+func currentRevision(u *schema.Unit, revisions []*schema.UnitRevision) int {
+	uf := schema.MapSchemaUnitOptionsToUnitFile(u.Options)
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if unit.MatchUnitFiles(uf, schema.MapSchemaUnitOptionsToUnitFile(revisions[i].Options)) {
+			return i
+		}
+	}
+	return -1
+}
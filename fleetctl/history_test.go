@@ -0,0 +1,126 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/schema"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestRunRollback(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	var files []*unit.UnitFile
+	for _, contents := range []string{"[Service]\nExecStart=/bin/v1", "[Service]\nExecStart=/bin/v2", "[Service]\nExecStart=/bin/v3"} {
+		uf, err := unit.NewUnitFile(contents)
+		if err != nil {
+			t.Fatalf("Unexpected error creating unit file: %v", err)
+		}
+		if _, err := createUnit("hello.service", uf); err != nil {
+			t.Fatalf("Unexpected error creating unit: %v", err)
+		}
+		files = append(files, uf)
+	}
+
+	if exit := runHistory(cmdHistory, []string{"hello.service"}); exit != 0 {
+		t.Errorf("history: expected exit code 0 but received %d", exit)
+	}
+
+	tests := []struct {
+		description string
+		to          int
+		// expected is the index of the unit file expected after the rollback
+		expected     int
+		expectedExit int
+	}{
+		{
+			description:  "roll back to the preceding revision",
+			expected:     1,
+			expectedExit: 0,
+		},
+		{
+			description:  "roll back to a given revision",
+			to:           1,
+			expected:     0,
+			expectedExit: 0,
+		},
+		{
+			description:  "roll back to the current revision",
+			to:           1,
+			expected:     0,
+			expectedExit: 0,
+		},
+		{
+			description:  "roll back to a missing revision",
+			to:           42,
+			expected:     0,
+			expectedExit: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		rollbackFlags.To = tt.to
+		if exit := runRollback(cmdRollback, []string{"hello.service"}); exit != tt.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", tt.description, tt.expectedExit, exit)
+		}
+
+		u, err := cAPI.Unit("hello.service")
+		if err != nil || u == nil {
+			t.Fatalf("%s: failed fetching unit: %v", tt.description, err)
+		}
+		if !unit.MatchUnitFiles(schema.MapSchemaUnitOptionsToUnitFile(u.Options), files[tt.expected]) {
+			t.Errorf("%s: expected unit file %d, got %v", tt.description, tt.expected+1, u.Options)
+		}
+	}
+	rollbackFlags.To = 0
+
+	// Both rollbacks were recorded as new revisions
+	revisions, err := reg.UnitRevisions("hello.service")
+	if err != nil {
+		t.Fatalf("Failed fetching revisions: %v", err)
+	}
+	if len(revisions) != 5 {
+		t.Errorf("Expected 5 revisions, got %d", len(revisions))
+	}
+
+	results := []commandTestResults{
+		{
+			"history without a unit",
+			[]string{},
+			1,
+		},
+		{
+			"history of a missing unit",
+			[]string{"missing.service"},
+			1,
+		},
+	}
+
+	for _, r := range results {
+		exit := runHistory(cmdHistory, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+		exit = runRollback(cmdRollback, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+	}
+}
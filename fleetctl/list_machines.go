@@ -17,6 +17,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -46,6 +47,27 @@ var (
 			}
 			return formatMetadata(ms.Metadata)
 		},
+		"cordoned": func(ms *machine.MachineState, full bool) string {
+			return strconv.FormatBool(ms.Cordoned)
+		},
+		"cores": func(ms *machine.MachineState, full bool) string {
+			if ms.TotalResources.Empty() {
+				return "-"
+			}
+			return formatResource(ms.FreeResources.Cores, ms.TotalResources.Cores)
+		},
+		"memory": func(ms *machine.MachineState, full bool) string {
+			if ms.TotalResources.Empty() {
+				return "-"
+			}
+			return formatResource(ms.FreeResources.Memory, ms.TotalResources.Memory)
+		},
+		"disk": func(ms *machine.MachineState, full bool) string {
+			if ms.TotalResources.Empty() {
+				return "-"
+			}
+			return formatResource(ms.FreeResources.Disk, ms.TotalResources.Disk)
+		},
 	}
 )
 
@@ -60,7 +82,10 @@ For easily parsable output, you can remove the column headers:
 fleetctl list-machines --no-legend
 
 Output the list without truncation:
-fleetctl list-machines --full`,
+fleetctl list-machines --full
+
+Show the free and total resources of each machine:
+fleetctl list-machines --fields=machine,cores,memory,disk`,
 	Run: runWrapper(runListMachines),
 }
 
@@ -132,6 +157,12 @@ func formatMetadata(metadata map[string]string) string {
 	return strings.Join(pairs, ",")
 }
 
+// formatResource renders a single resource dimension of a machine as
+// "free/total".
+func formatResource(free, total int) string {
+	return fmt.Sprintf("%d/%d", free, total)
+}
+
 func machineToFieldKeys(m map[string]machineToField) (keys []string) {
 	for k, _ := range m {
 		keys = append(keys, k)
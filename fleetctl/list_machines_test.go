@@ -19,6 +19,7 @@ import (
 
 	"github.com/coreos/fleet/machine"
 	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/resource"
 )
 
 func newTestRegistryForListMachines() registry.Registry {
@@ -48,6 +49,17 @@ func TestListMachinesFieldsToStrings(t *testing.T) {
 		PublicIP: ip,
 		Metadata: metadata,
 		Version:  ver,
+		TotalResources: resource.ResourceTuple{
+			Cores:  400,
+			Memory: 4096,
+			Disk:   10240,
+		},
+		FreeResources: resource.ResourceTuple{
+			Cores:  300,
+			Memory: 3840,
+			Disk:   10240,
+		},
+		Cordoned: true,
 	}
 
 	val := listMachinesFields["machine"](ms, false)
@@ -61,6 +73,18 @@ func TestListMachinesFieldsToStrings(t *testing.T) {
 
 	val = listMachinesFields["metadata"](ms, false)
 	assertEqual(t, "metadata", "foo=bar,ping=pong", val)
+
+	val = listMachinesFields["cores"](ms, false)
+	assertEqual(t, "cores", "300/400", val)
+
+	val = listMachinesFields["memory"](ms, false)
+	assertEqual(t, "memory", "3840/4096", val)
+
+	val = listMachinesFields["disk"](ms, false)
+	assertEqual(t, "disk", "10240/10240", val)
+
+	val = listMachinesFields["cordoned"](ms, false)
+	assertEqual(t, "cordoned", "true", val)
 }
 
 func TestListMachinesFieldsEmpty(t *testing.T) {
@@ -76,7 +100,7 @@ func TestListMachinesFieldsEmpty(t *testing.T) {
 		Version:  ver,
 	}
 
-	for _, tt := range []string{"ip", "metadata"} {
+	for _, tt := range []string{"ip", "metadata", "cores", "memory", "disk"} {
 		f := listMachinesFields[tt](ms, false)
 		assertEqual(t, tt, "-", f)
 	}
@@ -17,6 +17,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -66,6 +67,42 @@ var (
 			}
 			return machineFullLegend(*ms, full)
 		},
+		"health": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.Health == "" {
+				return "-"
+			}
+			return us.Health
+		},
+		"pid": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.MainPID == 0 {
+				return "-"
+			}
+			return strconv.FormatInt(us.MainPID, 10)
+		},
+		"exit": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.Result == "" {
+				return "-"
+			}
+			return strconv.FormatInt(us.ExecMainStatus, 10)
+		},
+		"restarts": func(us *schema.UnitState, full bool) string {
+			if us == nil {
+				return "-"
+			}
+			return strconv.FormatInt(us.NRestarts, 10)
+		},
+		"since": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.ActiveEnterTimestamp == "" {
+				return "-"
+			}
+			return us.ActiveEnterTimestamp
+		},
+		"result": func(us *schema.UnitState, full bool) string {
+			if us == nil || us.Result == "" {
+				return "-"
+			}
+			return us.Result
+		},
 		"hash": func(us *schema.UnitState, full bool) string {
 			if us == nil || us.Hash == "" {
 				return "-"
@@ -43,7 +43,7 @@ func TestListUnitsFieldsToStrings(t *testing.T) {
 	cAPI = fakeAPI{}
 
 	// nil UnitState shouldn't happen, but just in case
-	for _, tt := range []string{"unit", "load", "active", "sub", "machine", "hash"} {
+	for _, tt := range []string{"unit", "load", "active", "sub", "machine", "health", "pid", "exit", "restarts", "since", "result", "hash"} {
 		f := listUnitsFields[tt](nil, false)
 		assertEqual(t, tt, "-", f)
 	}
@@ -57,11 +57,17 @@ func TestListUnitsFieldsToStrings(t *testing.T) {
 	}
 
 	for k, want := range map[string]string{
-		"load":    "foo",
-		"active":  "bar",
-		"sub":     "baz",
-		"machine": "-",
-		"unit":    "sleep",
+		"load":     "foo",
+		"active":   "bar",
+		"sub":      "baz",
+		"machine":  "-",
+		"unit":     "sleep",
+		"health":   "-",
+		"pid":      "-",
+		"exit":     "-",
+		"restarts": "0",
+		"since":    "-",
+		"result":   "-",
 	} {
 		got := listUnitsFields[k](us, false)
 		assertEqual(t, k, want, got)
@@ -81,6 +87,24 @@ func TestListUnitsFieldsToStrings(t *testing.T) {
 	ms = listUnitsFields["machine"](us, true)
 	assertEqual(t, "machine", "other-id/1.2.3.4", ms)
 
+	us.Health = "unhealthy"
+	assertEqual(t, "health", "unhealthy", listUnitsFields["health"](us, false))
+
+	us.MainPID = 1234
+	us.ExecMainStatus = 0
+	us.NRestarts = 3
+	us.ActiveEnterTimestamp = "2016-10-17T12:00:00Z"
+	us.Result = "success"
+	for k, want := range map[string]string{
+		"pid":      "1234",
+		"exit":     "0",
+		"restarts": "3",
+		"since":    "2016-10-17T12:00:00Z",
+		"result":   "success",
+	} {
+		assertEqual(t, k, want, listUnitsFields[k](us, false))
+	}
+
 	uh := "a0f275d46bc6ee0eca06be7c339913c07d99c0c7"
 	us.Hash = uh
 	fuh := listUnitsFields["hash"](us, true)
@@ -0,0 +1,201 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/schema"
+)
+
+var (
+	maintenanceFlags = struct {
+		Machine  string
+		Metadata string
+		Start    string
+		Duration time.Duration
+		Lead     time.Duration
+	}{}
+
+	cmdScheduleMaintenance = &cobra.Command{
+		Use:   "schedule-maintenance [--machine=MACHINE|--metadata=KEY=VALUE,...] [--start=TIME] [--duration=DURATION] [--lead=DURATION] NAME",
+		Short: "Schedule a maintenance window for one or more machines",
+		Long: `Schedules a maintenance window covering either a single machine or all machines
+matching a metadata selector. The engine stops scheduling new units to the
+affected machines when the lead time before the window begins, moves their
+units elsewhere once the window starts, and makes them eligible again when it
+ends. Moving units honors the MaxUnavailable budget of template units.
+
+The start time is given in RFC3339 format and defaults to now. Scheduling a
+window with the name of an existing window replaces it.
+
+Take down machine 2444264c for an hour starting at 2am UTC:
+	fleetctl schedule-maintenance --machine=2444264c --start=2016-05-10T02:00:00Z --duration=1h kernel-upgrade
+
+Take down all machines in rack 3, refusing new units half an hour before:
+	fleetctl schedule-maintenance --metadata=rack=3 --start=2016-05-10T02:00:00Z --lead=30m rack-3`,
+		Run: runWrapper(runScheduleMaintenance),
+	}
+
+	cmdListMaintenance = &cobra.Command{
+		Use:   "list-maintenance [--no-legend]",
+		Short: "Enumerate scheduled maintenance windows",
+		Long: `Lists all maintenance windows which have not ended yet, along with their current
+phase: pending, cordoned (no new units are scheduled) or active (units are
+being moved away).`,
+		Run: runWrapper(runListMaintenance),
+	}
+
+	cmdCancelMaintenance = &cobra.Command{
+		Use:   "cancel-maintenance NAME",
+		Short: "Cancel a maintenance window",
+		Long: `Removes a maintenance window, making its machines eligible for new units again.
+Units moved off the machines are not moved back.`,
+		Run: runWrapper(runCancelMaintenance),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdScheduleMaintenance)
+	cmdFleet.AddCommand(cmdListMaintenance)
+	cmdFleet.AddCommand(cmdCancelMaintenance)
+
+	cmdScheduleMaintenance.Flags().StringVar(&maintenanceFlags.Machine, "machine", "", "ID or unique ID prefix of the machine to take down.")
+	cmdScheduleMaintenance.Flags().StringVar(&maintenanceFlags.Metadata, "metadata", "", "Take down all machines having each of these comma-separated KEY=VALUE metadata pairs.")
+	cmdScheduleMaintenance.Flags().StringVar(&maintenanceFlags.Start, "start", "", "Time at which the window starts, in RFC3339 format. Defaults to now.")
+	cmdScheduleMaintenance.Flags().DurationVar(&maintenanceFlags.Duration, "duration", time.Hour, "Length of the window.")
+	cmdScheduleMaintenance.Flags().DurationVar(&maintenanceFlags.Lead, "lead", 0, "How long before the window starts to stop scheduling new units to its machines.")
+
+	cmdListMaintenance.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+}
+
+func runScheduleMaintenance(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One maintenance window name must be provided")
+		return 1
+	}
+
+	mw := schema.MaintenanceWindow{Name: args[0]}
+
+	switch {
+	case maintenanceFlags.Machine != "" && maintenanceFlags.Metadata != "":
+		stderr("Only one of --machine and --metadata may be provided")
+		return 1
+	case maintenanceFlags.Machine != "":
+		ms, err := findMachine(maintenanceFlags.Machine)
+		if err != nil {
+			stderr("Error finding machine %s: %v", maintenanceFlags.Machine, err)
+			return 1
+		}
+		mw.MachineID = ms.ID
+	case maintenanceFlags.Metadata != "":
+		md, err := parseMetadataSelector(maintenanceFlags.Metadata)
+		if err != nil {
+			stderr("Invalid metadata selector: %v", err)
+			return 1
+		}
+		mw.Metadata = md
+	default:
+		stderr("One of --machine and --metadata must be provided")
+		return 1
+	}
+
+	start := time.Now()
+	if maintenanceFlags.Start != "" {
+		var err error
+		start, err = time.Parse(time.RFC3339, maintenanceFlags.Start)
+		if err != nil {
+			stderr("Invalid start time %q: %v", maintenanceFlags.Start, err)
+			return 1
+		}
+	}
+	if maintenanceFlags.Duration <= 0 {
+		stderr("Duration must be positive")
+		return 1
+	}
+
+	mw.Start = start.UTC().Format(time.RFC3339)
+	mw.End = start.Add(maintenanceFlags.Duration).UTC().Format(time.RFC3339)
+	if maintenanceFlags.Lead > 0 {
+		mw.Lead = maintenanceFlags.Lead.String()
+	}
+
+	if err := cAPI.SetMaintenanceWindow(&mw); err != nil {
+		stderr("Error scheduling maintenance window %s: %v", mw.Name, err)
+		return 1
+	}
+
+	stdout("Scheduled maintenance window %s from %s to %s", mw.Name, mw.Start, mw.End)
+	return 0
+}
+
+func runListMaintenance(cCmd *cobra.Command, args []string) (exit int) {
+	windows, err := cAPI.MaintenanceWindows()
+	if err != nil {
+		stderr("Error retrieving list of maintenance windows from fleet API: %v", err)
+		return 1
+	}
+
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "NAME\tMACHINES\tSTART\tEND\tLEAD\tPHASE")
+	}
+
+	for _, mw := range windows {
+		machines := mw.MachineID
+		if machines == "" {
+			machines = formatMetadata(mw.Metadata)
+		}
+		lead := mw.Lead
+		if lead == "" {
+			lead = "-"
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n", mw.Name, machines, mw.Start, mw.End, lead, mw.Phase)
+	}
+
+	out.Flush()
+	return 0
+}
+
+func runCancelMaintenance(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One maintenance window name must be provided")
+		return 1
+	}
+
+	if err := cAPI.DestroyMaintenanceWindow(args[0]); err != nil {
+		stderr("Error cancelling maintenance window %s: %v", args[0], err)
+		return 1
+	}
+
+	stdout("Cancelled maintenance window %s", args[0])
+	return 0
+}
+
+// parseMetadataSelector parses a comma-separated list of KEY=VALUE pairs.
+func parseMetadataSelector(s string) (map[string]string, error) {
+	md := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("expected KEY=VALUE, got %q", pair)
+		}
+		md[parts[0]] = parts[1]
+	}
+	return md, nil
+}
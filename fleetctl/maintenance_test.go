@@ -0,0 +1,102 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/client"
+)
+
+func TestRunScheduleMaintenance(t *testing.T) {
+	reg := newFakeRegistryForCordon()
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	maintenanceFlags.Machine = "5959"
+	maintenanceFlags.Start = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	maintenanceFlags.Duration = time.Hour
+	maintenanceFlags.Lead = 10 * time.Minute
+	defer func() {
+		maintenanceFlags.Machine = ""
+		maintenanceFlags.Start = ""
+		maintenanceFlags.Lead = 0
+	}()
+
+	if exit := runScheduleMaintenance(cmdScheduleMaintenance, []string{"kernel"}); exit != 0 {
+		t.Fatalf("schedule-maintenance: expected exit code 0 but received %d", exit)
+	}
+
+	windows, err := reg.MaintenanceWindows()
+	if err != nil {
+		t.Fatalf("Failed fetching maintenance windows: %v", err)
+	}
+	if len(windows) != 1 {
+		t.Fatalf("Expected 1 maintenance window, got %d", len(windows))
+	}
+	mw := windows[0]
+	if mw.Name != "kernel" || mw.MachineID != "595989bb-cbb7-49ce-8726-722d6e157b4e" || mw.Lead != 10*time.Minute || mw.End.Sub(mw.Start) != time.Hour {
+		t.Errorf("Unexpected maintenance window: %#v", mw)
+	}
+
+	if exit := runListMaintenance(cmdListMaintenance, nil); exit != 0 {
+		t.Errorf("list-maintenance: expected exit code 0 but received %d", exit)
+	}
+
+	if exit := runCancelMaintenance(cmdCancelMaintenance, []string{"kernel"}); exit != 0 {
+		t.Errorf("cancel-maintenance: expected exit code 0 but received %d", exit)
+	}
+	if exit := runCancelMaintenance(cmdCancelMaintenance, []string{"kernel"}); exit != 1 {
+		t.Errorf("cancel-maintenance twice: expected exit code 1 but received %d", exit)
+	}
+
+	// ambiguous machine
+	maintenanceFlags.Machine = "c3"
+	if exit := runScheduleMaintenance(cmdScheduleMaintenance, []string{"other"}); exit != 1 {
+		t.Errorf("schedule-maintenance ambiguous machine: expected exit code 1 but received %d", exit)
+	}
+
+	// both selectors
+	maintenanceFlags.Machine = "5959"
+	maintenanceFlags.Metadata = "rack=3"
+	if exit := runScheduleMaintenance(cmdScheduleMaintenance, []string{"other"}); exit != 1 {
+		t.Errorf("schedule-maintenance with both selectors: expected exit code 1 but received %d", exit)
+	}
+	maintenanceFlags.Metadata = ""
+}
+
+func TestParseMetadataSelector(t *testing.T) {
+	for i, tt := range []struct {
+		in   string
+		want map[string]string
+		err  bool
+	}{
+		{"rack=3", map[string]string{"rack": "3"}, false},
+		{"rack=3,region=us-east", map[string]string{"rack": "3", "region": "us-east"}, false},
+		{"rack", nil, true},
+		{"rack=", nil, true},
+		{"rack=3,", nil, true},
+	} {
+		got, err := parseMetadataSelector(tt.in)
+		if tt.err != (err != nil) {
+			t.Errorf("case %d: expected error=%t, got %v", i, tt.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: expected %v, got %v", i, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdPauseGlobal = &cobra.Command{
+		Use:   "pause-global UNIT MACHINE...",
+		Short: "Stop running a global unit on specific machines",
+		Long: `Stops a global unit on the given machines without destroying it. The unit keeps
+running on all other machines, and machines joining the cluster later still run
+it unless it is paused on them too.
+
+Each machine may be given as a full machine ID or any unique prefix of it.
+A global unit stays paused on a machine across the machine leaving and
+rejoining the cluster until it is resumed.`,
+		Run: runWrapper(runPauseGlobal),
+	}
+
+	cmdResumeGlobal = &cobra.Command{
+		Use:   "resume-global UNIT MACHINE...",
+		Short: "Run a paused global unit on specific machines again",
+		Long:  `Resumes a global unit previously paused on the given machines.`,
+		Run:   runWrapper(runResumeGlobal),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdPauseGlobal)
+	cmdFleet.AddCommand(cmdResumeGlobal)
+}
+
+func runPauseGlobal(cCmd *cobra.Command, args []string) (exit int) {
+	return setGlobalUnitPaused(args, true)
+}
+
+func runResumeGlobal(cCmd *cobra.Command, args []string) (exit int) {
+	return setGlobalUnitPaused(args, false)
+}
+
+func setGlobalUnitPaused(args []string, paused bool) (exit int) {
+	if len(args) < 2 {
+		stderr("One unit and at least one machine must be provided")
+		return 1
+	}
+
+	name := unitNameMangle(args[0])
+	u, err := cAPI.Unit(name)
+	if err != nil {
+		stderr("Error retrieving unit %s: %v", name, err)
+		return 1
+	}
+	if u == nil {
+		stderr("Unit %s does not exist", name)
+		return 1
+	}
+	if !suToGlobal(*u) {
+		stderr("Unit %s is not a global unit", name)
+		return 1
+	}
+
+	for _, lookup := range args[1:] {
+		ms, err := findMachine(lookup)
+		if err != nil {
+			stderr("Error finding machine %s: %v", lookup, err)
+			return 1
+		}
+
+		if err := cAPI.SetGlobalUnitPaused(ms.ID, name, paused); err != nil {
+			stderr("Error updating unit %s on machine %s: %v", name, ms.ID, err)
+			return 1
+		}
+
+		if paused {
+			stdout("Unit %s paused on machine %s", name, ms.ID)
+		} else {
+			stdout("Unit %s resumed on machine %s", name, ms.ID)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestRunPauseGlobal(t *testing.T) {
+	reg := newFakeRegistryForCordon()
+	uf, err := unit.NewUnitFile("[X-Fleet]\nGlobal=true")
+	if err != nil {
+		t.Fatalf("Failed creating unit file: %v", err)
+	}
+	reg.CreateUnit(&job.Unit{Name: "global.service", Unit: *uf, TargetState: job.JobStateLaunched})
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	if exit := runPauseGlobal(cmdPauseGlobal, []string{"global", "5959", "c31e"}); exit != 0 {
+		t.Fatalf("pause-global: expected exit code 0 but received %d", exit)
+	}
+	for _, lookup := range []string{"5959", "c31e"} {
+		if ms, _ := findMachine(lookup); !reflect.DeepEqual(ms.PausedUnits, []string{"global.service"}) {
+			t.Errorf("pause-global: expected unit paused on machine %s, got %v", lookup, ms.PausedUnits)
+		}
+	}
+
+	if exit := runResumeGlobal(cmdResumeGlobal, []string{"global.service", "5959"}); exit != 0 {
+		t.Fatalf("resume-global: expected exit code 0 but received %d", exit)
+	}
+	if ms, _ := findMachine("5959"); len(ms.PausedUnits) != 0 {
+		t.Errorf("resume-global: expected no paused units, got %v", ms.PausedUnits)
+	}
+
+	results := []commandTestResults{
+		{
+			"pause without machines",
+			[]string{"global.service"},
+			1,
+		},
+		{
+			"pause non-global unit",
+			[]string{"hello.service", "5959"},
+			1,
+		},
+		{
+			"pause non-existent unit",
+			[]string{"missing.service", "5959"},
+			1,
+		},
+		{
+			"pause on ambiguous machine",
+			[]string{"global.service", "c3"},
+			1,
+		},
+	}
+
+	for _, r := range results {
+		exit := runPauseGlobal(cmdPauseGlobal, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/api"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/schema"
+	"github.com/coreos/fleet/unit"
+)
+
+const (
+	onFailurePause    = "pause"
+	onFailureRollback = "rollback"
+
+	rollingUpdatePollInterval = 250 * time.Millisecond
+)
+
+var (
+	rollingUpdateFlags = struct {
+		File      string
+		Rollback  bool
+		Batch     int
+		Timeout   time.Duration
+		Window    time.Duration
+		OnFailure string
+	}{}
+
+	cmdRollingUpdate = &cobra.Command{
+		Use:   "rolling-update [--file=FILE|--rollback] [--batch=N] [--timeout=DURATION] [--window=DURATION] [--on-failure=pause|rollback] TEMPLATE",
+		Short: "Replace the unit file of all instances of a template unit in batches",
+		Long: `Rolling-update replaces the unit file of the instances of a template unit a
+batch at a time. After each batch, it waits for the launched instances of the
+batch to run the new unit file and to report an active systemd state, then
+keeps watching them for the given window. The next batch is only started if
+all of them stayed active.
+
+If an instance fails, or does not become active within the timeout, the
+update is paused: the remaining instances keep their unit file and the
+command exits with an error. With --on-failure=rollback, the instances
+updated so far are reverted to their previous unit file instead.
+
+The template unit keeps its previous unit file until all instances have been
+updated. Running the same command again resumes a paused update, skipping
+instances which already run the new unit file, while --rollback reverts all
+instances to the unit file of the template.
+
+Update the instances of web@.service two at a time:
+	fleetctl rolling-update --file=web@.service --batch=2 web@.service
+
+Revert the instances of a paused update:
+	fleetctl rolling-update --rollback web@.service`,
+		Run: runWrapper(runRollingUpdate),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdRollingUpdate)
+
+	cmdRollingUpdate.Flags().StringVar(&rollingUpdateFlags.File, "file", "", "Path to the new unit file of the template.")
+	cmdRollingUpdate.Flags().BoolVar(&rollingUpdateFlags.Rollback, "rollback", false, "Revert all instances to the unit file of the template.")
+	cmdRollingUpdate.Flags().IntVar(&rollingUpdateFlags.Batch, "batch", 1, "Number of instances to replace at a time.")
+	cmdRollingUpdate.Flags().DurationVar(&rollingUpdateFlags.Timeout, "timeout", 5*time.Minute, "How long to wait for the instances of a batch to become active.")
+	cmdRollingUpdate.Flags().DurationVar(&rollingUpdateFlags.Window, "window", 30*time.Second, "How long the instances of a batch must stay active before the next batch is started.")
+	cmdRollingUpdate.Flags().StringVar(&rollingUpdateFlags.OnFailure, "on-failure", onFailurePause, "What to do when an instance fails: pause or rollback.")
+}
+
+func runRollingUpdate(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One template unit must be provided")
+		return 1
+	}
+
+	switch {
+	case rollingUpdateFlags.File == "" && !rollingUpdateFlags.Rollback:
+		stderr("One of --file and --rollback must be provided")
+		return 1
+	case rollingUpdateFlags.File != "" && rollingUpdateFlags.Rollback:
+		stderr("Only one of --file and --rollback may be provided")
+		return 1
+	case rollingUpdateFlags.Batch < 1:
+		stderr("Batch size must be at least 1")
+		return 1
+	case rollingUpdateFlags.OnFailure != onFailurePause && rollingUpdateFlags.OnFailure != onFailureRollback:
+		stderr("Invalid --on-failure value %q: must be %s or %s", rollingUpdateFlags.OnFailure, onFailurePause, onFailureRollback)
+		return 1
+	}
+
+	name := unitNameMangle(args[0])
+	if uni := unit.NewUnitNameInfo(name); uni == nil || !uni.IsTemplate() {
+		stderr("Unit %s is not a template unit", name)
+		return 1
+	}
+
+	tmpl, err := cAPI.Unit(name)
+	if err != nil {
+		stderr("Error retrieving template unit %s: %v", name, err)
+		return 1
+	}
+	if tmpl == nil {
+		stderr("Template unit %s does not exist", name)
+		return 1
+	}
+	if suToGlobal(*tmpl) {
+		stderr("Rolling updates of global units are not supported")
+		return 1
+	}
+
+	uf := schema.MapSchemaUnitOptionsToUnitFile(tmpl.Options)
+	if !rollingUpdateFlags.Rollback {
+		if uf, err = getUnitFromFile(rollingUpdateFlags.File); err != nil {
+			stderr("Error reading unit file %s: %v", rollingUpdateFlags.File, err)
+			return 1
+		}
+		if err := api.ValidateOptions(schema.MapUnitFileToSchemaUnitOptions(uf)); err != nil {
+			stderr("Invalid unit file %s: %v", rollingUpdateFlags.File, err)
+			return 1
+		}
+	}
+
+	instances, err := outdatedInstances(name, uf)
+	if err != nil {
+		stderr("Error retrieving instances of %s: %v", name, err)
+		return 1
+	}
+
+	var updated []*schema.Unit
+	for len(instances) > 0 {
+		n := rollingUpdateFlags.Batch
+		if n > len(instances) {
+			n = len(instances)
+		}
+		batch := instances[:n]
+		instances = instances[n:]
+
+		var names, launched []string
+		for _, u := range batch {
+			if err := replaceUnitFile(u, schema.MapUnitFileToSchemaUnitOptions(uf)); err != nil {
+				stderr("Error updating %s: %v", u.Name, err)
+				return rollingUpdateFailed(append(updated, u))
+			}
+			updated = append(updated, u)
+			names = append(names, u.Name)
+			if u.DesiredState == string(job.JobStateLaunched) {
+				launched = append(launched, u.Name)
+			}
+		}
+
+		hash := uf.Hash().String()
+		err := waitForInstances(launched, hash, rollingUpdateFlags.Timeout)
+		if err == nil {
+			err = watchInstances(launched, hash, rollingUpdateFlags.Window)
+		}
+		if err != nil {
+			stderr("Error updating %s: %v", strings.Join(names, ", "), err)
+			return rollingUpdateFailed(updated)
+		}
+		stdout("Updated %s", strings.Join(names, ", "))
+	}
+
+	if !rollingUpdateFlags.Rollback && !unit.MatchUnitFiles(schema.MapSchemaUnitOptionsToUnitFile(tmpl.Options), uf) {
+		if err := replaceUnitFile(tmpl, schema.MapUnitFileToSchemaUnitOptions(uf)); err != nil {
+			stderr("Error updating template unit %s: %v", name, err)
+			return 1
+		}
+	}
+
+	stdout("Rolling update of %s complete", name)
+	return 0
+}
+
+// rollingUpdateFailed stops a rolling update after a failure, reverting the
+// given instances to their previous unit file if requested. It returns the
+// exit code of the command.
+func rollingUpdateFailed(updated []*schema.Unit) (exit int) {
+	if rollingUpdateFlags.OnFailure != onFailureRollback {
+		stderr("Rolling update paused, run the same command to resume it or use --rollback to revert it")
+		return 1
+	}
+
+	for i := len(updated) - 1; i >= 0; i-- {
+		u := updated[i]
+		if err := replaceUnitFile(u, u.Options); err != nil {
+			stderr("Error rolling back %s: %v", u.Name, err)
+			continue
+		}
+		stdout("Rolled back %s", u.Name)
+	}
+	return 1
+}
+
+// outdatedInstances returns the instances of the given template unit whose
+// unit file differs from the given one, ordered by instance number.
+func outdatedInstances(tmpl string, uf *unit.UnitFile) ([]*schema.Unit, error) {
+	units, err := cAPI.Units()
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []*schema.Unit
+	for _, u := range units {
+		uni := unit.NewUnitNameInfo(u.Name)
+		if uni == nil || !uni.IsInstance() || uni.Template != tmpl {
+			continue
+		}
+		if unit.MatchUnitFiles(schema.MapSchemaUnitOptionsToUnitFile(u.Options), uf) {
+			continue
+		}
+		instances = append(instances, u)
+	}
+
+	sort.Sort(instancesByNumber(instances))
+	return instances, nil
+}
+
+// replaceUnitFile replaces the unit file of the given unit, keeping its
+// desired state and the machine it is scheduled to.
+func replaceUnitFile(u *schema.Unit, options []*schema.UnitOption) error {
+	return cAPI.CreateUnit(&schema.Unit{
+		Name:         u.Name,
+		Options:      options,
+		DesiredState: u.DesiredState,
+		Client:       clientName(),
+	})
+}
+
+// waitForInstances waits until all the named units run the unit file with
+// the given hash and are active. It gives up early if any of them fails.
+func waitForInstances(names []string, hash string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := ""
+		for _, name := range names {
+			active, err := instanceActive(name, hash)
+			if err != nil {
+				return err
+			}
+			if !active {
+				pending = name
+				break
+			}
+		}
+
+		if pending == "" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("unit %s did not become active within %v", pending, timeout)
+		}
+		time.Sleep(rollingUpdatePollInterval)
+	}
+}
+
+// watchInstances checks that all the named units stay active for the given
+// window.
+func watchInstances(names []string, hash string, window time.Duration) error {
+	deadline := time.Now().Add(window)
+	for {
+		for _, name := range names {
+			active, err := instanceActive(name, hash)
+			if err != nil {
+				return err
+			}
+			if !active {
+				return fmt.Errorf("unit %s did not stay active for %v", name, window)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(rollingUpdatePollInterval)
+	}
+}
+
+// instanceActive determines whether the named unit runs the unit file with
+// the given hash and is active. An error is returned if the unit failed.
+func instanceActive(name, hash string) (bool, error) {
+	us, err := cAPI.UnitState(name)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving state of %s: %v", name, err)
+	}
+	if us == nil || us.Hash != hash {
+		return false, nil
+	}
+	if us.SystemdActiveState == "failed" {
+		return false, fmt.Errorf("unit %s failed", name)
+	}
+	return us.SystemdActiveState == "active", nil
+}
+
+// instancesByNumber orders instances named after a number numerically,
+// followed by all other instances in lexical order.
+type instancesByNumber []*schema.Unit
+
+func (s instancesByNumber) Len() int      { return len(s) }
+func (s instancesByNumber) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s instancesByNumber) Less(i, j int) bool {
+	ni, erri := strconv.Atoi(unit.NewUnitNameInfo(s[i].Name).Instance)
+	nj, errj := strconv.Atoi(unit.NewUnitNameInfo(s[j].Name).Instance)
+	switch {
+	case erri == nil && errj == nil:
+		return ni < nj
+	case erri == nil || errj == nil:
+		return erri == nil
+	}
+	return s[i].Name < s[j].Name
+}
@@ -0,0 +1,163 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/unit"
+)
+
+func newFakeRegistryForRollingUpdate(t *testing.T, newUnit *unit.UnitFile, failed string) *registry.FakeRegistry {
+	oldUnit, err := unit.NewUnitFile("[Service]\nExecStart=/usr/bin/sleep 1000")
+	if err != nil {
+		t.Fatalf("Unexpected error creating unit file: %v", err)
+	}
+
+	jobs := []job.Job{{Name: "web@.service", Unit: *oldUnit, TargetState: job.JobStateInactive}}
+	var states []unit.UnitState
+	for _, name := range []string{"web@10.service", "web@2.service", "web@1.service"} {
+		jobs = append(jobs, job.Job{Name: name, Unit: *oldUnit, TargetState: job.JobStateLaunched})
+
+		us := unit.UnitState{
+			LoadState:   "loaded",
+			ActiveState: "active",
+			MachineID:   "XXX",
+			UnitHash:    newUnit.Hash().String(),
+			UnitName:    name,
+		}
+		if name == failed {
+			us.ActiveState = "failed"
+		}
+		states = append(states, us)
+	}
+
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs(jobs)
+	reg.SetUnitStates(states)
+	return reg
+}
+
+func TestRunRollingUpdate(t *testing.T) {
+	contents := "[Service]\nExecStart=/usr/bin/sleep 2000"
+	newUnit, err := unit.NewUnitFile(contents)
+	if err != nil {
+		t.Fatalf("Unexpected error creating unit file: %v", err)
+	}
+
+	f, err := ioutil.TempFile("", "fleetctl-rolling-update")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatalf("Unexpected error writing temporary file: %v", err)
+	}
+	f.Close()
+
+	tests := []struct {
+		description string
+		failed      string
+		onFailure   string
+		rollback    bool
+		// expected is the set of units expected to run the new unit file
+		expected     map[string]bool
+		expectedExit int
+	}{
+		{
+			description:  "all instances are updated before the template",
+			onFailure:    onFailurePause,
+			expected:     map[string]bool{"web@.service": true, "web@1.service": true, "web@2.service": true, "web@10.service": true},
+			expectedExit: 0,
+		},
+		{
+			description:  "a failing instance pauses the update",
+			failed:       "web@2.service",
+			onFailure:    onFailurePause,
+			expected:     map[string]bool{"web@1.service": true, "web@2.service": true},
+			expectedExit: 1,
+		},
+		{
+			description:  "a failing instance rolls back the update",
+			failed:       "web@2.service",
+			onFailure:    onFailureRollback,
+			expected:     map[string]bool{},
+			expectedExit: 1,
+		},
+		{
+			description:  "an invalid --on-failure value is rejected",
+			onFailure:    "retry",
+			expected:     map[string]bool{},
+			expectedExit: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		reg := newFakeRegistryForRollingUpdate(t, newUnit, tt.failed)
+		cAPI = &client.RegistryClient{Registry: reg}
+
+		rollingUpdateFlags.File = f.Name()
+		rollingUpdateFlags.Batch = 1
+		rollingUpdateFlags.Timeout = 0
+		rollingUpdateFlags.Window = 0
+		rollingUpdateFlags.OnFailure = tt.onFailure
+
+		if exit := runRollingUpdate(cmdRollingUpdate, []string{"web@.service"}); exit != tt.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", tt.description, tt.expectedExit, exit)
+		}
+
+		for _, name := range []string{"web@.service", "web@1.service", "web@2.service", "web@10.service"} {
+			u, err := reg.Unit(name)
+			if err != nil || u == nil {
+				t.Fatalf("%s: failed fetching unit %s: %v", tt.description, name, err)
+			}
+			if updated := unit.MatchUnitFiles(&u.Unit, newUnit); updated != tt.expected[name] {
+				t.Errorf("%s: expected unit %s to be updated %t, got %t", tt.description, name, tt.expected[name], updated)
+			}
+		}
+	}
+
+	rollingUpdateFlags.File = ""
+	rollingUpdateFlags.OnFailure = onFailurePause
+	results := []commandTestResults{
+		{
+			"rolling-update without a template",
+			[]string{},
+			1,
+		},
+		{
+			"rolling-update of a non-template unit",
+			[]string{"web.service"},
+			1,
+		},
+		{
+			"rolling-update without --file or --rollback",
+			[]string{"web@.service"},
+			1,
+		},
+	}
+
+	for _, r := range results {
+		exit := runRollingUpdate(cmdRollingUpdate, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+	}
+}
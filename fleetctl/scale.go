@@ -0,0 +1,145 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/schema"
+)
+
+var (
+	cmdScale = &cobra.Command{
+		Use:   "scale TEMPLATE=REPLICAS...",
+		Short: "Set the number of instances of template units kept in the cluster",
+		Long: `Scale creates or updates a deployment of a template unit. The engine then
+creates the instances numbered 1 up to the given number of replicas, e.g.
+foo@1.service to foo@3.service for three replicas of foo@.service, and
+launches them. Instances numbered beyond the number of replicas are
+destroyed. Instances not named after a number are left untouched.
+
+If the template unit does not exist in the cluster yet, it is submitted from
+the local file of the same name.
+
+Run three instances of a web server:
+	fleetctl scale web@.service=3
+
+Destroy all numbered instances of the web server:
+	fleetctl scale web@.service=0`,
+		Run: runWrapper(runScale),
+	}
+
+	cmdListDeployments = &cobra.Command{
+		Use:   "list-deployments [--no-legend]",
+		Short: "Enumerate deployments of template units",
+		Long: `Lists all deployments, along with their desired number of replicas and the
+number of numbered instances currently existing in the cluster.`,
+		Run: runWrapper(runListDeployments),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdScale)
+	cmdFleet.AddCommand(cmdListDeployments)
+
+	cmdScale.Flags().IntVar(&sharedFlags.BlockAttempts, "block-attempts", 0, "Wait until template units are submitted, performing up to N attempts before giving up. A value of 0 indicates no limit.")
+	cmdListDeployments.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+}
+
+func runScale(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) == 0 {
+		stderr("At least one TEMPLATE=REPLICAS pair must be provided")
+		return 1
+	}
+
+	var deployments []job.Deployment
+	var files []string
+	for _, arg := range args {
+		i := strings.LastIndex(arg, "=")
+		if i == -1 {
+			stderr("Invalid argument %q: expected TEMPLATE=REPLICAS", arg)
+			return 1
+		}
+
+		replicas, err := strconv.Atoi(arg[i+1:])
+		if err != nil {
+			stderr("Invalid number of replicas in %q: %v", arg, err)
+			return 1
+		}
+
+		d := job.Deployment{
+			Template: unitNameMangle(arg[:i]),
+			Replicas: replicas,
+		}
+		if err := d.Validate(); err != nil {
+			stderr("Invalid argument %q: %v", arg, err)
+			return 1
+		}
+
+		deployments = append(deployments, d)
+		files = append(files, arg[:i])
+	}
+
+	if err := lazyCreateUnits(cCmd, files); err != nil {
+		stderr("Error creating template units: %v", err)
+		return 1
+	}
+
+	for _, d := range deployments {
+		if err := cAPI.SetDeployment(schema.MapDeploymentToSchema(&d)); err != nil {
+			stderr("Error scaling %s: %v", d.Template, err)
+			return 1
+		}
+		stdout("Scaled %s to %d replicas", d.Template, d.Replicas)
+	}
+	return 0
+}
+
+func runListDeployments(cCmd *cobra.Command, args []string) (exit int) {
+	deployments, err := cAPI.Deployments()
+	if err != nil {
+		stderr("Error retrieving list of deployments from fleet API: %v", err)
+		return 1
+	}
+
+	units, err := cAPI.Units()
+	if err != nil {
+		stderr("Error retrieving list of units from fleet API: %v", err)
+		return 1
+	}
+
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "TEMPLATE\tREPLICAS\tINSTANCES")
+	}
+
+	for _, sd := range deployments {
+		d := schema.MapSchemaToDeployment(sd)
+		instances := 0
+		for _, u := range units {
+			if _, ok := d.InstanceNumber(u.Name); ok {
+				instances++
+			}
+		}
+		fmt.Fprintf(out, "%s\t%d\t%d\n", d.Template, d.Replicas, instances)
+	}
+
+	out.Flush()
+	return 0
+}
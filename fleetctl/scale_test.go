@@ -0,0 +1,93 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/registry"
+)
+
+func TestRunScale(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs([]job.Job{
+		{Name: "web@.service", TargetState: job.JobStateInactive},
+		{Name: "web@1.service", TargetState: job.JobStateLaunched},
+	})
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	if exit := runScale(cmdScale, []string{"web@.service=3"}); exit != 0 {
+		t.Fatalf("scale: expected exit code 0 but received %d", exit)
+	}
+	if exit := runScale(cmdScale, []string{"web@=2"}); exit != 0 {
+		t.Fatalf("scale: expected exit code 0 but received %d", exit)
+	}
+
+	deployments, err := reg.Deployments()
+	if err != nil {
+		t.Fatalf("Failed fetching Deployments: %v", err)
+	}
+	expected := []job.Deployment{{Template: "web@.service", Replicas: 2}}
+	if !reflect.DeepEqual(expected, deployments) {
+		t.Errorf("Expected Deployments %v, got %v", expected, deployments)
+	}
+
+	if exit := runListDeployments(cmdListDeployments, nil); exit != 0 {
+		t.Errorf("list-deployments: expected exit code 0 but received %d", exit)
+	}
+
+	results := []commandTestResults{
+		{
+			"scale without arguments",
+			[]string{},
+			1,
+		},
+		{
+			"scale without replicas",
+			[]string{"web@.service"},
+			1,
+		},
+		{
+			"scale to a non-number",
+			[]string{"web@.service=many"},
+			1,
+		},
+		{
+			"scale to a negative number",
+			[]string{"web@.service=-1"},
+			1,
+		},
+		{
+			"scale a non-template unit",
+			[]string{"web.service=3"},
+			1,
+		},
+		{
+			"scale a missing template",
+			[]string{"missing@.service=3"},
+			1,
+		},
+	}
+
+	for _, r := range results {
+		exit := runScale(cmdScale, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+	}
+}
@@ -173,30 +173,12 @@ func globalMachineLookup(args []string) (string, error) {
 }
 
 func findAddressInMachineList(lookup string) (string, bool, error) {
-	states, err := cAPI.Machines()
+	ms, err := findMachine(lookup)
 	if err != nil {
 		return "", false, err
 	}
 
-	var match *machine.MachineState
-	for i := range states {
-		machState := states[i]
-		if !strings.HasPrefix(machState.ID, lookup) {
-			continue
-		}
-
-		if match != nil {
-			return "", false, fmt.Errorf("found more than one machine")
-		}
-
-		match = &machState
-	}
-
-	if match == nil {
-		return "", false, fmt.Errorf("machine does not exist")
-	}
-
-	return match.PublicIP, true, nil
+	return ms.PublicIP, true, nil
 }
 
 func findAddressInRunningUnits(name string) (string, bool, error) {
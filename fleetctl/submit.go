@@ -15,7 +15,13 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/api"
+	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/schema"
 )
 
 var cmdSubmit = &cobra.Command{
@@ -30,7 +36,10 @@ Submit a single unit:
 fleetctl submit foo.service
 
 Submit a directory of units with glob matching:
-fleetctl submit myservice/*`,
+fleetctl submit myservice/*
+
+Show where a unit would be scheduled once started, without submitting it:
+fleetctl submit --dry-run foo.service`,
 	Run: runWrapper(runSubmitUnit),
 }
 
@@ -39,6 +48,10 @@ func init() {
 
 	cmdSubmit.Flags().BoolVar(&sharedFlags.Sign, "sign", false, "DEPRECATED - this option cannot be used")
 	cmdSubmit.Flags().BoolVar(&sharedFlags.Replace, "replace", false, "Replace the old submitted units in the cluster with new versions.")
+	cmdSubmit.Flags().Bool("dry-run", false, "Show the scheduling tasks starting the units would cause, without submitting them.")
+	cmdSubmit.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdSubmit.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdSubmit.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
 }
 
 func runSubmitUnit(cCmd *cobra.Command, args []string) (exit int) {
@@ -47,9 +60,66 @@ func runSubmitUnit(cCmd *cobra.Command, args []string) (exit int) {
 		return 0
 	}
 
+	if dryRun, _ := cCmd.Flags().GetBool("dry-run"); dryRun {
+		return simulateUnits(cCmd, args)
+	}
+
 	if err := lazyCreateUnits(cCmd, args); err != nil {
 		stderr("Error creating units: %v", err)
 		return 1
 	}
 	return 0
 }
+
+// simulateUnits asks the cluster which scheduling tasks starting each of the
+// given units would cause, and prints them without submitting the units.
+func simulateUnits(cCmd *cobra.Command, args []string) (exit int) {
+	noLegend, _ := cCmd.Flags().GetBool("no-legend")
+	full, _ := cCmd.Flags().GetBool("full")
+
+	for _, arg := range args {
+		arg = maybeAppendDefaultUnitType(arg)
+		name := unitNameMangle(arg)
+
+		uf, err := getUnitFile(cCmd, arg)
+		if err != nil {
+			stderr("Error simulating unit %s: %v", name, err)
+			return 1
+		}
+
+		u := schema.Unit{
+			Name:    name,
+			Options: schema.MapUnitFileToSchemaUnitOptions(uf),
+		}
+		if err := api.ValidateName(name); err != nil {
+			stderr("Error simulating unit %s: %v", name, err)
+			return 1
+		}
+		if err := api.ValidateOptions(u.Options); err != nil {
+			stderr("Error simulating unit %s: %v", name, err)
+			return 1
+		}
+
+		sim, err := cAPI.SimulateUnit(&u)
+		if err != nil {
+			stderr("Error simulating unit %s: %v", name, err)
+			return 1
+		}
+
+		if len(sim.Tasks) == 0 {
+			stdout("Starting unit %s would not cause any scheduling tasks", name)
+			continue
+		}
+
+		if !noLegend {
+			fmt.Fprintln(out, "TASK\tUNIT\tMACHINE\tREASON")
+		}
+		for _, t := range sim.Tasks {
+			ms := machine.MachineState{ID: t.MachineID}
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", t.Type, t.UnitName, machineIDLegend(ms, full), t.Reason)
+		}
+		out.Flush()
+	}
+
+	return 0
+}
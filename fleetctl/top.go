@@ -0,0 +1,152 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/schema"
+)
+
+var (
+	topFlags = struct {
+		Sort  string
+		Limit int
+	}{}
+
+	// topSortKeys maps the values of the --sort flag to the usage they
+	// sort units by
+	topSortKeys = map[string]func(*schema.UnitUsage) uint64{
+		"memory": func(u *schema.UnitUsage) uint64 { return u.MemoryCurrent },
+		"peak":   func(u *schema.UnitUsage) uint64 { return u.MemoryPeak },
+		"cpu":    func(u *schema.UnitUsage) uint64 { return u.CpuUsageNSec },
+		"tasks":  func(u *schema.UnitUsage) uint64 { return u.Tasks },
+	}
+
+	cmdTop = &cobra.Command{
+		Use:   "top [--no-legend] [-l|--full] [--sort=memory|peak|cpu|tasks] [--limit=N]",
+		Short: "List the units in the cluster using the most resources",
+		Long: `Lists the resource usage of the active service units in the cluster, as
+accounted in their cgroups, heaviest first. Machines report the usage of their
+units periodically, so it may be up to a minute old. CPU is the total CPU time
+a unit consumed since it was started.
+
+Show the ten units using the most CPU time:
+	fleetctl top --sort=cpu --limit=10`,
+		Run: runWrapper(runTop),
+	}
+)
+
+func init() {
+	cmdFleet.AddCommand(cmdTop)
+
+	cmdTop.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdTop.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdTop.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+	cmdTop.Flags().StringVar(&topFlags.Sort, "sort", "memory", "Usage to sort units by, one of memory, peak, cpu or tasks.")
+	cmdTop.Flags().IntVar(&topFlags.Limit, "limit", 0, "Only list this many units. 0 lists all units.")
+}
+
+func runTop(cCmd *cobra.Command, args []string) (exit int) {
+	key, ok := topSortKeys[topFlags.Sort]
+	if !ok {
+		stderr("Invalid value for --sort: %q", topFlags.Sort)
+		return 1
+	}
+
+	states, err := cAPI.UnitStates()
+	if err != nil {
+		stderr("Error retrieving list of units from repository: %v", err)
+		return 1
+	}
+
+	states = sortByUsage(states, key)
+	if topFlags.Limit > 0 && len(states) > topFlags.Limit {
+		states = states[:topFlags.Limit]
+	}
+
+	if !sharedFlags.NoLegend {
+		fmt.Fprintln(out, "UNIT\tMACHINE\tTASKS\tCPU\tMEMORY\tPEAK")
+	}
+	for _, us := range states {
+		u := us.Usage
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			us.Name,
+			listUnitsFields["machine"](us, sharedFlags.Full),
+			strconv.FormatUint(u.Tasks, 10),
+			formatCPUUsage(u.CpuUsageNSec),
+			formatBytes(u.MemoryCurrent),
+			formatBytes(u.MemoryPeak),
+		)
+	}
+
+	out.Flush()
+	return 0
+}
+
+// sortByUsage returns the unit states that report resource usage, sorted by
+// the given usage in descending order
+func sortByUsage(states []*schema.UnitState, key func(*schema.UnitUsage) uint64) []*schema.UnitState {
+	var sorted []*schema.UnitState
+	for _, us := range states {
+		if us.Usage != nil {
+			sorted = append(sorted, us)
+		}
+	}
+	sort.Stable(unitStatesByUsage{sorted, key})
+	return sorted
+}
+
+type unitStatesByUsage struct {
+	states []*schema.UnitState
+	key    func(*schema.UnitUsage) uint64
+}
+
+func (s unitStatesByUsage) Len() int      { return len(s.states) }
+func (s unitStatesByUsage) Swap(i, j int) { s.states[i], s.states[j] = s.states[j], s.states[i] }
+func (s unitStatesByUsage) Less(i, j int) bool {
+	ui, uj := s.key(s.states[i].Usage), s.key(s.states[j].Usage)
+	if ui != uj {
+		return ui > uj
+	}
+	return s.states[i].Name < s.states[j].Name
+}
+
+// formatCPUUsage formats CPU time in nanoseconds with a precision of 10ms
+func formatCPUUsage(nsec uint64) string {
+	return (time.Duration(nsec) / (10 * time.Millisecond) * (10 * time.Millisecond)).String()
+}
+
+// formatBytes formats a number of bytes using binary prefixes, e.g. 1.5M, or
+// "-" if it is 0, i.e. not accounted.
+func formatBytes(b uint64) string {
+	if b == 0 {
+		return "-"
+	}
+	if b < 1024 {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(1024), 0
+	for n := b / 1024; n >= 1024 && exp < 5; n /= 1024 {
+		div *= 1024
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(b)/float64(div), "KMGTPE"[exp])
+}
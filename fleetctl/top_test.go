@@ -0,0 +1,86 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/registry"
+	"github.com/coreos/fleet/schema"
+	"github.com/coreos/fleet/unit"
+)
+
+func TestSortByUsage(t *testing.T) {
+	states := []*schema.UnitState{
+		{Name: "a.service", Usage: &schema.UnitUsage{MemoryCurrent: 100, CpuUsageNSec: 3, Tasks: 1}},
+		{Name: "b.service"},
+		{Name: "c.service", Usage: &schema.UnitUsage{MemoryCurrent: 300, CpuUsageNSec: 1, Tasks: 1}},
+		{Name: "d.service", Usage: &schema.UnitUsage{MemoryCurrent: 200, CpuUsageNSec: 2, Tasks: 2}},
+	}
+
+	names := func(states []*schema.UnitState) []string {
+		var names []string
+		for _, us := range states {
+			names = append(names, us.Name)
+		}
+		return names
+	}
+
+	for key, want := range map[string][]string{
+		"memory": []string{"c.service", "d.service", "a.service"},
+		"cpu":    []string{"a.service", "d.service", "c.service"},
+		"tasks":  []string{"d.service", "a.service", "c.service"},
+	} {
+		got := names(sortByUsage(states, topSortKeys[key]))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("sort by %s: got %v, expected %v", key, got, want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for b, want := range map[uint64]string{
+		0:       "-",
+		512:     "512B",
+		1536:    "1.5K",
+		1 << 20: "1.0M",
+		5 << 30: "5.0G",
+	} {
+		if got := formatBytes(b); got != want {
+			t.Errorf("formatBytes(%d) = %q, expected %q", b, got, want)
+		}
+	}
+}
+
+func TestRunTop(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetUnitStates([]unit.UnitState{
+		{UnitName: "hello.service", ActiveState: "active", Usage: &unit.UnitUsage{MemoryCurrent: 1 << 20, Tasks: 1}},
+	})
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	topFlags.Sort = "memory"
+	if exit := runTop(cmdTop, nil); exit != 0 {
+		t.Errorf("expected exit code 0 but received %d", exit)
+	}
+
+	topFlags.Sort = "disk"
+	if exit := runTop(cmdTop, nil); exit != 1 {
+		t.Errorf("expected exit code 1 for invalid sort key but received %d", exit)
+	}
+	topFlags.Sort = "memory"
+}
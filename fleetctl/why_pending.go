@@ -0,0 +1,106 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/machine"
+)
+
+var cmdWhyPending = &cobra.Command{
+	Use:   "why-pending [-l|--full] [--no-legend] UNIT",
+	Short: "Explain why a unit has not been scheduled",
+	Long: `Shows why the engine was unable to schedule a unit during its most recent
+attempt, listing each machine that cannot run the unit along with the reason.
+
+Only units that should be running but are not scheduled to any machine are
+pending. Global units are never scheduled by the engine.
+
+Output the reasons without truncating machine IDs:
+fleetctl why-pending --full foo.service`,
+	Run: runWrapper(runWhyPending),
+}
+
+func init() {
+	cmdFleet.AddCommand(cmdWhyPending)
+
+	cmdWhyPending.Flags().BoolVar(&sharedFlags.Full, "full", false, "Do not ellipsize fields on output")
+	cmdWhyPending.Flags().BoolVar(&sharedFlags.Full, "l", false, "Shorthand for --full")
+	cmdWhyPending.Flags().BoolVar(&sharedFlags.NoLegend, "no-legend", false, "Do not print a legend (column headers)")
+}
+
+func runWhyPending(cCmd *cobra.Command, args []string) (exit int) {
+	if len(args) != 1 {
+		stderr("One unit file must be provided")
+		return 1
+	}
+
+	name := unitNameMangle(args[0])
+	u, err := cAPI.Unit(name)
+	if err != nil {
+		stderr("Error retrieving Unit %s: %v", name, err)
+		return 1
+	}
+	if u == nil {
+		stderr("Unit %s not found", name)
+		return 1
+	}
+
+	switch {
+	case suToGlobal(*u):
+		stdout("Unit %s is global and is not scheduled by the engine", name)
+		return
+	case u.MachineID != "":
+		stdout("Unit %s is scheduled to machine %s", name, u.MachineID)
+		return
+	case job.JobState(u.DesiredState) == job.JobStateInactive:
+		stdout("Unit %s is inactive and will not be scheduled", name)
+		return
+	}
+
+	us, err := cAPI.UnitScheduling(name)
+	if err != nil {
+		stderr("Error retrieving scheduling explanation of Unit %s: %v", name, err)
+		return 1
+	}
+	if us == nil || us.Reason == "" {
+		stdout("Unit %s is pending, but no scheduling attempt has been recorded yet", name)
+		return
+	}
+
+	stdout("Unit %s could not be scheduled: %s", name, us.Reason)
+	if len(us.Rejections) == 0 {
+		return
+	}
+
+	stdout("")
+	noLegend, _ := cCmd.Flags().GetBool("no-legend")
+	if !noLegend {
+		fmt.Fprintln(out, "MACHINE\tREASON")
+	}
+
+	full, _ := cCmd.Flags().GetBool("full")
+	for _, r := range us.Rejections {
+		ms := machine.MachineState{ID: r.MachineID}
+		fmt.Fprintf(out, "%s\t%s\n", machineIDLegend(ms, full), r.Reason)
+	}
+	out.Flush()
+
+	return
+}
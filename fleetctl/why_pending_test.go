@@ -0,0 +1,68 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/coreos/fleet/client"
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/registry"
+)
+
+func TestRunWhyPending(t *testing.T) {
+	reg := registry.NewFakeRegistry()
+	reg.SetJobs([]job.Job{
+		{Name: "pending.service", TargetState: job.JobStateLaunched},
+		{Name: "scheduled.service", TargetState: job.JobStateLaunched, TargetMachineID: "XXX"},
+	})
+	reg.SetUnitSchedulingExplanation("pending.service", &job.SchedulingExplanation{
+		Reason: "no agents able to run job",
+		Rejections: map[string]string{
+			"XXX": "local Machine metadata insufficient",
+		},
+	}, 0)
+	cAPI = &client.RegistryClient{Registry: reg}
+
+	results := []commandTestResults{
+		{
+			"explain pending unit",
+			[]string{"pending.service"},
+			0,
+		},
+		{
+			"explain scheduled unit",
+			[]string{"scheduled.service"},
+			0,
+		},
+		{
+			"explain non-existent unit",
+			[]string{"missing.service"},
+			1,
+		},
+		{
+			"explain several units",
+			[]string{"pending.service", "scheduled.service"},
+			1,
+		},
+	}
+
+	for _, r := range results {
+		exit := runWhyPending(cmdWhyPending, r.units)
+		if exit != r.expectedExit {
+			t.Errorf("%s: expected exit code %d but received %d", r.description, r.expectedExit, exit)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 
@@ -27,6 +28,7 @@ import (
 
 	"github.com/coreos/fleet/agent"
 	"github.com/coreos/fleet/config"
+	"github.com/coreos/fleet/engine"
 	"github.com/coreos/fleet/log"
 	"github.com/coreos/fleet/pkg"
 	"github.com/coreos/fleet/registry"
@@ -85,11 +87,16 @@ func Main() {
 	cfgset.String("etcd_key_prefix", registry.DefaultKeyPrefix, "Keyspace for fleet data in etcd")
 	cfgset.Float64("etcd_request_timeout", 1.0, "Amount of time in seconds to allow a single etcd request before considering it failed.")
 	cfgset.Float64("engine_reconcile_interval", 2.0, "Interval at which the engine should reconcile the cluster schedule in etcd.")
+	cfgset.String("scheduler", engine.DefaultScheduler, fmt.Sprintf("Strategy the engine uses to place units on machines. Valid values are %q", strings.Join(engine.SchedulerNames(), ",")))
+	cfgset.String("scheduler_metadata_key", "", "Machine metadata key across whose values the spread-by-metadata scheduler spreads units")
+	cfgset.Int("rebalance_max_moves", 0, "Maximum number of Rebalanceable units the engine may be moving between machines at any time. Zero disables rebalancing.")
+	cfgset.String("rebalance_metric", engine.RebalanceByUnits, fmt.Sprintf("Measure of machine load the rebalancer evens out. Valid values are %q", strings.Join([]string{engine.RebalanceByUnits, engine.RebalanceByResources}, ",")))
 	cfgset.String("public_ip", "", "IP address that fleet machine should publish")
 	cfgset.String("metadata", "", "List of key-value metadata to assign to the fleet machine")
 	cfgset.String("agent_ttl", agent.DefaultTTL, "TTL in seconds of fleet machine state in etcd")
 	cfgset.String("units_directory", "/run/fleet/units/", "Path to the fleet units directory")
 	cfgset.Bool("systemd_user", false, "When true use systemd --user)")
+	cfgset.String("unit_manager", server.DefaultUnitManager, fmt.Sprintf("Backend the agent runs units with. Valid values are %q", strings.Join(server.UnitManagerNames(), ",")))
 	cfgset.Int("token_limit", 100, "Maximum number of entries per page returned from API requests")
 	cfgset.Bool("enable_grpc", false, "When possible, uses grpc to communicate between engine and agent")
 	cfgset.Bool("disable_engine", false, "Disable the engine entirely, use with care")
@@ -226,6 +233,10 @@ func getConfig(flagset *flag.FlagSet, userCfgFile string) (*config.Config, error
 		EtcdCAFile:              (*flagset.Lookup("etcd_cafile")).Value.(flag.Getter).Get().(string),
 		EtcdRequestTimeout:      (*flagset.Lookup("etcd_request_timeout")).Value.(flag.Getter).Get().(float64),
 		EngineReconcileInterval: (*flagset.Lookup("engine_reconcile_interval")).Value.(flag.Getter).Get().(float64),
+		Scheduler:               (*flagset.Lookup("scheduler")).Value.(flag.Getter).Get().(string),
+		SchedulerMetadataKey:    (*flagset.Lookup("scheduler_metadata_key")).Value.(flag.Getter).Get().(string),
+		RebalanceMaxMoves:       (*flagset.Lookup("rebalance_max_moves")).Value.(flag.Getter).Get().(int),
+		RebalanceMetric:         (*flagset.Lookup("rebalance_metric")).Value.(flag.Getter).Get().(string),
 		PublicIP:                (*flagset.Lookup("public_ip")).Value.(flag.Getter).Get().(string),
 		RawMetadata:             (*flagset.Lookup("metadata")).Value.(flag.Getter).Get().(string),
 		AgentTTL:                (*flagset.Lookup("agent_ttl")).Value.(flag.Getter).Get().(string),
@@ -235,6 +246,7 @@ func getConfig(flagset *flag.FlagSet, userCfgFile string) (*config.Config, error
 		VerifyUnits:             (*flagset.Lookup("verify_units")).Value.(flag.Getter).Get().(bool),
 		UnitsDirectory:          (*flagset.Lookup("units_directory")).Value.(flag.Getter).Get().(string),
 		SystemdUser:             (*flagset.Lookup("systemd_user")).Value.(flag.Getter).Get().(bool),
+		UnitManager:             (*flagset.Lookup("unit_manager")).Value.(flag.Getter).Get().(string),
 		TokenLimit:              (*flagset.Lookup("token_limit")).Value.(flag.Getter).Get().(int),
 		AuthorizedKeysFile:      (*flagset.Lookup("authorized_keys_file")).Value.(flag.Getter).Get().(string),
 	}
@@ -60,7 +60,7 @@ ExecStart=/usr/bin/sleep 3000
 	hash := uf.Hash().String()
 	j := job.NewJob(name, *uf)
 
-	if err := mgr.Load(j.Name, j.Unit); err != nil {
+	if err := mgr.Load(j.Name, j.Unit, nil); err != nil {
 		t.Fatalf("Failed loading job: %v", err)
 	}
 
@@ -73,7 +73,7 @@ ExecStart=/usr/bin/sleep 3000
 		t.Fatalf("Expected [hello.service], got %v", units)
 	}
 
-	err = waitForUnitState(mgr, name, unit.UnitState{"loaded", "inactive", "dead", "", hash, ""})
+	err = waitForUnitState(mgr, name, unit.UnitState{LoadState: "loaded", ActiveState: "inactive", SubState: "dead", UnitHash: hash})
 	if err != nil {
 		t.Error(err)
 	}
@@ -83,7 +83,7 @@ ExecStart=/usr/bin/sleep 3000
 		t.Error(err)
 	}
 
-	err = waitForUnitState(mgr, name, unit.UnitState{"loaded", "active", "running", "", hash, ""})
+	err = waitForUnitState(mgr, name, unit.UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", UnitHash: hash})
 	if err != nil {
 		t.Error(err)
 	}
@@ -119,6 +119,10 @@ func waitForUnitState(mgr unit.UnitManager, name string, want unit.UnitState) er
 			return err
 		}
 
+		// the details of the main process differ from run to run
+		got.MainPID, got.ExecMainStatus, got.NRestarts = 0, 0, 0
+		got.ActiveEnterTimestamp, got.Result = 0, ""
+
 		if reflect.DeepEqual(want, *got) {
 			return nil
 		}
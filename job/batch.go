@@ -0,0 +1,45 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// UnitTypeService is the default type of units, which are expected to
+	// keep running
+	UnitTypeService = "service"
+	// UnitTypeBatch is the type of units which are done once they exit
+	// successfully
+	UnitTypeBatch = "batch"
+)
+
+// Completion records that a batch Unit ran to completion.
+type Completion struct {
+	// MachineID is the ID of the machine the Unit completed on
+	MachineID string
+	Time      time.Time
+}
+
+// ValidateType returns an error if the given value of the Type option is
+// not a known unit type.
+func ValidateType(typ string) error {
+	if typ != UnitTypeService && typ != UnitTypeBatch {
+		return fmt.Errorf("%s must be %q or %q, got %q", fleetType, UnitTypeService, UnitTypeBatch, typ)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coreos/fleet/unit"
+)
+
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyAllow launches new instances of a scheduled template even
+	// if previous ones are still running
+	ConcurrencyAllow = ConcurrencyPolicy("allow")
+	// ConcurrencyForbid skips a tick of the schedule while a previous
+	// instance is still running
+	ConcurrencyForbid = ConcurrencyPolicy("forbid")
+	// ConcurrencyReplace destroys the instances still running before
+	// launching a new one
+	ConcurrencyReplace = ConcurrencyPolicy("replace")
+
+	// DefaultScheduleHistory is how many finished instances of a
+	// scheduled template are kept, unless the template says otherwise
+	DefaultScheduleHistory = 3
+)
+
+// ParseConcurrencyPolicy returns the ConcurrencyPolicy of the given name.
+func ParseConcurrencyPolicy(s string) (ConcurrencyPolicy, error) {
+	switch cp := ConcurrencyPolicy(s); cp {
+	case ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace:
+		return cp, nil
+	}
+	return "", fmt.Errorf("%s must be one of %q, %q or %q, got %q", fleetConcurrencyPolicy, ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace, s)
+}
+
+// ParseScheduleHistory parses the number of finished instances of a
+// scheduled template to keep, which must be at least 1.
+func ParseScheduleHistory(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s requires a positive number, got %q", fleetScheduleHistory, s)
+	}
+	return n, nil
+}
+
+// cronMacros maps the shorthands supported in place of the five fields of
+// a CronSchedule to their expansion.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronFields describes the fields of a CronSchedule in order. Both 0 and 7
+// stand for Sunday in the day of week field.
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// CronSchedule determines at which minutes a scheduled template is
+// launched, using the syntax of crontab(5). Schedules are evaluated in UTC.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record whether the day of month and day of week
+	// fields were unrestricted, as a day matches if either restricted
+	// field does
+	domAny, dowAny bool
+}
+
+// ParseCronSchedule parses a schedule made of five whitespace-separated
+// fields, minute, hour, day of month, month and day of week, e.g.
+// "*/15 * * * *". Each field is a comma-separated list of values, ranges
+// like "1-5" and "*", each optionally followed by a step like "/15". The
+// shorthands @hourly, @daily, @midnight, @weekly, @monthly, @yearly and
+// @annually are supported as well.
+func ParseCronSchedule(s string) (*CronSchedule, error) {
+	if expanded, ok := cronMacros[strings.TrimSpace(s)]; ok {
+		s = expanded
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%s requires %d fields, got %q", fleetSchedule, len(cronFields), s)
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, i)
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid %s field %q: %v", fleetSchedule, cronFields[i].name, field, err)
+		}
+		sets[i] = set
+	}
+
+	// 7 is an alias of Sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &CronSchedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: strings.HasPrefix(fields[2], "*"),
+		dowAny: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField returns the set of values matched by the given field of a
+// CronSchedule, as a bit set.
+func parseCronField(field string, i int) (uint64, error) {
+	min, max := cronFields[i].min, cronFields[i].max
+
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rng = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", part[idx+1:])
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rng)
+			}
+			lo, hi = n, n
+			// a stepped single value runs up to the maximum, e.g. 5/15
+			if rng != part {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max {
+			return 0, fmt.Errorf("values must be between %d and %d", min, max)
+		}
+		if lo > hi {
+			return 0, errors.New("range start is after its end")
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Matches determines whether the CronSchedule fires at the minute of the
+// given time.
+func (cs *CronSchedule) Matches(t time.Time) bool {
+	t = t.UTC()
+	if !hasBit(cs.minute, t.Minute()) || !hasBit(cs.hour, t.Hour()) || !hasBit(cs.month, int(t.Month())) {
+		return false
+	}
+
+	dom, dow := hasBit(cs.dom, t.Day()), hasBit(cs.dow, int(t.Weekday()))
+	switch {
+	case cs.domAny && cs.dowAny:
+		return true
+	case cs.domAny:
+		return dow
+	case cs.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+// LastTick returns the most recent minute at which the CronSchedule fired,
+// no later than the given time and no earlier than the given deadline
+// before it, and whether there is one.
+func (cs *CronSchedule) LastTick(now time.Time, deadline time.Duration) (time.Time, bool) {
+	earliest := now.Add(-deadline)
+	for t := now.Truncate(time.Minute); !t.Before(earliest); t = t.Add(-time.Minute) {
+		if cs.Matches(t) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func hasBit(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// ScheduledInstanceName returns the name of the instance of the given
+// template launched for the given tick of its schedule, which is named
+// after the Unix time of the tick, e.g. backup@1476700800.service.
+func ScheduledInstanceName(template string, tick time.Time) string {
+	uni := unit.NewUnitNameInfo(template)
+	return fmt.Sprintf("%s%d%s", uni.Name, tick.Unix(), template[len(uni.Name):])
+}
+
+// ScheduledInstanceTick returns the tick the named unit was launched for if
+// it is an instance of the given scheduled template, and whether it is.
+func ScheduledInstanceTick(template, name string) (time.Time, bool) {
+	uni := unit.NewUnitNameInfo(name)
+	if uni == nil || !uni.IsInstance() || uni.Template != template {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(uni.Instance, 10, 64)
+	if err != nil || sec < 0 || strconv.FormatInt(sec, 10) != uni.Instance {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
@@ -0,0 +1,132 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0 3 * * 1-5",
+		"5/10 0,12 1 1-12/3 7",
+		"@hourly",
+		"@daily",
+	}
+	for _, s := range valid {
+		if _, err := ParseCronSchedule(s); err != nil {
+			t.Errorf("unexpected error parsing %q: %v", s, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"@often",
+	}
+	for _, s := range invalid {
+		if cs, err := ParseCronSchedule(s); err == nil {
+			t.Errorf("expected error parsing %q, got %#v", s, cs)
+		}
+	}
+}
+
+func TestCronScheduleMatches(t *testing.T) {
+	// a Monday
+	base := time.Date(2016, time.October, 17, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		schedule string
+		t        time.Time
+		matches  bool
+	}{
+		{"* * * * *", base.Add(37 * time.Minute), true},
+		{"*/15 * * * *", base.Add(45 * time.Minute), true},
+		{"*/15 * * * *", base.Add(46 * time.Minute), false},
+		{"5/15 * * * *", base.Add(50 * time.Minute), true},
+		{"5/15 * * * *", base.Add(45 * time.Minute), false},
+		{"0 3 * * *", base.Add(3 * time.Hour), true},
+		{"0 3 * * *", base.Add(4 * time.Hour), false},
+		{"0 0 * * 1-5", base, true},
+		{"0 0 * * 0,6", base, false},
+		{"0 0 * * 7", base.AddDate(0, 0, 6), true},
+		{"0 0 1 * *", base, false},
+		{"0 0 * 10 *", base, true},
+		{"0 0 * 11 *", base, false},
+		// a day matches if either restricted day field does
+		{"0 0 1 * 1", base, true},
+		{"0 0 17 * 0", base, true},
+		{"0 0 1 * 0", base, false},
+		{"@daily", base, true},
+		{"@hourly", base.Add(30 * time.Minute), false},
+	}
+	for i, tt := range testCases {
+		cs, err := ParseCronSchedule(tt.schedule)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if got := cs.Matches(tt.t); got != tt.matches {
+			t.Errorf("case %d: %q at %v: got %t, want %t", i, tt.schedule, tt.t, got, tt.matches)
+		}
+	}
+}
+
+func TestCronScheduleLastTick(t *testing.T) {
+	cs, err := ParseCronSchedule("*/15 * * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	base := time.Date(2016, time.October, 17, 0, 0, 0, 0, time.UTC)
+	now := base.Add(15*time.Minute + 40*time.Second)
+	if tick, ok := cs.LastTick(now, time.Minute); !ok || !tick.Equal(base.Add(15*time.Minute)) {
+		t.Errorf("unexpected last tick: got %v, %t", tick, ok)
+	}
+
+	now = base.Add(17 * time.Minute)
+	if tick, ok := cs.LastTick(now, time.Minute); ok {
+		t.Errorf("expected no tick within the deadline, got %v", tick)
+	}
+}
+
+func TestScheduledInstanceName(t *testing.T) {
+	tick := time.Unix(1476700800, 0)
+	name := ScheduledInstanceName("backup@.service", tick)
+	if name != "backup@1476700800.service" {
+		t.Fatalf("unexpected instance name %q", name)
+	}
+
+	if got, ok := ScheduledInstanceTick("backup@.service", name); !ok || !got.Equal(tick) {
+		t.Errorf("unexpected tick of %s: got %v, %t", name, got, ok)
+	}
+	for _, name := range []string{"backup@.service", "backup@some.service", "backup@0123.service", "other@1476700800.service"} {
+		if got, ok := ScheduledInstanceTick("backup@.service", name); ok {
+			t.Errorf("expected %s not to be a scheduled instance, got %v", name, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/coreos/fleet/unit"
+)
+
+// Deployment asks for a number of instances of a template unit to be kept
+// in the cluster. The instances are named after the template, numbered
+// from 1 up to the number of replicas, e.g. foo@1.service and
+// foo@2.service for two replicas of foo@.service.
+type Deployment struct {
+	// Template is the name of the template unit, e.g. foo@.service
+	Template string
+	Replicas int
+}
+
+// Validate returns an error if the Deployment is malformed.
+func (d *Deployment) Validate() error {
+	uni := unit.NewUnitNameInfo(d.Template)
+	if uni == nil || !uni.IsTemplate() {
+		return fmt.Errorf("%q is not the name of a template unit", d.Template)
+	}
+	if d.Replicas < 0 {
+		return errors.New("number of replicas cannot be negative")
+	}
+	return nil
+}
+
+// InstanceName returns the name of the instance of the Deployment with the
+// given number.
+func (d *Deployment) InstanceName(n int) string {
+	uni := unit.NewUnitNameInfo(d.Template)
+	return fmt.Sprintf("%s%d%s", uni.Name, n, d.Template[len(uni.Name):])
+}
+
+// InstanceNumber returns the number of the named unit if it is an instance
+// of the Deployment, and whether it is.
+func (d *Deployment) InstanceNumber(name string) (int, bool) {
+	uni := unit.NewUnitNameInfo(name)
+	if uni == nil || !uni.IsInstance() || uni.Template != d.Template {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(uni.Instance)
+	if err != nil || n < 1 || strconv.Itoa(n) != uni.Instance {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,66 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"testing"
+)
+
+func TestDeploymentValidate(t *testing.T) {
+	for i, tt := range []struct {
+		d  Deployment
+		ok bool
+	}{
+		{Deployment{Template: "foo@.service", Replicas: 3}, true},
+		{Deployment{Template: "foo@.service", Replicas: 0}, true},
+		{Deployment{Template: "foo@.service", Replicas: -1}, false},
+		{Deployment{Template: "foo@1.service", Replicas: 1}, false},
+		{Deployment{Template: "foo.service", Replicas: 1}, false},
+		{Deployment{Template: "foo", Replicas: 1}, false},
+	} {
+		if err := tt.d.Validate(); tt.ok != (err == nil) {
+			t.Errorf("case %d: expected ok=%t, got error %v", i, tt.ok, err)
+		}
+	}
+}
+
+func TestDeploymentInstances(t *testing.T) {
+	d := Deployment{Template: "foo@.service", Replicas: 2}
+	if name := d.InstanceName(12); name != "foo@12.service" {
+		t.Errorf("unexpected instance name %q", name)
+	}
+
+	for i, tt := range []struct {
+		name string
+		n    int
+		ok   bool
+	}{
+		{"foo@1.service", 1, true},
+		{"foo@12.service", 12, true},
+		{"foo@0.service", 0, false},
+		{"foo@01.service", 0, false},
+		{"foo@-1.service", 0, false},
+		{"foo@bar.service", 0, false},
+		{"foo@1.socket", 0, false},
+		{"bar@1.service", 0, false},
+		{"foo@.service", 0, false},
+		{"foo.service", 0, false},
+	} {
+		n, ok := d.InstanceNumber(tt.name)
+		if n != tt.n || ok != tt.ok {
+			t.Errorf("case %d: expected (%d, %t), got (%d, %t)", i, tt.n, tt.ok, n, ok)
+		}
+	}
+}
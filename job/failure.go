@@ -0,0 +1,72 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBlacklistPeriod is how long a unit is kept off a machine it
+// repeatedly failed on, unless its FailurePolicy says otherwise.
+const DefaultBlacklistPeriod = time.Hour
+
+// FailurePolicy describes when the engine gives up on a unit that keeps
+// failing on the machine it is scheduled to, and moves it elsewhere.
+type FailurePolicy struct {
+	// Failures is how many times the unit must enter the failed state
+	// within Window before it is moved
+	Failures int
+	Window   time.Duration
+	// Blacklist is how long the unit is kept off the machine it failed on
+	Blacklist time.Duration
+}
+
+// ParseFailurePolicy parses a FailurePolicy of the form
+// "FAILURES/WINDOW[/BLACKLIST]", e.g. "3/10m" or "3/10m/2h". Durations use
+// the syntax of time.ParseDuration. If BLACKLIST is omitted,
+// DefaultBlacklistPeriod is used.
+func ParseFailurePolicy(s string) (*FailurePolicy, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("%s must be of the form FAILURES/WINDOW[/BLACKLIST], got %q", fleetRescheduleOnFailure, s)
+	}
+
+	failures, err := strconv.Atoi(parts[0])
+	if err != nil || failures < 1 {
+		return nil, fmt.Errorf("%s requires a positive number of failures, got %q", fleetRescheduleOnFailure, parts[0])
+	}
+
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("%s requires a positive window, got %q", fleetRescheduleOnFailure, parts[1])
+	}
+
+	blacklist := DefaultBlacklistPeriod
+	if len(parts) == 3 {
+		blacklist, err = time.ParseDuration(parts[2])
+		if err != nil || blacklist < time.Second {
+			return nil, fmt.Errorf("%s requires a blacklist period of at least 1s, got %q", fleetRescheduleOnFailure, parts[2])
+		}
+	}
+
+	return &FailurePolicy{
+		Failures:  failures,
+		Window:    window,
+		Blacklist: blacklist,
+	}, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want *FailurePolicy
+	}{
+		{"3/10m", &FailurePolicy{Failures: 3, Window: 10 * time.Minute, Blacklist: DefaultBlacklistPeriod}},
+		{"1/30s/2h", &FailurePolicy{Failures: 1, Window: 30 * time.Second, Blacklist: 2 * time.Hour}},
+		{"", nil},
+		{"3", nil},
+		{"3/10m/1h/1h", nil},
+		{"0/10m", nil},
+		{"-1/10m", nil},
+		{"some/10m", nil},
+		{"3/0s", nil},
+		{"3/ten", nil},
+		{"3/10m/0s", nil},
+		{"3/10m/some", nil},
+	}
+	for i, tt := range testCases {
+		got, err := ParseFailurePolicy(tt.in)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("case %d: expected error parsing %q, got %v", i, tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error parsing %q: %v", i, tt.in, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("case %d: unexpected FailurePolicy: got %#v, want %#v", i, got, tt.want)
+		}
+	}
+}
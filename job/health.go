@@ -0,0 +1,77 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+type HealthCheckType string
+
+const (
+	// HealthCheckHTTP passes if a GET request to the target URL is answered
+	// with a 2xx or 3xx status code
+	HealthCheckHTTP = HealthCheckType("http")
+	// HealthCheckTCP passes if a connection to the target host:port can be
+	// established
+	HealthCheckTCP = HealthCheckType("tcp")
+	// HealthCheckExec passes if the target command exits with status 0
+	HealthCheckExec = HealthCheckType("exec")
+)
+
+// healthCheckKeys maps each type of health check to the unit file
+// requirement key declaring it.
+var healthCheckKeys = map[HealthCheckType]string{
+	HealthCheckHTTP: fleetHealthCheckHTTP,
+	HealthCheckTCP:  fleetHealthCheckTCP,
+	HealthCheckExec: fleetHealthCheckExec,
+}
+
+// HealthCheck describes how an agent probes a unit to determine whether it
+// is healthy.
+type HealthCheck struct {
+	Type   HealthCheckType
+	Target string
+	// User and Group are the user and group the command of an exec check
+	// runs as, taken from the User= and Group= options of the unit. An
+	// empty User runs it as root, as systemd would run the unit.
+	User  string
+	Group string
+}
+
+// Validate returns an error if the HealthCheck is malformed.
+func (hc HealthCheck) Validate() error {
+	switch hc.Type {
+	case HealthCheckHTTP:
+		u, err := url.Parse(hc.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s requires an http or https URL, got %q", fleetHealthCheckHTTP, hc.Target)
+		}
+	case HealthCheckTCP:
+		if _, _, err := net.SplitHostPort(hc.Target); err != nil {
+			return fmt.Errorf("%s requires a host:port address, got %q", fleetHealthCheckTCP, hc.Target)
+		}
+	case HealthCheckExec:
+		if hc.Target == "" {
+			return errors.New(fleetHealthCheckExec + " requires a command")
+		}
+	default:
+		return fmt.Errorf("unknown health check type %q", hc.Type)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"testing"
+)
+
+func TestHealthCheckValidate(t *testing.T) {
+	testCases := []struct {
+		hc    HealthCheck
+		valid bool
+	}{
+		{HealthCheck{Type: HealthCheckHTTP, Target: "http://localhost:8080/health"}, true},
+		{HealthCheck{Type: HealthCheckHTTP, Target: "https://10.0.0.1/"}, true},
+		{HealthCheck{Type: HealthCheckHTTP, Target: "localhost:8080"}, false},
+		{HealthCheck{Type: HealthCheckHTTP, Target: "ftp://localhost/"}, false},
+		{HealthCheck{Type: HealthCheckTCP, Target: "localhost:6379"}, true},
+		{HealthCheck{Type: HealthCheckTCP, Target: "localhost"}, false},
+		{HealthCheck{Type: HealthCheckExec, Target: "/usr/bin/true"}, true},
+		{HealthCheck{Type: HealthCheckExec, Target: ""}, false},
+		{HealthCheck{Type: HealthCheckType("ping"), Target: "localhost"}, false},
+	}
+	for i, tt := range testCases {
+		err := tt.hc.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("case %d: bad error value (got err=%v, want valid=%t)", i, err, tt.valid)
+		}
+	}
+}
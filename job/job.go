@@ -16,9 +16,12 @@ package job
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/coreos/fleet/pkg"
+	"github.com/coreos/fleet/resource"
 	"github.com/coreos/fleet/unit"
 )
 
@@ -29,6 +32,9 @@ const (
 	JobStateInactive = JobState("inactive")
 	JobStateLoaded   = JobState("loaded")
 	JobStateLaunched = JobState("launched")
+	// JobStateCompleted is the current state of a batch unit that ran to
+	// completion. It is never a target state.
+	JobStateCompleted = JobState("completed")
 
 	JobActionSchedule   = JobAction("job_action_schedule")
 	JobActionUnschedule = JobAction("job_action_unschedule")
@@ -43,6 +49,8 @@ const (
 	fleetMachineID = "MachineID"
 	// Legacy form of fleetMachineID.
 	fleetMachineBootID = "MachineBootID"
+	// Prevent the unit from running on any of the machines identified by given IDs.
+	fleetExcludeMachineID = "ExcludeMachineID"
 	// Limit eligible machines to the one that hosts a specific unit.
 	fleetMachineOf = "MachineOf"
 	// Prevent a unit from being collocated with other units using glob-matching on the other unit names.
@@ -53,6 +61,40 @@ const (
 	fleetMachineMetadata = "MachineMetadata"
 	// Require that the unit be scheduled on every machine in the cluster
 	fleetGlobal = "Global"
+	// Resources (cores, memory, disk) the unit needs reserved on its machine
+	fleetResources = "Resources"
+	// Spread instances of the same template across the values of a machine metadata key
+	fleetSpreadBy = "SpreadBy"
+	// Prefer machines with this specific metadata, without requiring it
+	fleetPreferMachineMetadata = "PreferMachineMetadata"
+	// Prefer the machine that hosts a specific unit, without requiring it
+	fleetPreferMachineOf = "PreferMachineOf"
+	// Prefer not to collocate the unit with other units using glob-matching on the other unit names
+	fleetAvoidConflicts = "AvoidConflicts"
+	// Scheduling priority of the unit; higher-priority units may preempt lower-priority ones
+	fleetPriority = "Priority"
+	// Allow the engine to move the unit to another machine to even out the cluster load
+	fleetRebalanceable = "Rebalanceable"
+	// Maximum number of instances of a template the engine may voluntarily make unavailable
+	fleetMaxUnavailable = "MaxUnavailable"
+	// URL the agent requests periodically to determine whether the unit is healthy
+	fleetHealthCheckHTTP = "HealthCheckHTTP"
+	// Address the agent connects to periodically to determine whether the unit is healthy
+	fleetHealthCheckTCP = "HealthCheckTCP"
+	// Command the agent runs periodically to determine whether the unit is healthy
+	fleetHealthCheckExec = "HealthCheckExec"
+	// Move the unit to another machine after it failed too often on its current one
+	fleetRescheduleOnFailure = "RescheduleOnFailure"
+	// Kind of workload the unit runs, i.e. a long-running service or a batch job
+	fleetType = "Type"
+	// How long a completed batch unit is kept before it is destroyed
+	fleetCompletedTTL = "CompletedTTL"
+	// Launch a new instance of the template unit at each tick of a cron-style schedule
+	fleetSchedule = "Schedule"
+	// Number of finished instances of a scheduled template unit to keep
+	fleetScheduleHistory = "ScheduleHistory"
+	// Whether instances of a scheduled template unit may run concurrently
+	fleetConcurrencyPolicy = "ConcurrencyPolicy"
 
 	deprecatedXPrefix          = "X-"
 	deprecatedXConditionPrefix = "X-Condition"
@@ -65,12 +107,30 @@ var validRequirements = pkg.NewUnsafeSet(
 	deprecatedXConditionPrefix+fleetMachineBootID,
 	deprecatedXConditionPrefix+fleetMachineOf,
 	fleetMachineOf,
+	fleetExcludeMachineID,
 	deprecatedXPrefix+fleetConflicts,
 	fleetConflicts,
 	deprecatedXConditionPrefix+fleetMachineMetadata,
 	fleetMachineMetadata,
 	fleetGlobal,
 	fleetReplaces,
+	fleetResources,
+	fleetSpreadBy,
+	fleetPreferMachineMetadata,
+	fleetPreferMachineOf,
+	fleetAvoidConflicts,
+	fleetPriority,
+	fleetRebalanceable,
+	fleetMaxUnavailable,
+	fleetHealthCheckHTTP,
+	fleetHealthCheckTCP,
+	fleetHealthCheckExec,
+	fleetRescheduleOnFailure,
+	fleetType,
+	fleetCompletedTTL,
+	fleetSchedule,
+	fleetScheduleHistory,
+	fleetConcurrencyPolicy,
 )
 
 func ParseJobState(s string) (JobState, error) {
@@ -101,12 +161,25 @@ type ScheduledUnit struct {
 	TargetMachineID string
 }
 
+// SchedulingExplanation records why the engine was unable to schedule a Unit
+// during its most recent attempt.
+type SchedulingExplanation struct {
+	// Reason is the error returned by the scheduler
+	Reason string
+	// Rejections maps the ID of each Machine that cannot run the Unit to
+	// the reason it was rejected
+	Rejections map[string]string
+}
+
 // Unit represents a Unit that has been submitted to fleet
 // (list-unit-files)
 type Unit struct {
 	Name        string
 	Unit        unit.UnitFile
 	TargetState JobState
+	// DropIns are the drop-ins applying to the unit on the machine it is
+	// scheduled to. They are only set by the agent.
+	DropIns []unit.DropIn
 }
 
 // IsGlobal returns whether a Unit is considered a global unit
@@ -170,6 +243,37 @@ func (u *Unit) RequiredTarget() (string, bool) {
 	return j.RequiredTarget()
 }
 
+func (u *Unit) ExcludedMachineIDs() []string {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.ExcludedMachineIDs()
+}
+
+// HealthChecks returns the health checks of the Unit, with its drop-ins
+// applied, as they may change the user the unit runs as.
+func (u *Unit) HealthChecks() []HealthCheck {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	if len(u.DropIns) > 0 {
+		if uf, err := u.Unit.WithDropIns(u.DropIns); err == nil {
+			j.Unit = *uf
+		}
+	}
+	return j.HealthChecks()
+}
+
+func (u *Unit) IsBatch() bool {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.IsBatch()
+}
+
 func (u *Unit) RequiredTargetMetadata() map[string]pkg.Set {
 	j := &Job{
 		Name: u.Name,
@@ -178,6 +282,38 @@ func (u *Unit) RequiredTargetMetadata() map[string]pkg.Set {
 	return j.RequiredTargetMetadata()
 }
 
+func (u *Unit) AvoidConflicts() []string {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.AvoidConflicts()
+}
+
+func (u *Unit) SpreadBy() (string, bool) {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.SpreadBy()
+}
+
+func (u *Unit) Priority() int {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.Priority()
+}
+
+func (u *Unit) Resources() resource.ResourceTuple {
+	j := &Job{
+		Name: u.Name,
+		Unit: u.Unit,
+	}
+	return j.Resources()
+}
+
 // requirements returns all relevant options from the [X-Fleet] section of a unit file.
 // Relevant options are identified with a `X-` prefix in the unit.
 // This prefix is stripped from relevant options before being returned.
@@ -281,16 +417,49 @@ func (j *Job) RequiredTarget() (string, bool) {
 	return "", false
 }
 
+// ExcludedMachineIDs returns the IDs of the machines this Job must not run on.
+// Each ID may be given in full or as the short form of a machine ID.
+func (j *Job) ExcludedMachineIDs() []string {
+	return splitCombine(j.requirements()[fleetExcludeMachineID])
+}
+
 // RequiredTargetMetadata return all machine-related metadata from a Job's
 // requirements. Valid metadata fields are strings of the form `key=value`,
 // where both key and value are not the empty string.
 func (j *Job) RequiredTargetMetadata() map[string]pkg.Set {
+	return j.targetMetadata(
+		deprecatedXConditionPrefix+fleetMachineMetadata,
+		fleetMachineMetadata,
+	)
+}
+
+// PreferredTargetMetadata returns all machine-related metadata that a Job
+// would prefer, but does not require, its machine to have. The format is the
+// same as that of RequiredTargetMetadata.
+func (j *Job) PreferredTargetMetadata() map[string]pkg.Set {
+	return j.targetMetadata(fleetPreferMachineMetadata)
+}
+
+// PreferredPeers returns a list of Job names that this Job would prefer, but
+// does not require, to be scheduled to the same machine as.
+func (j *Job) PreferredPeers() []string {
+	peers := make([]string, 0)
+	peers = append(peers, j.requirements()[fleetPreferMachineOf]...)
+	return peers
+}
+
+// AvoidConflicts returns a list of Job names that this Job would prefer, but
+// does not require, not to be scheduled to the same machine as.
+func (j *Job) AvoidConflicts() []string {
+	return splitCombine(j.requirements()[fleetAvoidConflicts])
+}
+
+// targetMetadata parses the machine metadata found in the requirements with
+// the given keys.
+func (j *Job) targetMetadata(keys ...string) map[string]pkg.Set {
 	metadata := make(map[string]pkg.Set)
 
-	for _, key := range []string{
-		deprecatedXConditionPrefix + fleetMachineMetadata,
-		fleetMachineMetadata,
-	} {
+	for _, key := range keys {
 		for _, valuePair := range j.requirements()[key] {
 			s := strings.Split(valuePair, "=")
 
@@ -312,6 +481,258 @@ func (j *Job) RequiredTargetMetadata() map[string]pkg.Set {
 	return metadata
 }
 
+// SpreadBy returns the machine metadata key across whose values the
+// instances of this Job's template should be spread evenly. If the Job
+// declares no such key, an empty string along with a bool false is returned.
+// The last value found wins.
+func (j *Job) SpreadBy() (string, bool) {
+	values := j.requirements()[fleetSpreadBy]
+	if len(values) == 0 {
+		return "", false
+	}
+
+	key := values[len(values)-1]
+	if key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// Priority returns the scheduling priority of the Job. Jobs with a higher
+// priority are scheduled first, and may preempt jobs with a lower priority.
+// Jobs without a valid priority have priority 0. The last value found wins.
+func (j *Job) Priority() int {
+	values := j.requirements()[fleetPriority]
+	if len(values) == 0 {
+		return 0
+	}
+
+	priority, err := strconv.Atoi(values[len(values)-1])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// ValidatePriority returns an error if the given value of the Priority
+// option is not an integer.
+func ValidatePriority(value string) error {
+	if _, err := strconv.Atoi(value); err != nil {
+		return fmt.Errorf("%s requires an integer, got %q", fleetPriority, value)
+	}
+	return nil
+}
+
+// Rebalanceable returns whether the engine may move the Job to another
+// machine to even out the load of the cluster. The last value found wins.
+func (j *Job) Rebalanceable() bool {
+	values := j.requirements()[fleetRebalanceable]
+	if len(values) == 0 {
+		return false
+	}
+	return isTruthyValue(values[len(values)-1])
+}
+
+// MaxUnavailable returns the maximum number of instances of the Job's
+// template that the engine may voluntarily make unavailable at once, and
+// whether such a disruption budget is set. Negative and malformed values are
+// ignored. The last value found wins.
+func (j *Job) MaxUnavailable() (int, bool) {
+	values := j.requirements()[fleetMaxUnavailable]
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	max, err := strconv.Atoi(values[len(values)-1])
+	if err != nil || max < 0 {
+		return 0, false
+	}
+	return max, true
+}
+
+// ValidateMaxUnavailable returns an error if the given value of the
+// MaxUnavailable option is not a non-negative integer.
+func ValidateMaxUnavailable(value string) error {
+	if max, err := strconv.Atoi(value); err != nil || max < 0 {
+		return fmt.Errorf("%s requires a non-negative integer, got %q", fleetMaxUnavailable, value)
+	}
+	return nil
+}
+
+// Resources returns the resources a Job requires on the machine it is
+// scheduled to. Valid fields are strings of the form `key=value`, where key
+// is one of "cores", "memory" or "disk" and value is a non-negative integer.
+// Cores are expressed in hundredths of a core, memory and disk in MB. Unknown
+// keys and malformed values are ignored, and the last value found for a key
+// wins.
+func (j *Job) Resources() (res resource.ResourceTuple) {
+	for _, valuePair := range splitCombine(j.requirements()[fleetResources]) {
+		s := strings.Split(valuePair, "=")
+		if len(s) != 2 {
+			continue
+		}
+
+		val, err := strconv.Atoi(s[1])
+		if err != nil || val < 0 {
+			continue
+		}
+
+		switch strings.ToLower(s[0]) {
+		case "cores":
+			res.Cores = val
+		case "memory":
+			res.Memory = val
+		case "disk":
+			res.Disk = val
+		}
+	}
+
+	return
+}
+
+// ValidateResources returns an error if the given value of the Resources
+// option holds anything but `key=value` fields with a known key and a
+// non-negative integer value.
+func ValidateResources(value string) error {
+	for _, valuePair := range strings.Fields(value) {
+		s := strings.Split(valuePair, "=")
+		if len(s) != 2 {
+			return fmt.Errorf("%s requires fields of the form key=value, got %q", fleetResources, valuePair)
+		}
+
+		switch strings.ToLower(s[0]) {
+		case "cores", "memory", "disk":
+		default:
+			return fmt.Errorf("%s key must be \"cores\", \"memory\" or \"disk\", got %q", fleetResources, s[0])
+		}
+
+		if val, err := strconv.Atoi(s[1]); err != nil || val < 0 {
+			return fmt.Errorf("%s requires a non-negative integer for %s, got %q", fleetResources, s[0], s[1])
+		}
+	}
+	return nil
+}
+
+// HealthChecks returns the health checks declared by a Job, HTTP checks
+// first, then TCP checks, then commands. A Job is healthy only if all of
+// its health checks pass.
+func (j *Job) HealthChecks() []HealthCheck {
+	var checks []HealthCheck
+	reqs := j.requirements()
+	for _, typ := range []HealthCheckType{HealthCheckHTTP, HealthCheckTCP, HealthCheckExec} {
+		for _, target := range reqs[healthCheckKeys[typ]] {
+			hc := HealthCheck{Type: typ, Target: target}
+			if typ == HealthCheckExec {
+				// commands run with the privileges of the unit
+				hc.User = lastValue(j.Unit.Contents["Service"]["User"])
+				hc.Group = lastValue(j.Unit.Contents["Service"]["Group"])
+			}
+			checks = append(checks, hc)
+		}
+	}
+	return checks
+}
+
+// lastValue returns the last of the given values of a unit file option, which
+// takes effect if the option is single-valued, or an empty string if there
+// are none.
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// RescheduleOnFailure returns the FailurePolicy of the Job, and whether one
+// is set. Malformed values are ignored. The last value found wins.
+func (j *Job) RescheduleOnFailure() (*FailurePolicy, bool) {
+	values := j.requirements()[fleetRescheduleOnFailure]
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	policy, err := ParseFailurePolicy(values[len(values)-1])
+	if err != nil {
+		return nil, false
+	}
+	return policy, true
+}
+
+// IsBatch returns whether the Job is a batch job, which is done once its
+// unit exits successfully instead of being expected to keep running. The
+// last value found wins.
+func (j *Job) IsBatch() bool {
+	values := j.requirements()[fleetType]
+	if len(values) == 0 {
+		return false
+	}
+	return values[len(values)-1] == UnitTypeBatch
+}
+
+// CompletedTTL returns how long the Job is kept once it completed, and
+// whether it is destroyed at all. It only applies to batch jobs. Malformed
+// and non-positive values are ignored. The last value found wins.
+func (j *Job) CompletedTTL() (time.Duration, bool) {
+	values := j.requirements()[fleetCompletedTTL]
+	if len(values) == 0 || !j.IsBatch() {
+		return 0, false
+	}
+
+	ttl, err := time.ParseDuration(values[len(values)-1])
+	if err != nil || ttl <= 0 {
+		return 0, false
+	}
+	return ttl, true
+}
+
+// CronSchedule returns the schedule at which new instances of the Job are
+// launched, and whether the Job has one. Only template units are launched
+// on a schedule. Malformed values are ignored. The last value found wins.
+func (j *Job) CronSchedule() (*CronSchedule, bool) {
+	values := j.requirements()[fleetSchedule]
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	cs, err := ParseCronSchedule(values[len(values)-1])
+	if err != nil {
+		return nil, false
+	}
+	return cs, true
+}
+
+// ScheduleHistory returns how many finished instances of the Job's
+// schedule are kept, DefaultScheduleHistory unless a valid value is set.
+// The last value found wins.
+func (j *Job) ScheduleHistory() int {
+	values := j.requirements()[fleetScheduleHistory]
+	if len(values) == 0 {
+		return DefaultScheduleHistory
+	}
+
+	n, err := ParseScheduleHistory(values[len(values)-1])
+	if err != nil {
+		return DefaultScheduleHistory
+	}
+	return n
+}
+
+// ConcurrencyPolicy returns whether instances of the Job's schedule may run
+// concurrently, ConcurrencyAllow unless a valid value is set. The last
+// value found wins.
+func (j *Job) ConcurrencyPolicy() ConcurrencyPolicy {
+	values := j.requirements()[fleetConcurrencyPolicy]
+	if len(values) == 0 {
+		return ConcurrencyAllow
+	}
+
+	cp, err := ParseConcurrencyPolicy(values[len(values)-1])
+	if err != nil {
+		return ConcurrencyAllow
+	}
+	return cp
+}
+
 func (j *Job) Scheduled() bool {
 	return len(j.TargetMachineID) > 0
 }
@@ -18,8 +18,10 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/coreos/fleet/pkg"
+	"github.com/coreos/fleet/resource"
 	"github.com/coreos/fleet/unit"
 )
 
@@ -467,6 +469,345 @@ X-ConditionMachineMetadata="one=abc" "two=def"`,
 	}
 }
 
+func TestJobPreferences(t *testing.T) {
+	j := NewJob("echo.service", *newUnit(t, `[X-Fleet]
+PreferMachineMetadata="disk=ssd" "region=us-east-1"
+PreferMachineMetadata=disk=nvme
+PreferMachineOf=cache.service
+AvoidConflicts=db@* web@*
+AvoidConflicts=batch.service`))
+
+	wantMD := map[string]pkg.Set{
+		"disk":   pkg.NewUnsafeSet("ssd", "nvme"),
+		"region": pkg.NewUnsafeSet("us-east-1"),
+	}
+	if md := j.PreferredTargetMetadata(); !reflect.DeepEqual(md, wantMD) {
+		t.Errorf("unexpected preferred metadata: got %#v, want %#v", md, wantMD)
+	}
+	if md := j.RequiredTargetMetadata(); len(md) != 0 {
+		t.Errorf("unexpected required metadata: %#v", md)
+	}
+
+	wantPeers := []string{"cache.service"}
+	if peers := j.PreferredPeers(); !reflect.DeepEqual(peers, wantPeers) {
+		t.Errorf("unexpected preferred peers: got %#v, want %#v", peers, wantPeers)
+	}
+	if peers := j.Peers(); len(peers) != 0 {
+		t.Errorf("unexpected peers: %#v", peers)
+	}
+
+	wantAvoids := []string{"db@*", "web@*", "batch.service"}
+	if avoids := j.AvoidConflicts(); !reflect.DeepEqual(avoids, wantAvoids) {
+		t.Errorf("unexpected avoided conflicts: got %#v, want %#v", avoids, wantAvoids)
+	}
+	if conflicts := j.Conflicts(); len(conflicts) != 0 {
+		t.Errorf("unexpected conflicts: %#v", conflicts)
+	}
+}
+
+func TestJobSpreadBy(t *testing.T) {
+	testCases := []struct {
+		contents string
+		key      string
+		ok       bool
+	}{
+		{``, "", false},
+		{`[X-Fleet]
+SpreadBy=az`, "az", true},
+		{`[X-Fleet]
+SpreadBy=region
+SpreadBy=az`, "az", true},
+		{`[X-Fleet]
+SpreadBy=`, "", false},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo@1.service", *newUnit(t, tt.contents))
+		key, ok := j.SpreadBy()
+		if key != tt.key || ok != tt.ok {
+			t.Errorf("case %d: unexpected SpreadBy: got (%q, %t), want (%q, %t)", i, key, ok, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestJobPriority(t *testing.T) {
+	testCases := []struct {
+		contents string
+		priority int
+	}{
+		{``, 0},
+		{`[X-Fleet]
+Priority=10`, 10},
+		{`[X-Fleet]
+Priority=-5`, -5},
+		{`[X-Fleet]
+Priority=10
+Priority=20`, 20},
+		{`[X-Fleet]
+Priority=high`, 0},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		if priority := j.Priority(); priority != tt.priority {
+			t.Errorf("case %d: unexpected Priority: got %d, want %d", i, priority, tt.priority)
+		}
+	}
+}
+
+func TestJobExcludedMachineIDs(t *testing.T) {
+	testCases := []struct {
+		contents string
+		excluded []string
+	}{
+		{``, []string{}},
+		{`[X-Fleet]
+ExcludeMachineID=123`, []string{"123"}},
+		{`[X-Fleet]
+ExcludeMachineID=123 456
+ExcludeMachineID=789`, []string{"123", "456", "789"}},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		if excluded := j.ExcludedMachineIDs(); !reflect.DeepEqual(excluded, tt.excluded) {
+			t.Errorf("case %d: unexpected ExcludedMachineIDs: got %v, want %v", i, excluded, tt.excluded)
+		}
+	}
+}
+
+func TestJobHealthChecks(t *testing.T) {
+	testCases := []struct {
+		contents string
+		checks   []HealthCheck
+	}{
+		{``, nil},
+		{`[X-Fleet]
+HealthCheckExec=/usr/bin/check %i
+HealthCheckTCP=localhost:80%i
+HealthCheckHTTP=http://localhost:8080/health`, []HealthCheck{
+			{Type: HealthCheckHTTP, Target: "http://localhost:8080/health"},
+			{Type: HealthCheckTCP, Target: "localhost:801"},
+			{Type: HealthCheckExec, Target: "/usr/bin/check 1"},
+		}},
+		// commands run as the user of the unit
+		{`[Service]
+User=root
+User=web
+Group=web
+[X-Fleet]
+HealthCheckExec=/usr/bin/check
+HealthCheckTCP=localhost:80`, []HealthCheck{
+			{Type: HealthCheckTCP, Target: "localhost:80"},
+			{Type: HealthCheckExec, Target: "/usr/bin/check", User: "web", Group: "web"},
+		}},
+	}
+	for i, tt := range testCases {
+		j := NewJob("web@1.service", *newUnit(t, tt.contents))
+		if checks := j.HealthChecks(); !reflect.DeepEqual(checks, tt.checks) {
+			t.Errorf("case %d: unexpected HealthChecks: got %v, want %v", i, checks, tt.checks)
+		}
+	}
+}
+
+func TestJobMaxUnavailable(t *testing.T) {
+	testCases := []struct {
+		contents string
+		max      int
+		ok       bool
+	}{
+		{``, 0, false},
+		{`[X-Fleet]
+MaxUnavailable=1`, 1, true},
+		{`[X-Fleet]
+MaxUnavailable=0`, 0, true},
+		{`[X-Fleet]
+MaxUnavailable=1
+MaxUnavailable=3`, 3, true},
+		{`[X-Fleet]
+MaxUnavailable=-1`, 0, false},
+		{`[X-Fleet]
+MaxUnavailable=some`, 0, false},
+	}
+	for i, tt := range testCases {
+		j := NewJob("web@1.service", *newUnit(t, tt.contents))
+		max, ok := j.MaxUnavailable()
+		if max != tt.max || ok != tt.ok {
+			t.Errorf("case %d: unexpected MaxUnavailable: got %d, %t, want %d, %t", i, max, ok, tt.max, tt.ok)
+		}
+	}
+}
+
+func TestJobRescheduleOnFailure(t *testing.T) {
+	testCases := []struct {
+		contents string
+		policy   *FailurePolicy
+		ok       bool
+	}{
+		{``, nil, false},
+		{`[X-Fleet]
+RescheduleOnFailure=3/10m`, &FailurePolicy{Failures: 3, Window: 10 * time.Minute, Blacklist: DefaultBlacklistPeriod}, true},
+		{`[X-Fleet]
+RescheduleOnFailure=3/10m
+RescheduleOnFailure=5/1h/2h`, &FailurePolicy{Failures: 5, Window: time.Hour, Blacklist: 2 * time.Hour}, true},
+		{`[X-Fleet]
+RescheduleOnFailure=some`, nil, false},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		policy, ok := j.RescheduleOnFailure()
+		if ok != tt.ok || !reflect.DeepEqual(policy, tt.policy) {
+			t.Errorf("case %d: unexpected RescheduleOnFailure: got %v, %t, want %v, %t", i, policy, ok, tt.policy, tt.ok)
+		}
+	}
+}
+
+func TestJobIsBatch(t *testing.T) {
+	testCases := []struct {
+		contents string
+		batch    bool
+	}{
+		{``, false},
+		{`[X-Fleet]
+Type=batch`, true},
+		{`[X-Fleet]
+Type=service`, false},
+		{`[X-Fleet]
+Type=service
+Type=batch`, true},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		if batch := j.IsBatch(); batch != tt.batch {
+			t.Errorf("case %d: unexpected IsBatch: got %t, want %t", i, batch, tt.batch)
+		}
+	}
+}
+
+func TestJobCompletedTTL(t *testing.T) {
+	testCases := []struct {
+		contents string
+		ttl      time.Duration
+		ok       bool
+	}{
+		{``, 0, false},
+		{`[X-Fleet]
+Type=batch
+CompletedTTL=24h`, 24 * time.Hour, true},
+		// only batch units complete
+		{`[X-Fleet]
+CompletedTTL=24h`, 0, false},
+		{`[X-Fleet]
+Type=batch
+CompletedTTL=0s`, 0, false},
+		{`[X-Fleet]
+Type=batch
+CompletedTTL=some`, 0, false},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		ttl, ok := j.CompletedTTL()
+		if ttl != tt.ttl || ok != tt.ok {
+			t.Errorf("case %d: unexpected CompletedTTL: got %v, %t, want %v, %t", i, ttl, ok, tt.ttl, tt.ok)
+		}
+	}
+}
+
+func TestJobCronSchedule(t *testing.T) {
+	testCases := []struct {
+		contents string
+		ok       bool
+		history  int
+		policy   ConcurrencyPolicy
+	}{
+		{``, false, DefaultScheduleHistory, ConcurrencyAllow},
+		{`[X-Fleet]
+Schedule=*/15 * * * *
+ScheduleHistory=5
+ConcurrencyPolicy=forbid`, true, 5, ConcurrencyForbid},
+		{`[X-Fleet]
+Schedule=some
+ScheduleHistory=0
+ConcurrencyPolicy=some`, false, DefaultScheduleHistory, ConcurrencyAllow},
+	}
+	for i, tt := range testCases {
+		j := NewJob("backup@.service", *newUnit(t, tt.contents))
+		if _, ok := j.CronSchedule(); ok != tt.ok {
+			t.Errorf("case %d: unexpected CronSchedule: got %t, want %t", i, ok, tt.ok)
+		}
+		if history := j.ScheduleHistory(); history != tt.history {
+			t.Errorf("case %d: unexpected ScheduleHistory: got %d, want %d", i, history, tt.history)
+		}
+		if policy := j.ConcurrencyPolicy(); policy != tt.policy {
+			t.Errorf("case %d: unexpected ConcurrencyPolicy: got %s, want %s", i, policy, tt.policy)
+		}
+	}
+}
+
+func TestJobRebalanceable(t *testing.T) {
+	testCases := []struct {
+		contents string
+		want     bool
+	}{
+		{``, false},
+		{`[X-Fleet]
+Rebalanceable=true`, true},
+		{`[X-Fleet]
+Rebalanceable=yes`, true},
+		{`[X-Fleet]
+Rebalanceable=true
+Rebalanceable=false`, false},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.contents))
+		if got := j.Rebalanceable(); got != tt.want {
+			t.Errorf("case %d: unexpected Rebalanceable: got %t, want %t", i, got, tt.want)
+		}
+	}
+}
+
+func TestJobResources(t *testing.T) {
+	testCases := []struct {
+		unit string
+		out  resource.ResourceTuple
+	}{
+		// no resources
+		{
+			`[X-Fleet]`,
+			resource.ResourceTuple{},
+		},
+		// all keys on a single line
+		{
+			`[X-Fleet]
+Resources=cores=50 memory=512 disk=1024`,
+			resource.ResourceTuple{Cores: 50, Memory: 512, Disk: 1024},
+		},
+		// keys spread across lines
+		{
+			`[X-Fleet]
+Resources=cores=200
+Resources=memory=128`,
+			resource.ResourceTuple{Cores: 200, Memory: 128},
+		},
+		// last value wins
+		{
+			`[X-Fleet]
+Resources=memory=128 memory=256`,
+			resource.ResourceTuple{Memory: 256},
+		},
+		// bad fields just get ignored
+		{
+			`[X-Fleet]
+Resources=cores=-1 memory=lots disk= gpus=2 cores memory=64`,
+			resource.ResourceTuple{Memory: 64},
+		},
+	}
+	for i, tt := range testCases {
+		j := NewJob("echo.service", *newUnit(t, tt.unit))
+		res := j.Resources()
+		if res != tt.out {
+			t.Errorf("case %d: unexpected resources: got %#v, want %#v", i, res, tt.out)
+		}
+	}
+}
+
 func TestInstanceUnitPrintf(t *testing.T) {
 	u := unit.NewUnitNameInfo("foo@bar.waldo")
 	if u == nil {
@@ -606,6 +947,14 @@ func TestValidateRequirements(t *testing.T) {
 		"MachineMetadata=true=false",
 		"Global=true",
 		"Replaces=foo",
+		"Resources=cores=50 memory=512",
+		"SpreadBy=az",
+		"PreferMachineMetadata=disk=ssd",
+		"PreferMachineOf=cache.service",
+		"AvoidConflicts=db@*",
+		"Priority=10",
+		"Rebalanceable=true",
+		"MaxUnavailable=1",
 	}
 	for i, req := range tests {
 		contents := fmt.Sprintf("[X-Fleet]\n%s", req)
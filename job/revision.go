@@ -0,0 +1,32 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"time"
+
+	"github.com/coreos/fleet/unit"
+)
+
+// UnitRevision is a unit file once submitted under the name of a Unit.
+type UnitRevision struct {
+	// Number identifies the revision among the revisions of the same Unit.
+	// It increases by one with each unit file submitted.
+	Number  int
+	Unit    unit.UnitFile
+	Created time.Time
+	// Client identifies who submitted the unit file, e.g. core@host1
+	Client string
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/vishvananda/netlink"
 
 	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/resource"
 	"github.com/coreos/fleet/unit"
 )
 
@@ -33,11 +34,14 @@ const (
 	machineIDPath = "/etc/machine-id"
 )
 
-func NewCoreOSMachine(static MachineState, um unit.UnitManager) *CoreOSMachine {
+// NewCoreOSMachine creates a CoreOSMachine. The disk resources it reports are
+// those of the filesystem holding unitsDir.
+func NewCoreOSMachine(static MachineState, um unit.UnitManager, unitsDir string) *CoreOSMachine {
 	log.Debugf("Created CoreOSMachine with static state %s", static)
 	m := &CoreOSMachine{
 		staticState: static,
 		um:          um,
+		unitsDir:    unitsDir,
 	}
 	return m
 }
@@ -46,6 +50,7 @@ type CoreOSMachine struct {
 	sync.RWMutex
 
 	um           unit.UnitManager
+	unitsDir     string
 	staticState  MachineState
 	dynamicState *MachineState
 }
@@ -107,11 +112,38 @@ func (m *CoreOSMachine) currentState() *MachineState {
 		return nil
 	}
 	publicIP := getLocalIP()
+	totalResources, err := readLocalResources("/", m.unitsDir)
+	if err != nil {
+		log.Warningf("Unable to determine local resources: %v", err)
+	}
 	return &MachineState{
-		ID:       id,
-		PublicIP: publicIP,
-		Metadata: make(map[string]string, 0),
+		ID:             id,
+		PublicIP:       publicIP,
+		Metadata:       make(map[string]string, 0),
+		TotalResources: totalResources,
+		FreeResources:  freeResources(totalResources),
+	}
+}
+
+// freeResources returns what remains of the given total resources once those
+// reserved for the host are subtracted. Negative components are clamped to
+// zero, and nothing is free if the total is unknown.
+func freeResources(total resource.ResourceTuple) (free resource.ResourceTuple) {
+	if total.Empty() {
+		return
 	}
+
+	free = resource.Sub(total, resource.HostResources)
+	if free.Cores < 0 {
+		free.Cores = 0
+	}
+	if free.Memory < 0 {
+		free.Memory = 0
+	}
+	if free.Disk < 0 {
+		free.Disk = 0
+	}
+	return
 }
 
 // IsLocalMachineID returns whether the given machine ID is equal to that of the local machine
@@ -0,0 +1,100 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machine
+
+import (
+	"errors"
+	"time"
+)
+
+type MaintenancePhase string
+
+const (
+	// MaintenancePending means the window has not started yet and its
+	// machines are still fully in service
+	MaintenancePending = MaintenancePhase("pending")
+	// MaintenanceCordoned means the window is about to start, so its
+	// machines accept no new units
+	MaintenanceCordoned = MaintenancePhase("cordoned")
+	// MaintenanceActive means the window has started, so units are moved
+	// off its machines
+	MaintenanceActive = MaintenancePhase("active")
+	// MaintenanceOver means the window has ended
+	MaintenanceOver = MaintenancePhase("over")
+)
+
+// MaintenanceWindow is a period of time during which a set of machines is
+// taken out of service. The machines are selected either by ID or by
+// metadata.
+type MaintenanceWindow struct {
+	Name string
+	// MachineID selects the machine with the given ID
+	MachineID string
+	// Metadata selects all machines having each of the given key-value
+	// pairs in their metadata
+	Metadata map[string]string
+	// Lead is how long before Start the machines stop accepting new units
+	Lead  time.Duration
+	Start time.Time
+	End   time.Time
+}
+
+// Validate returns an error if the MaintenanceWindow is malformed.
+func (mw *MaintenanceWindow) Validate() error {
+	switch {
+	case mw.Name == "":
+		return errors.New("maintenance window must have a name")
+	case mw.MachineID == "" && len(mw.Metadata) == 0:
+		return errors.New("maintenance window must select machines by ID or by metadata")
+	case mw.MachineID != "" && len(mw.Metadata) != 0:
+		return errors.New("maintenance window cannot select machines both by ID and by metadata")
+	case mw.Lead < 0:
+		return errors.New("maintenance window lead time cannot be negative")
+	case !mw.End.After(mw.Start):
+		return errors.New("maintenance window must end after it starts")
+	}
+	return nil
+}
+
+// Selects determines whether the MaintenanceWindow applies to the given
+// machine.
+func (mw *MaintenanceWindow) Selects(ms *MachineState) bool {
+	if mw.MachineID != "" {
+		return ms.ID == mw.MachineID
+	}
+
+	if len(mw.Metadata) == 0 {
+		return false
+	}
+	for key, value := range mw.Metadata {
+		if local, ok := ms.Metadata[key]; !ok || local != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Phase returns the phase of the MaintenanceWindow at the given time.
+func (mw *MaintenanceWindow) Phase(now time.Time) MaintenancePhase {
+	switch {
+	case !now.Before(mw.End):
+		return MaintenanceOver
+	case !now.Before(mw.Start):
+		return MaintenanceActive
+	case !now.Before(mw.Start.Add(-mw.Lead)):
+		return MaintenanceCordoned
+	}
+	return MaintenancePending
+}
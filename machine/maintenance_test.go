@@ -0,0 +1,92 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	start := time.Date(2016, time.May, 1, 2, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		mw    MaintenanceWindow
+		valid bool
+	}{
+		{MaintenanceWindow{Name: "kernel", MachineID: "XXX", Start: start, End: end}, true},
+		{MaintenanceWindow{Name: "kernel", Metadata: map[string]string{"rack": "r12"}, Lead: time.Minute, Start: start, End: end}, true},
+		{MaintenanceWindow{MachineID: "XXX", Start: start, End: end}, false},
+		{MaintenanceWindow{Name: "kernel", Start: start, End: end}, false},
+		{MaintenanceWindow{Name: "kernel", MachineID: "XXX", Metadata: map[string]string{"rack": "r12"}, Start: start, End: end}, false},
+		{MaintenanceWindow{Name: "kernel", MachineID: "XXX", Lead: -time.Minute, Start: start, End: end}, false},
+		{MaintenanceWindow{Name: "kernel", MachineID: "XXX", Start: end, End: start}, false},
+		{MaintenanceWindow{Name: "kernel", MachineID: "XXX", Start: start, End: start}, false},
+	}
+
+	for i, tt := range tests {
+		err := tt.mw.Validate()
+		if tt.valid != (err == nil) {
+			t.Errorf("case %d: expected valid=%t, got error %v", i, tt.valid, err)
+		}
+	}
+}
+
+func TestMaintenanceWindowSelects(t *testing.T) {
+	ms := &MachineState{ID: "XXX", Metadata: map[string]string{"rack": "r12", "region": "us-west"}}
+
+	tests := []struct {
+		mw   MaintenanceWindow
+		want bool
+	}{
+		{MaintenanceWindow{MachineID: "XXX"}, true},
+		{MaintenanceWindow{MachineID: "YYY"}, false},
+		{MaintenanceWindow{Metadata: map[string]string{"rack": "r12"}}, true},
+		{MaintenanceWindow{Metadata: map[string]string{"rack": "r12", "region": "us-west"}}, true},
+		{MaintenanceWindow{Metadata: map[string]string{"rack": "r12", "region": "us-east"}}, false},
+		{MaintenanceWindow{Metadata: map[string]string{"az": "a"}}, false},
+		{MaintenanceWindow{}, false},
+	}
+
+	for i, tt := range tests {
+		if got := tt.mw.Selects(ms); got != tt.want {
+			t.Errorf("case %d: expected %t, got %t", i, tt.want, got)
+		}
+	}
+}
+
+func TestMaintenanceWindowPhase(t *testing.T) {
+	start := time.Date(2016, time.May, 1, 2, 0, 0, 0, time.UTC)
+	mw := MaintenanceWindow{Lead: 10 * time.Minute, Start: start, End: start.Add(time.Hour)}
+
+	tests := []struct {
+		now  time.Time
+		want MaintenancePhase
+	}{
+		{start.Add(-time.Hour), MaintenancePending},
+		{start.Add(-10 * time.Minute), MaintenanceCordoned},
+		{start.Add(-time.Minute), MaintenanceCordoned},
+		{start, MaintenanceActive},
+		{start.Add(59 * time.Minute), MaintenanceActive},
+		{start.Add(time.Hour), MaintenanceOver},
+	}
+
+	for i, tt := range tests {
+		if got := mw.Phase(tt.now); got != tt.want {
+			t.Errorf("case %d: expected phase %q, got %q", i, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2014 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/coreos/fleet/resource"
+)
+
+const (
+	cpuInfoPath = "/proc/cpuinfo"
+	memInfoPath = "/proc/meminfo"
+)
+
+// readLocalResources determines the total resources of the host. CPU and
+// memory are read from the proc filesystem found under the given root, while
+// disk space is that of the filesystem holding the given directory.
+func readLocalResources(root, dir string) (res resource.ResourceTuple, err error) {
+	cpus, err := readCPUCount(filepath.Join(root, cpuInfoPath))
+	if err != nil {
+		return
+	}
+
+	memory, err := readMemTotal(filepath.Join(root, memInfoPath))
+	if err != nil {
+		return
+	}
+
+	disk, err := readDiskTotal(dir)
+	if err != nil {
+		return
+	}
+
+	res = resource.ResourceTuple{
+		Cores:  cpus * 100,
+		Memory: memory,
+		Disk:   disk,
+	}
+	return
+}
+
+// readCPUCount returns the number of logical CPUs listed in the given
+// cpuinfo file.
+func readCPUCount(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 2)
+		if len(fields) == 2 && strings.TrimSpace(fields[0]) == "processor" {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no processors found in %s", path)
+	}
+
+	return count, nil
+}
+
+// readMemTotal returns the total memory, in MB, from the given meminfo file.
+func readMemTotal(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemTotal:" || fields[2] != "kB" {
+			continue
+		}
+
+		kb, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemTotal in %s: %v", path, err)
+		}
+		return kb / 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, fmt.Errorf("no MemTotal found in %s", path)
+}
+
+// readDiskTotal returns the size, in MB, of the filesystem holding the
+// given directory.
+func readDiskTotal(dir string) (int, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+
+	return int(st.Blocks * uint64(st.Bsize) / (1024 * 1024)), nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2014 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package machine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/fleet/resource"
+)
+
+const (
+	testCPUInfo = `processor	: 0
+vendor_id	: GenuineIntel
+model name	: Intel(R) Xeon(R) CPU
+
+processor	: 1
+vendor_id	: GenuineIntel
+model name	: Intel(R) Xeon(R) CPU
+`
+	testMemInfo = `MemTotal:        4046848 kB
+MemFree:          123456 kB
+MemAvailable:    2345678 kB
+`
+)
+
+func writeProcFile(t *testing.T, root, path, contents string) {
+	fullPath := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.FileMode(0755)); err != nil {
+		t.Fatalf("Failed setting up fake proc path: %v", err)
+	}
+	if err := ioutil.WriteFile(fullPath, []byte(contents), os.FileMode(0644)); err != nil {
+		t.Fatalf("Failed writing fake proc file: %v", err)
+	}
+}
+
+func TestReadLocalResources(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "fleet-")
+	if err != nil {
+		t.Fatalf("Failed creating tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeProcFile(t, dir, cpuInfoPath, testCPUInfo)
+	writeProcFile(t, dir, memInfoPath, testMemInfo)
+
+	res, err := readLocalResources(dir, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error reading resources: %v", err)
+	}
+	if res.Cores != 200 {
+		t.Errorf("Unexpected Cores value %d", res.Cores)
+	}
+	if res.Memory != 3952 {
+		t.Errorf("Unexpected Memory value %d", res.Memory)
+	}
+	if res.Disk <= 0 {
+		t.Errorf("Unexpected Disk value %d", res.Disk)
+	}
+}
+
+func TestReadLocalResourcesMissing(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "fleet-")
+	if err != nil {
+		t.Fatalf("Failed creating tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeProcFile(t, dir, cpuInfoPath, testCPUInfo)
+
+	if _, err := readLocalResources(dir, dir); err == nil {
+		t.Fatal("Expected error for missing meminfo, but got nil")
+	}
+}
+
+func TestFreeResources(t *testing.T) {
+	tests := []struct {
+		total resource.ResourceTuple
+		free  resource.ResourceTuple
+	}{
+		// unknown total
+		{
+			resource.ResourceTuple{},
+			resource.ResourceTuple{},
+		},
+		// host reservation is subtracted
+		{
+			resource.ResourceTuple{Cores: 400, Memory: 4096, Disk: 1024},
+			resource.ResourceTuple{Cores: 300, Memory: 3840, Disk: 1024},
+		},
+		// never negative
+		{
+			resource.ResourceTuple{Cores: 50, Memory: 128, Disk: 1024},
+			resource.ResourceTuple{Cores: 0, Memory: 0, Disk: 1024},
+		},
+	}
+
+	for i, tt := range tests {
+		if got := freeResources(tt.total); got != tt.free {
+			t.Errorf("case %d: got %v, want %v", i, got, tt.free)
+		}
+	}
+}
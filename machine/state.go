@@ -14,6 +14,10 @@
 
 package machine
 
+import (
+	"github.com/coreos/fleet/resource"
+)
+
 const (
 	shortIDLen = 8
 )
@@ -26,6 +30,18 @@ type MachineState struct {
 	Metadata     map[string]string
 	Capabilities Capabilities
 	Version      string
+	// TotalResources describes the resources of the host. It is empty
+	// if the machine does not report them.
+	TotalResources resource.ResourceTuple
+	// FreeResources is TotalResources less what is reserved for the
+	// host itself, i.e. what is left for units to use.
+	FreeResources resource.ResourceTuple
+	// Cordoned is set if the machine accepts no new units. It is
+	// managed through the Registry rather than by the machine itself.
+	Cordoned bool
+	// PausedUnits holds the names of the global units which must not run
+	// on the machine. Like Cordoned, it is managed through the Registry.
+	PausedUnits []string
 }
 
 func (ms MachineState) ShortID() string {
@@ -39,6 +55,17 @@ func (ms MachineState) MatchID(ID string) bool {
 	return ms.ID == ID || ms.ShortID() == ID
 }
 
+// HasPausedUnit returns whether the named global unit is paused on the
+// machine.
+func (ms MachineState) HasPausedUnit(name string) bool {
+	for _, paused := range ms.PausedUnits {
+		if paused == name {
+			return true
+		}
+	}
+	return false
+}
+
 // stackState is used to merge two MachineStates. Values configured on the top
 // MachineState always take precedence over those on the bottom.
 func stackState(top, bottom MachineState) MachineState {
@@ -67,5 +94,13 @@ func stackState(top, bottom MachineState) MachineState {
 		state.Version = top.Version
 	}
 
+	if !top.TotalResources.Empty() {
+		state.TotalResources = top.TotalResources
+	}
+
+	if !top.FreeResources.Empty() {
+		state.FreeResources = top.FreeResources
+	}
+
 	return state
 }
@@ -14,7 +14,11 @@
 
 package machine
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/coreos/fleet/resource"
+)
 
 func TestStackState(t *testing.T) {
 	top := MachineState{
@@ -22,6 +26,14 @@ func TestStackState(t *testing.T) {
 		PublicIP: "1.2.3.4",
 		Metadata: map[string]string{"ping": "pong"},
 		Version:  "1",
+		TotalResources: resource.ResourceTuple{
+			Cores:  400,
+			Memory: 8192,
+		},
+		FreeResources: resource.ResourceTuple{
+			Cores:  300,
+			Memory: 7936,
+		},
 	}
 	bottom := MachineState{
 		ID:       "595989bb-cbb7-49ce-8726-722d6e157b4e",
@@ -46,6 +58,14 @@ func TestStackState(t *testing.T) {
 	if stacked.Version != "1" {
 		t.Errorf("Unexpected Version value %s", stacked.Version)
 	}
+
+	if stacked.TotalResources != top.TotalResources {
+		t.Errorf("Unexpected TotalResources value %v", stacked.TotalResources)
+	}
+
+	if stacked.FreeResources != top.FreeResources {
+		t.Errorf("Unexpected FreeResources value %v", stacked.FreeResources)
+	}
 }
 
 func TestStackStateEmptyTop(t *testing.T) {
@@ -91,6 +111,10 @@ var shortIDTests = []struct {
 			map[string]string{"foo": "bar"},
 			Capabilities{},
 			"",
+			resource.ResourceTuple{},
+			resource.ResourceTuple{},
+			false,
+			nil,
 		},
 		s: "595989bb",
 		l: "595989bb-cbb7-49ce-8726-722d6e157b4e",
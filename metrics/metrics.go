@@ -16,22 +16,24 @@ package metrics
 
 import (
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type (
-	engineFailure string
+	// EngineFailure is the kind of a reconciliation failure of the engine
+	EngineFailure string
 	registryOp    string
 )
 
 const (
 	Namespace = "fleet"
 
-	MachineAway     engineFailure = "machine_away"
-	RunFailure      engineFailure = "run"
-	ScheduleFailure engineFailure = "schedule"
+	MachineAway     EngineFailure = "machine_away"
+	RunFailure      EngineFailure = "run"
+	ScheduleFailure EngineFailure = "schedule"
 	Get             registryOp    = "get"
 	Set             registryOp    = "set"
 	GetAll          registryOp    = "get_all"
@@ -100,6 +102,38 @@ var (
 		Name:      "operation_failed_count_total",
 		Help:      "Counter of failed registry operations.",
 	}, []string{"type"})
+	unitMemoryBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "unit",
+		Name:      "memory_bytes",
+		Help:      "Current memory usage of units in bytes.",
+	}, []string{"unit"})
+
+	unitMemoryPeakBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "unit",
+		Name:      "memory_peak_bytes",
+		Help:      "Highest memory usage of units in bytes.",
+	}, []string{"unit"})
+
+	unitCPUSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "unit",
+		Name:      "cpu_seconds_total",
+		Help:      "Total CPU time consumed by units in seconds.",
+	}, []string{"unit"})
+
+	unitTasks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "unit",
+		Name:      "tasks",
+		Help:      "Number of processes and threads of units.",
+	}, []string{"unit"})
+
+	// unitCPUNSec holds the CPU usage last reported for each unit, as
+	// systemd reports the CPU time a unit consumed since it was started
+	unitCPUNSec   = make(map[string]uint64)
+	unitCPUNSecMu sync.Mutex
 )
 
 func init() {
@@ -108,6 +142,10 @@ func init() {
 	prometheus.MustRegister(engineTaskFailureCount)
 	prometheus.MustRegister(engineReconcileCount)
 	prometheus.MustRegister(engineReconcileFailureCount)
+	prometheus.MustRegister(unitMemoryBytes)
+	prometheus.MustRegister(unitMemoryPeakBytes)
+	prometheus.MustRegister(unitCPUSeconds)
+	prometheus.MustRegister(unitTasks)
 }
 
 func ReportEngineLeader() {
@@ -126,7 +164,7 @@ func ReportEngineReconcileSuccess(start time.Time) {
 	engineReconcileCount.Inc()
 	engineReconcileDuration.Observe(float64(time.Since(start)) / float64(time.Second))
 }
-func ReportEngineReconcileFailure(reason engineFailure) {
+func ReportEngineReconcileFailure(reason EngineFailure) {
 	engineReconcileFailureCount.WithLabelValues(string(reason)).Inc()
 }
 func ReportRegistryOpSuccess(op registryOp, start time.Time) {
@@ -136,3 +174,34 @@ func ReportRegistryOpSuccess(op registryOp, start time.Time) {
 func ReportRegistryOpFailure(op registryOp) {
 	registryOpFailureCount.WithLabelValues(string(op)).Inc()
 }
+
+// ReportUnitUsage records the resource usage of the given unit, as reported
+// by systemd. cpuNSec is the CPU time the unit consumed since it was last
+// started; the CPU time of earlier runs stays accounted.
+func ReportUnitUsage(unit string, memory, memoryPeak, cpuNSec, tasks uint64) {
+	unitMemoryBytes.WithLabelValues(unit).Set(float64(memory))
+	unitMemoryPeakBytes.WithLabelValues(unit).Set(float64(memoryPeak))
+	unitTasks.WithLabelValues(unit).Set(float64(tasks))
+
+	unitCPUNSecMu.Lock()
+	defer unitCPUNSecMu.Unlock()
+	delta := cpuNSec
+	if last, ok := unitCPUNSec[unit]; ok && cpuNSec >= last {
+		delta = cpuNSec - last
+	}
+	unitCPUNSec[unit] = cpuNSec
+	unitCPUSeconds.WithLabelValues(unit).Add(float64(delta) / float64(time.Second))
+}
+
+// ForgetUnitUsage removes the resource usage metrics of the given unit, e.g.
+// once it is no longer active on this host.
+func ForgetUnitUsage(unit string) {
+	unitMemoryBytes.DeleteLabelValues(unit)
+	unitMemoryPeakBytes.DeleteLabelValues(unit)
+	unitTasks.DeleteLabelValues(unit)
+
+	unitCPUNSecMu.Lock()
+	defer unitCPUNSecMu.Unlock()
+	delete(unitCPUNSec, unit)
+	unitCPUSeconds.DeleteLabelValues(unit)
+}
@@ -0,0 +1,408 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/pkg"
+	"github.com/coreos/fleet/unit"
+)
+
+const (
+	// notifyBuffer is the number of state changes queued for each
+	// subscriber of NotifyUnitStates
+	notifyBuffer = 100
+)
+
+// processUnit is a service unit loaded by a processUnitManager
+type processUnit struct {
+	hash        unit.Hash
+	dropInsHash string
+	// svc is nil if the unit file describes a unit that cannot be run as
+	// a process, in which case loadErr tells why
+	svc     *service
+	loadErr error
+
+	state unit.UnitState
+	// ran is set once the unit was started since it was loaded
+	ran bool
+
+	// stop is non-nil while the process of the unit is supervised, and
+	// is closed to stop it
+	stop     chan struct{}
+	stopping bool
+	// done is closed once the supervision of the process ends
+	done chan struct{}
+}
+
+type processUnitManager struct {
+	unitsDir string
+
+	units       map[string]*processUnit
+	subscribers map[chan *unit.UnitStateHeartbeat]struct{}
+	// closed is set once Close was called, after which no process is
+	// started anymore
+	closed bool
+	mutex  sync.Mutex
+}
+
+// NewProcessUnitManager returns a UnitManager that runs service units as
+// child processes of fleetd rather than handing them to systemd. It only
+// supports services that consist of a single ExecStart command, see
+// newService. Unit files are kept in the given directory.
+func NewProcessUnitManager(uDir string) (*processUnitManager, error) {
+	if err := os.MkdirAll(uDir, os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+
+	m := &processUnitManager{
+		unitsDir:    uDir,
+		units:       make(map[string]*processUnit),
+		subscribers: make(map[chan *unit.UnitStateHeartbeat]struct{}),
+	}
+
+	names, err := lsUnitsDir(uDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		b, err := ioutil.ReadFile(m.getUnitFilePath(name))
+		if err != nil {
+			return nil, err
+		}
+		uf, err := unit.NewUnitFile(string(b))
+		if err != nil {
+			return nil, err
+		}
+		dropIns, err := unit.ReadDropIns(uDir, name)
+		if err != nil {
+			return nil, err
+		}
+		m.units[name] = newProcessUnit(name, uf, dropIns)
+	}
+
+	return m, nil
+}
+
+func newProcessUnit(name string, uf *unit.UnitFile, dropIns []unit.DropIn) *processUnit {
+	pu := &processUnit{
+		hash:        uf.Hash(),
+		dropInsHash: unit.HashDropIns(dropIns),
+		state: unit.UnitState{
+			LoadState:   "loaded",
+			ActiveState: "inactive",
+			SubState:    "dead",
+		},
+	}
+
+	merged, err := uf.WithDropIns(dropIns)
+	if err == nil {
+		pu.svc, err = newService(name, merged)
+	}
+	if pu.loadErr = err; pu.loadErr != nil {
+		log.Errorf("Unable to run unit %s as a process: %v", name, pu.loadErr)
+		// systemd reports unit files it cannot load the same way
+		pu.state.LoadState = "error"
+	}
+	return pu
+}
+
+// Load writes the given unit file and drop-ins to disk and prepares to run
+// the unit. A unit that cannot be run as a process is loaded nonetheless,
+// but reported with the "error" load state and fails to start.
+func (m *processUnitManager) Load(name string, uf unit.UnitFile, dropIns []unit.DropIn) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	contents := uf.Bytes()
+	log.Infof("Writing unit %s (%db)", name, len(contents))
+	if err := ioutil.WriteFile(m.getUnitFilePath(name), contents, os.FileMode(0644)); err != nil {
+		return err
+	}
+	if err := unit.WriteDropIns(m.unitsDir, name, dropIns); err != nil {
+		return err
+	}
+
+	pu := newProcessUnit(name, &uf, dropIns)
+	if old, ok := m.units[name]; ok && old.stop != nil {
+		// as with systemd, a running process keeps running and the new
+		// unit file applies once it is restarted
+		pu.state, pu.ran = old.state, old.ran
+		pu.stop, pu.stopping, pu.done = old.stop, old.stopping, old.done
+	}
+	m.units[name] = pu
+	return nil
+}
+
+// Unload stops the process of the indicated unit, if any, and removes the
+// unit from the filesystem.
+func (m *processUnitManager) Unload(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if pu, ok := m.units[name]; ok {
+		m.stopUnit(pu)
+		delete(m.units, name)
+	}
+
+	log.Infof("Removing unit %s", name)
+	err := os.Remove(m.getUnitFilePath(name))
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	if errd := unit.RemoveDropIns(m.unitsDir, name); err == nil {
+		err = errd
+	}
+	return err
+}
+
+// ReloadUnitFiles is a no-op, as units are read when they are loaded.
+func (m *processUnitManager) ReloadUnitFiles() error {
+	return nil
+}
+
+// TriggerStart starts the process of the unit identified by the given name
+// unless it is already running. This function does not wait for the process
+// to exit.
+func (m *processUnitManager) TriggerStart(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return errors.New("unit manager is closed")
+	}
+	pu, ok := m.units[name]
+	if !ok {
+		return fmt.Errorf("unit %s not loaded", name)
+	}
+	if pu.svc == nil {
+		return fmt.Errorf("unit %s failed to load: %v", name, pu.loadErr)
+	}
+
+	if pu.stop != nil {
+		if pu.stopping {
+			// start again once stopped, as a start job queued
+			// behind a stop job would with systemd
+			go func(done chan struct{}) {
+				<-done
+				m.mutex.Lock()
+				defer m.mutex.Unlock()
+				if !m.closed && m.units[name] == pu && pu.stop == nil {
+					m.startUnit(name, pu)
+				}
+			}(pu.done)
+		}
+		return nil
+	}
+
+	m.startUnit(name, pu)
+	return nil
+}
+
+func (m *processUnitManager) startUnit(name string, pu *processUnit) {
+	log.Infof("Starting unit %s", name)
+	pu.ran = true
+	pu.stop = make(chan struct{})
+	pu.stopping = false
+	pu.done = make(chan struct{})
+	go m.supervise(name, pu.svc, pu.stop, pu.done)
+}
+
+// TriggerStop asks the process of the unit identified by the given name to
+// terminate. This function does not wait for the process to exit.
+func (m *processUnitManager) TriggerStop(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if pu, ok := m.units[name]; ok {
+		m.stopUnit(pu)
+	}
+	return nil
+}
+
+func (m *processUnitManager) stopUnit(pu *processUnit) {
+	if pu.stop != nil && !pu.stopping {
+		pu.stopping = true
+		close(pu.stop)
+	}
+}
+
+// Close stops the processes of all units and waits for them to exit. Units
+// are left loaded, but cannot be started anymore. A manager must be closed
+// before another one takes over its units directory, lest the units run
+// twice.
+func (m *processUnitManager) Close() error {
+	m.mutex.Lock()
+	m.closed = true
+	var running []chan struct{}
+	for _, pu := range m.units {
+		if pu.stop != nil {
+			m.stopUnit(pu)
+			running = append(running, pu.done)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, done := range running {
+		<-done
+	}
+	return nil
+}
+
+// Units enumerates all files recognized as valid units in this manager's
+// units directory.
+func (m *processUnitManager) Units() ([]string, error) {
+	return lsUnitsDir(m.unitsDir)
+}
+
+// GetUnitState returns the current state of the named unit. Units that are
+// not loaded are reported as not found, as systemd does.
+func (m *processUnitManager) GetUnitState(name string) (*unit.UnitState, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if us := m.unitState(name); us != nil {
+		return us, nil
+	}
+	return &unit.UnitState{
+		LoadState:   "not-found",
+		ActiveState: "inactive",
+		SubState:    "dead",
+	}, nil
+}
+
+// GetUnitStates returns the current state of the loaded units in the
+// given filter.
+func (m *processUnitManager) GetUnitStates(filter pkg.Set) (map[string]*unit.UnitState, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	states := make(map[string]*unit.UnitState)
+	for _, name := range filter.Values() {
+		if us := m.unitState(name); us != nil {
+			states[name] = us
+		}
+	}
+	return states, nil
+}
+
+// unitState returns a copy of the state of the named unit, or nil if it is
+// not loaded.
+func (m *processUnitManager) unitState(name string) *unit.UnitState {
+	pu, ok := m.units[name]
+	if !ok {
+		return nil
+	}
+	us := pu.state
+	us.UnitHash = pu.hash.String()
+	us.DropInsHash = pu.dropInsHash
+	return &us
+}
+
+// UnitCompleted determines whether the process of the named unit was
+// started since the unit was loaded and has since exited successfully,
+// without being restarted.
+func (m *processUnitManager) UnitCompleted(name string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pu, ok := m.units[name]
+	if !ok {
+		return false, nil
+	}
+	return pu.ran && pu.stop == nil && pu.state.ActiveState == "inactive" && pu.state.Result == "success", nil
+}
+
+// NotifyUnitStates sends the state of a unit to the given channel each time
+// it changes, until stop is closed.
+func (m *processUnitManager) NotifyUnitStates(changes chan<- *unit.UnitStateHeartbeat, stop <-chan struct{}) error {
+	queue := make(chan *unit.UnitStateHeartbeat, notifyBuffer)
+
+	m.mutex.Lock()
+	m.subscribers[queue] = struct{}{}
+	m.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			m.mutex.Lock()
+			delete(m.subscribers, queue)
+			m.mutex.Unlock()
+		}()
+
+		for {
+			select {
+			case hb := <-queue:
+				select {
+				case changes <- hb:
+				case <-stop:
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// update applies the given change to the unit whose process is supervised
+// until done is closed, and notifies the subscribers of NotifyUnitStates.
+// Nothing happens if that unit was unloaded in the meantime.
+func (m *processUnitManager) update(name string, done chan struct{}, change func(*processUnit)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pu, ok := m.units[name]
+	if !ok || pu.done != done {
+		return
+	}
+	change(pu)
+
+	for queue := range m.subscribers {
+		select {
+		case queue <- &unit.UnitStateHeartbeat{Name: name, State: m.unitState(name)}:
+		default:
+			log.Warningf("Dropped state change of unit %s, subscriber is not keeping up", name)
+		}
+	}
+}
+
+func (m *processUnitManager) getUnitFilePath(name string) string {
+	return path.Join(m.unitsDir, name)
+}
+
+func lsUnitsDir(dir string) ([]string, error) {
+	filterFunc := func(name string) bool {
+		if unit.IsDropInDir(name) {
+			return true
+		}
+		if !unit.RecognizedUnitType(name) {
+			log.Warningf("Found unrecognized file in %s, ignoring", path.Join(dir, name))
+			return true
+		}
+
+		return false
+	}
+
+	return pkg.ListDirectory(dir, filterFunc)
+}
@@ -0,0 +1,370 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/pkg"
+	"github.com/coreos/fleet/unit"
+)
+
+func newTestManager(t *testing.T) (*processUnitManager, func()) {
+	dir, err := ioutil.TempDir("", "fleet-process-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewProcessUnitManager(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return m, func() { os.RemoveAll(dir) }
+}
+
+func loadUnit(t *testing.T, m *processUnitManager, name, contents string, dropIns ...unit.DropIn) {
+	uf, err := unit.NewUnitFile(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(name, *uf, dropIns); err != nil {
+		t.Fatalf("unexpected error loading %s: %v", name, err)
+	}
+}
+
+// waitForState waits until the named unit reaches the given active and sub
+// state with the given result, and returns its state.
+func waitForState(t *testing.T, m *processUnitManager, name, active, sub, result string) *unit.UnitState {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		us, err := m.GetUnitState(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if us.ActiveState == active && us.SubState == sub && us.Result == result {
+			return us
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unit %s did not reach %s/%s (%s), last state %#v", name, active, sub, result, us)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestProcessUnitManagerOneshot(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	loadUnit(t, m, "ok.service", "[Service]\nType=oneshot\nExecStart=/bin/sh -c 'exit 0'\n")
+	loadUnit(t, m, "fail.service", "[Service]\nType=oneshot\nExecStart=/bin/sh -c 'exit 3'\n")
+	loadUnit(t, m, "ignore.service", "[Service]\nType=oneshot\nExecStart=-/bin/sh -c 'exit 3'\n")
+
+	if done, _ := m.UnitCompleted("ok.service"); done {
+		t.Errorf("unit reported completed before being started")
+	}
+
+	for _, name := range []string{"ok.service", "fail.service", "ignore.service"} {
+		if err := m.TriggerStart(name); err != nil {
+			t.Fatalf("unexpected error starting %s: %v", name, err)
+		}
+	}
+
+	us := waitForState(t, m, "ok.service", "inactive", "dead", "success")
+	if us.MainPID != 0 {
+		t.Errorf("unexpected state of ok.service: %#v", us)
+	}
+	if done, err := m.UnitCompleted("ok.service"); !done || err != nil {
+		t.Errorf("ok.service not reported completed: %v", err)
+	}
+
+	us = waitForState(t, m, "fail.service", "failed", "failed", "exit-code")
+	if us.ExecMainStatus != 3 {
+		t.Errorf("unexpected state of fail.service: %#v", us)
+	}
+	if done, _ := m.UnitCompleted("fail.service"); done {
+		t.Errorf("fail.service reported completed")
+	}
+
+	us = waitForState(t, m, "ignore.service", "inactive", "dead", "success")
+	if us.ExecMainStatus != 3 {
+		t.Errorf("unexpected state of ignore.service: %#v", us)
+	}
+}
+
+func TestProcessUnitManagerStartStop(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	loadUnit(t, m, "sleep.service", "[Service]\nExecStart=/bin/sleep 60\n")
+
+	units, err := m.Units()
+	if err != nil || len(units) != 1 || units[0] != "sleep.service" {
+		t.Fatalf("unexpected units %v: %v", units, err)
+	}
+
+	if err := m.TriggerStart("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+	us := waitForState(t, m, "sleep.service", "active", "running", "success")
+	if us.MainPID == 0 || us.ActiveEnterTimestamp == 0 || us.LoadState != "loaded" || us.UnitHash == "" {
+		t.Errorf("unexpected state of running unit: %#v", us)
+	}
+
+	// starting a running unit is a no-op
+	if err := m.TriggerStart("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.TriggerStop("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+	us = waitForState(t, m, "sleep.service", "inactive", "dead", "success")
+	if us.MainPID != 0 {
+		t.Errorf("unexpected state of stopped unit: %#v", us)
+	}
+
+	// stopping a stopped unit is a no-op
+	if err := m.TriggerStop("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Unload("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+	if units, err := m.Units(); err != nil || len(units) != 0 {
+		t.Errorf("unexpected units after unload %v: %v", units, err)
+	}
+	if us, _ := m.GetUnitState("sleep.service"); us.LoadState != "not-found" {
+		t.Errorf("unexpected state of unloaded unit: %#v", us)
+	}
+}
+
+func TestProcessUnitManagerRestart(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	loadUnit(t, m, "crash.service", "[Service]\nExecStart=/bin/sh -c 'sleep 0.05; exit 1'\nRestart=on-failure\nRestartSec=10ms\n")
+	if err := m.TriggerStart("crash.service"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		us, _ := m.GetUnitState("crash.service")
+		if us.NRestarts >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unit was not restarted, last state %#v", us)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := m.TriggerStop("crash.service"); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, m, "crash.service", "inactive", "dead", "success")
+}
+
+func TestProcessUnitManagerUnsupportedUnit(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	loadUnit(t, m, "foo.service", "[Service]\nExecStartPre=/bin/true\nExecStart=/bin/true\n")
+
+	us, err := m.GetUnitState("foo.service")
+	if err != nil || us.LoadState != "error" {
+		t.Errorf("unexpected state of unsupported unit %#v: %v", us, err)
+	}
+	if err := m.TriggerStart("foo.service"); err == nil {
+		t.Errorf("expected error starting unsupported unit")
+	}
+}
+
+func TestProcessUnitManagerReadsUnitsDir(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	loadUnit(t, m, "foo.service", "[Service]\nExecStart=/bin/true\n")
+	want, _ := m.GetUnitState("foo.service")
+
+	m, err := NewProcessUnitManager(m.unitsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states, err := m.GetUnitStates(pkg.NewUnsafeSet("foo.service", "bar.service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states["foo.service"] == nil || states["foo.service"].UnitHash != want.UnitHash {
+		t.Errorf("unexpected states %#v", states)
+	}
+}
+
+func TestProcessUnitManagerClose(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	// each process of the unit records its PID
+	pids := path.Join(m.unitsDir, "pids")
+	loadUnit(t, m, "sleep.service", "[Service]\nExecStart=/bin/sh -c 'echo $$$$ >> "+pids+"; exec sleep 60'\n")
+	if err := m.TriggerStart("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, m, "sleep.service", "active", "running", "success")
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("unexpected error closing manager: %v", err)
+	}
+	if err := m.TriggerStart("sleep.service"); err == nil {
+		t.Errorf("expected error starting unit of closed manager")
+	}
+
+	// the manager of a new server takes over the units directory
+	m2, err := NewProcessUnitManager(m.unitsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m2.Close()
+	if err := m2.TriggerStart("sleep.service"); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, m2, "sleep.service", "active", "running", "success")
+
+	b, err := ioutil.ReadFile(pids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	running := 0
+	for _, f := range strings.Fields(string(b)) {
+		pid, err := strconv.Atoi(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if syscall.Kill(pid, 0) == nil {
+			running++
+		}
+	}
+	if running != 1 {
+		t.Errorf("expected unit to run once, found %d processes of %q", running, b)
+	}
+}
+
+func TestProcessUnitManagerEnvironment(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	os.Setenv("FLEET_TEST_SECRET", "secret")
+	defer os.Unsetenv("FLEET_TEST_SECRET")
+
+	out := path.Join(m.unitsDir, "env")
+	loadUnit(t, m, "env.service", "[Service]\nType=oneshot\nEnvironment=FOO=bar\nExecStart=/bin/sh -c 'echo \"$$PATH|$$FOO|$$FLEET_TEST_SECRET\" > "+out+"'\n")
+	if err := m.TriggerStart("env.service"); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, m, "env.service", "inactive", "dead", "success")
+
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(defaultEnvironment[0], "PATH=") + "|bar|\n"
+	if string(b) != want {
+		t.Errorf("expected environment %q, got %q", want, b)
+	}
+}
+
+func TestProcessUnitManagerDropIns(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	dropIns := []unit.DropIn{
+		{Name: "20-exit.conf", Contents: "[Service]\nExecStart=\nExecStart=/bin/sh -c 'exit $CODE'\n"},
+		{Name: "10-env.conf", Contents: "[Service]\nEnvironment=CODE=4\n"},
+	}
+	loadUnit(t, m, "foo.service", "[Service]\nType=oneshot\nExecStart=/bin/true\n", dropIns...)
+
+	us, _ := m.GetUnitState("foo.service")
+	if want := unit.HashDropIns(dropIns); us.DropInsHash != want {
+		t.Errorf("expected drop-ins hash %q, got %q", want, us.DropInsHash)
+	}
+
+	if err := m.TriggerStart("foo.service"); err != nil {
+		t.Fatal(err)
+	}
+	us = waitForState(t, m, "foo.service", "failed", "failed", "exit-code")
+	if us.ExecMainStatus != 4 {
+		t.Errorf("drop-ins not applied, unexpected state %#v", us)
+	}
+
+	// the drop-ins are read back from disk
+	m2, err := NewProcessUnitManager(m.unitsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us2, _ := m2.GetUnitState("foo.service"); us2.DropInsHash != us.DropInsHash {
+		t.Errorf("expected drop-ins hash %q after restart, got %q", us.DropInsHash, us2.DropInsHash)
+	}
+	if units, err := m2.Units(); err != nil || len(units) != 1 {
+		t.Errorf("unexpected units %v: %v", units, err)
+	}
+
+	if err := m.Unload("foo.service"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(unit.DropInDir(m.unitsDir, "foo.service")); !os.IsNotExist(err) {
+		t.Errorf("drop-ins not removed: %v", err)
+	}
+}
+
+func TestProcessUnitManagerNotify(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	changes := make(chan *unit.UnitStateHeartbeat)
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := m.NotifyUnitStates(changes, stop); err != nil {
+		t.Fatal(err)
+	}
+
+	loadUnit(t, m, "ok.service", "[Service]\nType=oneshot\nExecStart=/bin/true\n")
+	if err := m.TriggerStart("ok.service"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case hb := <-changes:
+			if hb.Name != "ok.service" {
+				t.Fatalf("unexpected heartbeat for %s", hb.Name)
+			}
+			got = append(got, hb.State.ActiveState+"/"+hb.State.SubState)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for state changes, got %v", got)
+		}
+	}
+	if got[0] != "activating/start" || got[1] != "inactive/dead" {
+		t.Errorf("unexpected state changes %v", got)
+	}
+}
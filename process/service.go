@@ -0,0 +1,351 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coreos/fleet/unit"
+)
+
+const (
+	serviceSimple  = "simple"
+	serviceOneshot = "oneshot"
+
+	restartNo        = "no"
+	restartAlways    = "always"
+	restartOnSuccess = "on-success"
+	restartOnFailure = "on-failure"
+
+	// defaults as documented in systemd.service(5)
+	defaultRestartSec     = 100 * time.Millisecond
+	defaultTimeoutStopSec = 90 * time.Second
+)
+
+// serviceOptions are the options of the [Service] section a service must be
+// described with. Options of other sections, e.g. dependencies in [Unit],
+// are ignored.
+var serviceOptions = map[string]bool{
+	"Type":             true,
+	"ExecStart":        true,
+	"Environment":      true,
+	"WorkingDirectory": true,
+	"Restart":          true,
+	"RestartSec":       true,
+	"TimeoutStopSec":   true,
+}
+
+// service describes how to run the process of a service unit
+type service struct {
+	Type string
+	// Args holds the command line of the process, with specifiers and
+	// environment variables already expanded
+	Args []string
+	// IgnoreFailure is set by prefixing ExecStart with "-", so that the
+	// service does not fail whatever the exit status of its process
+	IgnoreFailure    bool
+	Environment      []string
+	WorkingDirectory string
+	Restart          string
+	RestartSec       time.Duration
+	TimeoutStopSec   time.Duration
+}
+
+// newService builds the service described by the given unit file, failing
+// if the unit file uses anything this package cannot run.
+func newService(name string, uf *unit.UnitFile) (*service, error) {
+	if !strings.HasSuffix(name, ".service") {
+		return nil, errors.New("only service units are supported")
+	}
+
+	var unsupported []string
+	for opt := range uf.Contents["Service"] {
+		if !serviceOptions[opt] {
+			unsupported = append(unsupported, opt)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return nil, fmt.Errorf("unsupported [Service] options: %s", strings.Join(unsupported, ", "))
+	}
+
+	svc := &service{
+		Type:           serviceSimple,
+		Restart:        restartNo,
+		RestartSec:     defaultRestartSec,
+		TimeoutStopSec: defaultTimeoutStopSec,
+	}
+
+	// as with systemd, an empty assignment resets an option, e.g. so that
+	// a drop-in can replace ExecStart
+	values := func(opt string) []string {
+		v := uf.Contents["Service"][opt]
+		for i := len(v) - 1; i >= 0; i-- {
+			if v[i] == "" {
+				return v[i+1:]
+			}
+		}
+		return v
+	}
+	last := func(opt string) string {
+		if v := values(opt); len(v) > 0 {
+			return v[len(v)-1]
+		}
+		return ""
+	}
+
+	if v := last("Type"); v != "" {
+		if v != serviceSimple && v != serviceOneshot {
+			return nil, fmt.Errorf("unsupported Type %q, must be %s or %s", v, serviceSimple, serviceOneshot)
+		}
+		svc.Type = v
+	}
+
+	if v := last("Restart"); v != "" {
+		switch v {
+		case restartNo, restartOnFailure:
+		case restartAlways, restartOnSuccess:
+			if svc.Type == serviceOneshot {
+				return nil, fmt.Errorf("Restart=%s is not allowed for %s services", v, serviceOneshot)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported Restart %q", v)
+		}
+		svc.Restart = v
+	}
+
+	for _, opt := range []string{"RestartSec", "TimeoutStopSec"} {
+		v := last(opt)
+		if v == "" {
+			continue
+		}
+		d, err := parseSeconds(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", opt, err)
+		}
+		if opt == "RestartSec" {
+			svc.RestartSec = d
+		} else {
+			svc.TimeoutStopSec = d
+		}
+	}
+
+	var err error
+	if svc.WorkingDirectory, err = expandSpecifiers(name, last("WorkingDirectory")); err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	for _, line := range values("Environment") {
+		if line, err = expandSpecifiers(name, line); err != nil {
+			return nil, err
+		}
+		words, err := splitWords(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Environment: %v", err)
+		}
+		for _, w := range words {
+			kv := strings.SplitN(w, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("invalid Environment assignment %q", w)
+			}
+			if _, ok := env[kv[0]]; !ok {
+				svc.Environment = append(svc.Environment, kv[0])
+			}
+			env[kv[0]] = kv[1]
+		}
+	}
+	for i, k := range svc.Environment {
+		svc.Environment[i] = k + "=" + env[k]
+	}
+
+	if len(values("ExecStart")) != 1 {
+		return nil, errors.New("exactly one ExecStart is required")
+	}
+	cmdline, err := expandSpecifiers(name, last("ExecStart"))
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(cmdline, "-") {
+		svc.IgnoreFailure = true
+		cmdline = cmdline[1:]
+	}
+	if strings.IndexAny(cmdline, "@+!:") == 0 {
+		return nil, fmt.Errorf("unsupported ExecStart prefix %q", cmdline[0])
+	}
+	words, err := splitWords(cmdline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ExecStart: %v", err)
+	}
+	for _, w := range words {
+		svc.Args = append(svc.Args, expandEnvironment(w, env)...)
+	}
+	if len(svc.Args) == 0 {
+		return nil, errors.New("ExecStart has no command")
+	}
+
+	return svc, nil
+}
+
+// expandSpecifiers replaces the specifiers describing the unit name and the
+// host name in the given value of a unit file option, see
+// systemd.unit(5). Other specifiers cannot be resolved without systemd.
+func expandSpecifiers(name, value string) (string, error) {
+	if !strings.Contains(value, "%") {
+		return value, nil
+	}
+
+	nu := unit.NewUnitNameInfo(name)
+	if nu == nil {
+		return "", fmt.Errorf("invalid unit name %q", name)
+	}
+
+	var b bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		if i == len(value) {
+			return "", fmt.Errorf("incomplete specifier in %q", value)
+		}
+		switch value[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'n':
+			b.WriteString(nu.FullName)
+		case 'N':
+			b.WriteString(nu.Name)
+		case 'p':
+			b.WriteString(nu.Prefix)
+		case 'i':
+			b.WriteString(nu.Instance)
+		case 'H':
+			host, err := os.Hostname()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(host)
+		default:
+			return "", fmt.Errorf("unsupported specifier %%%c in %q", value[i], value)
+		}
+	}
+	return b.String(), nil
+}
+
+// expandEnvironment replaces references to the given environment variables
+// in a word of a command line. As with systemd, a word consisting only of
+// an unbraced reference, e.g. $ARGS, is split into one word per
+// whitespace-separated value, while ${ARGS} is replaced as a single word.
+func expandEnvironment(word string, env map[string]string) []string {
+	mapping := func(key string) string {
+		if key == "$" {
+			return "$"
+		}
+		return env[key]
+	}
+
+	if len(word) > 1 && word[0] == '$' && word[1] != '{' && isVariableName(word[1:]) {
+		return strings.Fields(env[word[1:]])
+	}
+	return []string{os.Expand(word, mapping)}
+}
+
+func isVariableName(s string) bool {
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !(i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitWords splits a line into whitespace-separated words, honoring single
+// and double quotes as well as backslash escapes.
+func splitWords(line string) ([]string, error) {
+	var (
+		words  []string
+		word   bytes.Buffer
+		quote  byte
+		inWord bool
+	)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			i++
+			if i == len(line) {
+				return nil, errors.New("trailing backslash")
+			}
+			word.WriteByte(line[i])
+			inWord = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// parseSeconds parses a time span given either as a number of seconds or as
+// a duration such as "500ms" or "1m30s".
+func parseSeconds(v string) (time.Duration, error) {
+	if s, err := strconv.ParseFloat(v, 64); err == nil {
+		if s < 0 {
+			return 0, fmt.Errorf("negative time span %q", v)
+		}
+		return time.Duration(s * float64(time.Second)), nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative time span %q", v)
+	}
+	return d, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/fleet/unit"
+)
+
+func TestNewService(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		want     *service
+		wantErr  bool
+	}{
+		// defaults
+		{
+			name:     "foo.service",
+			contents: "[Unit]\nDescription=foo\nAfter=bar.service\n[Service]\nExecStart=/bin/foo --bar\n[X-Fleet]\nConflicts=foo*\n",
+			want: &service{
+				Type:           serviceSimple,
+				Args:           []string{"/bin/foo", "--bar"},
+				Restart:        restartNo,
+				RestartSec:     defaultRestartSec,
+				TimeoutStopSec: defaultTimeoutStopSec,
+			},
+		},
+		// all supported options
+		{
+			name: "foo@1.service",
+			contents: `[Service]
+Type=oneshot
+Environment="GREETING=hello world" NAME=%i
+Environment=ARGS="-a -b"
+ExecStart=-/bin/echo "${GREETING}" $NAME $ARGS %p %n %H 100%% $$
+WorkingDirectory=/tmp/%N
+Restart=on-failure
+RestartSec=5
+TimeoutStopSec=500ms
+`,
+			want: &service{
+				Type:             serviceOneshot,
+				Args:             []string{"/bin/echo", "hello world", "1", "-a", "-b", "foo", "foo@1.service", host, "100%", "$"},
+				IgnoreFailure:    true,
+				Environment:      []string{"GREETING=hello world", "NAME=1", "ARGS=-a -b"},
+				WorkingDirectory: "/tmp/foo@1",
+				Restart:          restartOnFailure,
+				RestartSec:       5 * time.Second,
+				TimeoutStopSec:   500 * time.Millisecond,
+			},
+		},
+		// not a service
+		{
+			name:     "foo.timer",
+			contents: "[Timer]\nOnCalendar=daily\n",
+			wantErr:  true,
+		},
+		// no ExecStart
+		{
+			name:     "foo.service",
+			contents: "[Service]\nType=oneshot\n",
+			wantErr:  true,
+		},
+		// more than one ExecStart
+		{
+			name:     "foo.service",
+			contents: "[Service]\nType=oneshot\nExecStart=/bin/foo\nExecStart=/bin/bar\n",
+			wantErr:  true,
+		},
+		// unsupported options
+		{
+			name:     "foo.service",
+			contents: "[Service]\nExecStartPre=/bin/bar\nExecStart=/bin/foo\n",
+			wantErr:  true,
+		},
+		{
+			name:     "foo.service",
+			contents: "[Service]\nType=forking\nExecStart=/bin/foo\n",
+			wantErr:  true,
+		},
+		{
+			name:     "foo.service",
+			contents: "[Service]\nType=oneshot\nRestart=always\nExecStart=/bin/foo\n",
+			wantErr:  true,
+		},
+		{
+			name:     "foo.service",
+			contents: "[Service]\nExecStart=@/bin/foo foo\n",
+			wantErr:  true,
+		},
+		{
+			name:     "foo.service",
+			contents: "[Service]\nExecStart=/bin/foo %m\n",
+			wantErr:  true,
+		},
+		// malformed values
+		{
+			name:     "foo.service",
+			contents: "[Service]\nExecStart=/bin/foo \"bar\n",
+			wantErr:  true,
+		},
+		{
+			name:     "foo.service",
+			contents: "[Service]\nEnvironment=FOO\nExecStart=/bin/foo\n",
+			wantErr:  true,
+		},
+		{
+			name:     "foo.service",
+			contents: "[Service]\nRestartSec=soon\nExecStart=/bin/foo\n",
+			wantErr:  true,
+		},
+	}
+
+	for i, tt := range tests {
+		uf, err := unit.NewUnitFile(tt.contents)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		svc, err := newService(tt.name, uf)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("case %d: expected error, got %#v", i, svc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, svc) {
+			t.Errorf("case %d: unexpected service\nwant %#v\ngot  %#v", i, tt.want, svc)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  foo  bar\tbaz ", []string{"foo", "bar", "baz"}},
+		{`foo "bar baz" 'qux "quux"'`, []string{"foo", "bar baz", `qux "quux"`}},
+		{`foo\ bar "" x""y`, []string{"foo bar", "", "xy"}},
+	}
+
+	for i, tt := range tests {
+		got, err := splitWords(tt.line)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: want %q, got %q", i, tt.want, got)
+		}
+	}
+
+	for _, line := range []string{`foo "bar`, `foo 'bar`, `foo\`} {
+		if got, err := splitWords(line); err == nil {
+			t.Errorf("expected error splitting %q, got %q", line, got)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/coreos/fleet/log"
+)
+
+const (
+	// statusExec is the exit status systemd reports for a service whose
+	// command could not be executed
+	statusExec = 203
+)
+
+// defaultEnvironment is the environment processes start from, that systemd
+// gives services as well.
+var defaultEnvironment = []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}
+
+// supervise runs the process of the named unit, restarting it as the Restart
+// policy of the service requires, until it exits for good or stop is
+// closed. The state of the unit is updated along the way, following the
+// states systemd reports for services.
+func (m *processUnitManager) supervise(name string, svc *service, stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		status, result := m.run(name, svc, stop, done)
+
+		restart := !isClosed(stop) && shouldRestart(svc.Restart, result)
+		if restart {
+			log.Infof("Process of unit %s exited with status %d (%s), restarting in %v", name, status, result, svc.RestartSec)
+			m.update(name, done, func(pu *processUnit) {
+				pu.state.ActiveState, pu.state.SubState = "activating", "auto-restart"
+				pu.state.MainPID, pu.state.ExecMainStatus, pu.state.Result = 0, status, result
+			})
+
+			select {
+			case <-time.After(svc.RestartSec):
+			case <-stop:
+				restart = false
+			}
+		}
+
+		if restart {
+			m.update(name, done, func(pu *processUnit) {
+				pu.state.NRestarts++
+				// a unit file loaded meanwhile applies from now on
+				if pu.svc != nil {
+					svc = pu.svc
+				}
+			})
+			continue
+		}
+
+		log.Infof("Process of unit %s exited with status %d (%s)", name, status, result)
+		m.update(name, done, func(pu *processUnit) {
+			pu.state.ActiveState, pu.state.SubState = "inactive", "dead"
+			if result != "success" {
+				pu.state.ActiveState, pu.state.SubState = "failed", "failed"
+			}
+			pu.state.MainPID, pu.state.ExecMainStatus, pu.state.Result = 0, status, result
+			pu.stop, pu.stopping = nil, false
+		})
+		return
+	}
+}
+
+// run runs the process of a service once, terminating it if stop is closed
+// meanwhile. It returns the exit status of the process, or the number of
+// the signal that killed it, along with the result of the run as systemd
+// would report it.
+func (m *processUnitManager) run(name string, svc *service, stop, done chan struct{}) (int32, string) {
+	cmd := svc.command()
+	if err := cmd.Start(); err != nil {
+		log.Errorf("Failed to start process of unit %s: %v", name, err)
+		if svc.IgnoreFailure {
+			return statusExec, "success"
+		}
+		return statusExec, "exit-code"
+	}
+
+	pid := cmd.Process.Pid
+	m.update(name, done, func(pu *processUnit) {
+		if svc.Type == serviceOneshot {
+			pu.state.ActiveState, pu.state.SubState = "activating", "start"
+		} else {
+			pu.state.ActiveState, pu.state.SubState = "active", "running"
+			pu.state.ActiveEnterTimestamp = uint64(time.Now().UnixNano() / int64(time.Microsecond))
+		}
+		pu.state.MainPID, pu.state.ExecMainStatus, pu.state.Result = uint32(pid), 0, "success"
+	})
+
+	waitc := make(chan error, 1)
+	go func() {
+		waitc <- cmd.Wait()
+	}()
+
+	timedOut := false
+	select {
+	case <-waitc:
+	case <-stop:
+		m.update(name, done, func(pu *processUnit) {
+			pu.state.ActiveState, pu.state.SubState = "deactivating", "stop-sigterm"
+		})
+
+		// the process runs in a process group of its own, so that
+		// its children are terminated along with it
+		syscall.Kill(-pid, syscall.SIGTERM)
+		timer := time.NewTimer(svc.TimeoutStopSec)
+		select {
+		case <-waitc:
+			timer.Stop()
+		case <-timer.C:
+			log.Warningf("Process of unit %s did not terminate within %v, killing it", name, svc.TimeoutStopSec)
+			syscall.Kill(-pid, syscall.SIGKILL)
+			<-waitc
+			timedOut = true
+		}
+	}
+
+	status, result := exitResult(cmd.ProcessState)
+	switch {
+	case timedOut:
+		result = "timeout"
+	case svc.IgnoreFailure:
+		result = "success"
+	}
+	return status, result
+}
+
+// exitResult returns the exit status of a process, or the number of the
+// signal that killed it, along with the result of the service. As with
+// systemd, being terminated by SIGHUP, SIGINT, SIGTERM or SIGPIPE counts as
+// a clean exit.
+func exitResult(ps *os.ProcessState) (int32, string) {
+	ws, ok := ps.Sys().(syscall.WaitStatus)
+	if !ok {
+		if ps.Success() {
+			return 0, "success"
+		}
+		return 1, "exit-code"
+	}
+
+	if ws.Signaled() {
+		switch sig := ws.Signal(); sig {
+		case syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGPIPE:
+			return int32(sig), "success"
+		default:
+			return int32(sig), "signal"
+		}
+	}
+
+	if status := ws.ExitStatus(); status != 0 {
+		return int32(status), "exit-code"
+	}
+	return 0, "success"
+}
+
+// shouldRestart determines whether a process that exited with the given
+// result is to be restarted according to the given Restart policy.
+func shouldRestart(policy, result string) bool {
+	switch policy {
+	case restartAlways:
+		return true
+	case restartOnSuccess:
+		return result == "success"
+	case restartOnFailure:
+		return result != "success"
+	}
+	return false
+}
+
+// command returns the command running the process of the service. As with
+// systemd, the process does not inherit the environment of fleetd: it only
+// gets defaultEnvironment, extended or overridden by the Environment option.
+// Its standard input is /dev/null, while it writes to the standard output
+// and error of fleetd.
+func (svc *service) command() *exec.Cmd {
+	cmd := exec.Command(svc.Args[0], svc.Args[1:]...)
+	cmd.Dir = svc.WorkingDirectory
+	cmd.Env = mergeEnvironment(defaultEnvironment, svc.Environment)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+		// the process must not outlive fleetd, as a new fleetd would
+		// start the unit again
+		Pdeathsig: syscall.SIGKILL,
+	}
+	return cmd
+}
+
+// mergeEnvironment returns the given environment with the variables
+// assigned in overrides replaced or added.
+func mergeEnvironment(env, overrides []string) []string {
+	overridden := make(map[string]bool, len(overrides))
+	for _, kv := range overrides {
+		overridden[strings.SplitN(kv, "=", 2)[0]] = true
+	}
+
+	merged := make([]string, 0, len(env)+len(overrides))
+	for _, kv := range env {
+		if !overridden[strings.SplitN(kv, "=", 2)[0]] {
+			merged = append(merged, kv)
+		}
+	}
+	return append(merged, overrides...)
+}
+
+func isClosed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
@@ -261,12 +261,22 @@ func (m *UnitStates) GetUnitStates() []*UnitState {
 }
 
 type UnitState struct {
-	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Hash        string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
-	LoadState   string `protobuf:"bytes,3,opt,name=load_state,json=loadState,proto3" json:"load_state,omitempty"`
-	ActiveState string `protobuf:"bytes,4,opt,name=active_state,json=activeState,proto3" json:"active_state,omitempty"`
-	SubState    string `protobuf:"bytes,5,opt,name=sub_state,json=subState,proto3" json:"sub_state,omitempty"`
-	MachineID   string `protobuf:"bytes,6,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	Name                 string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Hash                 string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	LoadState            string `protobuf:"bytes,3,opt,name=load_state,json=loadState,proto3" json:"load_state,omitempty"`
+	ActiveState          string `protobuf:"bytes,4,opt,name=active_state,json=activeState,proto3" json:"active_state,omitempty"`
+	SubState             string `protobuf:"bytes,5,opt,name=sub_state,json=subState,proto3" json:"sub_state,omitempty"`
+	MachineID            string `protobuf:"bytes,6,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	Health               string `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
+	MainPID              uint32 `protobuf:"varint,8,opt,name=main_pid,json=mainPid,proto3" json:"main_pid,omitempty"`
+	ExecMainStatus       int32  `protobuf:"varint,9,opt,name=exec_main_status,json=execMainStatus,proto3" json:"exec_main_status,omitempty"`
+	NRestarts            uint32 `protobuf:"varint,10,opt,name=n_restarts,json=nRestarts,proto3" json:"n_restarts,omitempty"`
+	ActiveEnterTimestamp uint64 `protobuf:"varint,11,opt,name=active_enter_timestamp,json=activeEnterTimestamp,proto3" json:"active_enter_timestamp,omitempty"`
+	Result               string `protobuf:"bytes,12,opt,name=result,proto3" json:"result,omitempty"`
+	MemoryCurrent        uint64 `protobuf:"varint,13,opt,name=memory_current,json=memoryCurrent,proto3" json:"memory_current,omitempty"`
+	MemoryPeak           uint64 `protobuf:"varint,14,opt,name=memory_peak,json=memoryPeak,proto3" json:"memory_peak,omitempty"`
+	CPUUsageNSec         uint64 `protobuf:"varint,15,opt,name=cpu_usage_nsec,json=cpuUsageNsec,proto3" json:"cpu_usage_nsec,omitempty"`
+	Tasks                uint64 `protobuf:"varint,16,opt,name=tasks,proto3" json:"tasks,omitempty"`
 }
 
 func (m *UnitState) Reset()                    { *m = UnitState{} }
@@ -1722,6 +1732,60 @@ func (m *UnitState) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintFleet(dAtA, i, uint64(len(m.MachineID)))
 		i += copy(dAtA[i:], m.MachineID)
 	}
+	if len(m.Health) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(len(m.Health)))
+		i += copy(dAtA[i:], m.Health)
+	}
+	if m.MainPID != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.MainPID))
+	}
+	if m.ExecMainStatus != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.ExecMainStatus))
+	}
+	if m.NRestarts != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.NRestarts))
+	}
+	if m.ActiveEnterTimestamp != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.ActiveEnterTimestamp))
+	}
+	if len(m.Result) > 0 {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(len(m.Result)))
+		i += copy(dAtA[i:], m.Result)
+	}
+	if m.MemoryCurrent != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.MemoryCurrent))
+	}
+	if m.MemoryPeak != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.MemoryPeak))
+	}
+	if m.CPUUsageNSec != 0 {
+		dAtA[i] = 0x78
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.CPUUsageNSec))
+	}
+	if m.Tasks != 0 {
+		dAtA[i] = 0x80
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintFleet(dAtA, i, uint64(m.Tasks))
+	}
 	return i, nil
 }
 
@@ -2268,6 +2332,38 @@ func (m *UnitState) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovFleet(uint64(l))
 	}
+	l = len(m.Health)
+	if l > 0 {
+		n += 1 + l + sovFleet(uint64(l))
+	}
+	if m.MainPID != 0 {
+		n += 1 + sovFleet(uint64(m.MainPID))
+	}
+	if m.ExecMainStatus != 0 {
+		n += 1 + sovFleet(uint64(m.ExecMainStatus))
+	}
+	if m.NRestarts != 0 {
+		n += 1 + sovFleet(uint64(m.NRestarts))
+	}
+	if m.ActiveEnterTimestamp != 0 {
+		n += 1 + sovFleet(uint64(m.ActiveEnterTimestamp))
+	}
+	l = len(m.Result)
+	if l > 0 {
+		n += 1 + l + sovFleet(uint64(l))
+	}
+	if m.MemoryCurrent != 0 {
+		n += 1 + sovFleet(uint64(m.MemoryCurrent))
+	}
+	if m.MemoryPeak != 0 {
+		n += 1 + sovFleet(uint64(m.MemoryPeak))
+	}
+	if m.CPUUsageNSec != 0 {
+		n += 1 + sovFleet(uint64(m.CPUUsageNSec))
+	}
+	if m.Tasks != 0 {
+		n += 2 + sovFleet(uint64(m.Tasks))
+	}
 	return n
 }
 
@@ -3926,6 +4022,216 @@ func (m *UnitState) Unmarshal(dAtA []byte) error {
 			}
 			m.MachineID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Health", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFleet
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Health = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MainPID", wireType)
+			}
+			m.MainPID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MainPID |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExecMainStatus", wireType)
+			}
+			m.ExecMainStatus = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExecMainStatus |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NRestarts", wireType)
+			}
+			m.NRestarts = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NRestarts |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ActiveEnterTimestamp", wireType)
+			}
+			m.ActiveEnterTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ActiveEnterTimestamp |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Result", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFleet
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Result = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryCurrent", wireType)
+			}
+			m.MemoryCurrent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryCurrent |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryPeak", wireType)
+			}
+			m.MemoryPeak = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryPeak |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CPUUsageNSec", wireType)
+			}
+			m.CPUUsageNSec = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CPUUsageNSec |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tasks", wireType)
+			}
+			m.Tasks = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFleet
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Tasks |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFleet(dAtA[iNdEx:])
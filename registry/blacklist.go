@@ -0,0 +1,71 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"time"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+)
+
+const (
+	// Namespace for machines units are temporarily barred from
+	blacklistPrefix = "/blacklist/"
+)
+
+func (r *EtcdRegistry) blacklistPath(name, machID string) string {
+	return r.prefixed(blacklistPrefix, name, machID)
+}
+
+// UnitBlacklist maps the name of each unit that is temporarily barred from
+// one or more machines to the sorted IDs of those machines.
+func (r *EtcdRegistry) UnitBlacklist() (map[string][]string, error) {
+	key := r.prefixed(blacklistPrefix)
+	res, err := r.kAPI.Get(context.Background(), key, &etcd.GetOptions{Recursive: true})
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	blacklist := make(map[string][]string)
+	for _, dir := range res.Node.Nodes {
+		name := path.Base(dir.Key)
+		for _, node := range dir.Nodes {
+			blacklist[name] = append(blacklist[name], path.Base(node.Key))
+		}
+		sort.Strings(blacklist[name])
+	}
+	return blacklist, nil
+}
+
+// BlacklistUnit bars the named unit from the given machine for the given
+// period, after which the machine may run it again.
+func (r *EtcdRegistry) BlacklistUnit(name, machID string, ttl time.Duration) error {
+	if ttl < time.Second {
+		return errors.New("blacklist period must be at least 1s")
+	}
+
+	opts := &etcd.SetOptions{
+		TTL: ttl,
+	}
+	_, err := r.kAPI.Set(context.Background(), r.blacklistPath(name, machID), "", opts)
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"path"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/coreos/fleet/job"
+)
+
+const (
+	// Namespace for completions of batch units
+	completionPrefix = "/completed/"
+)
+
+func (r *EtcdRegistry) completionPath(name string) string {
+	return r.prefixed(completionPrefix, name)
+}
+
+// CompletedUnits maps the name of each batch unit that ran to completion to
+// its Completion.
+func (r *EtcdRegistry) CompletedUnits() (map[string]job.Completion, error) {
+	key := r.prefixed(completionPrefix)
+	res, err := r.kAPI.Get(context.Background(), key, &etcd.GetOptions{Recursive: true})
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	completions := make(map[string]job.Completion)
+	for _, node := range res.Node.Nodes {
+		var c job.Completion
+		if err := unmarshal(node.Value, &c); err != nil {
+			return nil, err
+		}
+		completions[path.Base(node.Key)] = c
+	}
+	return completions, nil
+}
+
+// SetUnitCompleted records that the named batch unit ran to completion. If
+// the unit already completed, its original Completion is kept.
+func (r *EtcdRegistry) SetUnitCompleted(name string, c job.Completion) error {
+	val, err := marshal(c)
+	if err != nil {
+		return err
+	}
+
+	opts := &etcd.SetOptions{
+		PrevExist: etcd.PrevNoExist,
+	}
+	_, err = r.kAPI.Set(context.Background(), r.completionPath(name), val, opts)
+	if isEtcdError(err, etcd.ErrorCodeNodeExist) {
+		err = nil
+	}
+	return err
+}
+
+// clearUnitCompletion forgets that the named unit completed, so that it
+// runs again.
+func (r *EtcdRegistry) clearUnitCompletion(name string) error {
+	_, err := r.kAPI.Delete(context.Background(), r.completionPath(name), nil)
+	if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"errors"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/coreos/fleet/job"
+)
+
+const (
+	// Namespace for deployments of template units
+	deploymentPrefix = "/deployment/"
+)
+
+func (r *EtcdRegistry) deploymentPath(template string) string {
+	return r.prefixed(deploymentPrefix, template)
+}
+
+// Deployments lists all Deployments stored in the Registry, ordered by
+// template name.
+func (r *EtcdRegistry) Deployments() ([]job.Deployment, error) {
+	key := r.prefixed(deploymentPrefix)
+	opts := &etcd.GetOptions{
+		Sort:      true,
+		Recursive: true,
+	}
+	res, err := r.kAPI.Get(context.Background(), key, opts)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var deployments []job.Deployment
+	for _, node := range res.Node.Nodes {
+		var d job.Deployment
+		if err := unmarshal(node.Value, &d); err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, d)
+	}
+	return deployments, nil
+}
+
+// SetDeployment stores the given Deployment, replacing any Deployment of
+// the same template.
+func (r *EtcdRegistry) SetDeployment(d job.Deployment) error {
+	val, err := marshal(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.kAPI.Set(context.Background(), r.deploymentPath(d.Template), val, nil)
+	return err
+}
+
+// DestroyDeployment removes the Deployment of the named template. The
+// instances of the template are left untouched.
+func (r *EtcdRegistry) DestroyDeployment(template string) error {
+	_, err := r.kAPI.Delete(context.Background(), r.deploymentPath(template), nil)
+	if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+		err = errors.New("deployment does not exist")
+	}
+	return err
+}
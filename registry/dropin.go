@@ -0,0 +1,94 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"errors"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/coreos/fleet/unit"
+)
+
+const (
+	// Namespace for drop-ins of units
+	dropInPrefix = "/dropin/"
+)
+
+func (r *EtcdRegistry) dropInPath(unitName, name string) string {
+	return r.prefixed(dropInPrefix, unitName, name)
+}
+
+// DropIns lists all DropIns stored in the Registry, ordered by unit name
+// and then drop-in name.
+func (r *EtcdRegistry) DropIns() ([]unit.DropIn, error) {
+	key := r.prefixed(dropInPrefix)
+	opts := &etcd.GetOptions{
+		Sort:      true,
+		Recursive: true,
+	}
+	res, err := r.kAPI.Get(context.Background(), key, opts)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var dropIns []unit.DropIn
+	for _, dir := range res.Node.Nodes {
+		for _, node := range dir.Nodes {
+			var d unit.DropIn
+			if err := unmarshal(node.Value, &d); err != nil {
+				return nil, err
+			}
+			dropIns = append(dropIns, d)
+		}
+	}
+	return dropIns, nil
+}
+
+// SetDropIn stores the given DropIn, replacing any DropIn of the same name
+// for the same unit.
+func (r *EtcdRegistry) SetDropIn(d unit.DropIn) error {
+	val, err := marshal(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.kAPI.Set(context.Background(), r.dropInPath(d.Unit, d.Name), val, nil)
+	return err
+}
+
+// RemoveDropIn removes the named DropIn of the given unit. Agents remove it
+// from the unit the next time they reconcile.
+func (r *EtcdRegistry) RemoveDropIn(unitName, name string) error {
+	_, err := r.kAPI.Delete(context.Background(), r.dropInPath(unitName, name), nil)
+	if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+		return errors.New("drop-in does not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	// remove the directory of the unit once its last drop-in is gone
+	opts := &etcd.DeleteOptions{Dir: true}
+	_, err = r.kAPI.Delete(context.Background(), r.prefixed(dropInPrefix, unitName), opts)
+	if isEtcdError(err, etcd.ErrorCodeDirNotEmpty) || isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+		err = nil
+	}
+	return err
+}
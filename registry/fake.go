@@ -33,6 +33,13 @@ func NewFakeRegistry() *FakeRegistry {
 		machines:      []machine.MachineState{},
 		jobStates:     map[string]map[string]*unit.UnitState{},
 		jobs:          map[string]job.Job{},
+		explanations:  map[string]*job.SchedulingExplanation{},
+		maintenance:   map[string]machine.MaintenanceWindow{},
+		deployments:   map[string]job.Deployment{},
+		revisions:     map[string][]job.UnitRevision{},
+		blacklist:     map[string]map[string]time.Time{},
+		completions:   map[string]job.Completion{},
+		dropIns:       map[string]map[string]unit.DropIn{},
 		daemonVersion: nil,
 	}
 }
@@ -47,6 +54,13 @@ type FakeRegistry struct {
 	machines      []machine.MachineState
 	jobStates     map[string]map[string]*unit.UnitState
 	jobs          map[string]job.Job
+	explanations  map[string]*job.SchedulingExplanation
+	maintenance   map[string]machine.MaintenanceWindow
+	deployments   map[string]job.Deployment
+	revisions     map[string][]job.UnitRevision
+	blacklist     map[string]map[string]time.Time
+	completions   map[string]job.Completion
+	dropIns       map[string]map[string]unit.DropIn
 	daemonVersion *semver.Version
 }
 
@@ -183,17 +197,16 @@ func (f *FakeRegistry) CreateUnit(u *job.Unit) error {
 	f.Lock()
 	defer f.Unlock()
 
-	_, ok := f.jobs[u.Name]
-	if ok {
-		return errors.New("unit already exists")
-	}
-
-	j := job.Job{
-		Name: u.Name,
-		Unit: u.Unit,
+	// Like EtcdRegistry, replace the unit file of an existing unit while
+	// keeping its schedule.
+	j, ok := f.jobs[u.Name]
+	if !ok {
+		j = job.Job{Name: u.Name}
 	}
+	j.Unit = u.Unit
 
 	f.jobs[u.Name] = j
+	delete(f.completions, u.Name)
 	return f.unsafeSetUnitTargetState(u.Name, u.TargetState)
 }
 
@@ -202,6 +215,7 @@ func (f *FakeRegistry) DestroyUnit(name string) error {
 	defer f.Unlock()
 
 	delete(f.jobs, name)
+	delete(f.completions, name)
 	return nil
 }
 
@@ -287,18 +301,9 @@ func (f *FakeRegistry) UnitState(unitName string) (*unit.UnitState, error) {
 	defer f.Unlock()
 
 	var us *unit.UnitState
-	for name := range f.jobStates {
-		sMIDs := make([]string, 0)
-		for machineID := range f.jobStates[name] {
-			sMIDs = append(sMIDs, machineID)
-		}
-		for _, mID := range sMIDs {
-			js := f.jobStates[name][mID]
-			if name == js.UnitName {
-				us = js
-				break
-			}
-		}
+	for _, js := range f.jobStates[unitName] {
+		us = js
+		break
 	}
 
 	if us == nil {
@@ -308,6 +313,21 @@ func (f *FakeRegistry) UnitState(unitName string) (*unit.UnitState, error) {
 	return us, nil
 }
 
+func (f *FakeRegistry) UnitSchedulingExplanation(name string) (*job.SchedulingExplanation, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.explanations[name], nil
+}
+
+func (f *FakeRegistry) SetUnitSchedulingExplanation(name string, ex *job.SchedulingExplanation, ttl time.Duration) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.explanations[name] = ex
+	return nil
+}
+
 func (f *FakeRegistry) UnitHeartbeat(name, machID string, ttl time.Duration) error {
 	return nil
 }
@@ -323,6 +343,244 @@ func (f *FakeRegistry) SetMachineMetadata(machID string, key string, value strin
 	return nil
 }
 
+func (f *FakeRegistry) SetMachineCordoned(machID string, cordoned bool) error {
+	f.Lock()
+	defer f.Unlock()
+
+	for i, mach := range f.machines {
+		if mach.ID == machID {
+			f.machines[i].Cordoned = cordoned
+		}
+	}
+	return nil
+}
+
+func (f *FakeRegistry) SetGlobalUnitPaused(machID, unitName string, paused bool) error {
+	f.Lock()
+	defer f.Unlock()
+
+	for i, mach := range f.machines {
+		if mach.ID != machID {
+			continue
+		}
+
+		var units []string
+		for _, name := range mach.PausedUnits {
+			if name != unitName {
+				units = append(units, name)
+			}
+		}
+		if paused {
+			units = append(units, unitName)
+			sort.Strings(units)
+		}
+		f.machines[i].PausedUnits = units
+	}
+	return nil
+}
+
+func (f *FakeRegistry) MaintenanceWindows() ([]machine.MaintenanceWindow, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	var windows []machine.MaintenanceWindow
+	for _, mw := range f.maintenance {
+		windows = append(windows, mw)
+	}
+	sort.Sort(maintenanceWindowsByStart(windows))
+	return windows, nil
+}
+
+func (f *FakeRegistry) SetMaintenanceWindow(mw machine.MaintenanceWindow) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.maintenance[mw.Name] = mw
+	return nil
+}
+
+func (f *FakeRegistry) RemoveMaintenanceWindow(name string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.maintenance[name]; !ok {
+		return errors.New("maintenance window does not exist")
+	}
+	delete(f.maintenance, name)
+	return nil
+}
+
+func (f *FakeRegistry) UnitBlacklist() (map[string][]string, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	now := time.Now()
+	blacklist := make(map[string][]string)
+	for name, machines := range f.blacklist {
+		for machID, expiry := range machines {
+			if now.Before(expiry) {
+				blacklist[name] = append(blacklist[name], machID)
+			}
+		}
+		sort.Strings(blacklist[name])
+	}
+	return blacklist, nil
+}
+
+func (f *FakeRegistry) BlacklistUnit(name, machID string, ttl time.Duration) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if ttl < time.Second {
+		return errors.New("blacklist period must be at least 1s")
+	}
+	if _, ok := f.blacklist[name]; !ok {
+		f.blacklist[name] = make(map[string]time.Time)
+	}
+	f.blacklist[name][machID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (f *FakeRegistry) CompletedUnits() (map[string]job.Completion, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	completions := make(map[string]job.Completion, len(f.completions))
+	for name, c := range f.completions {
+		completions[name] = c
+	}
+	return completions, nil
+}
+
+func (f *FakeRegistry) SetUnitCompleted(name string, c job.Completion) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.completions[name]; !ok {
+		f.completions[name] = c
+	}
+	return nil
+}
+
+func (f *FakeRegistry) DropIns() ([]unit.DropIn, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	var units []string
+	for name := range f.dropIns {
+		units = append(units, name)
+	}
+	sort.Strings(units)
+
+	var dropIns []unit.DropIn
+	for _, u := range units {
+		var names []string
+		for name := range f.dropIns[u] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dropIns = append(dropIns, f.dropIns[u][name])
+		}
+	}
+	return dropIns, nil
+}
+
+func (f *FakeRegistry) SetDropIn(d unit.DropIn) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.dropIns[d.Unit]; !ok {
+		f.dropIns[d.Unit] = make(map[string]unit.DropIn)
+	}
+	f.dropIns[d.Unit][d.Name] = d
+	return nil
+}
+
+func (f *FakeRegistry) RemoveDropIn(unitName, name string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.dropIns[unitName][name]; !ok {
+		return errors.New("drop-in does not exist")
+	}
+	delete(f.dropIns[unitName], name)
+	if len(f.dropIns[unitName]) == 0 {
+		delete(f.dropIns, unitName)
+	}
+	return nil
+}
+
+func (f *FakeRegistry) Deployments() ([]job.Deployment, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	var templates []string
+	for template := range f.deployments {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	var deployments []job.Deployment
+	for _, template := range templates {
+		deployments = append(deployments, f.deployments[template])
+	}
+	return deployments, nil
+}
+
+func (f *FakeRegistry) SetDeployment(d job.Deployment) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.deployments[d.Template] = d
+	return nil
+}
+
+func (f *FakeRegistry) DestroyDeployment(template string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.deployments[template]; !ok {
+		return errors.New("deployment does not exist")
+	}
+	delete(f.deployments, template)
+	return nil
+}
+
+func (f *FakeRegistry) AddUnitRevision(name string, uf unit.UnitFile, client string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	revisions := f.revisions[name]
+	number := 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if unit.MatchUnitFiles(&latest.Unit, &uf) {
+			return nil
+		}
+		number = latest.Number + 1
+	}
+
+	revisions = append(revisions, job.UnitRevision{
+		Number:  number,
+		Unit:    uf,
+		Created: time.Now(),
+		Client:  client,
+	})
+	if len(revisions) > maxUnitRevisions {
+		revisions = revisions[len(revisions)-maxUnitRevisions:]
+	}
+	f.revisions[name] = revisions
+	return nil
+}
+
+func (f *FakeRegistry) UnitRevisions(name string) ([]job.UnitRevision, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	return append([]job.UnitRevision(nil), f.revisions[name]...), nil
+}
+
 func (f *FakeRegistry) DeleteMachineMetadata(machID string, key string) error {
 	for _, mach := range f.machines {
 		if mach.ID == machID {
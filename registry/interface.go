@@ -37,10 +37,28 @@ type Registry interface {
 	ScheduleUnit(name, machID string) error
 	SetUnitTargetState(name string, state job.JobState) error
 	SetMachineState(ms machine.MachineState, ttl time.Duration) (uint64, error)
+	SetUnitSchedulingExplanation(name string, ex *job.SchedulingExplanation, ttl time.Duration) error
 	MachineState(machID string) (machine.MachineState, error)
 	UnscheduleUnit(name, machID string) error
 	SetMachineMetadata(machID string, key string, value string) error
 	DeleteMachineMetadata(machID string, key string) error
+	SetMachineCordoned(machID string, cordoned bool) error
+	SetGlobalUnitPaused(machID, unitName string, paused bool) error
+	MaintenanceWindows() ([]machine.MaintenanceWindow, error)
+	SetMaintenanceWindow(mw machine.MaintenanceWindow) error
+	RemoveMaintenanceWindow(name string) error
+	Deployments() ([]job.Deployment, error)
+	SetDeployment(d job.Deployment) error
+	DestroyDeployment(template string) error
+	AddUnitRevision(name string, uf unit.UnitFile, client string) error
+	UnitRevisions(name string) ([]job.UnitRevision, error)
+	UnitBlacklist() (map[string][]string, error)
+	BlacklistUnit(name, machID string, ttl time.Duration) error
+	CompletedUnits() (map[string]job.Completion, error)
+	SetUnitCompleted(name string, c job.Completion) error
+	DropIns() ([]unit.DropIn, error)
+	SetDropIn(d unit.DropIn) error
+	RemoveDropIn(unitName, name string) error
 
 	IsRegistryReady() bool
 	UseEtcdRegistry() bool
@@ -54,6 +72,7 @@ type UnitRegistry interface {
 	Units() ([]job.Unit, error)
 	UnitState(name string) (*unit.UnitState, error)
 	UnitStates() ([]*unit.UnitState, error)
+	UnitSchedulingExplanation(name string) (*job.SchedulingExplanation, error)
 }
 
 type ClusterRegistry interface {
@@ -307,6 +307,10 @@ func (r *EtcdRegistry) DestroyUnit(name string) error {
 		return err
 	}
 
+	if err := r.clearUnitCompletion(name); err != nil {
+		log.Errorf("Failed clearing completion of Unit(%s): %v", name, err)
+	}
+
 	// TODO(jonboulle): add unit reference counting and actually destroying Units
 	return nil
 }
@@ -338,6 +342,11 @@ func (r *EtcdRegistry) CreateUnit(u *job.Unit) error {
 		return err
 	}
 
+	// a batch unit submitted again runs again
+	if err := r.clearUnitCompletion(u.Name); err != nil {
+		return err
+	}
+
 	return r.SetUnitTargetState(u.Name, u.TargetState)
 }
 
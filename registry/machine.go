@@ -15,6 +15,7 @@
 package registry
 
 import (
+	"sort"
 	"strings"
 	"time"
 
@@ -135,6 +136,40 @@ func (r *EtcdRegistry) DeleteMachineMetadata(machID string, key string) error {
 	return r.SetMachineMetadata(machID, key, "")
 }
 
+// SetMachineCordoned marks the machine as accepting no new units, or clears
+// that mark. Like dynamic metadata, the mark persists across the machine
+// leaving and rejoining the cluster.
+func (r *EtcdRegistry) SetMachineCordoned(machID string, cordoned bool) error {
+	key := path.Join(r.keyPrefix, machinePrefix, machID, "cordoned")
+	if !cordoned {
+		_, err := r.kAPI.Delete(context.Background(), key, nil)
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return err
+	}
+
+	_, err := r.kAPI.Set(context.Background(), key, "true", &etcd.SetOptions{})
+	return err
+}
+
+// SetGlobalUnitPaused prevents the named global unit from running on the
+// machine, or allows it to run there again. Like the cordoned mark, this
+// persists across the machine leaving and rejoining the cluster.
+func (r *EtcdRegistry) SetGlobalUnitPaused(machID, unitName string, paused bool) error {
+	key := path.Join(r.keyPrefix, machinePrefix, machID, "paused", unitName)
+	if !paused {
+		_, err := r.kAPI.Delete(context.Background(), key, nil)
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return err
+	}
+
+	_, err := r.kAPI.Set(context.Background(), key, "true", &etcd.SetOptions{})
+	return err
+}
+
 func (r *EtcdRegistry) RemoveMachineState(machID string) error {
 	key := r.prefixed(machinePrefix, machID, "object")
 	_, err := r.kAPI.Delete(context.Background(), key, nil)
@@ -172,6 +207,8 @@ func mergeMetadata(machineMetadata, dynamicMetadata map[string]string) map[strin
 // readMachineState reads machine state from an etcd node
 func readMachineState(node *etcd.Node) (mach machine.MachineState, err error) {
 	var metadata map[string]string
+	var cordoned bool
+	var paused []string
 
 	for _, obj := range node.Nodes {
 		if strings.HasSuffix(obj.Key, "/object") {
@@ -185,9 +222,18 @@ func readMachineState(node *etcd.Node) (mach machine.MachineState, err error) {
 			for _, mdnode := range obj.Nodes {
 				metadata[path.Base(mdnode.Key)] = mdnode.Value
 			}
+		} else if strings.HasSuffix(obj.Key, "/cordoned") {
+			cordoned = obj.Value == "true"
+		} else if strings.HasSuffix(obj.Key, "/paused") {
+			for _, pnode := range obj.Nodes {
+				paused = append(paused, path.Base(pnode.Key))
+			}
+			sort.Strings(paused)
 		}
 	}
 
 	mach.Metadata = mergeMetadata(mach.Metadata, metadata)
+	mach.Cordoned = cordoned
+	mach.PausedUnits = paused
 	return
 }
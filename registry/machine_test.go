@@ -0,0 +1,94 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	etcd "github.com/coreos/etcd/client"
+)
+
+func TestReadMachineState(t *testing.T) {
+	object := &etcd.Node{
+		Key:   "/fleet/machines/XXX/object",
+		Value: `{"ID":"XXX","Metadata":{"region":"us-west"},"Cordoned":false}`,
+	}
+	metadata := &etcd.Node{
+		Key: "/fleet/machines/XXX/metadata",
+		Nodes: etcd.Nodes{
+			&etcd.Node{Key: "/fleet/machines/XXX/metadata/region", Value: "us-east"},
+		},
+	}
+	cordoned := &etcd.Node{Key: "/fleet/machines/XXX/cordoned", Value: "true"}
+	paused := &etcd.Node{
+		Key: "/fleet/machines/XXX/paused",
+		Nodes: etcd.Nodes{
+			&etcd.Node{Key: "/fleet/machines/XXX/paused/b.service", Value: "true"},
+			&etcd.Node{Key: "/fleet/machines/XXX/paused/a.service", Value: "true"},
+		},
+	}
+
+	tests := []struct {
+		nodes    etcd.Nodes
+		metadata map[string]string
+		cordoned bool
+		paused   []string
+	}{
+		{
+			nodes:    etcd.Nodes{object},
+			metadata: map[string]string{"region": "us-west"},
+			cordoned: false,
+		},
+		{
+			nodes:    etcd.Nodes{paused, object},
+			metadata: map[string]string{"region": "us-west"},
+			paused:   []string{"a.service", "b.service"},
+		},
+		// the cordoned key takes precedence over the object published by
+		// the machine, whatever the order of the nodes
+		{
+			nodes:    etcd.Nodes{cordoned, metadata, object},
+			metadata: map[string]string{"region": "us-east"},
+			cordoned: true,
+		},
+		{
+			nodes:    etcd.Nodes{object, cordoned},
+			metadata: map[string]string{"region": "us-west"},
+			cordoned: true,
+		},
+	}
+
+	for i, tt := range tests {
+		ms, err := readMachineState(&etcd.Node{Key: "/fleet/machines/XXX", Nodes: tt.nodes})
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if ms.ID != "XXX" {
+			t.Errorf("case %d: unexpected ID %q", i, ms.ID)
+		}
+		if !reflect.DeepEqual(ms.Metadata, tt.metadata) {
+			t.Errorf("case %d: unexpected Metadata: got %v, want %v", i, ms.Metadata, tt.metadata)
+		}
+		if ms.Cordoned != tt.cordoned {
+			t.Errorf("case %d: unexpected Cordoned: got %t, want %t", i, ms.Cordoned, tt.cordoned)
+		}
+		if !reflect.DeepEqual(ms.PausedUnits, tt.paused) {
+			t.Errorf("case %d: unexpected PausedUnits: got %v, want %v", i, ms.PausedUnits, tt.paused)
+		}
+	}
+}
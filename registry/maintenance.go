@@ -0,0 +1,103 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/coreos/fleet/machine"
+)
+
+const (
+	// Namespace for maintenance windows of machines
+	maintenancePrefix = "/maintenance/"
+)
+
+func (r *EtcdRegistry) maintenanceWindowPath(name string) string {
+	return r.prefixed(maintenancePrefix, name)
+}
+
+// MaintenanceWindows returns all MaintenanceWindows that have not yet
+// ended, sorted by start time and then name.
+func (r *EtcdRegistry) MaintenanceWindows() ([]machine.MaintenanceWindow, error) {
+	key := r.prefixed(maintenancePrefix)
+	res, err := r.kAPI.Get(context.Background(), key, &etcd.GetOptions{Recursive: true})
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var windows []machine.MaintenanceWindow
+	for _, node := range res.Node.Nodes {
+		var mw machine.MaintenanceWindow
+		if err := unmarshal(node.Value, &mw); err != nil {
+			return nil, err
+		}
+		windows = append(windows, mw)
+	}
+
+	sort.Sort(maintenanceWindowsByStart(windows))
+	return windows, nil
+}
+
+// SetMaintenanceWindow stores the given MaintenanceWindow, replacing any
+// window of the same name. The window is removed from the registry once it
+// ends.
+func (r *EtcdRegistry) SetMaintenanceWindow(mw machine.MaintenanceWindow) error {
+	ttl := mw.End.Sub(time.Now())
+	if ttl < time.Second {
+		return errors.New("maintenance window has already ended")
+	}
+
+	val, err := marshal(mw)
+	if err != nil {
+		return err
+	}
+
+	opts := &etcd.SetOptions{
+		TTL: ttl,
+	}
+	_, err = r.kAPI.Set(context.Background(), r.maintenanceWindowPath(mw.Name), val, opts)
+	return err
+}
+
+// RemoveMaintenanceWindow removes the named MaintenanceWindow, putting its
+// machines back in service if it had already started.
+func (r *EtcdRegistry) RemoveMaintenanceWindow(name string) error {
+	_, err := r.kAPI.Delete(context.Background(), r.maintenanceWindowPath(name), nil)
+	if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+		err = errors.New("maintenance window does not exist")
+	}
+	return err
+}
+
+type maintenanceWindowsByStart []machine.MaintenanceWindow
+
+func (mws maintenanceWindowsByStart) Len() int      { return len(mws) }
+func (mws maintenanceWindowsByStart) Swap(i, j int) { mws[i], mws[j] = mws[j], mws[i] }
+
+func (mws maintenanceWindowsByStart) Less(i, j int) bool {
+	if !mws[i].Start.Equal(mws[j].Start) {
+		return mws[i].Start.Before(mws[j].Start)
+	}
+	return mws[i].Name < mws[j].Name
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/coreos/fleet/job"
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/unit"
+)
+
+const (
+	// Namespace for the revision history of units
+	revisionPrefix = "/revision/"
+
+	// maxUnitRevisions is the number of revisions kept for each unit name
+	maxUnitRevisions = 10
+)
+
+type revisionModel struct {
+	UnitHash unit.Hash
+	Created  time.Time
+	Client   string
+}
+
+func (r *EtcdRegistry) revisionPath(name string, number int) string {
+	return r.prefixed(revisionPrefix, name, strconv.Itoa(number))
+}
+
+// UnitRevisions lists the revisions kept for the named unit, oldest first.
+// Revisions are kept even if the unit is destroyed, so that the history of
+// a unit resubmitted later goes on.
+func (r *EtcdRegistry) UnitRevisions(name string) ([]job.UnitRevision, error) {
+	key := r.prefixed(revisionPrefix, name)
+	opts := &etcd.GetOptions{
+		Recursive: true,
+	}
+	res, err := r.kAPI.Get(context.Background(), key, opts)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var revisions []job.UnitRevision
+	for _, node := range res.Node.Nodes {
+		number, err := strconv.Atoi(path.Base(node.Key))
+		if err != nil {
+			log.Errorf("Invalid revision key %s: %v", node.Key, err)
+			continue
+		}
+
+		var rm revisionModel
+		if err := unmarshal(node.Value, &rm); err != nil {
+			return nil, err
+		}
+
+		uf := r.getUnitByHash(rm.UnitHash)
+		if uf == nil {
+			log.Errorf("No Unit found in Registry for revision %d of %s (hash %s)", number, name, rm.UnitHash)
+			continue
+		}
+
+		revisions = append(revisions, job.UnitRevision{
+			Number:  number,
+			Unit:    *uf,
+			Created: rm.Created,
+			Client:  rm.Client,
+		})
+	}
+
+	sort.Sort(revisionsByNumber(revisions))
+	return revisions, nil
+}
+
+// AddUnitRevision records the given unit file as the latest revision of the
+// named unit, unless it already is, and forgets the oldest revisions beyond
+// maxUnitRevisions.
+func (r *EtcdRegistry) AddUnitRevision(name string, uf unit.UnitFile, client string) error {
+	revisions, err := r.UnitRevisions(name)
+	if err != nil {
+		return err
+	}
+
+	number := 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if unit.MatchUnitFiles(&latest.Unit, &uf) {
+			return nil
+		}
+		number = latest.Number + 1
+	}
+
+	if err := r.storeOrGetUnitFile(uf); err != nil {
+		return err
+	}
+
+	val, err := marshal(revisionModel{
+		UnitHash: uf.Hash(),
+		Created:  time.Now(),
+		Client:   client,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Fail rather than overwrite a revision recorded concurrently
+	opts := &etcd.SetOptions{
+		PrevExist: etcd.PrevNoExist,
+	}
+	if _, err := r.kAPI.Set(context.Background(), r.revisionPath(name, number), val, opts); err != nil {
+		if isEtcdError(err, etcd.ErrorCodeNodeExist) {
+			err = fmt.Errorf("revision %d of %s was recorded concurrently", number, name)
+		}
+		return err
+	}
+
+	for i := 0; i <= len(revisions)-maxUnitRevisions; i++ {
+		_, err := r.kAPI.Delete(context.Background(), r.revisionPath(name, revisions[i].Number), nil)
+		if err != nil && !isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type revisionsByNumber []job.UnitRevision
+
+func (s revisionsByNumber) Len() int           { return len(s) }
+func (s revisionsByNumber) Less(i, j int) bool { return s[i].Number < s[j].Number }
+func (s revisionsByNumber) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -326,6 +326,14 @@ func (r *RegistryMux) UnitStates() ([]*unit.UnitState, error) {
 	return r.getRegistry().UnitStates()
 }
 
+func (r *RegistryMux) UnitSchedulingExplanation(name string) (*job.SchedulingExplanation, error) {
+	return r.etcdRegistry.UnitSchedulingExplanation(name)
+}
+
+func (r *RegistryMux) SetUnitSchedulingExplanation(name string, ex *job.SchedulingExplanation, ttl time.Duration) error {
+	return r.etcdRegistry.SetUnitSchedulingExplanation(name, ex, ttl)
+}
+
 func (r *RegistryMux) LatestDaemonVersion() (*semver.Version, error) {
 	return r.etcdRegistry.LatestDaemonVersion()
 }
@@ -345,3 +353,71 @@ func (r *RegistryMux) SetMachineMetadata(machID string, key string, value string
 func (r *RegistryMux) DeleteMachineMetadata(machID string, key string) error {
 	return r.etcdRegistry.DeleteMachineMetadata(machID, key)
 }
+
+func (r *RegistryMux) SetMachineCordoned(machID string, cordoned bool) error {
+	return r.etcdRegistry.SetMachineCordoned(machID, cordoned)
+}
+
+func (r *RegistryMux) SetGlobalUnitPaused(machID, unitName string, paused bool) error {
+	return r.etcdRegistry.SetGlobalUnitPaused(machID, unitName, paused)
+}
+
+func (r *RegistryMux) UnitBlacklist() (map[string][]string, error) {
+	return r.etcdRegistry.UnitBlacklist()
+}
+
+func (r *RegistryMux) BlacklistUnit(name, machID string, ttl time.Duration) error {
+	return r.etcdRegistry.BlacklistUnit(name, machID, ttl)
+}
+
+func (r *RegistryMux) CompletedUnits() (map[string]job.Completion, error) {
+	return r.etcdRegistry.CompletedUnits()
+}
+
+func (r *RegistryMux) SetUnitCompleted(name string, c job.Completion) error {
+	return r.etcdRegistry.SetUnitCompleted(name, c)
+}
+
+func (r *RegistryMux) MaintenanceWindows() ([]machine.MaintenanceWindow, error) {
+	return r.etcdRegistry.MaintenanceWindows()
+}
+
+func (r *RegistryMux) SetMaintenanceWindow(mw machine.MaintenanceWindow) error {
+	return r.etcdRegistry.SetMaintenanceWindow(mw)
+}
+
+func (r *RegistryMux) RemoveMaintenanceWindow(name string) error {
+	return r.etcdRegistry.RemoveMaintenanceWindow(name)
+}
+
+func (r *RegistryMux) DropIns() ([]unit.DropIn, error) {
+	return r.etcdRegistry.DropIns()
+}
+
+func (r *RegistryMux) SetDropIn(d unit.DropIn) error {
+	return r.etcdRegistry.SetDropIn(d)
+}
+
+func (r *RegistryMux) RemoveDropIn(unitName, name string) error {
+	return r.etcdRegistry.RemoveDropIn(unitName, name)
+}
+
+func (r *RegistryMux) Deployments() ([]job.Deployment, error) {
+	return r.etcdRegistry.Deployments()
+}
+
+func (r *RegistryMux) SetDeployment(d job.Deployment) error {
+	return r.etcdRegistry.SetDeployment(d)
+}
+
+func (r *RegistryMux) DestroyDeployment(template string) error {
+	return r.etcdRegistry.DestroyDeployment(template)
+}
+
+func (r *RegistryMux) AddUnitRevision(name string, uf unit.UnitFile, client string) error {
+	return r.etcdRegistry.AddUnitRevision(name, uf, client)
+}
+
+func (r *RegistryMux) UnitRevisions(name string) ([]job.UnitRevision, error) {
+	return r.etcdRegistry.UnitRevisions(name)
+}
@@ -107,7 +107,7 @@ func TestRegistryMuxUnitManagement(t *testing.T) {
 		t.Fatalf("unexpected error creating systemd unit manager: %v", err)
 	}
 
-	mach := machine.NewCoreOSMachine(*state, mgr)
+	mach := machine.NewCoreOSMachine(*state, mgr, uDir)
 	e := &testEtcdKeysAPI{}
 	etcdReg := registry.NewEtcdRegistry(e, "/fleet/")
 
@@ -224,6 +224,42 @@ func (r *RPCRegistry) DeleteMachineMetadata(machID string, key string) error {
 	panic("Delete machine metadata function not implemented")
 }
 
+func (r *RPCRegistry) SetMachineCordoned(machID string, cordoned bool) error {
+	panic("Set machine cordoned function not implemented")
+}
+
+func (r *RPCRegistry) SetGlobalUnitPaused(machID, unitName string, paused bool) error {
+	panic("Set global unit paused function not implemented")
+}
+
+func (r *RPCRegistry) UnitBlacklist() (map[string][]string, error) {
+	panic("Unit blacklist function not implemented")
+}
+
+func (r *RPCRegistry) BlacklistUnit(name, machID string, ttl time.Duration) error {
+	panic("Blacklist unit function not implemented")
+}
+
+func (r *RPCRegistry) CompletedUnits() (map[string]job.Completion, error) {
+	panic("Completed units function not implemented")
+}
+
+func (r *RPCRegistry) SetUnitCompleted(name string, c job.Completion) error {
+	panic("Set unit completed function not implemented")
+}
+
+func (r *RPCRegistry) MaintenanceWindows() ([]machine.MaintenanceWindow, error) {
+	panic("Maintenance windows function not implemented")
+}
+
+func (r *RPCRegistry) SetMaintenanceWindow(mw machine.MaintenanceWindow) error {
+	panic("Set maintenance window function not implemented")
+}
+
+func (r *RPCRegistry) RemoveMaintenanceWindow(name string) error {
+	panic("Remove maintenance window function not implemented")
+}
+
 func (r *RPCRegistry) Machines() ([]machine.MachineState, error) {
 	panic("Machines function not implemented")
 }
@@ -339,6 +375,15 @@ func (r *RPCRegistry) UnitState(unitName string) (*unit.UnitState, error) {
 		LoadState:   state.LoadState,
 		ActiveState: state.ActiveState,
 		SubState:    state.SubState,
+		Health:      state.Health,
+
+		MainPID:              state.MainPID,
+		ExecMainStatus:       state.ExecMainStatus,
+		NRestarts:            state.NRestarts,
+		ActiveEnterTimestamp: state.ActiveEnterTimestamp,
+		Result:               state.Result,
+
+		Usage: unit.UnitUsageFromPB(state),
 	}, nil
 }
 
@@ -362,11 +407,28 @@ func (r *RPCRegistry) UnitStates() ([]*unit.UnitState, error) {
 			LoadState:   state.LoadState,
 			ActiveState: state.ActiveState,
 			SubState:    state.SubState,
+			Health:      state.Health,
+
+			MainPID:              state.MainPID,
+			ExecMainStatus:       state.ExecMainStatus,
+			NRestarts:            state.NRestarts,
+			ActiveEnterTimestamp: state.ActiveEnterTimestamp,
+			Result:               state.Result,
+
+			Usage: unit.UnitUsageFromPB(state),
 		}
 	}
 	return nUnitStates, nil
 }
 
+func (r *RPCRegistry) UnitSchedulingExplanation(unitName string) (*job.SchedulingExplanation, error) {
+	panic("Unit scheduling explanation function not implemented")
+}
+
+func (r *RPCRegistry) SetUnitSchedulingExplanation(unitName string, ex *job.SchedulingExplanation, ttl time.Duration) error {
+	panic("Set unit scheduling explanation function not implemented")
+}
+
 func (r *RPCRegistry) EngineVersion() (int, error) {
 	return 0, errors.New("Engine version function not implemented")
 }
@@ -378,3 +440,35 @@ func (r *RPCRegistry) UpdateEngineVersion(from, to int) error {
 func (r *RPCRegistry) LatestDaemonVersion() (*semver.Version, error) {
 	return nil, errors.New("Latest daemon version function not implemented")
 }
+
+func (r *RPCRegistry) DropIns() ([]unit.DropIn, error) {
+	panic("Drop-ins function not implemented")
+}
+
+func (r *RPCRegistry) SetDropIn(d unit.DropIn) error {
+	panic("Set drop-in function not implemented")
+}
+
+func (r *RPCRegistry) RemoveDropIn(unitName, name string) error {
+	panic("Remove drop-in function not implemented")
+}
+
+func (r *RPCRegistry) Deployments() ([]job.Deployment, error) {
+	panic("Deployments function not implemented")
+}
+
+func (r *RPCRegistry) SetDeployment(d job.Deployment) error {
+	panic("Set deployment function not implemented")
+}
+
+func (r *RPCRegistry) DestroyDeployment(template string) error {
+	panic("Destroy deployment function not implemented")
+}
+
+func (r *RPCRegistry) AddUnitRevision(name string, uf unit.UnitFile, client string) error {
+	panic("Add unit revision function not implemented")
+}
+
+func (r *RPCRegistry) UnitRevisions(name string) ([]job.UnitRevision, error) {
+	panic("Unit revisions function not implemented")
+}
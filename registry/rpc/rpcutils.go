@@ -42,6 +42,15 @@ func rpcUnitStateToExtUnitState(state *pb.UnitState) *unit.UnitState {
 		ActiveState: state.ActiveState,
 		SubState:    state.SubState,
 		MachineID:   state.MachineID,
+		Health:      state.Health,
+
+		MainPID:              state.MainPID,
+		ExecMainStatus:       state.ExecMainStatus,
+		NRestarts:            state.NRestarts,
+		ActiveEnterTimestamp: state.ActiveEnterTimestamp,
+		Result:               state.Result,
+
+		Usage: unit.UnitUsageFromPB(state),
 	}
 }
 
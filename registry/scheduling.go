@@ -0,0 +1,68 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"time"
+
+	etcd "github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/coreos/fleet/job"
+)
+
+const (
+	// Namespace for explanations of why units could not be scheduled
+	schedulingPrefix = "/scheduling/"
+)
+
+func (r *EtcdRegistry) schedulingExplanationPath(jobName string) string {
+	return r.prefixed(schedulingPrefix, jobName)
+}
+
+// UnitSchedulingExplanation returns the SchedulingExplanation most recently
+// recorded for the given unit, or nil if none exists.
+func (r *EtcdRegistry) UnitSchedulingExplanation(name string) (*job.SchedulingExplanation, error) {
+	key := r.schedulingExplanationPath(name)
+	res, err := r.kAPI.Get(context.Background(), key, nil)
+	if err != nil {
+		if isEtcdError(err, etcd.ErrorCodeKeyNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var ex job.SchedulingExplanation
+	if err := unmarshal(res.Node.Value, &ex); err != nil {
+		return nil, err
+	}
+
+	return &ex, nil
+}
+
+// SetUnitSchedulingExplanation persists the given SchedulingExplanation for
+// a unit. The explanation expires after the given TTL unless it is refreshed.
+func (r *EtcdRegistry) SetUnitSchedulingExplanation(name string, ex *job.SchedulingExplanation, ttl time.Duration) error {
+	val, err := marshal(ex)
+	if err != nil {
+		return err
+	}
+
+	opts := &etcd.SetOptions{
+		TTL: ttl,
+	}
+	_, err = r.kAPI.Set(context.Background(), r.schedulingExplanationPath(name), val, opts)
+	return err
+}
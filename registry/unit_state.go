@@ -244,6 +244,22 @@ type unitStateModel struct {
 	SubState     string                `json:"subState"`
 	MachineState *machine.MachineState `json:"machineState"`
 	UnitHash     string                `json:"unitHash"`
+	Health       string                `json:"health,omitempty"`
+
+	MainPID              uint32 `json:"mainPID,omitempty"`
+	ExecMainStatus       int32  `json:"execMainStatus,omitempty"`
+	NRestarts            uint32 `json:"nRestarts,omitempty"`
+	ActiveEnterTimestamp uint64 `json:"activeEnterTimestamp,omitempty"`
+	Result               string `json:"result,omitempty"`
+
+	Usage *unitUsageModel `json:"usage,omitempty"`
+}
+
+type unitUsageModel struct {
+	MemoryCurrent uint64 `json:"memoryCurrent"`
+	MemoryPeak    uint64 `json:"memoryPeak"`
+	CPUUsageNSec  uint64 `json:"cpuUsageNSec"`
+	Tasks         uint64 `json:"tasks"`
 }
 
 func modelToUnitState(usm *unitStateModel, name string) *unit.UnitState {
@@ -257,12 +273,28 @@ func modelToUnitState(usm *unitStateModel, name string) *unit.UnitState {
 		SubState:    usm.SubState,
 		UnitHash:    usm.UnitHash,
 		UnitName:    name,
+		Health:      usm.Health,
+
+		MainPID:              usm.MainPID,
+		ExecMainStatus:       usm.ExecMainStatus,
+		NRestarts:            usm.NRestarts,
+		ActiveEnterTimestamp: usm.ActiveEnterTimestamp,
+		Result:               usm.Result,
 	}
 
 	if usm.MachineState != nil {
 		us.MachineID = usm.MachineState.ID
 	}
 
+	if usm.Usage != nil {
+		us.Usage = &unit.UnitUsage{
+			MemoryCurrent: usm.Usage.MemoryCurrent,
+			MemoryPeak:    usm.Usage.MemoryPeak,
+			CPUUsageNSec:  usm.Usage.CPUUsageNSec,
+			Tasks:         usm.Usage.Tasks,
+		}
+	}
+
 	return &us
 }
 
@@ -281,11 +313,27 @@ func unitStateToModel(us *unit.UnitState) *unitStateModel {
 		ActiveState: us.ActiveState,
 		SubState:    us.SubState,
 		UnitHash:    us.UnitHash,
+		Health:      us.Health,
+
+		MainPID:              us.MainPID,
+		ExecMainStatus:       us.ExecMainStatus,
+		NRestarts:            us.NRestarts,
+		ActiveEnterTimestamp: us.ActiveEnterTimestamp,
+		Result:               us.Result,
 	}
 
 	if us.MachineID != "" {
 		usm.MachineState = &machine.MachineState{ID: us.MachineID}
 	}
 
+	if us.Usage != nil {
+		usm.Usage = &unitUsageModel{
+			MemoryCurrent: us.Usage.MemoryCurrent,
+			MemoryPeak:    us.Usage.MemoryPeak,
+			CPUUsageNSec:  us.Usage.CPUUsageNSec,
+			Tasks:         us.Usage.Tasks,
+		}
+	}
+
 	return &usm
 }
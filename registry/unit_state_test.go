@@ -122,7 +122,7 @@ func TestSaveUnitState(t *testing.T) {
 	us.UnitHash = "quickbrownfox"
 	r.SaveUnitState(j, us, time.Second)
 
-	json := `{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":""},"unitHash":"quickbrownfox"}`
+	json := `{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0},"Cordoned":false,"PausedUnits":null},"unitHash":"quickbrownfox"}`
 	p1 := "/fleet/state/foo.service"
 	p2 := "/fleet/states/foo.service/mymachine"
 	want := []action{
@@ -240,6 +240,39 @@ func TestUnitStateToModel(t *testing.T) {
 				UnitHash:     "miaow",
 			},
 		},
+		{
+			in: &unit.UnitState{
+				LoadState:   "loaded",
+				ActiveState: "active",
+				SubState:    "running",
+				MachineID:   "woof",
+				UnitHash:    "miaow",
+				UnitName:    "name",
+
+				MainPID:              1234,
+				ExecMainStatus:       1,
+				NRestarts:            2,
+				ActiveEnterTimestamp: 1476700800000000,
+				Result:               "exit-code",
+
+				Usage: &unit.UnitUsage{MemoryCurrent: 1024, MemoryPeak: 2048, CPUUsageNSec: 1000000, Tasks: 4},
+			},
+			want: &unitStateModel{
+				LoadState:    "loaded",
+				ActiveState:  "active",
+				SubState:     "running",
+				MachineState: &machine.MachineState{ID: "woof"},
+				UnitHash:     "miaow",
+
+				MainPID:              1234,
+				ExecMainStatus:       1,
+				NRestarts:            2,
+				ActiveEnterTimestamp: 1476700800000000,
+				Result:               "exit-code",
+
+				Usage: &unitUsageModel{MemoryCurrent: 1024, MemoryPeak: 2048, CPUUsageNSec: 1000000, Tasks: 4},
+			},
+		},
 	} {
 		got := unitStateToModel(tt.in)
 		if !reflect.DeepEqual(got, tt.want) {
@@ -258,7 +291,7 @@ func TestModelToUnitState(t *testing.T) {
 			want: nil,
 		},
 		{
-			in: &unitStateModel{"foo", "bar", "baz", nil, ""},
+			in: &unitStateModel{LoadState: "foo", ActiveState: "bar", SubState: "baz"},
 			want: &unit.UnitState{
 				LoadState:   "foo",
 				ActiveState: "bar",
@@ -269,7 +302,7 @@ func TestModelToUnitState(t *testing.T) {
 			},
 		},
 		{
-			in: &unitStateModel{"z", "x", "y", &machine.MachineState{ID: "abcd"}, ""},
+			in: &unitStateModel{LoadState: "z", ActiveState: "x", SubState: "y", MachineState: &machine.MachineState{ID: "abcd"}},
 			want: &unit.UnitState{
 				LoadState:   "z",
 				ActiveState: "x",
@@ -317,7 +350,7 @@ func TestGetUnitState(t *testing.T) {
 		},
 		{
 			// Unit state with UnitHash should be OK
-			res: makeResponse(`{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0}},"unitHash":"quickbrownfox"}`),
+			res: makeResponse(`{"loadState":"abc","activeState":"def","subState":"ghi","machineState":{"ID":"mymachine","PublicIP":"","Metadata":null,"Capabilities":null,"Version":"","TotalResources":{"Cores":0,"Memory":0,"Disk":0},"FreeResources":{"Cores":0,"Memory":0,"Disk":0},"Cordoned":false,"PausedUnits":null},"unitHash":"quickbrownfox"}`),
 			err: nil,
 			wantUS: &unit.UnitState{
 				LoadState:   "abc",
@@ -15,10 +15,15 @@
 package schema
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
 	gsunit "github.com/coreos/go-systemd/unit"
 
 	"github.com/coreos/fleet/job"
 	"github.com/coreos/fleet/machine"
+	"github.com/coreos/fleet/resource"
 	"github.com/coreos/fleet/unit"
 )
 
@@ -87,6 +92,11 @@ func MapMachineStateToSchema(ms *machine.MachineState) *Machine {
 	sm := Machine{
 		Id:        ms.ID,
 		PrimaryIP: ms.PublicIP,
+		Cordoned:  ms.Cordoned,
+	}
+
+	if len(ms.PausedUnits) > 0 {
+		sm.PausedUnits = append([]string(nil), ms.PausedUnits...)
 	}
 
 	sm.Metadata = make(map[string]string, len(ms.Metadata))
@@ -94,6 +104,9 @@ func MapMachineStateToSchema(ms *machine.MachineState) *Machine {
 		sm.Metadata[k] = v
 	}
 
+	sm.TotalResources = mapResourceTupleToSchema(ms.TotalResources)
+	sm.FreeResources = mapResourceTupleToSchema(ms.FreeResources)
+
 	return &sm
 }
 
@@ -105,6 +118,11 @@ func MapSchemaToMachineStates(entities []*Machine) []machine.MachineState {
 		ms := machine.MachineState{
 			ID:       me.Id,
 			PublicIP: me.PrimaryIP,
+			Cordoned: me.Cordoned,
+		}
+
+		if len(me.PausedUnits) > 0 {
+			ms.PausedUnits = append([]string(nil), me.PausedUnits...)
 		}
 
 		ms.Metadata = make(map[string]string, len(me.Metadata))
@@ -112,12 +130,39 @@ func MapSchemaToMachineStates(entities []*Machine) []machine.MachineState {
 			ms.Metadata[k] = v
 		}
 
+		ms.TotalResources = mapSchemaToResourceTuple(me.TotalResources)
+		ms.FreeResources = mapSchemaToResourceTuple(me.FreeResources)
+
 		machines[i] = ms
 	}
 
 	return machines
 }
 
+func mapResourceTupleToSchema(rt resource.ResourceTuple) *Resources {
+	if rt.Empty() {
+		return nil
+	}
+
+	return &Resources{
+		Cores:  int64(rt.Cores),
+		Memory: int64(rt.Memory),
+		Disk:   int64(rt.Disk),
+	}
+}
+
+func mapSchemaToResourceTuple(r *Resources) resource.ResourceTuple {
+	if r == nil {
+		return resource.ResourceTuple{}
+	}
+
+	return resource.ResourceTuple{
+		Cores:  int(r.Cores),
+		Memory: int(r.Memory),
+		Disk:   int(r.Disk),
+	}
+}
+
 func MapUnitStatesToSchemaUnitStates(entities []*unit.UnitState) []*UnitState {
 	sus := make([]*UnitState, len(entities))
 	for i, e := range entities {
@@ -135,6 +180,22 @@ func MapUnitStateToSchemaUnitState(entity *unit.UnitState) *UnitState {
 		SystemdLoadState:   entity.LoadState,
 		SystemdActiveState: entity.ActiveState,
 		SystemdSubState:    entity.SubState,
+		Health:             entity.Health,
+
+		MainPID:              int64(entity.MainPID),
+		ExecMainStatus:       int64(entity.ExecMainStatus),
+		NRestarts:            int64(entity.NRestarts),
+		ActiveEnterTimestamp: formatUsecTimestamp(entity.ActiveEnterTimestamp),
+		Result:               entity.Result,
+	}
+
+	if entity.Usage != nil {
+		us.Usage = &UnitUsage{
+			MemoryCurrent: entity.Usage.MemoryCurrent,
+			MemoryPeak:    entity.Usage.MemoryPeak,
+			CpuUsageNSec:  entity.Usage.CPUUsageNSec,
+			Tasks:         entity.Usage.Tasks,
+		}
 	}
 
 	return &us
@@ -150,12 +211,46 @@ func MapSchemaUnitStatesToUnitStates(entities []*UnitState) []*unit.UnitState {
 			LoadState:   e.SystemdLoadState,
 			ActiveState: e.SystemdActiveState,
 			SubState:    e.SystemdSubState,
+			Health:      e.Health,
+
+			MainPID:              uint32(e.MainPID),
+			ExecMainStatus:       int32(e.ExecMainStatus),
+			NRestarts:            uint32(e.NRestarts),
+			ActiveEnterTimestamp: parseUsecTimestamp(e.ActiveEnterTimestamp),
+			Result:               e.Result,
+		}
+		if e.Usage != nil {
+			us[i].Usage = &unit.UnitUsage{
+				MemoryCurrent: e.Usage.MemoryCurrent,
+				MemoryPeak:    e.Usage.MemoryPeak,
+				CPUUsageNSec:  e.Usage.CpuUsageNSec,
+				Tasks:         e.Usage.Tasks,
+			}
 		}
 	}
 
 	return us
 }
 
+// formatUsecTimestamp formats a systemd timestamp, in microseconds since the
+// epoch, as RFC 3339. 0 means the timestamp is unset.
+func formatUsecTimestamp(usec uint64) string {
+	if usec == 0 {
+		return ""
+	}
+	return time.Unix(0, int64(usec)*int64(time.Microsecond)).UTC().Format(time.RFC3339)
+}
+
+// parseUsecTimestamp is the inverse of formatUsecTimestamp, returning 0 for
+// a timestamp that cannot be parsed.
+func parseUsecTimestamp(ts string) uint64 {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return 0
+	}
+	return uint64(t.UnixNano() / int64(time.Microsecond))
+}
+
 func MapSchemaUnitToScheduledUnit(entity *Unit) *job.ScheduledUnit {
 	cs := job.JobState(entity.CurrentState)
 	return &job.ScheduledUnit{
@@ -173,3 +268,150 @@ func MapSchemaUnitsToScheduledUnits(entities []*Unit) []job.ScheduledUnit {
 
 	return su
 }
+
+// MapSchedulingExplanationToSchema describes why the named unit could not be
+// scheduled, listing the rejecting machines ordered by ID. A nil explanation
+// results in an entity carrying only the unit name.
+func MapSchedulingExplanationToSchema(name string, ex *job.SchedulingExplanation) *UnitScheduling {
+	us := UnitScheduling{
+		Name: name,
+	}
+	if ex == nil {
+		return &us
+	}
+
+	us.Reason = ex.Reason
+
+	machIDs := make([]string, 0, len(ex.Rejections))
+	for machID := range ex.Rejections {
+		machIDs = append(machIDs, machID)
+	}
+	sort.Strings(machIDs)
+
+	for _, machID := range machIDs {
+		us.Rejections = append(us.Rejections, &SchedulingRejection{
+			MachineID: machID,
+			Reason:    ex.Rejections[machID],
+		})
+	}
+
+	return &us
+}
+
+// MapMaintenanceWindowToSchema describes the given MaintenanceWindow, along
+// with its phase at the given time.
+func MapMaintenanceWindowToSchema(mw *machine.MaintenanceWindow, now time.Time) *MaintenanceWindow {
+	smw := MaintenanceWindow{
+		Name:      mw.Name,
+		MachineID: mw.MachineID,
+		Start:     mw.Start.UTC().Format(time.RFC3339),
+		End:       mw.End.UTC().Format(time.RFC3339),
+		Phase:     string(mw.Phase(now)),
+	}
+
+	if len(mw.Metadata) > 0 {
+		smw.Metadata = make(map[string]string, len(mw.Metadata))
+		for k, v := range mw.Metadata {
+			smw.Metadata[k] = v
+		}
+	}
+
+	if mw.Lead > 0 {
+		smw.Lead = mw.Lead.String()
+	}
+
+	return &smw
+}
+
+// MapSchemaToMaintenanceWindow parses the given entity into a
+// MaintenanceWindow. Start and end times are expected in RFC 3339 format,
+// and the lead time, if any, as a duration such as "30m". The phase of the
+// entity is ignored.
+func MapSchemaToMaintenanceWindow(entity *MaintenanceWindow) (*machine.MaintenanceWindow, error) {
+	mw := machine.MaintenanceWindow{
+		Name:      entity.Name,
+		MachineID: entity.MachineID,
+	}
+
+	if len(entity.Metadata) > 0 {
+		mw.Metadata = make(map[string]string, len(entity.Metadata))
+		for k, v := range entity.Metadata {
+			mw.Metadata[k] = v
+		}
+	}
+
+	var err error
+	if mw.Start, err = time.Parse(time.RFC3339, entity.Start); err != nil {
+		return nil, fmt.Errorf("invalid start time: %v", err)
+	}
+	if mw.End, err = time.Parse(time.RFC3339, entity.End); err != nil {
+		return nil, fmt.Errorf("invalid end time: %v", err)
+	}
+	if entity.Lead != "" {
+		if mw.Lead, err = time.ParseDuration(entity.Lead); err != nil {
+			return nil, fmt.Errorf("invalid lead time: %v", err)
+		}
+	}
+
+	return &mw, nil
+}
+
+func MapDeploymentToSchema(d *job.Deployment) *Deployment {
+	return &Deployment{
+		Name:     d.Template,
+		Replicas: int64(d.Replicas),
+		// zero replicas is meaningful
+		ForceSendFields: []string{"Replicas"},
+	}
+}
+
+func MapSchemaToDeployment(sd *Deployment) *job.Deployment {
+	return &job.Deployment{
+		Template: sd.Name,
+		Replicas: int(sd.Replicas),
+	}
+}
+
+func MapUnitRevisionToSchema(rev *job.UnitRevision) *UnitRevision {
+	return &UnitRevision{
+		Number:  int64(rev.Number),
+		Created: rev.Created.UTC().Format(time.RFC3339),
+		Client:  rev.Client,
+		Hash:    rev.Unit.Hash().String(),
+		Options: MapUnitFileToSchemaUnitOptions(&rev.Unit),
+	}
+}
+
+func MapDropInToSchema(d *unit.DropIn) *DropIn {
+	sd := DropIn{
+		Unit:     d.Unit,
+		Name:     d.Name,
+		Contents: d.Contents,
+	}
+
+	if len(d.Metadata) > 0 {
+		sd.Metadata = make(map[string]string, len(d.Metadata))
+		for k, v := range d.Metadata {
+			sd.Metadata[k] = v
+		}
+	}
+
+	return &sd
+}
+
+func MapSchemaToDropIn(sd *DropIn) *unit.DropIn {
+	d := unit.DropIn{
+		Unit:     sd.Unit,
+		Name:     sd.Name,
+		Contents: sd.Contents,
+	}
+
+	if len(sd.Metadata) > 0 {
+		d.Metadata = make(map[string]string, len(sd.Metadata))
+		for k, v := range sd.Metadata {
+			d.Metadata[k] = v
+		}
+	}
+
+	return &d
+}
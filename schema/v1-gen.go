@@ -65,7 +65,13 @@ func New(client *http.Client) (*Service, error) {
 		return nil, errors.New("client is nil")
 	}
 	s := &Service{client: client, BasePath: basePath}
+	s.Deployments = NewDeploymentsService(s)
+	s.DropIns = NewDropInsService(s)
 	s.Machines = NewMachinesService(s)
+	s.Maintenance = NewMaintenanceService(s)
+	s.Schedule = NewScheduleService(s)
+	s.UnitRevisions = NewUnitRevisionsService(s)
+	s.UnitScheduling = NewUnitSchedulingService(s)
 	s.UnitState = NewUnitStateService(s)
 	s.Units = NewUnitsService(s)
 	return s, nil
@@ -76,8 +82,20 @@ type Service struct {
 	BasePath  string // API endpoint base URL
 	UserAgent string // optional additional User-Agent fragment
 
+	Deployments *DeploymentsService
+
+	DropIns *DropInsService
+
 	Machines *MachinesService
 
+	Maintenance *MaintenanceService
+
+	Schedule *ScheduleService
+
+	UnitRevisions *UnitRevisionsService
+
+	UnitScheduling *UnitSchedulingService
+
 	UnitState *UnitStateService
 
 	Units *UnitsService
@@ -90,6 +108,24 @@ func (s *Service) userAgent() string {
 	return googleapi.UserAgent + " " + s.UserAgent
 }
 
+func NewDeploymentsService(s *Service) *DeploymentsService {
+	rs := &DeploymentsService{s: s}
+	return rs
+}
+
+type DeploymentsService struct {
+	s *Service
+}
+
+func NewDropInsService(s *Service) *DropInsService {
+	rs := &DropInsService{s: s}
+	return rs
+}
+
+type DropInsService struct {
+	s *Service
+}
+
 func NewMachinesService(s *Service) *MachinesService {
 	rs := &MachinesService{s: s}
 	return rs
@@ -99,6 +135,42 @@ type MachinesService struct {
 	s *Service
 }
 
+func NewMaintenanceService(s *Service) *MaintenanceService {
+	rs := &MaintenanceService{s: s}
+	return rs
+}
+
+type MaintenanceService struct {
+	s *Service
+}
+
+func NewScheduleService(s *Service) *ScheduleService {
+	rs := &ScheduleService{s: s}
+	return rs
+}
+
+type ScheduleService struct {
+	s *Service
+}
+
+func NewUnitRevisionsService(s *Service) *UnitRevisionsService {
+	rs := &UnitRevisionsService{s: s}
+	return rs
+}
+
+type UnitRevisionsService struct {
+	s *Service
+}
+
+func NewUnitSchedulingService(s *Service) *UnitSchedulingService {
+	rs := &UnitSchedulingService{s: s}
+	return rs
+}
+
+type UnitSchedulingService struct {
+	s *Service
+}
+
 func NewUnitStateService(s *Service) *UnitStateService {
 	rs := &UnitStateService{s: s}
 	return rs
@@ -117,14 +189,12 @@ type UnitsService struct {
 	s *Service
 }
 
-type Machine struct {
-	Id string `json:"id,omitempty"`
-
-	Metadata map[string]string `json:"metadata,omitempty"`
+type Deployment struct {
+	Name string `json:"name,omitempty"`
 
-	PrimaryIP string `json:"primaryIP,omitempty"`
+	Replicas int64 `json:"replicas,omitempty"`
 
-	// ForceSendFields is a list of field names (e.g. "Id") to
+	// ForceSendFields is a list of field names (e.g. "Name") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -132,7 +202,7 @@ type Machine struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "Id") to include in API
+	// NullFields is a list of field names (e.g. "Name") to include in API
 	// requests with the JSON null value. By default, fields with empty
 	// values are omitted from API requests. However, any field with an
 	// empty value appearing in NullFields will be sent to the server as
@@ -141,14 +211,14 @@ type Machine struct {
 	NullFields []string `json:"-"`
 }
 
-func (s *Machine) MarshalJSON() ([]byte, error) {
-	type noMethod Machine
+func (s *Deployment) MarshalJSON() ([]byte, error) {
+	type noMethod Deployment
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-type MachinePage struct {
-	Machines []*Machine `json:"machines,omitempty"`
+type DeploymentPage struct {
+	Deployments []*Deployment `json:"deployments,omitempty"`
 
 	NextPageToken string `json:"nextPageToken,omitempty"`
 
@@ -156,7 +226,7 @@ type MachinePage struct {
 	// server.
 	googleapi.ServerResponse `json:"-"`
 
-	// ForceSendFields is a list of field names (e.g. "Machines") to
+	// ForceSendFields is a list of field names (e.g. "Deployments") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -164,45 +234,63 @@ type MachinePage struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "Machines") to include in
-	// API requests with the JSON null value. By default, fields with empty
-	// values are omitted from API requests. However, any field with an
-	// empty value appearing in NullFields will be sent to the server as
+	// NullFields is a list of field names (e.g. "Deployments") to include
+	// in API requests with the JSON null value. By default, fields with
+	// empty values are omitted from API requests. However, any field with
+	// an empty value appearing in NullFields will be sent to the server as
 	// null. It is an error if a field in this list has a non-empty value.
 	// This may be used to include null fields in Patch requests.
 	NullFields []string `json:"-"`
 }
 
-func (s *MachinePage) MarshalJSON() ([]byte, error) {
-	type noMethod MachinePage
+func (s *DeploymentPage) MarshalJSON() ([]byte, error) {
+	type noMethod DeploymentPage
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-type Unit struct {
-	// Possible values:
-	//   "inactive"
-	//   "loaded"
-	//   "launched"
-	CurrentState string `json:"currentState,omitempty"`
-
-	// Possible values:
-	//   "inactive"
-	//   "loaded"
-	//   "launched"
-	DesiredState string `json:"desiredState,omitempty"`
+type DropIn struct {
+	Contents string `json:"contents,omitempty"`
 
-	MachineID string `json:"machineID,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 
 	Name string `json:"name,omitempty"`
 
-	Options []*UnitOption `json:"options,omitempty"`
+	Unit string `json:"unit,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Contents") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Contents") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *DropIn) MarshalJSON() ([]byte, error) {
+	type noMethod DropIn
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type DropInPage struct {
+	DropIns []*DropIn `json:"dropIns,omitempty"`
+
+	NextPageToken string `json:"nextPageToken,omitempty"`
 
 	// ServerResponse contains the HTTP response code and headers from the
 	// server.
 	googleapi.ServerResponse `json:"-"`
 
-	// ForceSendFields is a list of field names (e.g. "CurrentState") to
+	// ForceSendFields is a list of field names (e.g. "DropIns") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -210,29 +298,37 @@ type Unit struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "CurrentState") to include
-	// in API requests with the JSON null value. By default, fields with
-	// empty values are omitted from API requests. However, any field with
-	// an empty value appearing in NullFields will be sent to the server as
+	// NullFields is a list of field names (e.g. "DropIns") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
 	// null. It is an error if a field in this list has a non-empty value.
 	// This may be used to include null fields in Patch requests.
 	NullFields []string `json:"-"`
 }
 
-func (s *Unit) MarshalJSON() ([]byte, error) {
-	type noMethod Unit
+func (s *DropInPage) MarshalJSON() ([]byte, error) {
+	type noMethod DropInPage
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-type UnitOption struct {
-	Name string `json:"name,omitempty"`
+type Machine struct {
+	Cordoned bool `json:"cordoned,omitempty"`
 
-	Section string `json:"section,omitempty"`
+	FreeResources *Resources `json:"freeResources,omitempty"`
 
-	Value string `json:"value,omitempty"`
+	Id string `json:"id,omitempty"`
 
-	// ForceSendFields is a list of field names (e.g. "Name") to
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	PausedUnits []string `json:"pausedUnits,omitempty"`
+
+	PrimaryIP string `json:"primaryIP,omitempty"`
+
+	TotalResources *Resources `json:"totalResources,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Cordoned") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -240,8 +336,8 @@ type UnitOption struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "Name") to include in API
-	// requests with the JSON null value. By default, fields with empty
+	// NullFields is a list of field names (e.g. "Cordoned") to include in
+	// API requests with the JSON null value. By default, fields with empty
 	// values are omitted from API requests. However, any field with an
 	// empty value appearing in NullFields will be sent to the server as
 	// null. It is an error if a field in this list has a non-empty value.
@@ -249,22 +345,22 @@ type UnitOption struct {
 	NullFields []string `json:"-"`
 }
 
-func (s *UnitOption) MarshalJSON() ([]byte, error) {
-	type noMethod UnitOption
+func (s *Machine) MarshalJSON() ([]byte, error) {
+	type noMethod Machine
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-type UnitPage struct {
-	NextPageToken string `json:"nextPageToken,omitempty"`
+type MachinePage struct {
+	Machines []*Machine `json:"machines,omitempty"`
 
-	Units []*Unit `json:"units,omitempty"`
+	NextPageToken string `json:"nextPageToken,omitempty"`
 
 	// ServerResponse contains the HTTP response code and headers from the
 	// server.
 	googleapi.ServerResponse `json:"-"`
 
-	// ForceSendFields is a list of field names (e.g. "NextPageToken") to
+	// ForceSendFields is a list of field names (e.g. "Machines") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -272,39 +368,100 @@ type UnitPage struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "NextPageToken") to include
-	// in API requests with the JSON null value. By default, fields with
-	// empty values are omitted from API requests. However, any field with
-	// an empty value appearing in NullFields will be sent to the server as
+	// NullFields is a list of field names (e.g. "Machines") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
 	// null. It is an error if a field in this list has a non-empty value.
 	// This may be used to include null fields in Patch requests.
 	NullFields []string `json:"-"`
 }
 
-func (s *UnitPage) MarshalJSON() ([]byte, error) {
-	type noMethod UnitPage
+func (s *MachinePage) MarshalJSON() ([]byte, error) {
+	type noMethod MachinePage
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-type UnitState struct {
-	Hash string `json:"hash,omitempty"`
+type MaintenanceWindow struct {
+	End string `json:"end,omitempty"`
+
+	Lead string `json:"lead,omitempty"`
 
 	MachineID string `json:"machineID,omitempty"`
 
+	Metadata map[string]string `json:"metadata,omitempty"`
+
 	Name string `json:"name,omitempty"`
 
-	SystemdActiveState string `json:"systemdActiveState,omitempty"`
+	Phase string `json:"phase,omitempty"`
 
-	SystemdLoadState string `json:"systemdLoadState,omitempty"`
+	Start string `json:"start,omitempty"`
 
-	SystemdSubState string `json:"systemdSubState,omitempty"`
+	// ForceSendFields is a list of field names (e.g. "End") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "End") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *MaintenanceWindow) MarshalJSON() ([]byte, error) {
+	type noMethod MaintenanceWindow
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type MaintenanceWindowPage struct {
+	MaintenanceWindows []*MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	NextPageToken string `json:"nextPageToken,omitempty"`
 
 	// ServerResponse contains the HTTP response code and headers from the
 	// server.
 	googleapi.ServerResponse `json:"-"`
 
-	// ForceSendFields is a list of field names (e.g. "Hash") to
+	// ForceSendFields is a list of field names (e.g. "MaintenanceWindows")
+	// to unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "MaintenanceWindows") to
+	// include in API requests with the JSON null value. By default, fields
+	// with empty values are omitted from API requests. However, any field
+	// with an empty value appearing in NullFields will be sent to the
+	// server as null. It is an error if a field in this list has a
+	// non-empty value. This may be used to include null fields in Patch
+	// requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *MaintenanceWindowPage) MarshalJSON() ([]byte, error) {
+	type noMethod MaintenanceWindowPage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type Resources struct {
+	Cores int64 `json:"cores,omitempty"`
+
+	Disk int64 `json:"disk,omitempty"`
+
+	Memory int64 `json:"memory,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Cores") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -312,7 +469,7 @@ type UnitState struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "Hash") to include in API
+	// NullFields is a list of field names (e.g. "Cores") to include in API
 	// requests with the JSON null value. By default, fields with empty
 	// values are omitted from API requests. However, any field with an
 	// empty value appearing in NullFields will be sent to the server as
@@ -321,22 +478,20 @@ type UnitState struct {
 	NullFields []string `json:"-"`
 }
 
-func (s *UnitState) MarshalJSON() ([]byte, error) {
-	type noMethod UnitState
+func (s *Resources) MarshalJSON() ([]byte, error) {
+	type noMethod Resources
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-type UnitStatePage struct {
-	NextPageToken string `json:"nextPageToken,omitempty"`
-
-	States []*UnitState `json:"states,omitempty"`
+type ScheduleSimulation struct {
+	Tasks []*SimulatedTask `json:"tasks,omitempty"`
 
 	// ServerResponse contains the HTTP response code and headers from the
 	// server.
 	googleapi.ServerResponse `json:"-"`
 
-	// ForceSendFields is a list of field names (e.g. "NextPageToken") to
+	// ForceSendFields is a list of field names (e.g. "Tasks") to
 	// unconditionally include in API requests. By default, fields with
 	// empty values are omitted from API requests. However, any non-pointer,
 	// non-interface field appearing in ForceSendFields will be sent to the
@@ -344,79 +499,1696 @@ type UnitStatePage struct {
 	// used to include empty fields in Patch requests.
 	ForceSendFields []string `json:"-"`
 
-	// NullFields is a list of field names (e.g. "NextPageToken") to include
-	// in API requests with the JSON null value. By default, fields with
-	// empty values are omitted from API requests. However, any field with
-	// an empty value appearing in NullFields will be sent to the server as
+	// NullFields is a list of field names (e.g. "Tasks") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
 	// null. It is an error if a field in this list has a non-empty value.
 	// This may be used to include null fields in Patch requests.
 	NullFields []string `json:"-"`
 }
 
-func (s *UnitStatePage) MarshalJSON() ([]byte, error) {
-	type noMethod UnitStatePage
+func (s *ScheduleSimulation) MarshalJSON() ([]byte, error) {
+	type noMethod ScheduleSimulation
 	raw := noMethod(*s)
 	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-// method id "fleet.Machine.List":
+type SchedulingRejection struct {
+	MachineID string `json:"machineID,omitempty"`
 
-type MachinesListCall struct {
-	s            *Service
-	urlParams_   gensupport.URLParams
-	ifNoneMatch_ string
-	ctx_         context.Context
-	header_      http.Header
-}
+	Reason string `json:"reason,omitempty"`
 
-// List: Retrieve a page of Machine objects.
-func (r *MachinesService) List() *MachinesListCall {
-	c := &MachinesListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
-	return c
-}
+	// ForceSendFields is a list of field names (e.g. "MachineID") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
 
-// NextPageToken sets the optional parameter "nextPageToken":
-func (c *MachinesListCall) NextPageToken(nextPageToken string) *MachinesListCall {
-	c.urlParams_.Set("nextPageToken", nextPageToken)
-	return c
+	// NullFields is a list of field names (e.g. "MachineID") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
 }
 
-// Fields allows partial responses to be retrieved. See
-// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
-// for more information.
-func (c *MachinesListCall) Fields(s ...googleapi.Field) *MachinesListCall {
-	c.urlParams_.Set("fields", googleapi.CombineFields(s))
-	return c
+func (s *SchedulingRejection) MarshalJSON() ([]byte, error) {
+	type noMethod SchedulingRejection
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
 }
 
-// IfNoneMatch sets the optional parameter which makes the operation
-// fail if the object's ETag matches the given value. This is useful for
-// getting updates only after the object has changed since the last
-// request. Use googleapi.IsNotModified to check whether the response
-// error from Do is the result of In-None-Match.
-func (c *MachinesListCall) IfNoneMatch(entityTag string) *MachinesListCall {
-	c.ifNoneMatch_ = entityTag
-	return c
+type SimulatedTask struct {
+	MachineID string `json:"machineID,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	Type string `json:"type,omitempty"`
+
+	UnitName string `json:"unitName,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "MachineID") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "MachineID") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *SimulatedTask) MarshalJSON() ([]byte, error) {
+	type noMethod SimulatedTask
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type Unit struct {
+	Client string `json:"client,omitempty"`
+
+	// Possible values:
+	//   "inactive"
+	//   "loaded"
+	//   "launched"
+	//   "completed"
+	CurrentState string `json:"currentState,omitempty"`
+
+	// Possible values:
+	//   "inactive"
+	//   "loaded"
+	//   "launched"
+	DesiredState string `json:"desiredState,omitempty"`
+
+	MachineID string `json:"machineID,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	Options []*UnitOption `json:"options,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "Client") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Client") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *Unit) MarshalJSON() ([]byte, error) {
+	type noMethod Unit
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitOption struct {
+	Name string `json:"name,omitempty"`
+
+	Section string `json:"section,omitempty"`
+
+	Value string `json:"value,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Name") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Name") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitOption) MarshalJSON() ([]byte, error) {
+	type noMethod UnitOption
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitPage struct {
+	NextPageToken string `json:"nextPageToken,omitempty"`
+
+	Units []*Unit `json:"units,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "NextPageToken") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "NextPageToken") to include
+	// in API requests with the JSON null value. By default, fields with
+	// empty values are omitted from API requests. However, any field with
+	// an empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitPage) MarshalJSON() ([]byte, error) {
+	type noMethod UnitPage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitRevision struct {
+	Client string `json:"client,omitempty"`
+
+	Created string `json:"created,omitempty"`
+
+	Hash string `json:"hash,omitempty"`
+
+	Number int64 `json:"number,omitempty"`
+
+	Options []*UnitOption `json:"options,omitempty"`
+
+	// ForceSendFields is a list of field names (e.g. "Client") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Client") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitRevision) MarshalJSON() ([]byte, error) {
+	type noMethod UnitRevision
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitRevisionPage struct {
+	Revisions []*UnitRevision `json:"revisions,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "Revisions") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Revisions") to include in
+	// API requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitRevisionPage) MarshalJSON() ([]byte, error) {
+	type noMethod UnitRevisionPage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitScheduling struct {
+	Name string `json:"name,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	Rejections []*SchedulingRejection `json:"rejections,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "Name") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "Name") to include in API
+	// requests with the JSON null value. By default, fields with empty
+	// values are omitted from API requests. However, any field with an
+	// empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitScheduling) MarshalJSON() ([]byte, error) {
+	type noMethod UnitScheduling
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitState struct {
+	ActiveEnterTimestamp string `json:"activeEnterTimestamp,omitempty"`
+
+	ExecMainStatus int64 `json:"execMainStatus,omitempty"`
+
+	Hash string `json:"hash,omitempty"`
+
+	// Possible values:
+	//   "healthy"
+	//   "unhealthy"
+	Health string `json:"health,omitempty"`
+
+	MachineID string `json:"machineID,omitempty"`
+
+	MainPID int64 `json:"mainPID,omitempty"`
+
+	NRestarts int64 `json:"nRestarts,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	Result string `json:"result,omitempty"`
+
+	SystemdActiveState string `json:"systemdActiveState,omitempty"`
+
+	SystemdLoadState string `json:"systemdLoadState,omitempty"`
+
+	SystemdSubState string `json:"systemdSubState,omitempty"`
+
+	Usage *UnitUsage `json:"usage,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g.
+	// "ActiveEnterTimestamp") to unconditionally include in API requests.
+	// By default, fields with empty values are omitted from API requests.
+	// However, any non-pointer, non-interface field appearing in
+	// ForceSendFields will be sent to the server regardless of whether the
+	// field is empty or not. This may be used to include empty fields in
+	// Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "ActiveEnterTimestamp") to
+	// include in API requests with the JSON null value. By default, fields
+	// with empty values are omitted from API requests. However, any field
+	// with an empty value appearing in NullFields will be sent to the
+	// server as null. It is an error if a field in this list has a
+	// non-empty value. This may be used to include null fields in Patch
+	// requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitState) MarshalJSON() ([]byte, error) {
+	type noMethod UnitState
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitStatePage struct {
+	NextPageToken string `json:"nextPageToken,omitempty"`
+
+	States []*UnitState `json:"states,omitempty"`
+
+	// ServerResponse contains the HTTP response code and headers from the
+	// server.
+	googleapi.ServerResponse `json:"-"`
+
+	// ForceSendFields is a list of field names (e.g. "NextPageToken") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "NextPageToken") to include
+	// in API requests with the JSON null value. By default, fields with
+	// empty values are omitted from API requests. However, any field with
+	// an empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitStatePage) MarshalJSON() ([]byte, error) {
+	type noMethod UnitStatePage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+type UnitUsage struct {
+	CpuUsageNSec uint64 `json:"cpuUsageNSec,omitempty,string"`
+
+	MemoryCurrent uint64 `json:"memoryCurrent,omitempty,string"`
+
+	MemoryPeak uint64 `json:"memoryPeak,omitempty,string"`
+
+	Tasks uint64 `json:"tasks,omitempty,string"`
+
+	// ForceSendFields is a list of field names (e.g. "CpuUsageNSec") to
+	// unconditionally include in API requests. By default, fields with
+	// empty values are omitted from API requests. However, any non-pointer,
+	// non-interface field appearing in ForceSendFields will be sent to the
+	// server regardless of whether the field is empty or not. This may be
+	// used to include empty fields in Patch requests.
+	ForceSendFields []string `json:"-"`
+
+	// NullFields is a list of field names (e.g. "CpuUsageNSec") to include
+	// in API requests with the JSON null value. By default, fields with
+	// empty values are omitted from API requests. However, any field with
+	// an empty value appearing in NullFields will be sent to the server as
+	// null. It is an error if a field in this list has a non-empty value.
+	// This may be used to include null fields in Patch requests.
+	NullFields []string `json:"-"`
+}
+
+func (s *UnitUsage) MarshalJSON() ([]byte, error) {
+	type noMethod UnitUsage
+	raw := noMethod(*s)
+	return gensupport.MarshalJSON(raw, s.ForceSendFields, s.NullFields)
+}
+
+// method id "fleet.Deployment.Delete":
+
+type DeploymentsDeleteCall struct {
+	s              *Service
+	deploymentName string
+	urlParams_     gensupport.URLParams
+	ctx_           context.Context
+	header_        http.Header
+}
+
+// Delete: Delete the referenced Deployment object.
+func (r *DeploymentsService) Delete(deploymentName string) *DeploymentsDeleteCall {
+	c := &DeploymentsDeleteCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.deploymentName = deploymentName
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *DeploymentsDeleteCall) Fields(s ...googleapi.Field) *DeploymentsDeleteCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *DeploymentsDeleteCall) Context(ctx context.Context) *DeploymentsDeleteCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *DeploymentsDeleteCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *DeploymentsDeleteCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "deployments/{deploymentName}")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("DELETE", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"deploymentName": c.deploymentName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Deployment.Delete" call.
+func (c *DeploymentsDeleteCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Delete the referenced Deployment object.",
+	//   "httpMethod": "DELETE",
+	//   "id": "fleet.Deployment.Delete",
+	//   "parameterOrder": [
+	//     "deploymentName"
+	//   ],
+	//   "parameters": {
+	//     "deploymentName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "deployments/{deploymentName}"
+	// }
+
+}
+
+// method id "fleet.Deployment.List":
+
+type DeploymentsListCall struct {
+	s            *Service
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// List: Retrieve a page of Deployment objects.
+func (r *DeploymentsService) List() *DeploymentsListCall {
+	c := &DeploymentsListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	return c
+}
+
+// NextPageToken sets the optional parameter "nextPageToken":
+func (c *DeploymentsListCall) NextPageToken(nextPageToken string) *DeploymentsListCall {
+	c.urlParams_.Set("nextPageToken", nextPageToken)
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *DeploymentsListCall) Fields(s ...googleapi.Field) *DeploymentsListCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *DeploymentsListCall) IfNoneMatch(entityTag string) *DeploymentsListCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *DeploymentsListCall) Context(ctx context.Context) *DeploymentsListCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *DeploymentsListCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *DeploymentsListCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "deployments")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Deployment.List" call.
+// Exactly one of *DeploymentPage or error will be non-nil. Any non-2xx
+// status code is an error. Response headers are in either
+// *DeploymentPage.ServerResponse.Header or (if a response was returned
+// at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *DeploymentsListCall) Do(opts ...googleapi.CallOption) (*DeploymentPage, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &DeploymentPage{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve a page of Deployment objects.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.Deployment.List",
+	//   "parameters": {
+	//     "nextPageToken": {
+	//       "location": "query",
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "deployments",
+	//   "response": {
+	//     "$ref": "DeploymentPage"
+	//   }
+	// }
+
+}
+
+// method id "fleet.Deployment.Set":
+
+type DeploymentsSetCall struct {
+	s              *Service
+	deploymentName string
+	deployment     *Deployment
+	urlParams_     gensupport.URLParams
+	ctx_           context.Context
+	header_        http.Header
+}
+
+// Set: Create or update a Deployment.
+func (r *DeploymentsService) Set(deploymentName string, deployment *Deployment) *DeploymentsSetCall {
+	c := &DeploymentsSetCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.deploymentName = deploymentName
+	c.deployment = deployment
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *DeploymentsSetCall) Fields(s ...googleapi.Field) *DeploymentsSetCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *DeploymentsSetCall) Context(ctx context.Context) *DeploymentsSetCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *DeploymentsSetCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *DeploymentsSetCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	body, err := googleapi.WithoutDataWrapper.JSONReader(c.deployment)
+	if err != nil {
+		return nil, err
+	}
+	reqHeaders.Set("Content-Type", "application/json")
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "deployments/{deploymentName}")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("PUT", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"deploymentName": c.deploymentName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Deployment.Set" call.
+func (c *DeploymentsSetCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Create or update a Deployment.",
+	//   "httpMethod": "PUT",
+	//   "id": "fleet.Deployment.Set",
+	//   "parameterOrder": [
+	//     "deploymentName"
+	//   ],
+	//   "parameters": {
+	//     "deploymentName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "deployments/{deploymentName}",
+	//   "request": {
+	//     "$ref": "Deployment"
+	//   }
+	// }
+
+}
+
+// method id "fleet.DropIns.Delete":
+
+type DropInsDeleteCall struct {
+	s          *Service
+	unitName   string
+	dropInName string
+	urlParams_ gensupport.URLParams
+	ctx_       context.Context
+	header_    http.Header
+}
+
+// Delete: Delete the referenced DropIn object.
+func (r *DropInsService) Delete(unitName string, dropInName string) *DropInsDeleteCall {
+	c := &DropInsDeleteCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.unitName = unitName
+	c.dropInName = dropInName
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *DropInsDeleteCall) Fields(s ...googleapi.Field) *DropInsDeleteCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *DropInsDeleteCall) Context(ctx context.Context) *DropInsDeleteCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *DropInsDeleteCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *DropInsDeleteCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "dropins/{unitName}/{dropInName}")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("DELETE", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"unitName":   c.unitName,
+		"dropInName": c.dropInName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.DropIns.Delete" call.
+func (c *DropInsDeleteCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Delete the referenced DropIn object.",
+	//   "httpMethod": "DELETE",
+	//   "id": "fleet.DropIns.Delete",
+	//   "parameterOrder": [
+	//     "unitName",
+	//     "dropInName"
+	//   ],
+	//   "parameters": {
+	//     "dropInName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     },
+	//     "unitName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "dropins/{unitName}/{dropInName}"
+	// }
+
+}
+
+// method id "fleet.DropIns.List":
+
+type DropInsListCall struct {
+	s            *Service
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// List: Retrieve a page of DropIn objects.
+func (r *DropInsService) List() *DropInsListCall {
+	c := &DropInsListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	return c
+}
+
+// NextPageToken sets the optional parameter "nextPageToken":
+func (c *DropInsListCall) NextPageToken(nextPageToken string) *DropInsListCall {
+	c.urlParams_.Set("nextPageToken", nextPageToken)
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *DropInsListCall) Fields(s ...googleapi.Field) *DropInsListCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *DropInsListCall) IfNoneMatch(entityTag string) *DropInsListCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *DropInsListCall) Context(ctx context.Context) *DropInsListCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *DropInsListCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *DropInsListCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "dropins")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.DropIns.List" call.
+// Exactly one of *DropInPage or error will be non-nil. Any non-2xx
+// status code is an error. Response headers are in either
+// *DropInPage.ServerResponse.Header or (if a response was returned at
+// all) in error.(*googleapi.Error).Header. Use googleapi.IsNotModified
+// to check whether the returned error was because
+// http.StatusNotModified was returned.
+func (c *DropInsListCall) Do(opts ...googleapi.CallOption) (*DropInPage, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &DropInPage{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve a page of DropIn objects.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.DropIns.List",
+	//   "parameters": {
+	//     "nextPageToken": {
+	//       "location": "query",
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "dropins",
+	//   "response": {
+	//     "$ref": "DropInPage"
+	//   }
+	// }
+
+}
+
+// method id "fleet.DropIns.Set":
+
+type DropInsSetCall struct {
+	s          *Service
+	unitName   string
+	dropInName string
+	dropin     *DropIn
+	urlParams_ gensupport.URLParams
+	ctx_       context.Context
+	header_    http.Header
+}
+
+// Set: Create or update a DropIn.
+func (r *DropInsService) Set(unitName string, dropInName string, dropin *DropIn) *DropInsSetCall {
+	c := &DropInsSetCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.unitName = unitName
+	c.dropInName = dropInName
+	c.dropin = dropin
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *DropInsSetCall) Fields(s ...googleapi.Field) *DropInsSetCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *DropInsSetCall) Context(ctx context.Context) *DropInsSetCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *DropInsSetCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *DropInsSetCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	body, err := googleapi.WithoutDataWrapper.JSONReader(c.dropin)
+	if err != nil {
+		return nil, err
+	}
+	reqHeaders.Set("Content-Type", "application/json")
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "dropins/{unitName}/{dropInName}")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("PUT", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"unitName":   c.unitName,
+		"dropInName": c.dropInName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.DropIns.Set" call.
+func (c *DropInsSetCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Create or update a DropIn.",
+	//   "httpMethod": "PUT",
+	//   "id": "fleet.DropIns.Set",
+	//   "parameterOrder": [
+	//     "unitName",
+	//     "dropInName"
+	//   ],
+	//   "parameters": {
+	//     "dropInName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     },
+	//     "unitName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "dropins/{unitName}/{dropInName}",
+	//   "request": {
+	//     "$ref": "DropIn"
+	//   }
+	// }
+
+}
+
+// method id "fleet.Machine.List":
+
+type MachinesListCall struct {
+	s            *Service
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// List: Retrieve a page of Machine objects.
+func (r *MachinesService) List() *MachinesListCall {
+	c := &MachinesListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	return c
+}
+
+// NextPageToken sets the optional parameter "nextPageToken":
+func (c *MachinesListCall) NextPageToken(nextPageToken string) *MachinesListCall {
+	c.urlParams_.Set("nextPageToken", nextPageToken)
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *MachinesListCall) Fields(s ...googleapi.Field) *MachinesListCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *MachinesListCall) IfNoneMatch(entityTag string) *MachinesListCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *MachinesListCall) Context(ctx context.Context) *MachinesListCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *MachinesListCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *MachinesListCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "machines")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Machine.List" call.
+// Exactly one of *MachinePage or error will be non-nil. Any non-2xx
+// status code is an error. Response headers are in either
+// *MachinePage.ServerResponse.Header or (if a response was returned at
+// all) in error.(*googleapi.Error).Header. Use googleapi.IsNotModified
+// to check whether the returned error was because
+// http.StatusNotModified was returned.
+func (c *MachinesListCall) Do(opts ...googleapi.CallOption) (*MachinePage, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &MachinePage{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve a page of Machine objects.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.Machine.List",
+	//   "parameters": {
+	//     "nextPageToken": {
+	//       "location": "query",
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "machines",
+	//   "response": {
+	//     "$ref": "MachinePage"
+	//   }
+	// }
+
+}
+
+// method id "fleet.Maintenance.Delete":
+
+type MaintenanceDeleteCall struct {
+	s          *Service
+	windowName string
+	urlParams_ gensupport.URLParams
+	ctx_       context.Context
+	header_    http.Header
+}
+
+// Delete: Delete the referenced MaintenanceWindow object.
+func (r *MaintenanceService) Delete(windowName string) *MaintenanceDeleteCall {
+	c := &MaintenanceDeleteCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.windowName = windowName
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *MaintenanceDeleteCall) Fields(s ...googleapi.Field) *MaintenanceDeleteCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *MaintenanceDeleteCall) Context(ctx context.Context) *MaintenanceDeleteCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *MaintenanceDeleteCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *MaintenanceDeleteCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "maintenance/{windowName}")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("DELETE", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"windowName": c.windowName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Maintenance.Delete" call.
+func (c *MaintenanceDeleteCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Delete the referenced MaintenanceWindow object.",
+	//   "httpMethod": "DELETE",
+	//   "id": "fleet.Maintenance.Delete",
+	//   "parameterOrder": [
+	//     "windowName"
+	//   ],
+	//   "parameters": {
+	//     "windowName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "maintenance/{windowName}"
+	// }
+
+}
+
+// method id "fleet.Maintenance.List":
+
+type MaintenanceListCall struct {
+	s            *Service
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// List: Retrieve a page of MaintenanceWindow objects.
+func (r *MaintenanceService) List() *MaintenanceListCall {
+	c := &MaintenanceListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	return c
+}
+
+// NextPageToken sets the optional parameter "nextPageToken":
+func (c *MaintenanceListCall) NextPageToken(nextPageToken string) *MaintenanceListCall {
+	c.urlParams_.Set("nextPageToken", nextPageToken)
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *MaintenanceListCall) Fields(s ...googleapi.Field) *MaintenanceListCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *MaintenanceListCall) IfNoneMatch(entityTag string) *MaintenanceListCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *MaintenanceListCall) Context(ctx context.Context) *MaintenanceListCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *MaintenanceListCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *MaintenanceListCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "maintenance")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Maintenance.List" call.
+// Exactly one of *MaintenanceWindowPage or error will be non-nil. Any
+// non-2xx status code is an error. Response headers are in either
+// *MaintenanceWindowPage.ServerResponse.Header or (if a response was
+// returned at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *MaintenanceListCall) Do(opts ...googleapi.CallOption) (*MaintenanceWindowPage, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &MaintenanceWindowPage{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve a page of MaintenanceWindow objects.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.Maintenance.List",
+	//   "parameters": {
+	//     "nextPageToken": {
+	//       "location": "query",
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "maintenance",
+	//   "response": {
+	//     "$ref": "MaintenanceWindowPage"
+	//   }
+	// }
+
+}
+
+// method id "fleet.Maintenance.Set":
+
+type MaintenanceSetCall struct {
+	s                 *Service
+	windowName        string
+	maintenancewindow *MaintenanceWindow
+	urlParams_        gensupport.URLParams
+	ctx_              context.Context
+	header_           http.Header
+}
+
+// Set: Create or update a MaintenanceWindow.
+func (r *MaintenanceService) Set(windowName string, maintenancewindow *MaintenanceWindow) *MaintenanceSetCall {
+	c := &MaintenanceSetCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.windowName = windowName
+	c.maintenancewindow = maintenancewindow
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *MaintenanceSetCall) Fields(s ...googleapi.Field) *MaintenanceSetCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *MaintenanceSetCall) Context(ctx context.Context) *MaintenanceSetCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *MaintenanceSetCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *MaintenanceSetCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	body, err := googleapi.WithoutDataWrapper.JSONReader(c.maintenancewindow)
+	if err != nil {
+		return nil, err
+	}
+	reqHeaders.Set("Content-Type", "application/json")
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "maintenance/{windowName}")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("PUT", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"windowName": c.windowName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Maintenance.Set" call.
+func (c *MaintenanceSetCall) Do(opts ...googleapi.CallOption) error {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return err
+	}
+	return nil
+	// {
+	//   "description": "Create or update a MaintenanceWindow.",
+	//   "httpMethod": "PUT",
+	//   "id": "fleet.Maintenance.Set",
+	//   "parameterOrder": [
+	//     "windowName"
+	//   ],
+	//   "parameters": {
+	//     "windowName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "maintenance/{windowName}",
+	//   "request": {
+	//     "$ref": "MaintenanceWindow"
+	//   }
+	// }
+
+}
+
+// method id "fleet.Schedule.Simulate":
+
+type ScheduleSimulateCall struct {
+	s          *Service
+	unit       *Unit
+	urlParams_ gensupport.URLParams
+	ctx_       context.Context
+	header_    http.Header
+}
+
+// Simulate: Compute the scheduling tasks submitting a Unit would cause,
+// without performing them.
+func (r *ScheduleService) Simulate(unit *Unit) *ScheduleSimulateCall {
+	c := &ScheduleSimulateCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.unit = unit
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *ScheduleSimulateCall) Fields(s ...googleapi.Field) *ScheduleSimulateCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
 }
 
 // Context sets the context to be used in this call's Do method. Any
 // pending HTTP request will be aborted if the provided context is
 // canceled.
-func (c *MachinesListCall) Context(ctx context.Context) *MachinesListCall {
+func (c *ScheduleSimulateCall) Context(ctx context.Context) *ScheduleSimulateCall {
 	c.ctx_ = ctx
 	return c
 }
 
 // Header returns an http.Header that can be modified by the caller to
 // add HTTP headers to the request.
-func (c *MachinesListCall) Header() http.Header {
+func (c *ScheduleSimulateCall) Header() http.Header {
 	if c.header_ == nil {
 		c.header_ = make(http.Header)
 	}
 	return c.header_
 }
 
-func (c *MachinesListCall) doRequest(alt string) (*http.Response, error) {
+func (c *ScheduleSimulateCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	var body io.Reader = nil
+	body, err := googleapi.WithoutDataWrapper.JSONReader(c.unit)
+	if err != nil {
+		return nil, err
+	}
+	reqHeaders.Set("Content-Type", "application/json")
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "./schedule:simulate")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("POST", urls, body)
+	req.Header = reqHeaders
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.Schedule.Simulate" call.
+// Exactly one of *ScheduleSimulation or error will be non-nil. Any
+// non-2xx status code is an error. Response headers are in either
+// *ScheduleSimulation.ServerResponse.Header or (if a response was
+// returned at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *ScheduleSimulateCall) Do(opts ...googleapi.CallOption) (*ScheduleSimulation, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &ScheduleSimulation{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Compute the scheduling tasks submitting a Unit would cause, without performing them.",
+	//   "httpMethod": "POST",
+	//   "id": "fleet.Schedule.Simulate",
+	//   "path": "./schedule:simulate",
+	//   "request": {
+	//     "$ref": "Unit"
+	//   },
+	//   "response": {
+	//     "$ref": "ScheduleSimulation"
+	//   }
+	// }
+
+}
+
+// method id "fleet.UnitRevisions.List":
+
+type UnitRevisionsListCall struct {
+	s            *Service
+	unitName     string
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// List: Retrieve the revisions of a Unit, oldest first.
+func (r *UnitRevisionsService) List(unitName string) *UnitRevisionsListCall {
+	c := &UnitRevisionsListCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.unitName = unitName
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *UnitRevisionsListCall) Fields(s ...googleapi.Field) *UnitRevisionsListCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *UnitRevisionsListCall) IfNoneMatch(entityTag string) *UnitRevisionsListCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *UnitRevisionsListCall) Context(ctx context.Context) *UnitRevisionsListCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *UnitRevisionsListCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *UnitRevisionsListCall) doRequest(alt string) (*http.Response, error) {
 	reqHeaders := make(http.Header)
 	for k, v := range c.header_ {
 		reqHeaders[k] = v
@@ -427,21 +2199,157 @@ func (c *MachinesListCall) doRequest(alt string) (*http.Response, error) {
 	}
 	var body io.Reader = nil
 	c.urlParams_.Set("alt", alt)
-	urls := googleapi.ResolveRelative(c.s.BasePath, "machines")
+	urls := googleapi.ResolveRelative(c.s.BasePath, "units/{unitName}/revisions")
 	urls += "?" + c.urlParams_.Encode()
 	req, _ := http.NewRequest("GET", urls, body)
 	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"unitName": c.unitName,
+	})
 	return gensupport.SendRequest(c.ctx_, c.s.client, req)
 }
 
-// Do executes the "fleet.Machine.List" call.
-// Exactly one of *MachinePage or error will be non-nil. Any non-2xx
+// Do executes the "fleet.UnitRevisions.List" call.
+// Exactly one of *UnitRevisionPage or error will be non-nil. Any
+// non-2xx status code is an error. Response headers are in either
+// *UnitRevisionPage.ServerResponse.Header or (if a response was
+// returned at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *UnitRevisionsListCall) Do(opts ...googleapi.CallOption) (*UnitRevisionPage, error) {
+	gensupport.SetOptions(c.urlParams_, opts...)
+	res, err := c.doRequest("json")
+	if res != nil && res.StatusCode == http.StatusNotModified {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, &googleapi.Error{
+			Code:   res.StatusCode,
+			Header: res.Header,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(res)
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	ret := &UnitRevisionPage{
+		ServerResponse: googleapi.ServerResponse{
+			Header:         res.Header,
+			HTTPStatusCode: res.StatusCode,
+		},
+	}
+	target := &ret
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return nil, err
+	}
+	return ret, nil
+	// {
+	//   "description": "Retrieve the revisions of a Unit, oldest first.",
+	//   "httpMethod": "GET",
+	//   "id": "fleet.UnitRevisions.List",
+	//   "parameterOrder": [
+	//     "unitName"
+	//   ],
+	//   "parameters": {
+	//     "unitName": {
+	//       "location": "path",
+	//       "required": true,
+	//       "type": "string"
+	//     }
+	//   },
+	//   "path": "units/{unitName}/revisions",
+	//   "response": {
+	//     "$ref": "UnitRevisionPage"
+	//   }
+	// }
+
+}
+
+// method id "fleet.UnitScheduling.Get":
+
+type UnitSchedulingGetCall struct {
+	s            *Service
+	unitName     string
+	urlParams_   gensupport.URLParams
+	ifNoneMatch_ string
+	ctx_         context.Context
+	header_      http.Header
+}
+
+// Get: Retrieve the reasons a Unit could not be scheduled.
+func (r *UnitSchedulingService) Get(unitName string) *UnitSchedulingGetCall {
+	c := &UnitSchedulingGetCall{s: r.s, urlParams_: make(gensupport.URLParams)}
+	c.unitName = unitName
+	return c
+}
+
+// Fields allows partial responses to be retrieved. See
+// https://developers.google.com/gdata/docs/2.0/basics#PartialResponse
+// for more information.
+func (c *UnitSchedulingGetCall) Fields(s ...googleapi.Field) *UnitSchedulingGetCall {
+	c.urlParams_.Set("fields", googleapi.CombineFields(s))
+	return c
+}
+
+// IfNoneMatch sets the optional parameter which makes the operation
+// fail if the object's ETag matches the given value. This is useful for
+// getting updates only after the object has changed since the last
+// request. Use googleapi.IsNotModified to check whether the response
+// error from Do is the result of In-None-Match.
+func (c *UnitSchedulingGetCall) IfNoneMatch(entityTag string) *UnitSchedulingGetCall {
+	c.ifNoneMatch_ = entityTag
+	return c
+}
+
+// Context sets the context to be used in this call's Do method. Any
+// pending HTTP request will be aborted if the provided context is
+// canceled.
+func (c *UnitSchedulingGetCall) Context(ctx context.Context) *UnitSchedulingGetCall {
+	c.ctx_ = ctx
+	return c
+}
+
+// Header returns an http.Header that can be modified by the caller to
+// add HTTP headers to the request.
+func (c *UnitSchedulingGetCall) Header() http.Header {
+	if c.header_ == nil {
+		c.header_ = make(http.Header)
+	}
+	return c.header_
+}
+
+func (c *UnitSchedulingGetCall) doRequest(alt string) (*http.Response, error) {
+	reqHeaders := make(http.Header)
+	for k, v := range c.header_ {
+		reqHeaders[k] = v
+	}
+	reqHeaders.Set("User-Agent", c.s.userAgent())
+	if c.ifNoneMatch_ != "" {
+		reqHeaders.Set("If-None-Match", c.ifNoneMatch_)
+	}
+	var body io.Reader = nil
+	c.urlParams_.Set("alt", alt)
+	urls := googleapi.ResolveRelative(c.s.BasePath, "units/{unitName}/scheduling")
+	urls += "?" + c.urlParams_.Encode()
+	req, _ := http.NewRequest("GET", urls, body)
+	req.Header = reqHeaders
+	googleapi.Expand(req.URL, map[string]string{
+		"unitName": c.unitName,
+	})
+	return gensupport.SendRequest(c.ctx_, c.s.client, req)
+}
+
+// Do executes the "fleet.UnitScheduling.Get" call.
+// Exactly one of *UnitScheduling or error will be non-nil. Any non-2xx
 // status code is an error. Response headers are in either
-// *MachinePage.ServerResponse.Header or (if a response was returned at
-// all) in error.(*googleapi.Error).Header. Use googleapi.IsNotModified
-// to check whether the returned error was because
-// http.StatusNotModified was returned.
-func (c *MachinesListCall) Do(opts ...googleapi.CallOption) (*MachinePage, error) {
+// *UnitScheduling.ServerResponse.Header or (if a response was returned
+// at all) in error.(*googleapi.Error).Header. Use
+// googleapi.IsNotModified to check whether the returned error was
+// because http.StatusNotModified was returned.
+func (c *UnitSchedulingGetCall) Do(opts ...googleapi.CallOption) (*UnitScheduling, error) {
 	gensupport.SetOptions(c.urlParams_, opts...)
 	res, err := c.doRequest("json")
 	if res != nil && res.StatusCode == http.StatusNotModified {
@@ -460,7 +2368,7 @@ func (c *MachinesListCall) Do(opts ...googleapi.CallOption) (*MachinePage, error
 	if err := googleapi.CheckResponse(res); err != nil {
 		return nil, err
 	}
-	ret := &MachinePage{
+	ret := &UnitScheduling{
 		ServerResponse: googleapi.ServerResponse{
 			Header:         res.Header,
 			HTTPStatusCode: res.StatusCode,
@@ -472,18 +2380,22 @@ func (c *MachinesListCall) Do(opts ...googleapi.CallOption) (*MachinePage, error
 	}
 	return ret, nil
 	// {
-	//   "description": "Retrieve a page of Machine objects.",
+	//   "description": "Retrieve the reasons a Unit could not be scheduled.",
 	//   "httpMethod": "GET",
-	//   "id": "fleet.Machine.List",
+	//   "id": "fleet.UnitScheduling.Get",
+	//   "parameterOrder": [
+	//     "unitName"
+	//   ],
 	//   "parameters": {
-	//     "nextPageToken": {
-	//       "location": "query",
+	//     "unitName": {
+	//       "location": "path",
+	//       "required": true,
 	//       "type": "string"
 	//     }
 	//   },
-	//   "path": "machines",
+	//   "path": "units/{unitName}/scheduling",
 	//   "response": {
-	//     "$ref": "MachinePage"
+	//     "$ref": "UnitScheduling"
 	//   }
 	// }
 
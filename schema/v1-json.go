@@ -58,6 +58,39 @@ const DiscoveryJSON = `{
           "additionalProperties": {
             "type": "string"
           }
+        },
+        "totalResources": {
+          "$ref": "Resources"
+        },
+        "freeResources": {
+          "$ref": "Resources"
+        },
+        "cordoned": {
+          "type": "boolean"
+        },
+        "pausedUnits": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
+    "Resources": {
+      "id": "Resources",
+      "type": "object",
+      "properties": {
+        "cores": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "memory": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "disk": {
+          "type": "integer",
+          "format": "int32"
         }
       }
     },
@@ -117,12 +150,16 @@ const DiscoveryJSON = `{
           "enum": [
             "inactive",
             "loaded",
-            "launched"
+            "launched",
+            "completed"
           ]
         },
         "machineID": {
           "type": "string",
           "required": true
+        },
+        "client": {
+          "type": "string"
         }
       }
     },
@@ -141,6 +178,44 @@ const DiscoveryJSON = `{
         }
       }
     },
+    "UnitRevision": {
+      "id": "UnitRevision",
+      "type": "object",
+      "properties": {
+        "number": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "created": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "client": {
+          "type": "string"
+        },
+        "hash": {
+          "type": "string"
+        },
+        "options": {
+          "type": "array",
+          "items": {
+            "$ref": "UnitOption"
+          }
+        }
+      }
+    },
+    "UnitRevisionPage": {
+      "id": "UnitRevisionPage",
+      "type": "object",
+      "properties": {
+        "revisions": {
+          "type": "array",
+          "items": {
+            "$ref": "UnitRevision"
+          }
+        }
+      }
+    },
     "UnitState": {
       "id": "UnitState",
       "type": "object",
@@ -162,6 +237,57 @@ const DiscoveryJSON = `{
         },
         "systemdSubState": {
           "type": "string"
+        },
+        "health": {
+          "type": "string",
+          "enum": [
+            "healthy",
+            "unhealthy"
+          ]
+        },
+        "mainPID": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "execMainStatus": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "nRestarts": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "activeEnterTimestamp": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "result": {
+          "type": "string"
+        },
+        "usage": {
+          "$ref": "UnitUsage"
+        }
+      }
+    },
+    "UnitUsage": {
+      "id": "UnitUsage",
+      "type": "object",
+      "properties": {
+        "memoryCurrent": {
+          "type": "string",
+          "format": "uint64"
+        },
+        "memoryPeak": {
+          "type": "string",
+          "format": "uint64"
+        },
+        "cpuUsageNSec": {
+          "type": "string",
+          "format": "uint64"
+        },
+        "tasks": {
+          "type": "string",
+          "format": "uint64"
         }
       }
     },
@@ -179,6 +305,180 @@ const DiscoveryJSON = `{
           "type": "string"
         }
       }
+    },
+    "UnitScheduling": {
+      "id": "UnitScheduling",
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "reason": {
+          "type": "string"
+        },
+        "rejections": {
+          "type": "array",
+          "items": {
+            "$ref": "SchedulingRejection"
+          }
+        }
+      }
+    },
+    "SchedulingRejection": {
+      "id": "SchedulingRejection",
+      "type": "object",
+      "properties": {
+        "machineID": {
+          "type": "string"
+        },
+        "reason": {
+          "type": "string"
+        }
+      }
+    }
+,
+    "ScheduleSimulation": {
+      "id": "ScheduleSimulation",
+      "type": "object",
+      "properties": {
+        "tasks": {
+          "type": "array",
+          "items": {
+            "$ref": "SimulatedTask"
+          }
+        }
+      }
+    },
+    "SimulatedTask": {
+      "id": "SimulatedTask",
+      "type": "object",
+      "properties": {
+        "type": {
+          "type": "string"
+        },
+        "reason": {
+          "type": "string"
+        },
+        "unitName": {
+          "type": "string"
+        },
+        "machineID": {
+          "type": "string"
+        }
+      }
+    },
+    "MaintenanceWindow": {
+      "id": "MaintenanceWindow",
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "machineID": {
+          "type": "string"
+        },
+        "metadata": {
+          "type": "object",
+          "properties": {},
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "lead": {
+          "type": "string"
+        },
+        "start": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "end": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "phase": {
+          "type": "string"
+        }
+      }
+    },
+    "MaintenanceWindowPage": {
+      "id": "MaintenanceWindowPage",
+      "type": "object",
+      "properties": {
+        "maintenanceWindows": {
+          "type": "array",
+          "items": {
+            "$ref": "MaintenanceWindow"
+          }
+        },
+        "nextPageToken": {
+          "type": "string"
+        }
+      }
+    },
+    "DropIn": {
+      "id": "DropIn",
+      "type": "object",
+      "properties": {
+        "unit": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "metadata": {
+          "type": "object",
+          "properties": {},
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "contents": {
+          "type": "string"
+        }
+      }
+    },
+    "DropInPage": {
+      "id": "DropInPage",
+      "type": "object",
+      "properties": {
+        "dropIns": {
+          "type": "array",
+          "items": {
+            "$ref": "DropIn"
+          }
+        },
+        "nextPageToken": {
+          "type": "string"
+        }
+      }
+    },
+    "Deployment": {
+      "id": "Deployment",
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "replicas": {
+          "type": "integer",
+          "format": "int32"
+        }
+      }
+    },
+    "DeploymentPage": {
+      "id": "DeploymentPage",
+      "type": "object",
+      "properties": {
+        "deployments": {
+          "type": "array",
+          "items": {
+            "$ref": "Deployment"
+          }
+        },
+        "nextPageToken": {
+          "type": "string"
+        }
+      }
     }
   },
   "resources": {
@@ -319,6 +619,242 @@ const DiscoveryJSON = `{
           }
         }
       }
+    },
+    "UnitScheduling": {
+      "methods": {
+        "Get": {
+          "id": "fleet.UnitScheduling.Get",
+          "description": "Retrieve the reasons a Unit could not be scheduled.",
+          "httpMethod": "GET",
+          "path": "units/{unitName}/scheduling",
+          "parameters": {
+            "unitName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "unitName"
+          ],
+          "response": {
+            "$ref": "UnitScheduling"
+          }
+        }
+      }
+    },
+    "UnitRevisions": {
+      "methods": {
+        "List": {
+          "id": "fleet.UnitRevisions.List",
+          "description": "Retrieve the revisions of a Unit, oldest first.",
+          "httpMethod": "GET",
+          "path": "units/{unitName}/revisions",
+          "parameters": {
+            "unitName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "unitName"
+          ],
+          "response": {
+            "$ref": "UnitRevisionPage"
+          }
+        }
+      }
+    },
+    "Schedule": {
+      "methods": {
+        "Simulate": {
+          "id": "fleet.Schedule.Simulate",
+          "description": "Compute the scheduling tasks submitting a Unit would cause, without performing them.",
+          "httpMethod": "POST",
+          "path": "./schedule:simulate",
+          "request": {
+            "$ref": "Unit"
+          },
+          "response": {
+            "$ref": "ScheduleSimulation"
+          }
+        }
+      }
+    },
+    "Maintenance": {
+      "methods": {
+        "List": {
+          "id": "fleet.Maintenance.List",
+          "description": "Retrieve a page of MaintenanceWindow objects.",
+          "httpMethod": "GET",
+          "path": "maintenance",
+          "parameters": {
+            "nextPageToken": {
+              "type": "string",
+              "location": "query"
+            }
+          },
+          "response": {
+            "$ref": "MaintenanceWindowPage"
+          }
+        },
+        "Set": {
+          "id": "fleet.Maintenance.Set",
+          "description": "Create or update a MaintenanceWindow.",
+          "httpMethod": "PUT",
+          "path": "maintenance/{windowName}",
+          "parameters": {
+            "windowName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "windowName"
+          ],
+          "request": {
+            "$ref": "MaintenanceWindow"
+          }
+        },
+        "Delete": {
+          "id": "fleet.Maintenance.Delete",
+          "description": "Delete the referenced MaintenanceWindow object.",
+          "httpMethod": "DELETE",
+          "path": "maintenance/{windowName}",
+          "parameters": {
+            "windowName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "windowName"
+          ]
+        }
+      }
+    },
+    "DropIns": {
+      "methods": {
+        "List": {
+          "id": "fleet.DropIns.List",
+          "description": "Retrieve a page of DropIn objects.",
+          "httpMethod": "GET",
+          "path": "dropins",
+          "parameters": {
+            "nextPageToken": {
+              "type": "string",
+              "location": "query"
+            }
+          },
+          "response": {
+            "$ref": "DropInPage"
+          }
+        },
+        "Set": {
+          "id": "fleet.DropIns.Set",
+          "description": "Create or update a DropIn.",
+          "httpMethod": "PUT",
+          "path": "dropins/{unitName}/{dropInName}",
+          "parameters": {
+            "unitName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            },
+            "dropInName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "unitName",
+            "dropInName"
+          ],
+          "request": {
+            "$ref": "DropIn"
+          }
+        },
+        "Delete": {
+          "id": "fleet.DropIns.Delete",
+          "description": "Delete the referenced DropIn object.",
+          "httpMethod": "DELETE",
+          "path": "dropins/{unitName}/{dropInName}",
+          "parameters": {
+            "unitName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            },
+            "dropInName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "unitName",
+            "dropInName"
+          ]
+        }
+      }
+    },
+    "Deployments": {
+      "methods": {
+        "List": {
+          "id": "fleet.Deployment.List",
+          "description": "Retrieve a page of Deployment objects.",
+          "httpMethod": "GET",
+          "path": "deployments",
+          "parameters": {
+            "nextPageToken": {
+              "type": "string",
+              "location": "query"
+            }
+          },
+          "response": {
+            "$ref": "DeploymentPage"
+          }
+        },
+        "Set": {
+          "id": "fleet.Deployment.Set",
+          "description": "Create or update a Deployment.",
+          "httpMethod": "PUT",
+          "path": "deployments/{deploymentName}",
+          "parameters": {
+            "deploymentName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "deploymentName"
+          ],
+          "request": {
+            "$ref": "Deployment"
+          }
+        },
+        "Delete": {
+          "id": "fleet.Deployment.Delete",
+          "description": "Delete the referenced Deployment object.",
+          "httpMethod": "DELETE",
+          "path": "deployments/{deploymentName}",
+          "parameters": {
+            "deploymentName": {
+              "type": "string",
+              "location": "path",
+              "required": true
+            }
+          },
+          "parameterOrder": [
+            "deploymentName"
+          ]
+        }
+      }
     }
   }
 }
@@ -17,8 +17,12 @@ package server
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,6 +38,7 @@ import (
 	"github.com/coreos/fleet/machine"
 	"github.com/coreos/fleet/pkg"
 	"github.com/coreos/fleet/pkg/lease"
+	"github.com/coreos/fleet/process"
 	"github.com/coreos/fleet/registry"
 	"github.com/coreos/fleet/registry/rpc"
 	"github.com/coreos/fleet/systemd"
@@ -47,15 +52,59 @@ const (
 	machineStateRefreshInterval = time.Minute
 
 	shutdownTimeout = time.Minute
+
+	// DefaultUnitManager is the name of the backend the agent runs units
+	// with unless configured otherwise.
+	DefaultUnitManager = "systemd"
 )
 
+// unitManagers maps the names of the available backends running units to
+// their constructors.
+var unitManagers = map[string]func(cfg config.Config) (unit.UnitManager, error){
+	"systemd": func(cfg config.Config) (unit.UnitManager, error) {
+		return systemd.NewSystemdUnitManager(cfg.UnitsDirectory, cfg.SystemdUser)
+	},
+	"process": func(cfg config.Config) (unit.UnitManager, error) {
+		return process.NewProcessUnitManager(cfg.UnitsDirectory)
+	},
+}
+
+// newUnitManager returns the backend running units selected by the given
+// config. An empty name selects DefaultUnitManager.
+func newUnitManager(cfg config.Config) (unit.UnitManager, error) {
+	name := cfg.UnitManager
+	if name == "" {
+		name = DefaultUnitManager
+	}
+
+	newMgr, ok := unitManagers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit manager %q, must be one of %s", name, strings.Join(UnitManagerNames(), ", "))
+	}
+
+	return newMgr(cfg)
+}
+
+// UnitManagerNames returns the sorted names of all available backends
+// running units.
+func UnitManagerNames() []string {
+	names := make([]string, 0, len(unitManagers))
+	for name := range unitManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 type Server struct {
 	agent          *agent.Agent
 	aReconciler    *agent.AgentReconciler
 	usPub          *agent.UnitStatePublisher
 	usGen          *unit.UnitStateGenerator
+	health         *agent.HealthMonitor
 	engine         *engine.Engine
 	mach           *machine.CoreOSMachine
+	mgr            unit.UnitManager
 	hrt            heart.Heart
 	mon            *Monitor
 	api            *api.Server
@@ -76,7 +125,7 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 		return nil, err
 	}
 
-	mgr, err := systemd.NewSystemdUnitManager(cfg.UnitsDirectory, cfg.SystemdUser)
+	mgr, err := newUnitManager(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -126,9 +175,10 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 	}
 
 	pub := agent.NewUnitStatePublisher(reg, mach, agentTTL)
-	gen := unit.NewUnitStateGenerator(mgr)
+	hm := agent.NewHealthMonitor()
+	gen := unit.NewUnitStateGenerator(mgr, hm)
 
-	a := agent.New(mgr, gen, reg, mach, agentTTL)
+	a := agent.New(mgr, gen, hm, reg, mach, agentTTL)
 
 	var rStream pkg.EventStream
 	if !cfg.DisableWatches {
@@ -137,12 +187,22 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 
 	ar := agent.NewReconciler(reg, rStream)
 
+	sched, err := engine.NewScheduler(cfg.Scheduler, cfg.SchedulerMetadataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rebalancer, err := engine.NewRebalancer(cfg.RebalanceMaxMoves, cfg.RebalanceMetric)
+	if err != nil {
+		return nil, err
+	}
+
 	var e *engine.Engine
 	if !cfg.EnableGRPC {
-		e = engine.New(reg, lManager, rStream, mach, nil)
+		e = engine.New(reg, lManager, rStream, mach, sched, rebalancer, nil)
 	} else {
 		regMux := genericReg.(*rpc.RegistryMux)
-		e = engine.New(reg, lManager, rStream, mach, regMux.EngineChanged)
+		e = engine.New(reg, lManager, rStream, mach, sched, rebalancer, regMux.EngineChanged)
 		if cfg.DisableEngine {
 			go regMux.ConnectToRegistry(e)
 		}
@@ -158,7 +218,7 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 	hrt := heart.New(reg, mach)
 	mon := NewMonitor(agentTTL)
 
-	apiServer := api.NewServer(listeners, api.NewServeMux(reg, cfg.TokenLimit))
+	apiServer := api.NewServer(listeners, api.NewServeMux(reg, sched, cfg.TokenLimit))
 	apiServer.Serve()
 
 	eIval := time.Duration(cfg.EngineReconcileInterval*1000) * time.Millisecond
@@ -168,8 +228,10 @@ func New(cfg config.Config, listeners []net.Listener) (*Server, error) {
 		aReconciler: ar,
 		usGen:       gen,
 		usPub:       pub,
+		health:      hm,
 		engine:      e,
 		mach:        mach,
+		mgr:         mgr,
 		hrt:         hrt,
 		mon:         mon,
 		api:         apiServer,
@@ -192,7 +254,7 @@ func newMachineFromConfig(cfg config.Config, mgr unit.UnitManager) (*machine.Cor
 		Version:      version.Version,
 	}
 
-	mach := machine.NewCoreOSMachine(state, mgr)
+	mach := machine.NewCoreOSMachine(state, mgr, cfg.UnitsDirectory)
 	mach.Refresh()
 
 	if mach.State().ID == "" {
@@ -238,6 +300,7 @@ func (s *Server) Run() {
 		func() { s.aReconciler.Run(s.agent, s.stopc) },
 		func() { s.usGen.Run(beatc, s.stopc) },
 		func() { s.usPub.Run(beatc, s.stopc) },
+		func() { s.health.Run(s.stopc) },
 	}
 	if s.disableEngine {
 		log.Info("Not starting engine; disable-engine is set")
@@ -292,6 +355,14 @@ func (s *Server) Kill() {
 	if !s.reconfigServer {
 		close(s.killc)
 	}
+
+	// A unit manager running the processes of units itself stops them,
+	// as the unit manager of the next server would start them again.
+	if c, ok := s.mgr.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Errorf("Failed closing unit manager: %v", err)
+		}
+	}
 }
 
 func (s *Server) Purge() {
@@ -306,10 +377,12 @@ func (s *Server) MarshalJSON() ([]byte, error) {
 		Agent              *agent.Agent
 		UnitStatePublisher *agent.UnitStatePublisher
 		UnitStateGenerator *unit.UnitStateGenerator
+		HealthMonitor      *agent.HealthMonitor
 	}{
 		Agent:              s.agent,
 		UnitStatePublisher: s.usPub,
 		UnitStateGenerator: s.usGen,
+		HealthMonitor:      s.health,
 	})
 }
 
@@ -0,0 +1,43 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/coreos/fleet/config"
+)
+
+func TestNewUnitManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet-server-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mgr, err := newUnitManager(config.Config{UnitManager: "process", UnitsDirectory: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if units, err := mgr.Units(); err != nil || len(units) != 0 {
+		t.Errorf("unexpected units %v: %v", units, err)
+	}
+
+	if _, err := newUnitManager(config.Config{UnitManager: "bogus", UnitsDirectory: dir}); err == nil {
+		t.Errorf("expected error for unknown unit manager")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/coreos/go-systemd/dbus"
@@ -28,12 +29,24 @@ import (
 	"github.com/coreos/fleet/unit"
 )
 
+// runtimeUnitDir is the directory the system instance of systemd reads
+// units and drop-ins from which only last until reboot
+var runtimeUnitDir = "/run/systemd/system"
+
 type systemdUnitManager struct {
-	systemd  *dbus.Conn
-	unitsDir string
+	systemd     *dbus.Conn
+	systemdUser bool
+	unitsDir    string
 
 	hashes map[string]unit.Hash
-	mutex  sync.RWMutex
+	// dropIns holds the hash of the drop-ins of each unit having any
+	dropIns map[string]string
+	// states caches the last state fetched with all its details for
+	// each unit, see detailedUnitState
+	states map[string]unit.UnitState
+	// cgroups holds the cgroup of each service unit, see unitUsage
+	cgroups map[string]string
+	mutex   sync.RWMutex
 }
 
 func NewSystemdUnitManager(uDir string, systemdUser bool) (*systemdUnitManager, error) {
@@ -57,11 +70,20 @@ func NewSystemdUnitManager(uDir string, systemdUser bool) (*systemdUnitManager,
 		return nil, err
 	}
 
+	dropIns, err := hashDropIns(uDir, hashes)
+	if err != nil {
+		return nil, err
+	}
+
 	mgr := systemdUnitManager{
-		systemd:  systemd,
-		unitsDir: uDir,
-		hashes:   hashes,
-		mutex:    sync.RWMutex{},
+		systemd:     systemd,
+		systemdUser: systemdUser,
+		unitsDir:    uDir,
+		hashes:      hashes,
+		dropIns:     dropIns,
+		states:      make(map[string]unit.UnitState),
+		cgroups:     make(map[string]string),
+		mutex:       sync.RWMutex{},
 	}
 	return &mgr, nil
 }
@@ -85,6 +107,22 @@ func hashUnitFiles(dir string) (map[string]unit.Hash, error) {
 	return hMap, nil
 }
 
+func hashDropIns(dir string, hashes map[string]unit.Hash) (map[string]string, error) {
+	hMap := make(map[string]string)
+	for uName := range hashes {
+		dropIns, err := unit.ReadDropIns(dir, uName)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(dropIns) > 0 {
+			hMap[uName] = unit.HashDropIns(dropIns)
+		}
+	}
+
+	return hMap, nil
+}
+
 func hashUnitFile(loc string) (unit.Hash, error) {
 	b, err := ioutil.ReadFile(loc)
 	if err != nil {
@@ -99,15 +137,20 @@ func hashUnitFile(loc string) (unit.Hash, error) {
 	return uf.Hash(), nil
 }
 
-// Load writes the given Unit to disk, subscribing to relevant dbus
-// events and caching the Unit's Hash.
-func (m *systemdUnitManager) Load(name string, u unit.UnitFile) error {
+// Load writes the given Unit and its drop-ins to disk, subscribing to
+// relevant dbus events and caching the Unit's Hash as well as that of its
+// drop-ins.
+func (m *systemdUnitManager) Load(name string, u unit.UnitFile, dropIns []unit.DropIn) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	err := m.writeUnit(name, u.String())
 	if err != nil {
 		return err
 	}
+	if err := m.writeDropIns(name, dropIns); err != nil {
+		m.removeUnit(name)
+		return fmt.Errorf("Failed to write drop-ins of systemd unit %s: %v", name, err)
+	}
 	if _, exists := u.Contents["Install"]; exists {
 		log.Debugf("Detected [Install] section in the systemd unit (%s)", name)
 		ok, err := m.enableUnit(name)
@@ -117,6 +160,13 @@ func (m *systemdUnitManager) Load(name string, u unit.UnitFile) error {
 		}
 	}
 	m.hashes[name] = u.Hash()
+	if len(dropIns) > 0 {
+		m.dropIns[name] = unit.HashDropIns(dropIns)
+	} else {
+		delete(m.dropIns, name)
+	}
+	delete(m.states, name)
+	delete(m.cgroups, name)
 	return nil
 }
 
@@ -126,6 +176,9 @@ func (m *systemdUnitManager) Unload(name string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	delete(m.hashes, name)
+	delete(m.dropIns, name)
+	delete(m.states, name)
+	delete(m.cgroups, name)
 	return m.removeUnit(name)
 }
 
@@ -165,6 +218,8 @@ func (m *systemdUnitManager) GetUnitState(name string) (*unit.UnitState, error)
 	if h, ok := m.hashes[name]; ok {
 		us.UnitHash = h.String()
 	}
+	us.DropInsHash = m.dropIns[name]
+	us.Usage = m.unitUsage(name, us)
 	return us, nil
 }
 
@@ -178,9 +233,83 @@ func (m *systemdUnitManager) getUnitState(name string) (*unit.UnitState, error)
 		ActiveState: info["ActiveState"].(string),
 		SubState:    info["SubState"].(string),
 	}
+	us.ActiveEnterTimestamp, _ = info["ActiveEnterTimestamp"].(uint64)
+
+	if strings.HasSuffix(name, ".service") {
+		// the main process is described by the Service interface
+		svc, err := m.systemd.GetUnitTypeProperties(name, "Service")
+		if err != nil {
+			return nil, err
+		}
+		us.MainPID, _ = svc["MainPID"].(uint32)
+		us.ExecMainStatus, _ = svc["ExecMainStatus"].(int32)
+		us.NRestarts, _ = svc["NRestarts"].(uint32)
+		us.Result, _ = svc["Result"].(string)
+		m.cgroups[name], _ = svc["ControlGroup"].(string)
+	}
+
+	m.states[name] = us
 	return &us, nil
 }
 
+// detailedUnitState adds the details to the given state of the named unit,
+// which systemd does not report when listing units. Fetching them takes
+// further D-Bus calls, so the details fetched last are reused as long as the
+// load, active and sub states of the unit, as well as when it last became
+// active if known, did not change since.
+func (m *systemdUnitManager) detailedUnitState(name string, us *unit.UnitState) (*unit.UnitState, error) {
+	last, ok := m.states[name]
+	if !ok || last.LoadState != us.LoadState || last.ActiveState != us.ActiveState || last.SubState != us.SubState {
+		return m.getUnitState(name)
+	}
+	if us.ActiveEnterTimestamp != 0 && last.ActiveEnterTimestamp != us.ActiveEnterTimestamp {
+		return m.getUnitState(name)
+	}
+	return &last, nil
+}
+
+// UnitCompleted determines whether the named unit ran to completion, i.e.
+// it left the inactive state since it was loaded, is now inactive again
+// without having failed and has no job pending. Service units must also
+// report a successful result.
+func (m *systemdUnitManager) UnitCompleted(name string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	info, err := m.systemd.GetUnitProperties(name)
+	if err != nil {
+		return false, err
+	}
+	if info["ActiveState"] != "inactive" || info["SubState"] != "dead" {
+		return false, nil
+	}
+
+	// a unit that never left the inactive state has not run yet
+	if ts, _ := info["InactiveExitTimestamp"].(uint64); ts == 0 {
+		return false, nil
+	}
+
+	// a pending job, e.g. the start job queued by fleet, means the unit
+	// is about to run again
+	if j, ok := info["Job"].([]interface{}); ok && len(j) > 0 {
+		if id, _ := j[0].(uint32); id != 0 {
+			return false, nil
+		}
+	}
+
+	if strings.HasSuffix(name, ".service") {
+		prop, err := m.systemd.GetUnitTypeProperty(name, "Service", "Result")
+		if err != nil {
+			return false, err
+		}
+		if result, _ := prop.Value.Value().(string); result != "success" {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 func (m *systemdUnitManager) readUnit(name string) (string, error) {
 	path := m.getUnitFilePath(name)
 	contents, err := ioutil.ReadFile(path)
@@ -230,14 +359,19 @@ func (m *systemdUnitManager) GetUnitStates(filter pkg.Set) (map[string]*unit.Uni
 			continue
 		}
 
-		us := &unit.UnitState{
+		us, err := m.detailedUnitState(dus.Name, &unit.UnitState{
 			LoadState:   dus.LoadState,
 			ActiveState: dus.ActiveState,
 			SubState:    dus.SubState,
+		})
+		if err != nil {
+			return nil, err
 		}
 		if h, ok := m.hashes[dus.Name]; ok {
 			us.UnitHash = h.String()
 		}
+		us.DropInsHash = m.dropIns[dus.Name]
+		us.Usage = m.unitUsage(dus.Name, us)
 		states[dus.Name] = us
 	}
 
@@ -256,6 +390,7 @@ func (m *systemdUnitManager) GetUnitStates(filter pkg.Set) (map[string]*unit.Uni
 			if h, ok := m.hashes[name]; ok {
 				us.UnitHash = h.String()
 			}
+			us.DropInsHash = m.dropIns[name]
 			states[name] = us
 		}
 	}
@@ -310,15 +445,64 @@ func (m *systemdUnitManager) removeUnit(name string) (err error) {
 	ufPath := m.getUnitFilePath(name)
 	os.Remove(ufPath)
 
+	if errf = m.writeDropIns(name, nil); errf != nil {
+		err = fmt.Errorf("%v, %v", err, errf)
+	}
+
 	return err
 }
 
+// writeDropIns replaces the drop-ins of the named unit. systemd does not
+// look for drop-ins next to linked unit files but only in its search path,
+// so the directory holding them is linked into the runtime unit directory
+// of systemd.
+func (m *systemdUnitManager) writeDropIns(name string, dropIns []unit.DropIn) error {
+	if len(dropIns) > 0 {
+		log.Infof("Writing %d drop-ins of systemd unit %s", len(dropIns), name)
+	}
+	if err := unit.WriteDropIns(m.unitsDir, name, dropIns); err != nil {
+		return err
+	}
+
+	link := unit.DropInDir(m.runtimeUnitDir(), name)
+	if fi, err := os.Lstat(link); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(link); err != nil {
+			return err
+		}
+	}
+	if len(dropIns) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(path.Dir(link), os.FileMode(0755)); err != nil {
+		return err
+	}
+	return os.Symlink(unit.DropInDir(m.unitsDir, name), link)
+}
+
+// runtimeUnitDir returns the directory systemd reads units and drop-ins
+// from which only last until reboot, where units are linked by fleet.
+func (m *systemdUnitManager) runtimeUnitDir() string {
+	if !m.systemdUser {
+		return runtimeUnitDir
+	}
+
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return path.Join(dir, "systemd/user")
+}
+
 func (m *systemdUnitManager) getUnitFilePath(name string) string {
 	return path.Join(m.unitsDir, name)
 }
 
 func lsUnitsDir(dir string) ([]string, error) {
 	filterFunc := func(name string) bool {
+		if unit.IsDropInDir(name) {
+			return true
+		}
 		if !unit.RecognizedUnitType(name) {
 			log.Warningf("Found unrecognized file in %s, ignoring", path.Join(dir, name))
 			return true
@@ -20,6 +20,8 @@ import (
 	"path"
 	"reflect"
 	"testing"
+
+	"github.com/coreos/fleet/unit"
 )
 
 func TestHashUnitFile(t *testing.T) {
@@ -111,3 +113,68 @@ func TestHashUnitFileDirectory(t *testing.T) {
 		t.Fatalf("hashUnitFileDirectory returned unexpected values: want=%v, got=%v", want, got)
 	}
 }
+
+func TestWriteDropIns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet-testing-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldRuntimeUnitDir := runtimeUnitDir
+	runtimeUnitDir = path.Join(dir, "run")
+	defer func() { runtimeUnitDir = oldRuntimeUnitDir }()
+
+	unitsDir := path.Join(dir, "units")
+	if err := os.MkdirAll(unitsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(unitsDir, "foo.service"), []byte("[Service]\nExecStart=/usr/bin/sleep infinity"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &systemdUnitManager{unitsDir: unitsDir}
+	dropIns := []unit.DropIn{
+		{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"},
+	}
+	if err := m.writeDropIns("foo.service", dropIns); err != nil {
+		t.Fatal(err)
+	}
+
+	// systemd finds the drop-ins through the link in its runtime directory
+	got, err := unit.ReadDropIns(runtimeUnitDir, "foo.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dropIns, got) {
+		t.Errorf("unexpected drop-ins in runtime directory: want=%v, got=%v", dropIns, got)
+	}
+
+	// the directory holding the drop-ins is not taken for a unit
+	units, err := lsUnitsDir(unitsDir)
+	if err != nil || !reflect.DeepEqual(units, []string{"foo.service"}) {
+		t.Errorf("unexpected units %v: %v", units, err)
+	}
+
+	hashes, err := hashUnitFiles(unitsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dHashes, err := hashDropIns(unitsDir, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"foo.service": unit.HashDropIns(dropIns)}; !reflect.DeepEqual(want, dHashes) {
+		t.Errorf("unexpected drop-in hashes: want=%v, got=%v", want, dHashes)
+	}
+
+	if err := m.writeDropIns("foo.service", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(unit.DropInDir(runtimeUnitDir, "foo.service")); !os.IsNotExist(err) {
+		t.Errorf("link to drop-ins not removed: %v", err)
+	}
+	if _, err := os.Stat(unit.DropInDir(unitsDir, "foo.service")); !os.IsNotExist(err) {
+		t.Errorf("drop-ins not removed: %v", err)
+	}
+}
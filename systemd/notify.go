@@ -0,0 +1,219 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus"
+
+	"github.com/coreos/fleet/log"
+	"github.com/coreos/fleet/unit"
+)
+
+const (
+	unitInterface  = "org.freedesktop.systemd1.Unit"
+	unitPathPrefix = "/org/freedesktop/systemd1/unit/"
+
+	propertiesChanged = "org.freedesktop.DBus.Properties.PropertiesChanged"
+
+	signalBuffer = 100
+)
+
+// NotifyUnitStates subscribes to the PropertiesChanged signals systemd emits
+// for units and sends the state of a unit loaded by this manager to the
+// given channel each time it changes, until stop is closed. The signals
+// carry the new state of the unit, so no further D-Bus calls are needed in
+// the common case. If the D-Bus connection is lost, the channel is closed.
+func (m *systemdUnitManager) NotifyUnitStates(changes chan<- *unit.UnitStateHeartbeat, stop <-chan struct{}) error {
+	conn, err := m.dialSignals()
+	if err != nil {
+		return err
+	}
+
+	sigchan := make(chan *dbus.Signal, signalBuffer)
+	conn.Signal(sigchan)
+
+	go func() {
+		<-stop
+		conn.Close()
+	}()
+
+	go func() {
+		// sigchan is closed along with the connection
+		for sig := range sigchan {
+			name, us := m.unitStateChange(sig)
+			if us == nil {
+				continue
+			}
+
+			select {
+			case changes <- &unit.UnitStateHeartbeat{Name: name, State: us}:
+			case <-stop:
+			}
+		}
+
+		select {
+		case <-stop:
+		default:
+			log.Errorf("Lost D-Bus connection to systemd, no longer receiving unit state changes")
+			close(changes)
+		}
+	}()
+
+	return nil
+}
+
+// dialSignals opens a private connection to the bus systemd listens on, on
+// which systemd sends the PropertiesChanged signals of its units.
+func (m *systemdUnitManager) dialSignals() (*dbus.Conn, error) {
+	var conn *dbus.Conn
+	var err error
+	if m.systemdUser {
+		conn, err = dbus.SessionBusPrivate()
+	} else {
+		conn, err = dbus.SystemBusPrivate()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only use the EXTERNAL method with the uid, like go-systemd does
+	methods := []dbus.Auth{dbus.AuthExternal(strconv.Itoa(os.Getuid()))}
+	if err = conn.Auth(methods); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err = conn.Hello(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	match := "type='signal',sender='org.freedesktop.systemd1',interface='org.freedesktop.DBus.Properties',member='PropertiesChanged',arg0='" + unitInterface + "'"
+	if err = conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, match).Store(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// systemd only emits signals while at least one client is subscribed
+	obj := conn.Object("org.freedesktop.systemd1", dbus.ObjectPath("/org/freedesktop/systemd1"))
+	if err = obj.Call("org.freedesktop.systemd1.Manager.Subscribe", 0).Store(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// unitStateChange determines the new state of a unit from a PropertiesChanged
+// signal. It returns a nil UnitState if the signal does not concern a unit
+// loaded by this manager.
+func (m *systemdUnitManager) unitStateChange(sig *dbus.Signal) (string, *unit.UnitState) {
+	if sig.Name != propertiesChanged || len(sig.Body) < 2 {
+		return "", nil
+	}
+	if iface, _ := sig.Body[0].(string); iface != unitInterface {
+		return "", nil
+	}
+	name, ok := unitNameFromPath(sig.Path)
+	if !ok {
+		return "", nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h, ok := m.hashes[name]
+	if !ok {
+		return "", nil
+	}
+
+	changed, _ := sig.Body[1].(map[string]dbus.Variant)
+	us := unitStateFromProperties(changed)
+	var err error
+	if us != nil {
+		us, err = m.detailedUnitState(name, us)
+	} else {
+		// the signal did not carry the whole state, so ask for it
+		us, err = m.getUnitState(name)
+	}
+	if err != nil {
+		log.Errorf("Failed fetching state of unit %s: %v", name, err)
+		return "", nil
+	}
+	us.UnitHash = h.String()
+	us.DropInsHash = m.dropIns[name]
+	us.Usage = m.unitUsage(name, us)
+
+	return name, us
+}
+
+// unitStateFromProperties returns the UnitState described by the given unit
+// properties, or nil if any of the load, active and sub states is missing.
+func unitStateFromProperties(props map[string]dbus.Variant) *unit.UnitState {
+	var state [3]string
+	for i, key := range []string{"LoadState", "ActiveState", "SubState"} {
+		v, ok := props[key]
+		if !ok {
+			return nil
+		}
+		if state[i], ok = v.Value().(string); !ok {
+			return nil
+		}
+	}
+
+	us := &unit.UnitState{
+		LoadState:   state[0],
+		ActiveState: state[1],
+		SubState:    state[2],
+	}
+	if v, ok := props["ActiveEnterTimestamp"]; ok {
+		us.ActiveEnterTimestamp, _ = v.Value().(uint64)
+	}
+	return us
+}
+
+// unitNameFromPath returns the name of the unit identified by the given
+// D-Bus object path, reversing the escaping done by go-systemd's PathBusEscape.
+func unitNameFromPath(path dbus.ObjectPath) (string, bool) {
+	if !strings.HasPrefix(string(path), unitPathPrefix) {
+		return "", false
+	}
+	escaped := strings.TrimPrefix(string(path), unitPathPrefix)
+
+	name := make([]byte, 0, len(escaped))
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] != '_' {
+			name = append(name, escaped[i])
+			continue
+		}
+		if i+2 >= len(escaped) {
+			return "", false
+		}
+		c, err := strconv.ParseUint(escaped[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", false
+		}
+		name = append(name, byte(c))
+		i += 2
+	}
+
+	if len(name) == 0 {
+		return "", false
+	}
+	return string(name), true
+}
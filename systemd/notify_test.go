@@ -0,0 +1,106 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"reflect"
+	"testing"
+
+	sd "github.com/coreos/go-systemd/dbus"
+	"github.com/godbus/dbus"
+
+	"github.com/coreos/fleet/unit"
+)
+
+func TestUnitNameFromPath(t *testing.T) {
+	for _, name := range []string{"foo.service", "foo@bar.service", "foo-bar_baz.service", "foo@1476700800.service", "a:b.socket"} {
+		path := dbus.ObjectPath(unitPathPrefix + sd.PathBusEscape(name))
+		got, ok := unitNameFromPath(path)
+		if !ok || got != name {
+			t.Errorf("unitNameFromPath(%q) = %q, %t; expected %q", path, got, ok, name)
+		}
+	}
+
+	for _, path := range []dbus.ObjectPath{
+		"/org/freedesktop/systemd1",
+		"/org/freedesktop/systemd1/job/42",
+		unitPathPrefix,
+		unitPathPrefix + "foo_2",
+		unitPathPrefix + "foo_zz",
+	} {
+		if got, ok := unitNameFromPath(path); ok {
+			t.Errorf("unitNameFromPath(%q) = %q, expected failure", path, got)
+		}
+	}
+}
+
+func TestUnitStateFromProperties(t *testing.T) {
+	props := map[string]dbus.Variant{
+		"LoadState":   dbus.MakeVariant("loaded"),
+		"ActiveState": dbus.MakeVariant("active"),
+		"SubState":    dbus.MakeVariant("running"),
+		"Description": dbus.MakeVariant("foo"),
+
+		"ActiveEnterTimestamp": dbus.MakeVariant(uint64(1476700800000000)),
+	}
+	expect := &unit.UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", ActiveEnterTimestamp: 1476700800000000}
+	if us := unitStateFromProperties(props); !reflect.DeepEqual(us, expect) {
+		t.Errorf("got %#v, expected %#v", us, expect)
+	}
+
+	delete(props, "SubState")
+	if us := unitStateFromProperties(props); us != nil {
+		t.Errorf("expected nil UnitState without SubState, got %#v", us)
+	}
+}
+
+func TestUnitStateChange(t *testing.T) {
+	// the details of the failed state are known already, so there is no
+	// need to ask systemd
+	failed := unit.UnitState{LoadState: "loaded", ActiveState: "failed", SubState: "failed", ExecMainStatus: 1, NRestarts: 5, Result: "exit-code"}
+	m := &systemdUnitManager{
+		hashes: map[string]unit.Hash{"foo.service": unit.Hash{}},
+		states: map[string]unit.UnitState{"foo.service": failed},
+	}
+	props := map[string]dbus.Variant{
+		"LoadState":   dbus.MakeVariant("loaded"),
+		"ActiveState": dbus.MakeVariant("failed"),
+		"SubState":    dbus.MakeVariant("failed"),
+	}
+	sig := func(name, iface string) *dbus.Signal {
+		return &dbus.Signal{
+			Path: dbus.ObjectPath(unitPathPrefix + sd.PathBusEscape(name)),
+			Name: propertiesChanged,
+			Body: []interface{}{iface, props, []string{}},
+		}
+	}
+
+	name, us := m.unitStateChange(sig("foo.service", unitInterface))
+	expect := failed
+	expect.UnitHash = unit.Hash{}.String()
+	if name != "foo.service" || !reflect.DeepEqual(us, &expect) {
+		t.Errorf("got %q %#v, expected foo.service %#v", name, us, &expect)
+	}
+
+	// units not loaded by fleet are ignored
+	if _, us := m.unitStateChange(sig("bar.service", unitInterface)); us != nil {
+		t.Errorf("expected change of bar.service to be ignored, got %#v", us)
+	}
+
+	// so are changes of the properties of other interfaces
+	if _, us := m.unitStateChange(sig("foo.service", "org.freedesktop.systemd1.Service")); us != nil {
+		t.Errorf("expected change of the Service interface to be ignored, got %#v", us)
+	}
+}
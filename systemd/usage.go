@@ -0,0 +1,108 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/fleet/unit"
+)
+
+// cgroupRoot is where the cgroup hierarchies are mounted
+var cgroupRoot = "/sys/fs/cgroup"
+
+// unitUsage reads the resource usage of the named unit from its cgroup. It
+// returns nil unless the unit is an active service.
+func (m *systemdUnitManager) unitUsage(name string, us *unit.UnitState) *unit.UnitUsage {
+	cgroup := m.cgroups[name]
+	if cgroup == "" || us.ActiveState != "active" {
+		return nil
+	}
+	return readCgroupUsage(cgroupRoot, cgroup)
+}
+
+// readCgroupUsage reads the resource usage accounted in the given cgroup, as
+// reported by systemd in the ControlGroup property of a unit, from the cgroup
+// hierarchies mounted at root. Both the unified hierarchy and the legacy
+// per-controller hierarchies are supported. Usage which is not accounted,
+// e.g. because accounting is disabled for the unit, is reported as 0. It
+// returns nil if no usage could be read at all.
+func readCgroupUsage(root, cgroup string) *unit.UnitUsage {
+	var usage unit.UnitUsage
+	found := false
+	read := func(dst *uint64, path string) {
+		if v, err := readCgroupValue(path); err == nil {
+			*dst = v
+			found = true
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		dir := filepath.Join(root, cgroup)
+		read(&usage.MemoryCurrent, filepath.Join(dir, "memory.current"))
+		read(&usage.MemoryPeak, filepath.Join(dir, "memory.peak"))
+		if usec, err := readCgroupStat(filepath.Join(dir, "cpu.stat"), "usage_usec"); err == nil {
+			usage.CPUUsageNSec = usec * 1000
+			found = true
+		}
+		read(&usage.Tasks, filepath.Join(dir, "pids.current"))
+	} else {
+		read(&usage.MemoryCurrent, filepath.Join(root, "memory", cgroup, "memory.usage_in_bytes"))
+		read(&usage.MemoryPeak, filepath.Join(root, "memory", cgroup, "memory.max_usage_in_bytes"))
+		read(&usage.CPUUsageNSec, filepath.Join(root, "cpuacct", cgroup, "cpuacct.usage"))
+		read(&usage.Tasks, filepath.Join(root, "pids", cgroup, "pids.current"))
+	}
+
+	if !found {
+		return nil
+	}
+	return &usage
+}
+
+// readCgroupValue reads a cgroup file holding a single number
+func readCgroupValue(path string) (uint64, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(contents)), 10, 64)
+}
+
+// readCgroupStat reads the value of the given key from a cgroup file holding
+// one "key value" pair per line
+func readCgroupStat(path, key string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, os.ErrNotExist
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/fleet/unit"
+)
+
+func writeCgroupFiles(t *testing.T, root string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadCgroupUsage(t *testing.T) {
+	tests := []struct {
+		files map[string]string
+		want  *unit.UnitUsage
+	}{
+		// unified hierarchy
+		{
+			files: map[string]string{
+				"cgroup.controllers":                      "cpu memory pids\n",
+				"system.slice/foo.service/memory.current": "1048576\n",
+				"system.slice/foo.service/memory.peak":    "2097152\n",
+				"system.slice/foo.service/cpu.stat":       "usage_usec 1500\nuser_usec 1000\nsystem_usec 500\n",
+				"system.slice/foo.service/pids.current":   "3\n",
+				"system.slice/bar.service/memory.current": "1\n",
+			},
+			want: &unit.UnitUsage{MemoryCurrent: 1048576, MemoryPeak: 2097152, CPUUsageNSec: 1500000, Tasks: 3},
+		},
+		// unified hierarchy of a kernel without memory.peak
+		{
+			files: map[string]string{
+				"cgroup.controllers":                      "cpu memory pids\n",
+				"system.slice/foo.service/memory.current": "1048576\n",
+			},
+			want: &unit.UnitUsage{MemoryCurrent: 1048576},
+		},
+		// legacy hierarchies
+		{
+			files: map[string]string{
+				"memory/system.slice/foo.service/memory.usage_in_bytes":     "1048576\n",
+				"memory/system.slice/foo.service/memory.max_usage_in_bytes": "2097152\n",
+				"cpuacct/system.slice/foo.service/cpuacct.usage":            "1500000\n",
+				"pids/system.slice/foo.service/pids.current":                "3\n",
+			},
+			want: &unit.UnitUsage{MemoryCurrent: 1048576, MemoryPeak: 2097152, CPUUsageNSec: 1500000, Tasks: 3},
+		},
+		// no accounting at all
+		{
+			files: map[string]string{
+				"cgroup.controllers": "\n",
+			},
+			want: nil,
+		},
+	}
+
+	for i, tt := range tests {
+		root, err := ioutil.TempDir("", "fleet-cgroup-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeCgroupFiles(t, root, tt.files)
+
+		got := readCgroupUsage(root, "/system.slice/foo.service")
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("case %d: got %#v, expected %#v", i, got, tt.want)
+		}
+		os.RemoveAll(root)
+	}
+}
+
+func TestUnitUsage(t *testing.T) {
+	root, err := ioutil.TempDir("", "fleet-cgroup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers":                    "pids\n",
+		"system.slice/foo.service/pids.current": "2\n",
+	})
+
+	oldRoot := cgroupRoot
+	cgroupRoot = root
+	defer func() { cgroupRoot = oldRoot }()
+
+	m := &systemdUnitManager{cgroups: map[string]string{"foo.service": "/system.slice/foo.service"}}
+	if usage := m.unitUsage("foo.service", &unit.UnitState{ActiveState: "active"}); !reflect.DeepEqual(usage, &unit.UnitUsage{Tasks: 2}) {
+		t.Errorf("unexpected usage of active unit: %#v", usage)
+	}
+	if usage := m.unitUsage("foo.service", &unit.UnitState{ActiveState: "failed"}); usage != nil {
+		t.Errorf("expected no usage of failed unit, got %#v", usage)
+	}
+	if usage := m.unitUsage("bar.service", &unit.UnitState{ActiveState: "active"}); usage != nil {
+		t.Errorf("expected no usage of unit without cgroup, got %#v", usage)
+	}
+}
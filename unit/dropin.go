@@ -0,0 +1,205 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/coreos/go-systemd/unit"
+)
+
+const (
+	dropInDirSuffix = ".d"
+	dropInSuffix    = ".conf"
+)
+
+// DropIn is a unit file fragment extending or overriding the options of a
+// unit, as systemd reads them from the foo.service.d directory of a unit
+// foo.service.
+type DropIn struct {
+	// Unit is the name of the unit the drop-in applies to. The drop-ins of
+	// a template unit apply to all of its instances.
+	Unit string
+	// Name is the file name of the drop-in, ending in ".conf". Drop-ins
+	// are applied in lexical order of their names.
+	Name string
+	// Metadata restricts the drop-in to the machines having each of the
+	// given key-value pairs in their metadata. A drop-in without Metadata
+	// applies to all machines.
+	Metadata map[string]string
+	// Contents is the raw contents of the drop-in file
+	Contents string
+}
+
+// Validate returns an error if the DropIn is malformed.
+func (d *DropIn) Validate() error {
+	switch {
+	case !RecognizedUnitType(d.Unit) || strings.Contains(d.Unit, "/"):
+		return fmt.Errorf("drop-in must apply to a valid unit name, got %q", d.Unit)
+	case !strings.HasSuffix(d.Name, dropInSuffix) || len(d.Name) == len(dropInSuffix) || strings.Contains(d.Name, "/"):
+		return fmt.Errorf("drop-in name must be a file name ending in %s, got %q", dropInSuffix, d.Name)
+	}
+	if _, err := NewUnitFile(d.Contents); err != nil {
+		return fmt.Errorf("invalid drop-in contents: %v", err)
+	}
+	return nil
+}
+
+// AppliesTo determines whether the DropIn applies to the named unit on a
+// machine with the given metadata.
+func (d *DropIn) AppliesTo(name string, metadata map[string]string) bool {
+	if d.Unit != name {
+		nu := NewUnitNameInfo(name)
+		if nu == nil || !nu.IsInstance() || nu.Template != d.Unit {
+			return false
+		}
+	}
+
+	for key, value := range d.Metadata {
+		if local, ok := metadata[key]; !ok || local != value {
+			return false
+		}
+	}
+	return true
+}
+
+// WithDropIns returns the unit file resulting from applying the given
+// drop-ins to the UnitFile in lexical order of their names. Note that, as
+// with systemd, options of drop-ins are appended to those of the unit file,
+// so that single-valued options are overridden while lists need to be reset
+// with an empty assignment first.
+func (u *UnitFile) WithDropIns(dropIns []DropIn) (*UnitFile, error) {
+	opts := make([]*unit.UnitOption, len(u.Options))
+	copy(opts, u.Options)
+
+	for _, d := range sortedDropIns(dropIns) {
+		uf, err := NewUnitFile(d.Contents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drop-in %s: %v", d.Name, err)
+		}
+		opts = append(opts, uf.Options...)
+	}
+	return NewUnitFromOptions(opts), nil
+}
+
+// HashDropIns returns a hash identifying the given drop-ins regardless of
+// their order, or an empty string if there are none.
+func HashDropIns(dropIns []DropIn) string {
+	if len(dropIns) == 0 {
+		return ""
+	}
+
+	h := sha1.New()
+	for _, d := range sortedDropIns(dropIns) {
+		io.WriteString(h, d.Name)
+		h.Write([]byte{0})
+		io.WriteString(h, d.Contents)
+		h.Write([]byte{0})
+	}
+
+	var sum Hash
+	copy(sum[:], h.Sum(nil))
+	return sum.String()
+}
+
+func sortedDropIns(dropIns []DropIn) []DropIn {
+	sorted := make([]DropIn, len(dropIns))
+	copy(sorted, dropIns)
+	sort.Sort(dropInsByName(sorted))
+	return sorted
+}
+
+type dropInsByName []DropIn
+
+func (ds dropInsByName) Len() int           { return len(ds) }
+func (ds dropInsByName) Swap(i, j int)      { ds[i], ds[j] = ds[j], ds[i] }
+func (ds dropInsByName) Less(i, j int) bool { return ds[i].Name < ds[j].Name }
+
+// IsDropInDir determines whether the given file name is that of the
+// directory holding the drop-ins of a unit.
+func IsDropInDir(name string) bool {
+	return strings.HasSuffix(name, dropInDirSuffix) && RecognizedUnitType(strings.TrimSuffix(name, dropInDirSuffix))
+}
+
+// DropInDir returns the path of the directory holding the drop-ins of the
+// named unit whose unit file is in the given directory.
+func DropInDir(dir, name string) string {
+	return path.Join(dir, name+dropInDirSuffix)
+}
+
+// WriteDropIns replaces the drop-ins of the named unit whose unit file is in
+// the given directory.
+func WriteDropIns(dir, name string, dropIns []DropIn) error {
+	if err := RemoveDropIns(dir, name); err != nil {
+		return err
+	}
+	if len(dropIns) == 0 {
+		return nil
+	}
+
+	ddir := DropInDir(dir, name)
+	if err := os.MkdirAll(ddir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	for _, d := range dropIns {
+		if err := ioutil.WriteFile(path.Join(ddir, d.Name), []byte(d.Contents), os.FileMode(0644)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadDropIns returns the drop-ins of the named unit whose unit file is in
+// the given directory, sorted by name.
+func ReadDropIns(dir, name string) ([]DropIn, error) {
+	ddir := DropInDir(dir, name)
+	fis, err := ioutil.ReadDir(ddir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	var dropIns []DropIn
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), dropInSuffix) {
+			continue
+		}
+		b, err := ioutil.ReadFile(path.Join(ddir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		dropIns = append(dropIns, DropIn{Unit: name, Name: fi.Name(), Contents: string(b)})
+	}
+	return dropIns, nil
+}
+
+// RemoveDropIns removes the drop-ins of the named unit whose unit file is in
+// the given directory.
+func RemoveDropIns(dir, name string) error {
+	if name == "" {
+		return errors.New("unit name must not be empty")
+	}
+	return os.RemoveAll(DropInDir(dir, name))
+}
@@ -0,0 +1,166 @@
+// Copyright 2016 The fleet Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDropInValidate(t *testing.T) {
+	tests := []struct {
+		dropIn DropIn
+		valid  bool
+	}{
+		{DropIn{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service]\nEnvironment=FOO=bar\n"}, true},
+		{DropIn{Unit: "foo@.service", Name: "limits.conf", Contents: "[Service]\nMemoryLimit=1G\n"}, true},
+		{DropIn{Unit: "foo", Name: "10-env.conf"}, false},
+		{DropIn{Unit: "../foo.service", Name: "10-env.conf"}, false},
+		{DropIn{Unit: "foo.service", Name: "10-env"}, false},
+		{DropIn{Unit: "foo.service", Name: ".conf"}, false},
+		{DropIn{Unit: "foo.service", Name: "../10-env.conf"}, false},
+		{DropIn{Unit: "foo.service", Name: "10-env.conf", Contents: "[Service\n"}, false},
+	}
+
+	for i, tt := range tests {
+		err := tt.dropIn.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestDropInAppliesTo(t *testing.T) {
+	tests := []struct {
+		dropIn   DropIn
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{DropIn{Unit: "foo.service"}, "foo.service", nil, true},
+		{DropIn{Unit: "foo.service"}, "bar.service", nil, false},
+		// template drop-ins apply to all instances
+		{DropIn{Unit: "foo@.service"}, "foo@1.service", nil, true},
+		{DropIn{Unit: "foo@.service"}, "foo@.service", nil, true},
+		{DropIn{Unit: "foo@.service"}, "bar@1.service", nil, false},
+		{DropIn{Unit: "foo@1.service"}, "foo@2.service", nil, false},
+		// metadata selectors
+		{DropIn{Unit: "foo.service", Metadata: map[string]string{"region": "us-west"}}, "foo.service", map[string]string{"region": "us-west", "az": "1"}, true},
+		{DropIn{Unit: "foo.service", Metadata: map[string]string{"region": "us-west"}}, "foo.service", map[string]string{"region": "us-east"}, false},
+		{DropIn{Unit: "foo.service", Metadata: map[string]string{"region": "us-west", "az": "2"}}, "foo.service", map[string]string{"region": "us-west", "az": "1"}, false},
+		{DropIn{Unit: "foo.service", Metadata: map[string]string{"region": "us-west"}}, "foo.service", nil, false},
+	}
+
+	for i, tt := range tests {
+		if got := tt.dropIn.AppliesTo(tt.name, tt.metadata); got != tt.want {
+			t.Errorf("case %d: expected %t, got %t", i, tt.want, got)
+		}
+	}
+}
+
+func TestHashDropIns(t *testing.T) {
+	a := DropIn{Name: "10-a.conf", Contents: "[Service]\nEnvironment=A=1\n"}
+	b := DropIn{Name: "20-b.conf", Contents: "[Service]\nEnvironment=B=1\n"}
+
+	if h := HashDropIns(nil); h != "" {
+		t.Errorf("expected empty hash without drop-ins, got %q", h)
+	}
+	if HashDropIns([]DropIn{a, b}) != HashDropIns([]DropIn{b, a}) {
+		t.Errorf("hash depends on order of drop-ins")
+	}
+	if HashDropIns([]DropIn{a}) == HashDropIns([]DropIn{a, b}) {
+		t.Errorf("hash does not depend on set of drop-ins")
+	}
+
+	changed := b
+	changed.Contents = "[Service]\nEnvironment=B=2\n"
+	if HashDropIns([]DropIn{a, b}) == HashDropIns([]DropIn{a, changed}) {
+		t.Errorf("hash does not depend on contents of drop-ins")
+	}
+}
+
+func TestUnitFileWithDropIns(t *testing.T) {
+	uf, err := NewUnitFile("[Service]\nExecStart=/bin/foo\nEnvironment=A=1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := uf.WithDropIns([]DropIn{
+		{Name: "20-b.conf", Contents: "[Service]\nEnvironment=A=3\n"},
+		{Name: "10-a.conf", Contents: "[Service]\nEnvironment=A=2\nRestart=always\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"ExecStart":   {"/bin/foo"},
+		"Environment": {"A=1", "A=2", "A=3"},
+		"Restart":     {"always"},
+	}
+	if !reflect.DeepEqual(want, merged.Contents["Service"]) {
+		t.Errorf("unexpected merged options %v", merged.Contents["Service"])
+	}
+	if len(uf.Options) != 2 {
+		t.Errorf("original unit file was modified: %v", uf.Contents)
+	}
+
+	if _, err := uf.WithDropIns([]DropIn{{Name: "bad.conf", Contents: "[Service\n"}}); err == nil {
+		t.Errorf("expected error for malformed drop-in")
+	}
+}
+
+func TestWriteReadDropIns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet-dropins-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dropIns := []DropIn{
+		{Unit: "foo.service", Name: "20-b.conf", Contents: "[Service]\nEnvironment=B=1\n"},
+		{Unit: "foo.service", Name: "10-a.conf", Contents: "[Service]\nEnvironment=A=1\n"},
+	}
+	if err := WriteDropIns(dir, "foo.service", dropIns); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadDropIns(dir, "foo.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []DropIn{dropIns[1], dropIns[0]}; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected drop-ins\nwant %#v\ngot  %#v", want, got)
+	}
+
+	// writing again replaces all drop-ins
+	if err := WriteDropIns(dir, "foo.service", dropIns[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadDropIns(dir, "foo.service"); err != nil || len(got) != 1 || got[0].Name != "20-b.conf" {
+		t.Errorf("unexpected drop-ins %#v: %v", got, err)
+	}
+
+	if err := RemoveDropIns(dir, "foo.service"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadDropIns(dir, "foo.service"); err != nil || len(got) != 0 {
+		t.Errorf("unexpected drop-ins after removal %#v: %v", got, err)
+	}
+}
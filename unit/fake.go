@@ -21,19 +21,22 @@ import (
 )
 
 func NewFakeUnitManager() *FakeUnitManager {
-	return &FakeUnitManager{u: map[string]bool{}}
+	return &FakeUnitManager{u: map[string]bool{}, completed: map[string]bool{}, dropIns: map[string]string{}}
 }
 
 type FakeUnitManager struct {
 	sync.RWMutex
-	u map[string]bool
+	u         map[string]bool
+	completed map[string]bool
+	dropIns   map[string]string
 }
 
-func (fum *FakeUnitManager) Load(name string, u UnitFile) error {
+func (fum *FakeUnitManager) Load(name string, u UnitFile, dropIns []DropIn) error {
 	fum.Lock()
 	defer fum.Unlock()
 
 	fum.u[name] = false
+	fum.dropIns[name] = HashDropIns(dropIns)
 	return nil
 }
 
@@ -46,6 +49,8 @@ func (fum *FakeUnitManager) Unload(name string) error {
 	defer fum.Unlock()
 
 	delete(fum.u, name)
+	delete(fum.completed, name)
+	delete(fum.dropIns, name)
 	return nil
 }
 
@@ -72,6 +77,7 @@ func (fum *FakeUnitManager) GetUnitState(name string) (us *UnitState, err error)
 			LoadState:   "loaded",
 			ActiveState: "active",
 			SubState:    "running",
+			DropInsHash: fum.dropIns[name],
 		}
 	}
 	return
@@ -84,13 +90,29 @@ func (fum *FakeUnitManager) GetUnitStates(filter pkg.Set) (map[string]*UnitState
 	states := make(map[string]*UnitState)
 	for _, name := range filter.Values() {
 		if _, ok := fum.u[name]; ok {
-			states[name] = &UnitState{"loaded", "active", "running", "", "", name}
+			states[name] = &UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", UnitName: name, DropInsHash: fum.dropIns[name]}
 		}
 	}
 
 	return states, nil
 }
 
+// Complete makes the named unit appear to have run to completion.
+func (fum *FakeUnitManager) Complete(name string) {
+	fum.Lock()
+	defer fum.Unlock()
+
+	fum.completed[name] = true
+}
+
+func (fum *FakeUnitManager) UnitCompleted(name string) (bool, error) {
+	fum.RLock()
+	defer fum.RUnlock()
+
+	_, ok := fum.u[name]
+	return ok && fum.completed[name], nil
+}
+
 func (fum *FakeUnitManager) MarshalJSON() ([]byte, error) {
 	return nil, nil
 }
@@ -37,7 +37,7 @@ func TestFakeUnitManagerEmpty(t *testing.T) {
 func TestFakeUnitManagerLoadUnload(t *testing.T) {
 	fum := NewFakeUnitManager()
 
-	err := fum.Load("hello.service", UnitFile{})
+	err := fum.Load("hello.service", UnitFile{}, nil)
 	if err != nil {
 		t.Fatalf("Expected no error from Load(), got %v", err)
 	}
@@ -92,7 +92,7 @@ func TestFakeUnitManagerLoadUnload(t *testing.T) {
 func TestFakeUnitManagerGetUnitStates(t *testing.T) {
 	fum := NewFakeUnitManager()
 
-	err := fum.Load("hello.service", UnitFile{})
+	err := fum.Load("hello.service", UnitFile{}, nil)
 	if err != nil {
 		t.Fatalf("Expected no error from Load(), got %v", err)
 	}
@@ -22,20 +22,38 @@ import (
 	"github.com/coreos/fleet/pkg"
 )
 
+const (
+	// pollInterval is how often unit states are polled from a
+	// UnitManager that cannot report changes as they happen
+	pollInterval = time.Second
+
+	// resyncInterval is how often unit states are polled from a
+	// UnitStateNotifier, as a safety net against missed changes
+	resyncInterval = 10 * time.Second
+)
+
 type UnitStateHeartbeat struct {
 	Name  string
 	State *UnitState
 }
 
-func NewUnitStateGenerator(mgr UnitManager) *UnitStateGenerator {
+// HealthSource reports the health of units as determined by their health
+// checks, i.e. UnitHealthy, UnitUnhealthy or an empty string if unknown.
+type HealthSource interface {
+	Health(name string) string
+}
+
+func NewUnitStateGenerator(mgr UnitManager, health HealthSource) *UnitStateGenerator {
 	return &UnitStateGenerator{
 		mgr:        mgr,
+		health:     health,
 		subscribed: pkg.NewThreadsafeSet(),
 	}
 }
 
 type UnitStateGenerator struct {
-	mgr UnitManager
+	mgr    UnitManager
+	health HealthSource
 
 	subscribed     pkg.Set
 	lastSubscribed pkg.Set
@@ -52,14 +70,64 @@ func (g *UnitStateGenerator) MarshalJSON() ([]byte, error) {
 }
 
 // Run periodically calls Generate and sends received *UnitStateHeartbeat
-// objects to the provided channel.
+// objects to the provided channel. If the UnitManager is a UnitStateNotifier,
+// changes to the state of subscribed units are sent as they happen, and
+// Generate is only called every resyncInterval. Should the UnitManager stop
+// reporting changes, Generate is called every pollInterval again until the
+// UnitManager can be subscribed to anew.
 func (g *UnitStateGenerator) Run(receiver chan<- *UnitStateHeartbeat, stop <-chan struct{}) {
-	tick := time.Tick(time.Second)
+	n, notifies := g.mgr.(UnitStateNotifier)
+
+	// changes stays nil, i.e. never ready, unless the UnitManager
+	// reports changes
+	var changes chan *UnitStateHeartbeat
+	// resubscribe is only ready while the UnitManager could report
+	// changes, but does not
+	var resubscribe <-chan time.Time
+	subscribe := func() {
+		c := make(chan *UnitStateHeartbeat)
+		if err := n.NotifyUnitStates(c, stop); err != nil {
+			log.Errorf("Failed subscribing to unit state changes, polling instead: %v", err)
+			resubscribe = time.After(resyncInterval)
+			return
+		}
+		changes = c
+	}
+
+	interval := pollInterval
+	if notifies {
+		subscribe()
+		if changes != nil {
+			interval = resyncInterval
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer func() { ticker.Stop() }()
 	for {
 		select {
 		case <-stop:
 			return
-		case <-tick:
+		case ush, ok := <-changes:
+			if !ok {
+				log.Errorf("Lost subscription to unit state changes, polling instead")
+				changes = nil
+				resubscribe = time.After(resyncInterval)
+				ticker.Stop()
+				ticker = time.NewTicker(pollInterval)
+				continue
+			}
+			if ush = g.filter(ush); ush != nil {
+				receiver <- ush
+			}
+		case <-resubscribe:
+			resubscribe = nil
+			subscribe()
+			if changes != nil {
+				ticker.Stop()
+				ticker = time.NewTicker(resyncInterval)
+			}
+		case <-ticker.C:
 			beatchan, err := g.Generate()
 			if err != nil {
 				log.Errorf("Failed fetching current unit states: %v", err)
@@ -73,6 +141,17 @@ func (g *UnitStateGenerator) Run(receiver chan<- *UnitStateHeartbeat, stop <-cha
 	}
 }
 
+// filter returns the given *UnitStateHeartbeat reported by the UnitManager
+// with the health of the unit added, or nil if this generator is not
+// subscribed to the unit.
+func (g *UnitStateGenerator) filter(ush *UnitStateHeartbeat) *UnitStateHeartbeat {
+	if ush.State == nil || !g.subscribed.Contains(ush.Name) {
+		return nil
+	}
+	g.addHealth(ush.Name, ush.State)
+	return ush
+}
+
 // Generate returns and fills a channel with *UnitStateHeartbeat objects. Objects will
 // only be returned for units to which this generator is currently subscribed.
 func (g *UnitStateGenerator) Generate() (<-chan *UnitStateHeartbeat, error) {
@@ -89,6 +168,10 @@ func (g *UnitStateGenerator) Generate() (<-chan *UnitStateHeartbeat, error) {
 		return nil, err
 	}
 
+	for name, us := range reportable {
+		g.addHealth(name, us)
+	}
+
 	beatchan := make(chan *UnitStateHeartbeat)
 	go func() {
 		for name, us := range reportable {
@@ -116,6 +199,14 @@ func (g *UnitStateGenerator) Generate() (<-chan *UnitStateHeartbeat, error) {
 	return beatchan, nil
 }
 
+// addHealth sets the health of the given unit, which is only meaningful
+// while the unit is active
+func (g *UnitStateGenerator) addHealth(name string, us *UnitState) {
+	if g.health != nil && us != nil && us.ActiveState == "active" {
+		us.Health = g.health.Health(name)
+	}
+}
+
 // Subscribe adds a unit to the internal state filter
 func (g *UnitStateGenerator) Subscribe(name string) {
 	g.subscribed.Add(name)
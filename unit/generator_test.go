@@ -17,6 +17,7 @@ package unit
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func assertGenerateUnitStateHeartbeats(t *testing.T, um UnitManager, gen *UnitStateGenerator, expect []UnitStateHeartbeat) {
@@ -38,9 +39,9 @@ func assertGenerateUnitStateHeartbeats(t *testing.T, um UnitManager, gen *UnitSt
 
 func TestUnitStateGeneratorSubscribeLifecycle(t *testing.T) {
 	um := NewFakeUnitManager()
-	um.Load("foo.service", UnitFile{})
+	um.Load("foo.service", UnitFile{}, nil)
 
-	gen := NewUnitStateGenerator(um)
+	gen := NewUnitStateGenerator(um, nil)
 
 	// not subscribed to anything yet, so no heartbeats
 	assertGenerateUnitStateHeartbeats(t, um, gen, []UnitStateHeartbeat{})
@@ -49,7 +50,7 @@ func TestUnitStateGeneratorSubscribeLifecycle(t *testing.T) {
 
 	// subscribed to foo.service so we should get a heartbeat
 	expect := []UnitStateHeartbeat{
-		UnitStateHeartbeat{Name: "foo.service", State: &UnitState{"loaded", "active", "running", "", "", "foo.service"}},
+		UnitStateHeartbeat{Name: "foo.service", State: &UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", UnitName: "foo.service"}},
 	}
 	assertGenerateUnitStateHeartbeats(t, um, gen, expect)
 
@@ -67,7 +68,7 @@ func TestUnitStateGeneratorSubscribeLifecycle(t *testing.T) {
 
 func TestUnitStateGeneratorNoState(t *testing.T) {
 	um := NewFakeUnitManager()
-	gen := NewUnitStateGenerator(um)
+	gen := NewUnitStateGenerator(um, nil)
 
 	// not subscribed to anything yet, so no heartbeats
 	assertGenerateUnitStateHeartbeats(t, um, gen, []UnitStateHeartbeat{})
@@ -77,3 +78,101 @@ func TestUnitStateGeneratorNoState(t *testing.T) {
 	// subscribed to foo.service but no underlying state so no heartbeat
 	assertGenerateUnitStateHeartbeats(t, um, gen, []UnitStateHeartbeat{})
 }
+
+type fakeHealthSource map[string]string
+
+func (f fakeHealthSource) Health(name string) string {
+	return f[name]
+}
+
+func TestUnitStateGeneratorHealth(t *testing.T) {
+	um := NewFakeUnitManager()
+	um.Load("foo.service", UnitFile{}, nil)
+
+	gen := NewUnitStateGenerator(um, fakeHealthSource{"foo.service": UnitUnhealthy})
+	gen.Subscribe("foo.service")
+
+	expect := []UnitStateHeartbeat{
+		UnitStateHeartbeat{Name: "foo.service", State: &UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", UnitName: "foo.service", Health: UnitUnhealthy}},
+	}
+	assertGenerateUnitStateHeartbeats(t, um, gen, expect)
+}
+
+type fakeNotifyingUnitManager struct {
+	*FakeUnitManager
+	notified chan chan<- *UnitStateHeartbeat
+}
+
+func (f *fakeNotifyingUnitManager) NotifyUnitStates(changes chan<- *UnitStateHeartbeat, stop <-chan struct{}) error {
+	f.notified <- changes
+	return nil
+}
+
+func TestUnitStateGeneratorRunNotified(t *testing.T) {
+	um := &fakeNotifyingUnitManager{
+		FakeUnitManager: NewFakeUnitManager(),
+		notified:        make(chan chan<- *UnitStateHeartbeat, 1),
+	}
+	gen := NewUnitStateGenerator(um, fakeHealthSource{"foo.service": UnitHealthy})
+	gen.Subscribe("foo.service")
+
+	receiver := make(chan *UnitStateHeartbeat)
+	stop := make(chan struct{})
+	defer close(stop)
+	go gen.Run(receiver, stop)
+
+	var changes chan<- *UnitStateHeartbeat
+	select {
+	case changes = <-um.notified:
+	case <-time.After(time.Second):
+		t.Fatalf("Run did not subscribe to unit state changes")
+	}
+
+	// changes of units the generator is not subscribed to are dropped
+	changes <- &UnitStateHeartbeat{Name: "bar.service", State: &UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running"}}
+	changes <- &UnitStateHeartbeat{Name: "foo.service", State: &UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running"}}
+
+	select {
+	case ush := <-receiver:
+		expect := &UnitStateHeartbeat{Name: "foo.service", State: &UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", Health: UnitHealthy}}
+		if !reflect.DeepEqual(ush, expect) {
+			t.Errorf("got %#v, expected %#v", ush, expect)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("change of foo.service was not sent")
+	}
+}
+
+func TestUnitStateGeneratorRunLostNotifications(t *testing.T) {
+	um := &fakeNotifyingUnitManager{
+		FakeUnitManager: NewFakeUnitManager(),
+		notified:        make(chan chan<- *UnitStateHeartbeat, 1),
+	}
+	um.Load("foo.service", UnitFile{}, nil)
+	gen := NewUnitStateGenerator(um, nil)
+	gen.Subscribe("foo.service")
+
+	receiver := make(chan *UnitStateHeartbeat)
+	stop := make(chan struct{})
+	defer close(stop)
+	go gen.Run(receiver, stop)
+
+	var changes chan<- *UnitStateHeartbeat
+	select {
+	case changes = <-um.notified:
+	case <-time.After(time.Second):
+		t.Fatalf("Run did not subscribe to unit state changes")
+	}
+
+	// once changes are no longer reported, states are polled again
+	// well before the next resync
+	close(changes)
+	select {
+	case ush := <-receiver:
+		if ush.Name != "foo.service" || ush.State == nil {
+			t.Errorf("unexpected heartbeat %#v", ush)
+		}
+	case <-time.After(3 * pollInterval):
+		t.Errorf("unit states were not polled after losing notifications")
+	}
+}
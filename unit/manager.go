@@ -19,7 +19,8 @@ import (
 )
 
 type UnitManager interface {
-	Load(string, UnitFile) error
+	// Load writes the given unit file along with the given drop-ins
+	Load(string, UnitFile, []DropIn) error
 	Unload(string) error
 	ReloadUnitFiles() error
 
@@ -29,4 +30,17 @@ type UnitManager interface {
 	Units() ([]string, error)
 	GetUnitStates(pkg.Set) (map[string]*UnitState, error)
 	GetUnitState(string) (*UnitState, error)
+	// UnitCompleted determines whether the named unit was started and
+	// has since exited successfully
+	UnitCompleted(string) (bool, error)
+}
+
+// UnitStateNotifier is implemented by UnitManagers that can report changes
+// to the state of units as they happen, rather than only when polled.
+type UnitStateNotifier interface {
+	// NotifyUnitStates sends a UnitStateHeartbeat to the given channel
+	// each time the state of a unit changes, until stop is closed. If
+	// changes can no longer be reported before stop is closed, the
+	// channel is closed.
+	NotifyUnitStates(chan<- *UnitStateHeartbeat, <-chan struct{}) error
 }
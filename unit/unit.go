@@ -193,6 +193,13 @@ func HashFromHexString(key string) (Hash, error) {
 	return h, nil
 }
 
+const (
+	// UnitHealthy means all health checks of an active unit pass
+	UnitHealthy = "healthy"
+	// UnitUnhealthy means some health check of an active unit fails
+	UnitUnhealthy = "unhealthy"
+)
+
 // UnitState encodes the current state of a unit loaded into a fleet agent
 type UnitState struct {
 	LoadState   string
@@ -201,6 +208,46 @@ type UnitState struct {
 	MachineID   string
 	UnitHash    string
 	UnitName    string
+	// Health is the outcome of the health checks of an active unit, or an
+	// empty string if the unit has none or is not active
+	Health string
+	// DropInsHash identifies the drop-ins the unit was loaded with, see
+	// HashDropIns. It is only used by the agent to detect changed drop-ins
+	// and is not published.
+	DropInsHash string
+
+	// MainPID is the PID of the main process of a service unit, or 0 if
+	// it has none
+	MainPID uint32
+	// ExecMainStatus is the exit status of the last main process of a
+	// service unit
+	ExecMainStatus int32
+	// NRestarts is how often systemd restarted a service unit
+	// automatically since it was loaded
+	NRestarts uint32
+	// ActiveEnterTimestamp is when the unit last became active, in
+	// microseconds since the epoch, or 0 if it never did
+	ActiveEnterTimestamp uint64
+	// Result is how the last run of a service unit ended, e.g.
+	// "success" or "exit-code"
+	Result string
+
+	// Usage is the resource usage of an active service unit, or nil if
+	// it is unknown
+	Usage *UnitUsage
+}
+
+// UnitUsage is the resource usage of a unit as accounted in its cgroup.
+// Usage which is not accounted is reported as 0.
+type UnitUsage struct {
+	// MemoryCurrent and MemoryPeak are the current and the highest
+	// memory usage in bytes
+	MemoryCurrent uint64
+	MemoryPeak    uint64
+	// CPUUsageNSec is the CPU time consumed, in nanoseconds
+	CPUUsageNSec uint64
+	// Tasks is the number of processes and threads
+	Tasks uint64
 }
 
 func NewUnitState(loadState, activeState, subState, mID string) *UnitState {
@@ -32,12 +32,39 @@ func (unitFile *UnitFile) ToPB() pb.UnitFile {
 }
 
 func (s UnitState) ToPB() *pb.UnitState {
-	return &pb.UnitState{
-		Name:        s.UnitName,
-		Hash:        s.UnitHash,
-		LoadState:   s.LoadState,
-		ActiveState: s.ActiveState,
-		SubState:    s.SubState,
-		MachineID:   s.MachineID,
+	us := &pb.UnitState{
+		Name:                 s.UnitName,
+		Hash:                 s.UnitHash,
+		LoadState:            s.LoadState,
+		ActiveState:          s.ActiveState,
+		SubState:             s.SubState,
+		MachineID:            s.MachineID,
+		Health:               s.Health,
+		MainPID:              s.MainPID,
+		ExecMainStatus:       s.ExecMainStatus,
+		NRestarts:            s.NRestarts,
+		ActiveEnterTimestamp: s.ActiveEnterTimestamp,
+		Result:               s.Result,
+	}
+	if s.Usage != nil {
+		us.MemoryCurrent = s.Usage.MemoryCurrent
+		us.MemoryPeak = s.Usage.MemoryPeak
+		us.CPUUsageNSec = s.Usage.CPUUsageNSec
+		us.Tasks = s.Usage.Tasks
+	}
+	return us
+}
+
+// UnitUsageFromPB returns the resource usage carried by the given protobuf
+// UnitState, or nil if it carries none.
+func UnitUsageFromPB(s *pb.UnitState) *UnitUsage {
+	if s.MemoryCurrent == 0 && s.MemoryPeak == 0 && s.CPUUsageNSec == 0 && s.Tasks == 0 {
+		return nil
+	}
+	return &UnitUsage{
+		MemoryCurrent: s.MemoryCurrent,
+		MemoryPeak:    s.MemoryPeak,
+		CPUUsageNSec:  s.CPUUsageNSec,
+		Tasks:         s.Tasks,
 	}
 }
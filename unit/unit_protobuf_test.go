@@ -59,6 +59,14 @@ func TestUnitStateProtoBuf(t *testing.T) {
 		MachineID:   "machine1",
 		UnitHash:    "heh",
 		UnitName:    "foo",
+
+		MainPID:              1234,
+		ExecMainStatus:       -1,
+		NRestarts:            3,
+		ActiveEnterTimestamp: 1476700800000000,
+		Result:               "exit-code",
+
+		Usage: &UnitUsage{MemoryCurrent: 1 << 30, MemoryPeak: 1 << 31, CPUUsageNSec: 1 << 40, Tasks: 12},
 	}
 
 	got := want.ToPB()
@@ -72,8 +80,37 @@ func TestUnitStateProtoBuf(t *testing.T) {
 		ActiveState: "bar",
 		SubState:    "baz",
 		MachineID:   "machine1",
+
+		MainPID:              1234,
+		ExecMainStatus:       -1,
+		NRestarts:            3,
+		ActiveEnterTimestamp: 1476700800000000,
+		Result:               "exit-code",
+		MemoryCurrent:        1 << 30,
+		MemoryPeak:           1 << 31,
+		CPUUsageNSec:         1 << 40,
+		Tasks:                12,
 	}
 	if !reflect.DeepEqual(got, expect) {
 		t.Fatalf("got %#v, expected %#v", got, expect)
 	}
+
+	data, err := got.Marshal()
+	if err != nil {
+		t.Fatalf("Unexpected error marshaling unit state: %v", err)
+	}
+	decoded := &pb.UnitState{}
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unexpected error unmarshaling unit state: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, expect) {
+		t.Fatalf("got %#v after a round trip, expected %#v", decoded, expect)
+	}
+
+	if usage := UnitUsageFromPB(decoded); !reflect.DeepEqual(usage, want.Usage) {
+		t.Fatalf("got usage %#v, expected %#v", usage, want.Usage)
+	}
+	if usage := UnitUsageFromPB(&pb.UnitState{}); usage != nil {
+		t.Fatalf("expected no usage, got %#v", usage)
+	}
 }